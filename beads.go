@@ -114,6 +114,7 @@ const (
 	SortPolicyHybrid   = types.SortPolicyHybrid
 	SortPolicyPriority = types.SortPolicyPriority
 	SortPolicyOldest   = types.SortPolicyOldest
+	SortPolicyScore    = types.SortPolicyScore
 )
 
 // EventType constants
@@ -129,4 +130,5 @@ const (
 	EventLabelAdded        = types.EventLabelAdded
 	EventLabelRemoved      = types.EventLabelRemoved
 	EventCompacted         = types.EventCompacted
+	EventValidated         = types.EventValidated
 )
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/utils"
+)
+
+var approveCmd = &cobra.Command{
+	Use:     "approve <gate-id>",
+	GroupID: "issues",
+	Short:   "Approve or reject an approval gate",
+	Long: `Record your decision on an approval gate and re-evaluate it.
+
+Approval gates (await type "approval") resolve once the required number of
+approvers have approved. Their await_id is <n>[ of <approver>,...], where an
+approver is a name or @<role> for the names under gate.approval.roles.<role>
+in config:
+
+  2                         # any two approvers
+  2 of alice,bob,carol      # two of these three
+  2 of @release-managers    # two members of a role
+
+Each decision is appended to the gate's validations; an approver's latest
+decision counts. Any standing rejection escalates the gate (see 'bd gate
+check' for escalation). You approve as your actor name (--actor, BD_ACTOR or
+git user.name).
+
+Examples:
+  bd approve bd-mol-abc.gate-release
+  bd approve bd-mol-abc.gate-release --reject --reason "changelog missing"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("approve")
+
+		reject, _ := cmd.Flags().GetBool("reject")
+		reason, _ := cmd.Flags().GetString("reason")
+
+		ctx := rootCtx
+		gateID, err := utils.ResolvePartialID(ctx, store, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		gate, err := store.GetIssue(ctx, gateID)
+		if err != nil || gate == nil {
+			fmt.Fprintf(os.Stderr, "Error: gate %s not found\n", gateID)
+			os.Exit(1)
+		}
+		if gate.IssueType != "gate" || gate.AwaitType != approvalGateType {
+			fmt.Fprintf(os.Stderr, "Error: %s is not an approval gate\n", gateID)
+			os.Exit(1)
+		}
+		if gate.Status == types.StatusClosed {
+			fmt.Fprintf(os.Stderr, "Error: gate %s is already closed\n", gateID)
+			os.Exit(1)
+		}
+
+		spec, err := parseApprovalGate(gate.AwaitID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		approver := getActorWithGit()
+		if !spec.Allows(approver) {
+			fmt.Fprintf(os.Stderr, "Error: %s is not an approver of %s (approvers: %s)\n",
+				approver, gateID, strings.Join(spec.Approvers, ", "))
+			os.Exit(1)
+		}
+
+		validation := types.Validation{
+			Validator: &types.EntityRef{Name: approver},
+			Outcome:   types.ValidationAccepted,
+			Timestamp: time.Now().UTC(),
+			Reason:    reason,
+		}
+		if reject {
+			validation.Outcome = types.ValidationRejected
+		}
+		if err := store.AddValidation(ctx, gateID, validation, approver); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !jsonOutput {
+			if reject {
+				fmt.Printf("%s %s rejected %s\n", ui.RenderFail("✗"), approver, ui.RenderID(gateID))
+			} else {
+				fmt.Printf("%s %s approved %s\n", ui.RenderPass("✓"), approver, ui.RenderID(gateID))
+			}
+		}
+
+		// Re-evaluate the gate with the new decision
+		gate.Validations = append(gate.Validations, validation)
+		now := time.Now()
+		result := gateCheckResult{gate: gate}
+		result.resolved, result.escalated, result.reason, result.err = checkApprovalGate(gate, now)
+		outcome := applyGateResult(ctx, result, now, false, true)
+
+		var unblocked []string
+		if outcome == gateOutcomeResolved || outcome == gateOutcomeEscalated {
+			unblocked = announceUnblocked(ctx, gateID)
+		}
+
+		if jsonOutput {
+			status := map[gateOutcome]string{
+				gateOutcomePending:   "pending",
+				gateOutcomeResolved:  "resolved",
+				gateOutcomeEscalated: "escalated",
+				gateOutcomeError:     "error",
+			}[outcome]
+			outputJSON(map[string]interface{}{
+				"gate":      gateID,
+				"approver":  approver,
+				"outcome":   validation.Outcome,
+				"reason":    reason,
+				"status":    status,
+				"detail":    result.reason,
+				"unblocked": unblocked,
+			})
+		}
+	},
+}
+
+func init() {
+	approveCmd.Flags().Bool("reject", false, "Reject instead of approving (escalates the gate)")
+	approveCmd.Flags().StringP("reason", "r", "", "Reason for the decision")
+	approveCmd.ValidArgsFunction = issueIDCompletion
+	rootCmd.AddCommand(approveCmd)
+}
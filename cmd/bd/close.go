@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/gitlab"
 	"github.com/steveyegge/beads/internal/hooks"
+	"github.com/steveyegge/beads/internal/storage/dolt"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/ui"
 	"github.com/steveyegge/beads/internal/utils"
@@ -119,26 +123,34 @@ create, update, show, or close operation).`,
 				}
 			}
 
-			if err := store.CloseIssue(ctx, id, reason, actor, session); err != nil {
+			// A before_close hook may veto the close or patch it
+			closeReason, patched, err := runCloseBeforeHook(issue, reason)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+
+			if err := closeWithPatch(ctx, store, id, closeReason, session, patched); err != nil {
 				fmt.Fprintf(os.Stderr, "Error closing %s: %v\n", id, err)
 				continue
 			}
 
 			closedCount++
 
-			// Run close hook (best effort: hook runs only if re-fetch succeeds)
+			// Run close hooks (best effort: hooks run only if re-fetch succeeds)
 			closedIssue, _ := store.GetIssue(ctx, id)
-			if closedIssue != nil && hookRunner != nil {
-				hookRunner.Run(hooks.EventClose, closedIssue)
-			}
+			emitChangeHooks(hooks.EventClose, issue, closedIssue)
+			emitUnblockedHooks(ctx, store, id)
 
 			if jsonOutput {
 				if closedIssue != nil {
 					closedIssues = append(closedIssues, closedIssue)
 				}
 			} else {
-				fmt.Printf("%s Closed %s: %s\n", ui.RenderPass("✓"), id, reason)
+				fmt.Printf("%s Closed %s: %s\n", ui.RenderPass("✓"), id, closeReason)
 			}
+
+			pourFanOutOnClose(ctx, store, id)
 		}
 
 		// Handle routed IDs (cross-rig)
@@ -186,7 +198,15 @@ create, update, show, or close operation).`,
 				}
 			}
 
-			if err := result.Store.CloseIssue(ctx, result.ResolvedID, reason, actor, session); err != nil {
+			// A before_close hook may veto the close or patch it
+			closeReason, patched, err := runCloseBeforeHook(result.Issue, reason)
+			if err != nil {
+				result.Close()
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				continue
+			}
+
+			if err := closeWithPatch(ctx, result.Store, result.ResolvedID, closeReason, session, patched); err != nil {
 				result.Close()
 				fmt.Fprintf(os.Stderr, "Error closing %s: %v\n", id, err)
 				continue
@@ -194,19 +214,20 @@ create, update, show, or close operation).`,
 
 			closedCount++
 
-			// Get updated issue for hook (best effort: hook runs only if re-fetch succeeds)
+			// Get updated issue for hooks (best effort: hooks run only if re-fetch succeeds)
 			closedIssue, _ := result.Store.GetIssue(ctx, result.ResolvedID)
-			if closedIssue != nil && hookRunner != nil {
-				hookRunner.Run(hooks.EventClose, closedIssue)
-			}
+			emitChangeHooks(hooks.EventClose, result.Issue, closedIssue)
+			emitUnblockedHooks(ctx, result.Store, result.ResolvedID)
 
 			if jsonOutput {
 				if closedIssue != nil {
 					closedIssues = append(closedIssues, closedIssue)
 				}
 			} else {
-				fmt.Printf("%s Closed %s: %s\n", ui.RenderPass("✓"), result.ResolvedID, reason)
+				fmt.Printf("%s Closed %s: %s\n", ui.RenderPass("✓"), result.ResolvedID, closeReason)
 			}
+
+			pourFanOutOnClose(ctx, result.Store, result.ResolvedID)
 			result.Close()
 		}
 
@@ -268,7 +289,38 @@ func init() {
 	rootCmd.AddCommand(closeCmd)
 }
 
+// closeWithPatch closes id. Fields a before_close hook patched are set in
+// the same update as the close.
+func closeWithPatch(ctx context.Context, s *dolt.DoltStore, id, reason, session string, patched map[string]interface{}) error {
+	if len(patched) == 0 {
+		return s.CloseIssue(ctx, id, reason, actor, session)
+	}
+	updates := maps.Clone(patched)
+	updates["status"] = string(types.StatusClosed)
+	updates["close_reason"] = reason
+	updates["closed_by_session"] = session
+	return s.UpdateIssue(ctx, id, updates, actor)
+}
+
+// pourFanOutOnClose pours the closed step's on_complete fan-out, if any,
+// and reports the outcome.
+func pourFanOutOnClose(ctx context.Context, s *dolt.DoltStore, id string) {
+	fanOut, err := fanOutOnClose(ctx, s, id, actor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: on_complete fan-out for %s failed: %v\n", id, err)
+	} else if fanOut != nil && !jsonOutput {
+		if fanOut.Result == advanceSpawned {
+			fmt.Printf("  Poured %d %s molecule(s) for %s\n", fanOut.Items, fanOut.Bond, fanOut.Condition)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: on_complete fan-out for %s: %s\n", id, fanOut.Reason)
+		}
+	}
+}
+
 // isMachineCheckableGate returns true if the issue is a gate with a machine-checkable await type.
+// Only gates that are read-only to evaluate qualify: cmd gates run a
+// user-configured command, which a close must not do as a side effect, and
+// are left to 'bd gate check'.
 func isMachineCheckableGate(issue *types.Issue) bool {
 	if issue == nil || issue.IssueType != "gate" {
 		return false
@@ -278,10 +330,16 @@ func isMachineCheckableGate(issue *types.Issue) bool {
 		return true
 	case strings.HasPrefix(issue.AwaitType, "gh:run"):
 		return true
+	case isGitLabGate(issue.AwaitType):
+		return true
 	case issue.AwaitType == "timer":
 		return true
 	case issue.AwaitType == "bead":
 		return true
+	case issue.AwaitType == queryGateType:
+		return true
+	case issue.AwaitType == approvalGateType:
+		return true
 	default:
 		return false
 	}
@@ -304,6 +362,11 @@ func checkGateSatisfaction(issue *types.Issue) error {
 		resolved, escalated, reason, err = checkGHRun(issue)
 	case strings.HasPrefix(issue.AwaitType, "gh:pr"):
 		resolved, escalated, reason, err = checkGHPR(issue)
+	case isGitLabGate(issue.AwaitType):
+		var client *gitlab.Client
+		if client, err = newGitLabGateClient(); err == nil {
+			resolved, escalated, reason, err = checkGitLabGate(rootCtx, client, issue)
+		}
 	case issue.AwaitType == "timer":
 		resolved, escalated, reason, err = checkTimer(issue, time.Now())
 	case issue.AwaitType == "bead":
@@ -312,6 +375,10 @@ func checkGateSatisfaction(issue *types.Issue) error {
 			return nil
 		}
 		return fmt.Errorf("gate condition not satisfied: %s (use --force to override)", reason)
+	case issue.AwaitType == queryGateType:
+		resolved, escalated, reason, err = checkQueryGate(rootCtx, store, issue, time.Now())
+	case issue.AwaitType == approvalGateType:
+		resolved, escalated, reason, err = checkApprovalGate(issue, time.Now())
 	}
 
 	if err != nil {
@@ -65,6 +65,14 @@ func TestIsMachineCheckableGate(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "gate with cmd await type",
+			issue: &types.Issue{
+				IssueType: "gate",
+				AwaitType: "cmd",
+			},
+			want: false,
+		},
 		{
 			name: "gate with empty await type",
 			issue: &types.Issue{
@@ -109,6 +117,15 @@ func TestCheckGateSatisfaction_NonGateIssues(t *testing.T) {
 				Title:     "A bug",
 			},
 		},
+		{
+			name: "cmd gate (left to bd gate check)",
+			issue: &types.Issue{
+				IssueType: "gate",
+				AwaitType: "cmd",
+				AwaitID:   "exit 1",
+				Title:     "Cmd gate",
+			},
+		},
 		{
 			name: "gate with human await (not machine-checkable)",
 			issue: &types.Issue{
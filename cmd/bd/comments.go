@@ -134,6 +134,7 @@ Examples:
 		if err != nil {
 			FatalErrorRespectJSON("adding comment: %v", err)
 		}
+		emitCommentHook(ctx, store, comment)
 
 		if jsonOutput {
 			outputJSON(comment)
@@ -211,9 +211,7 @@ func loadAndResolveFormula(formulaPath string, searchPaths []string) (*formula.F
 			if aspectFormula.Type != formula.TypeAspect {
 				return nil, fmt.Errorf("%q is not an aspect formula (type=%s)", aspectName, aspectFormula.Type)
 			}
-			if len(aspectFormula.Advice) > 0 {
-				resolved.Steps = formula.ApplyAdvice(resolved.Steps, aspectFormula.Advice)
-			}
+			resolved.Steps = formula.ApplyAspect(resolved.Steps, aspectFormula)
 		}
 	}
 
@@ -517,12 +515,55 @@ func createGateIssue(step *formula.Step, parentID string) *types.Issue {
 		AwaitType:   step.Gate.Type,
 		AwaitID:     step.Gate.ID,
 		Timeout:     timeout,
+		Metadata:    gateEscalationMetadata(step.Gate.Escalation),
 		IsTemplate:  true,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 }
 
+// createConditionGateIssues creates a gate issue for each compose.gate rule
+// applied to the step (see formula.ApplyGates). The gates have
+// await_type=condition with the condition expression as await_id, and are
+// closed by 'bd mol advance' once the condition holds.
+func createConditionGateIssues(step *formula.Step, parentID string) []*types.Issue {
+	var gates []*types.Issue
+	for _, label := range step.Labels {
+		condition, ok := formula.ParseGateLabel(label)
+		if !ok {
+			continue
+		}
+
+		// Generate gate issue ID: {parentID}.cond-{step.ID}[-N]
+		gateID := fmt.Sprintf("%s.cond-%s", parentID, step.ID)
+		if len(gates) > 0 {
+			gateID = fmt.Sprintf("%s-%d", gateID, len(gates)+1)
+		}
+
+		gate := newConditionGateIssue(step.ID, condition)
+		gate.ID = gateID
+		gate.IsTemplate = true
+		gates = append(gates, gate)
+	}
+	return gates
+}
+
+// newConditionGateIssue returns an open condition gate for the given step.
+// Also used by bd mol advance to gate the steps of new loop iterations.
+func newConditionGateIssue(stepID, condition string) *types.Issue {
+	return &types.Issue{
+		Title:       fmt.Sprintf("Gate: %s", condition),
+		Description: fmt.Sprintf("Condition gate for step %s (evaluated by bd mol advance)", stepID),
+		Status:      types.StatusOpen,
+		Priority:    2,
+		IssueType:   "gate",
+		AwaitType:   conditionGateType,
+		AwaitID:     condition,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
 // processStepToIssue converts a formula.Step to a types.Issue.
 // The issue includes all fields including Labels populated from step.Labels and waits_for.
 // This is the shared core logic used by both DB-persisted and in-memory cooking.
@@ -566,6 +607,16 @@ func processStepToIssue(step *formula.Step, parentID string) *types.Issue {
 		issue.Labels = append(issue.Labels, gateLabel)
 	}
 
+	// Record on_complete fan-out for the runtime (see fanOutStep)
+	if step.OnComplete != nil && step.OnComplete.ForEach != "" {
+		issue.Labels = append(issue.Labels, formula.OnCompleteLabel(step.OnComplete))
+	}
+
+	// Record the retry policy for bd mol retry
+	if step.MaxRetries > 0 {
+		issue.Labels = append(issue.Labels, formula.MaxRetriesLabel(step.MaxRetries))
+	}
+
 	return issue
 }
 
@@ -609,13 +660,19 @@ func collectSteps(steps []*formula.Step, parentID string,
 			Type:        types.DepParentChild,
 		})
 
-		// Create gate issue if step has a Gate (bd-7zka.2)
+		// Create gate issues: one for the step's Gate (bd-7zka.2) and one for
+		// each compose.gate condition, which bd mol advance evaluates
+		var gateIssues []*types.Issue
 		if step.Gate != nil {
-			gateIssue := createGateIssue(step, parentID)
+			gateIssues = append(gateIssues, createGateIssue(step, parentID))
+		}
+		gateIssues = append(gateIssues, createConditionGateIssues(step, parentID)...)
+
+		for _, gateIssue := range gateIssues {
 			*issues = append(*issues, gateIssue)
 
-			// Add gate to mapping (use gate-{step.ID} as key)
-			gateKey := fmt.Sprintf("gate-%s", step.ID)
+			// Add gate to mapping (gate-{step.ID}, cond-{step.ID}, ...)
+			gateKey := strings.TrimPrefix(gateIssue.ID, parentID+".")
 			idMapping[gateKey] = gateIssue.ID
 			if issueMap != nil {
 				issueMap[gateIssue.ID] = gateIssue
@@ -715,9 +772,7 @@ func resolveAndCookFormulaWithVars(formulaName string, searchPaths []string, con
 			if aspectFormula.Type != formula.TypeAspect {
 				return nil, fmt.Errorf("%q is not an aspect formula (type=%s)", aspectName, aspectFormula.Type)
 			}
-			if len(aspectFormula.Advice) > 0 {
-				resolved.Steps = formula.ApplyAdvice(resolved.Steps, aspectFormula.Advice)
-			}
+			resolved.Steps = formula.ApplyAspect(resolved.Steps, aspectFormula)
 		}
 	}
 
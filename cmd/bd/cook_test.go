@@ -501,3 +501,33 @@ func TestCookFormulaToSubgraph_GateParentChild(t *testing.T) {
 		}
 	}
 }
+
+func TestCookFormulaToSubgraph_OnCompleteLabel(t *testing.T) {
+	oc := &formula.OnCompleteSpec{
+		ForEach: "output.workers",
+		Bond:    "mol-worker-arm",
+		Vars:    map[string]string{"name": "{item.name}"},
+	}
+	f := &formula.Formula{
+		Formula: "mol-fanout",
+		Version: 1,
+		Type:    formula.TypeWorkflow,
+		Steps: []*formula.Step{
+			{ID: "survey", Title: "Survey", OnComplete: oc},
+			{ID: "aggregate", Title: "Aggregate", Needs: []string{"survey"}},
+		},
+	}
+
+	subgraph, err := cookFormulaToSubgraph(f, "mol-fanout")
+	if err != nil {
+		t.Fatalf("cookFormulaToSubgraph failed: %v", err)
+	}
+
+	labels := subgraph.IssueMap["mol-fanout.survey"].Labels
+	if len(labels) != 1 || labels[0] != formula.OnCompleteLabel(oc) {
+		t.Errorf("survey labels = %v, want on_complete label", labels)
+	}
+	if labels := subgraph.IssueMap["mol-fanout.aggregate"].Labels; len(labels) != 0 {
+		t.Errorf("aggregate labels = %v, want none", labels)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/query"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/utils"
 )
@@ -30,6 +31,10 @@ Examples:
   bd count --by-assignee            # Group count by assignee
   bd count --by-label               # Group count by label
   bd count --assignee alice --by-status  # Count alice's issues by status
+  bd count --view triage            # Count issues in a saved view
+
+For other breakdowns and aggregates, use GROUP BY in bd query:
+  bd query "status=open GROUP BY assignee, type, sum(estimate)"
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		status, _ := cmd.Flags().GetString("status")
@@ -69,6 +74,8 @@ Examples:
 		byAssignee, _ := cmd.Flags().GetBool("by-assignee")
 		byLabel, _ := cmd.Flags().GetBool("by-label")
 
+		viewName, _ := cmd.Flags().GetString("view")
+
 		// Determine groupBy value
 		groupBy := ""
 		groupCount := 0
@@ -205,6 +212,21 @@ Examples:
 			filter.PriorityMax = &priorityMax
 		}
 
+		// Saved view: narrow to its query; a GROUP BY view supplies the grouping
+		var viewQuery *query.Query
+		if viewName != "" {
+			_, viewQuery = mustLoadView(viewName)
+			if err := applyView(&filter, viewQuery); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if viewQuery != nil && groupBy == "" && len(viewQuery.GroupBy) > 0 {
+			outputQueryGroups(ctx, store, filter, viewQuery)
+			return
+		}
+
 		issues, err := store.SearchIssues(ctx, "", filter)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -316,6 +338,8 @@ func init() {
 	countCmd.Flags().String("title", "", "Filter by title text (case-insensitive substring match)")
 	countCmd.Flags().String("id", "", "Filter by specific issue IDs (comma-separated)")
 
+	countCmd.Flags().String("view", "", "Narrow to a saved view; GROUP BY views print their groups (see bd views)")
+
 	// Pattern matching
 	countCmd.Flags().String("title-contains", "", "Filter by title substring")
 	countCmd.Flags().String("desc-contains", "", "Filter by description substring")
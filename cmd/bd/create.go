@@ -534,6 +534,19 @@ var createCmd = &cobra.Command{
 			// If error getting parent or parent has no source_repo, continue with default
 		}
 
+		// A before_create hook may veto the issue or patch it, labels included
+		issue.Labels = labels
+		patch, err := runBeforeHook(hooks.EventBeforeCreate, nil, issue, nil)
+		if err != nil {
+			FatalError("%v", err)
+		}
+		if patch != nil {
+			if issue, err = hooks.ApplyPatch(issue, patch); err != nil {
+				FatalError("applying before_create patch: %v", err)
+			}
+		}
+		labels, issue.Labels = issue.Labels, nil
+
 		if err := store.CreateIssue(ctx, issue, actor); err != nil {
 			FatalError("%v", err)
 		}
@@ -672,9 +685,7 @@ var createCmd = &cobra.Command{
 		}
 
 		// Run create hook
-		if hookRunner != nil {
-			hookRunner.Run(hooks.EventCreate, issue)
-		}
+		emitIssueHook(hooks.EventCreate, nil, issue)
 
 		if jsonOutput {
 			outputJSON(issue)
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/hooks"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/ui"
 )
@@ -168,6 +169,11 @@ Force: Delete and orphan dependents
 			fmt.Printf("To proceed, run: %s\n\n", ui.RenderWarn("bd delete "+issueID+" --force"))
 			return
 		}
+		// A before_delete hook may veto the deletion
+		if _, err := runBeforeHook(hooks.EventBeforeDelete, issue, nil, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 		// Actually delete
 		// 1. Update text references in connected issues (all text fields)
 		updatedIssueCount := 0
@@ -228,6 +234,7 @@ Force: Delete and orphan dependents
 		}
 		// Remove the issue from the JSONL file as well
 		_ = removeIssueFromJSONL(issueID)
+		emitIssueHook(hooks.EventDelete, issue, nil)
 		totalDepsRemoved := outgoingRemoved + inboundRemoved
 		if jsonOutput {
 			outputJSON(map[string]interface{}{
@@ -374,6 +381,13 @@ func deleteBatch(_ *cobra.Command, issueIDs []string, force bool, dryRun bool, c
 		}
 		return
 	}
+	// A before_delete hook may veto the deletion; any veto deletes nothing
+	for _, id := range issueIDs {
+		if _, err := runBeforeHook(hooks.EventBeforeDelete, issues[id], nil, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	// Pre-collect connected issues before deletion (so we can update their text references)
 	connectedIssues := make(map[string]*types.Issue)
 	idSet := make(map[string]bool)
@@ -406,6 +420,9 @@ func deleteBatch(_ *cobra.Command, issueIDs []string, force bool, dryRun bool, c
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	for _, id := range issueIDs {
+		emitIssueHook(hooks.EventDelete, issues[id], nil)
+	}
 
 	// Update text references in connected issues (using pre-collected issues)
 	updatedCount := updateTextReferencesInIssues(ctx, issueIDs, connectedIssues)
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/hooks"
 	"github.com/steveyegge/beads/internal/routing"
 	"github.com/steveyegge/beads/internal/storage/dolt"
 	"github.com/steveyegge/beads/internal/types"
@@ -138,6 +139,7 @@ Examples:
 			if err := store.AddDependency(ctx, dep, actor); err != nil {
 				FatalErrorRespectJSON("%v", err)
 			}
+			emitDependencyHook(ctx, store, hooks.EventDependencyAdded, dep)
 
 			// Check for cycles after adding dependency (both daemon and direct mode)
 			warnIfCyclesExist(store)
@@ -277,6 +279,7 @@ Examples:
 		if err := store.AddDependency(ctx, dep, actor); err != nil {
 			FatalErrorRespectJSON("%v", err)
 		}
+		emitDependencyHook(ctx, store, hooks.EventDependencyAdded, dep)
 
 		// Check for cycles after adding dependency
 		warnIfCyclesExist(store)
@@ -464,6 +467,8 @@ var depRemoveCmd = &cobra.Command{
 		if err := store.RemoveDependency(ctx, fullFromID, fullToID, actor); err != nil {
 			FatalErrorRespectJSON("%v", err)
 		}
+		emitDependencyHook(ctx, store, hooks.EventDependencyRemoved,
+			&types.Dependency{IssueID: fullFromID, DependsOnID: fullToID})
 
 		if jsonOutput {
 			outputJSON(map[string]interface{}{
@@ -20,6 +20,8 @@ dolt-access.lock
 bd.sock
 sync-state.json
 last-touched
+hook-log.jsonl
+hook-log.jsonl.1
 
 # Local version tracking (prevents upgrade notification spam after git ops)
 .local_version
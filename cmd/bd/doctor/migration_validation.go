@@ -531,8 +531,9 @@ func checkDoltLocks(beadsDir string) (bool, string) {
 
 	ctx := context.Background()
 
-	// Check dolt_status for uncommitted changes
-	rows, err := conn.db.QueryContext(ctx, "SELECT table_name, staged, status FROM dolt_status")
+	// Check dolt_status for uncommitted changes, skipping dolt_ignore'd
+	// tables (the local webhook outbox), which are never committed
+	rows, err := conn.db.QueryContext(ctx, "SELECT table_name, staged, status FROM dolt_status_ignored WHERE ignored = 0")
 	if err != nil {
 		return false, ""
 	}
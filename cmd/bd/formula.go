@@ -35,8 +35,13 @@ Search paths (in order):
   3. $GT_ROOT/.beads/formulas/ (orchestrator, if GT_ROOT set)
 
 Commands:
-  list   List available formulas from all search paths
-  show   Show formula details, steps, and composition rules`,
+  list     List available formulas from all search paths
+  show     Show formula details, steps, and composition rules
+  lint     Check formulas for graph, variable and expression errors
+  test     Check formulas against golden cook fixtures
+  graph    Render the cooked step graph (ascii, dot, mermaid)
+  install  Install a versioned formula package (git or local)
+  update   Update formula packages within their version constraints`,
 }
 
 // formulaListCmd lists all available formulas.
@@ -51,9 +56,11 @@ Search paths (in order of priority):
   3. $GT_ROOT/.beads/formulas/ (orchestrator, if GT_ROOT set)
 
 Formulas in earlier paths shadow those with the same name in later paths.
+Formulas from installed packages are listed as <package>/<formula>.
 
 Examples:
   bd formula list
+  bd formula list --outdated
   bd formula list --json
   bd formula list --type workflow
   bd formula list --type aspect`,
@@ -93,6 +100,10 @@ type FormulaListEntry struct {
 
 func runFormulaList(cmd *cobra.Command, args []string) {
 	typeFilter, _ := cmd.Flags().GetString("type")
+	if outdated, _ := cmd.Flags().GetBool("outdated"); outdated {
+		runFormulaOutdated()
+		return
+	}
 
 	// Get all search paths
 	searchPaths := getFormulaSearchPaths()
@@ -130,6 +141,27 @@ func runFormulaList(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Formulas in installed packages, by qualified name
+	for pkgName, dir := range getFormulaPackageDirs() {
+		formulas, err := scanFormulaDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range formulas {
+			if typeFilter != "" && string(f.Type) != typeFilter {
+				continue
+			}
+			entries = append(entries, FormulaListEntry{
+				Name:        pkgName + "/" + f.Formula,
+				Type:        string(f.Type),
+				Description: truncateDescription(f.Description, 60),
+				Source:      f.Source,
+				Steps:       countSteps(f.Steps),
+				Vars:        len(f.Vars),
+			})
+		}
+	}
+
 	// Sort by name
 	sort.Slice(entries, func(i, j int) bool {
 		return entries[i].Name < entries[j].Name
@@ -764,6 +796,7 @@ func fixIntegerFields(m map[string]interface{}) {
 
 func init() {
 	formulaListCmd.Flags().String("type", "", "Filter by type (workflow, expansion, aspect)")
+	formulaListCmd.Flags().Bool("outdated", false, "List installed formula packages with newer versions")
 	formulaConvertCmd.Flags().BoolVar(&convertAll, "all", false, "Convert all JSON formulas")
 	formulaConvertCmd.Flags().BoolVar(&convertDelete, "delete", false, "Delete JSON file after conversion")
 	formulaConvertCmd.Flags().BoolVar(&convertStdout, "stdout", false, "Print TOML to stdout instead of file")
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// formulaFixtureExt is the suffix of golden-file fixtures for bd formula test.
+const formulaFixtureExt = ".formula.test.toml"
+
+// formulaTestCmd checks formulas against golden cook fixtures.
+var formulaTestCmd = &cobra.Command{
+	Use:   "test [name|fixture...]",
+	Short: "Check formulas against golden cook fixtures",
+	Long: `Cook formulas and compare the result against golden-file fixtures.
+
+A fixture is a <name>.formula.test.toml file next to the formula. It supplies
+vars and the expected cooked step graph:
+
+  formula = "mol-feature"          # defaults to the fixture file name
+
+  [vars]
+  component = "auth"
+
+  [[steps]]
+  id = "design"
+  title = "Design auth"
+  type = "task"
+
+  [[steps]]
+  id = "implement"
+  title = "Implement auth"
+  type = "task"
+  needs = ["design"]
+
+Each step records its ID, title, type, parent step, needs (blocking
+dependencies), waits_for, labels and, for gate steps, await_type/await_id.
+Several fixtures may test the same formula with different vars, e.g.
+mol-feature.prod.formula.test.toml with formula = "mol-feature".
+
+The formula is cooked in memory with the same pipeline as bd cook (extends,
+control flow, advice, expansions, aspects, step conditions and vars), so no
+database is needed. Differences are reported per step; --update rewrites the
+fixtures from the actual cook (creating <name>.formula.test.toml for a
+formula without one). Comments in rewritten fixtures are not preserved.
+
+With no arguments, every fixture in the formula search paths is run.
+
+Examples:
+  bd formula test                     # Run all fixtures
+  bd formula test mol-feature         # Run fixtures for one formula
+  bd formula test ./mol-feature.formula.test.toml
+  bd formula test mol-feature --update`,
+	Run: runFormulaTest,
+}
+
+// formulaFixture is the contents of a .formula.test.toml file.
+type formulaFixture struct {
+	Formula string            `toml:"formula" json:"formula"`
+	Vars    map[string]string `toml:"vars,omitempty" json:"vars,omitempty"`
+	Steps   []*goldenStep     `toml:"steps" json:"steps"`
+}
+
+// goldenStep is one issue of a cooked formula, as recorded in a fixture.
+// IDs are relative to the proto root.
+type goldenStep struct {
+	ID        string   `toml:"id" json:"id"`
+	Title     string   `toml:"title" json:"title"`
+	Type      string   `toml:"type" json:"type"`
+	Parent    string   `toml:"parent,omitempty" json:"parent,omitempty"`
+	Needs     []string `toml:"needs,omitempty" json:"needs,omitempty"`
+	WaitsFor  []string `toml:"waits_for,omitempty" json:"waits_for,omitempty"`
+	Labels    []string `toml:"labels,omitempty" json:"labels,omitempty"`
+	AwaitType string   `toml:"await_type,omitempty" json:"await_type,omitempty"`
+	AwaitID   string   `toml:"await_id,omitempty" json:"await_id,omitempty"`
+}
+
+// formulaTestResult is the outcome of running one fixture.
+type formulaTestResult struct {
+	Fixture string   `json:"fixture"`
+	Formula string   `json:"formula"`
+	Passed  bool     `json:"passed"`
+	Updated bool     `json:"updated,omitempty"`
+	Steps   int      `json:"steps"`
+	Diffs   []string `json:"diffs,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+func runFormulaTest(cmd *cobra.Command, args []string) {
+	update, _ := cmd.Flags().GetBool("update")
+	searchPaths := getFormulaSearchPaths()
+
+	var fixtures []string
+	if len(args) == 0 {
+		fixtures = findFormulaFixtures(searchPaths, "")
+	}
+	for _, arg := range args {
+		if strings.HasSuffix(arg, formulaFixtureExt) {
+			fixtures = append(fixtures, arg)
+			continue
+		}
+		found := findFormulaFixtures(searchPaths, arg)
+		if len(found) == 0 {
+			formulaPath := findFormulaFile(arg, searchPaths)
+			if !update || formulaPath == "" {
+				fmt.Fprintf(os.Stderr, "Error: no %s fixtures found for formula %q\n", formulaFixtureExt, arg)
+				if formulaPath != "" {
+					fmt.Fprintf(os.Stderr, "Create one with: bd formula test %s --update\n", arg)
+				}
+				os.Exit(1)
+			}
+			found = []string{filepath.Join(filepath.Dir(formulaPath), arg+formulaFixtureExt)}
+		}
+		fixtures = append(fixtures, found...)
+	}
+
+	cwd, _ := os.Getwd()
+	var results []*formulaTestResult
+	failed := 0
+	for _, path := range fixtures {
+		result := runFormulaFixture(path, searchPaths, update)
+		result.Fixture = displayPath(result.Fixture, cwd)
+		results = append(results, result)
+		if !result.Passed {
+			failed++
+		}
+		if !jsonOutput {
+			printFormulaTestResult(result)
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(results)
+	} else if len(fixtures) == 0 {
+		fmt.Printf("No %s fixtures found.\n", formulaFixtureExt)
+	} else {
+		fmt.Printf("\n%d fixture(s): %d passed, %d failed\n", len(results), len(results)-failed, failed)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// runFormulaFixture cooks the formula a fixture tests and diffs the result
+// against it, rewriting the fixture if update is set.
+func runFormulaFixture(path string, searchPaths []string, update bool) *formulaTestResult {
+	result := &formulaTestResult{Fixture: path}
+	fixture, err := loadFormulaFixture(path)
+	if err != nil {
+		if !update || !os.IsNotExist(err) {
+			result.Error = err.Error()
+			return result
+		}
+		// --update for a formula without a fixture yet
+		fixture = &formulaFixture{Formula: strings.TrimSuffix(filepath.Base(path), formulaFixtureExt)}
+	}
+	result.Formula = fixture.Formula
+
+	// Formulas next to the fixture take precedence
+	paths := append([]string{filepath.Dir(path)}, searchPaths...)
+	formulaPath := findFormulaFile(fixture.Formula, paths)
+	if formulaPath == "" {
+		result.Error = fmt.Sprintf("formula %q not found", fixture.Formula)
+		return result
+	}
+	actual, err := cookGoldenSteps(formulaPath, paths, fixture.Vars)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Steps = len(actual)
+	result.Diffs = diffGoldenSteps(fixture.Steps, actual)
+	result.Passed = len(result.Diffs) == 0
+
+	if update && !result.Passed {
+		fixture.Steps = actual
+		if err := writeFormulaFixture(path, fixture); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Updated = true
+		result.Passed = true
+	}
+	return result
+}
+
+// cookGoldenSteps cooks a formula in memory with the given vars, the way
+// bd mol pour would, and returns its steps in cook order.
+func cookGoldenSteps(formulaPath string, searchPaths []string, inputVars map[string]string) ([]*goldenStep, error) {
+	_, subgraph, vars, err := cookFormulaPreview(formulaPath, searchPaths, inputVars)
+	if err != nil {
+		return nil, err
+	}
+	return goldenStepsFromSubgraph(subgraph, vars), nil
+}
+
+// cookFormulaPreview resolves and cooks a formula in memory with the given
+// vars (plus defaults), dropping steps whose conditions are false. Returns
+// the resolved formula, the cooked subgraph and the effective vars.
+func cookFormulaPreview(formulaPath string, searchPaths []string, inputVars map[string]string) (*formula.Formula, *TemplateSubgraph, map[string]string, error) {
+	resolved, err := loadAndResolveFormula(formulaPath, searchPaths)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := formula.ValidateVars(resolved, inputVars); err != nil {
+		return nil, nil, nil, err
+	}
+
+	vars := make(map[string]string)
+	for name, def := range resolved.Vars {
+		if def != nil && def.Default != nil {
+			vars[name] = *def.Default
+		}
+	}
+	for k, v := range inputVars {
+		vars[k] = v
+	}
+
+	if resolved.Steps, err = formula.FilterStepsByCondition(resolved.Steps, vars); err != nil {
+		return nil, nil, nil, fmt.Errorf("filtering steps by condition: %w", err)
+	}
+	subgraph, err := cookFormulaToSubgraph(resolved, resolved.Formula)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return resolved, subgraph, vars, nil
+}
+
+// goldenStepsFromSubgraph converts a cooked subgraph (minus its root) to
+// fixture steps with vars substituted and lists sorted.
+func goldenStepsFromSubgraph(subgraph *TemplateSubgraph, vars map[string]string) []*goldenStep {
+	rootPrefix := subgraph.Root.ID + "."
+	rel := func(id string) string {
+		return strings.TrimPrefix(id, rootPrefix)
+	}
+
+	steps := make(map[string]*goldenStep)
+	var ordered []*goldenStep
+	for _, issue := range subgraph.Issues {
+		if issue.ID == subgraph.Root.ID {
+			continue
+		}
+		step := &goldenStep{
+			ID:        rel(issue.ID),
+			Title:     substituteVariables(issue.Title, vars),
+			Type:      string(issue.IssueType),
+			AwaitType: issue.AwaitType,
+			AwaitID:   issue.AwaitID,
+		}
+		for _, label := range issue.Labels {
+			step.Labels = append(step.Labels, substituteVariables(label, vars))
+		}
+		sort.Strings(step.Labels)
+		steps[issue.ID] = step
+		ordered = append(ordered, step)
+	}
+
+	for _, dep := range subgraph.Dependencies {
+		step := steps[dep.IssueID]
+		if step == nil {
+			continue
+		}
+		switch dep.Type {
+		case types.DepParentChild:
+			if dep.DependsOnID != subgraph.Root.ID {
+				step.Parent = rel(dep.DependsOnID)
+			}
+		case types.DepBlocks:
+			step.Needs = append(step.Needs, rel(dep.DependsOnID))
+		case types.DepWaitsFor:
+			step.WaitsFor = append(step.WaitsFor, rel(dep.DependsOnID))
+		}
+	}
+	for _, step := range ordered {
+		sort.Strings(step.Needs)
+		sort.Strings(step.WaitsFor)
+	}
+	return ordered
+}
+
+// diffGoldenSteps describes how the actual cook differs from the fixture.
+// Steps are matched by ID; the order of steps and of lists is ignored.
+func diffGoldenSteps(want, got []*goldenStep) []string {
+	var diffs []string
+	gotByID := make(map[string]*goldenStep)
+	for _, step := range got {
+		gotByID[step.ID] = step
+	}
+	wantIDs := make(map[string]bool)
+	for _, w := range want {
+		wantIDs[w.ID] = true
+		g := gotByID[w.ID]
+		if g == nil {
+			diffs = append(diffs, fmt.Sprintf("step %q: expected but not cooked", w.ID))
+			continue
+		}
+		diffs = append(diffs, diffGoldenStep(w, g)...)
+	}
+	for _, g := range got {
+		if !wantIDs[g.ID] {
+			diffs = append(diffs, fmt.Sprintf("step %q: cooked but not in fixture", g.ID))
+		}
+	}
+	return diffs
+}
+
+// diffGoldenStep compares the fields of one step.
+func diffGoldenStep(want, got *goldenStep) []string {
+	var diffs []string
+	field := func(name, w, g string) {
+		if w != g {
+			diffs = append(diffs, fmt.Sprintf("step %q: %s: want %q, got %q", want.ID, name, w, g))
+		}
+	}
+	list := func(name string, w, g []string) {
+		w, g = sortedCopy(w), sortedCopy(g)
+		if !reflect.DeepEqual(w, g) {
+			diffs = append(diffs, fmt.Sprintf("step %q: %s: want [%s], got [%s]", want.ID, name, strings.Join(w, ", "), strings.Join(g, ", ")))
+		}
+	}
+	field("title", want.Title, got.Title)
+	field("type", want.Type, got.Type)
+	field("parent", want.Parent, got.Parent)
+	list("needs", want.Needs, got.Needs)
+	list("waits_for", want.WaitsFor, got.WaitsFor)
+	list("labels", want.Labels, got.Labels)
+	field("await_type", want.AwaitType, got.AwaitType)
+	field("await_id", want.AwaitID, got.AwaitID)
+	return diffs
+}
+
+// sortedCopy returns a sorted copy of s, treating nil and empty alike.
+func sortedCopy(s []string) []string {
+	out := append([]string{}, s...)
+	sort.Strings(out)
+	return out
+}
+
+// loadFormulaFixture reads a fixture, defaulting its formula to the file name.
+func loadFormulaFixture(path string) (*formulaFixture, error) {
+	// #nosec G304 -- path is a fixture from the search paths or user input
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture formulaFixture
+	if _, err := toml.Decode(string(data), &fixture); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if fixture.Formula == "" {
+		fixture.Formula = strings.TrimSuffix(filepath.Base(path), formulaFixtureExt)
+	}
+	return &fixture, nil
+}
+
+// writeFormulaFixture writes a fixture as TOML.
+func writeFormulaFixture(path string, fixture *formulaFixture) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Golden cook output for %s. Regenerate with: bd formula test %s --update\n", fixture.Formula, fixture.Formula)
+	encoder := toml.NewEncoder(&buf)
+	encoder.Indent = ""
+	if err := encoder.Encode(fixture); err != nil {
+		return fmt.Errorf("encoding fixture: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// findFormulaFixtures returns the fixtures in the search paths, optionally
+// only those testing the named formula.
+func findFormulaFixtures(searchPaths []string, name string) []string {
+	var fixtures []string
+	for _, dir := range searchPaths {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*"+formulaFixtureExt))
+		sort.Strings(matches)
+		for _, path := range matches {
+			if name != "" {
+				fixture, err := loadFormulaFixture(path)
+				if err != nil || fixture.Formula != name {
+					continue
+				}
+			}
+			fixtures = append(fixtures, path)
+		}
+	}
+	return fixtures
+}
+
+// printFormulaTestResult prints one fixture's outcome and its diffs.
+func printFormulaTestResult(result *formulaTestResult) {
+	switch {
+	case result.Error != "":
+		fmt.Printf("%s %s: %s\n", ui.RenderFail("✗"), result.Fixture, result.Error)
+	case result.Updated:
+		fmt.Printf("%s %s (%s, %d steps) updated\n", ui.RenderWarn("↻"), result.Fixture, result.Formula, result.Steps)
+	case result.Passed:
+		fmt.Printf("%s %s (%s, %d steps)\n", ui.RenderPass("✓"), result.Fixture, result.Formula, result.Steps)
+	default:
+		fmt.Printf("%s %s (%s)\n", ui.RenderFail("✗"), result.Fixture, result.Formula)
+		for _, diff := range result.Diffs {
+			fmt.Printf("    %s\n", diff)
+		}
+	}
+}
+
+func init() {
+	formulaTestCmd.Flags().Bool("update", false, "Rewrite fixtures from the actual cook output")
+	formulaCmd.AddCommand(formulaTestCmd)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const goldenTestFormula = `formula = "mol-golden"
+version = 1
+
+[vars.component]
+required = true
+
+[vars.env]
+default = "dev"
+
+[[steps]]
+id = "design"
+title = "Design {{component}}"
+
+[[steps]]
+id = "build"
+title = "Build {{component}}"
+needs = ["design"]
+
+[steps.gate]
+type = "timer"
+timeout = "1h"
+
+[[steps]]
+id = "ship"
+title = "Ship {{component}}"
+needs = ["build"]
+condition = "{{env}} == prod"
+`
+
+// writeGoldenFormula writes the test formula into a temp formula directory.
+func writeGoldenFormula(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mol-golden.formula.toml"), []byte(goldenTestFormula), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestCookGoldenSteps(t *testing.T) {
+	dir := writeGoldenFormula(t)
+	path := filepath.Join(dir, "mol-golden.formula.toml")
+
+	steps, err := cookGoldenSteps(path, []string{dir}, map[string]string{"component": "auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	byID := make(map[string]*goldenStep)
+	for _, step := range steps {
+		ids = append(ids, step.ID)
+		byID[step.ID] = step
+	}
+	if got := strings.Join(ids, ","); got != "design,build,gate-build" {
+		t.Fatalf("steps = %s, want design,build,gate-build (ship filtered by condition)", got)
+	}
+	if build := byID["build"]; build.Title != "Build auth" || strings.Join(build.Needs, ",") != "design,gate-build" {
+		t.Errorf("build = %+v", build)
+	}
+	if gate := byID["gate-build"]; gate.Type != "gate" || gate.AwaitType != "timer" {
+		t.Errorf("gate = %+v", gate)
+	}
+
+	steps, err = cookGoldenSteps(path, []string{dir}, map[string]string{"component": "auth", "env": "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 4 {
+		t.Errorf("with env=prod got %d steps, want 4", len(steps))
+	}
+
+	if _, err := cookGoldenSteps(path, []string{dir}, nil); err == nil || !strings.Contains(err.Error(), "required") {
+		t.Errorf("missing required var error = %v", err)
+	}
+}
+
+func TestRunFormulaFixture(t *testing.T) {
+	dir := writeGoldenFormula(t)
+	fixturePath := filepath.Join(dir, "mol-golden"+formulaFixtureExt)
+
+	// --update cannot create a fixture for a formula with required vars
+	result := runFormulaFixture(fixturePath, nil, true)
+	if result.Error == "" || result.Updated {
+		t.Fatalf("fixture without vars should fail to cook: %+v", result)
+	}
+	if err := os.WriteFile(fixturePath, []byte("[vars]\ncomponent = \"auth\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	result = runFormulaFixture(fixturePath, nil, false)
+	if result.Passed || len(result.Diffs) != 3 {
+		t.Fatalf("empty fixture result = %+v, want 3 unexpected steps", result)
+	}
+	result = runFormulaFixture(fixturePath, nil, true)
+	if !result.Updated || !result.Passed {
+		t.Fatalf("update result = %+v", result)
+	}
+
+	// The regenerated golden passes as is
+	result = runFormulaFixture(fixturePath, nil, false)
+	if !result.Passed || result.Formula != "mol-golden" || result.Steps != 3 {
+		t.Fatalf("golden result = %+v", result)
+	}
+
+	// A formula change shows up as a step diff
+	changed := strings.Replace(goldenTestFormula, `title = "Design {{component}}"`, `title = "Sketch {{component}}"`, 1)
+	if err := os.WriteFile(filepath.Join(dir, "mol-golden.formula.toml"), []byte(changed), 0600); err != nil {
+		t.Fatal(err)
+	}
+	result = runFormulaFixture(fixturePath, nil, false)
+	if result.Passed || len(result.Diffs) != 1 || !strings.Contains(result.Diffs[0], `title: want "Design auth", got "Sketch auth"`) {
+		t.Errorf("changed result = %+v", result)
+	}
+}
+
+func TestDiffGoldenSteps(t *testing.T) {
+	want := []*goldenStep{
+		{ID: "a", Title: "A", Type: "task", Labels: []string{"x", "y"}},
+		{ID: "b", Title: "B", Type: "task", Needs: []string{"a"}},
+	}
+	got := []*goldenStep{
+		{ID: "b", Title: "B", Type: "bug", Needs: []string{"a"}},
+		{ID: "a", Title: "A", Type: "task", Labels: []string{"y", "x"}},
+		{ID: "c", Title: "C", Type: "task"},
+	}
+	diffs := diffGoldenSteps(want, got)
+	if len(diffs) != 2 {
+		t.Fatalf("diffs = %v, want type change and unexpected step", diffs)
+	}
+	if !strings.Contains(diffs[0], `step "b": type: want "task", got "bug"`) {
+		t.Errorf("diffs[0] = %q", diffs[0])
+	}
+	if !strings.Contains(diffs[1], `step "c": cooked but not in fixture`) {
+		t.Errorf("diffs[1] = %q", diffs[1])
+	}
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// formulaGraphCmd renders the cooked step DAG of a formula.
+var formulaGraphCmd = &cobra.Command{
+	Use:   "graph <name|path>",
+	Short: "Render the cooked step graph of a formula",
+	Long: `Render the step DAG a formula cooks to, without touching the database.
+
+The graph is the fully expanded one bd mol pour would create: after extends,
+control flow (loops, branches, gates), advice, expansions and aspects are
+applied, with conditions evaluated against --var values and defaults.
+
+Steps are clustered by the formula that defined them, so steps woven in by
+aspects or expansions stand out. Gates and conditional steps (a condition or
+a compose.gate) are styled differently from plain steps.
+
+Formats:
+  ascii     Terminal DAG, like bd graph (default)
+  dot       Graphviz DOT (pipe to: dot -Tsvg > graph.svg)
+  mermaid   Mermaid flowchart (paste into Markdown)
+
+Examples:
+  bd formula graph mol-release --var version=1.2.0
+  bd formula graph mol-release --format dot | dot -Tsvg > release.svg
+  bd formula graph ./mol-release.formula.toml --format mermaid`,
+	Args: cobra.ExactArgs(1),
+	Run:  runFormulaGraph,
+}
+
+// Node kinds in a formula graph
+const (
+	formulaNodeStep        = "step"
+	formulaNodeGate        = "gate"
+	formulaNodeConditional = "conditional"
+)
+
+// formulaGraphNode is a cooked step in a formula graph.
+type formulaGraphNode struct {
+	ID       string `json:"id"` // Relative to the formula root
+	Title    string `json:"title"`
+	Kind     string `json:"kind"`
+	Source   string `json:"source"` // Formula that defined the step
+	Location string `json:"location,omitempty"`
+	Detail   string `json:"detail,omitempty"` // Gate await spec or step condition
+}
+
+// formulaGraphEdge is a dependency between cooked steps.
+type formulaGraphEdge struct {
+	From string               `json:"from"` // Blocker
+	To   string               `json:"to"`   // Blocked step
+	Type types.DependencyType `json:"type"`
+}
+
+// formulaGraph is the cooked step graph of a formula.
+type formulaGraph struct {
+	Formula string              `json:"formula"`
+	Sources []string            `json:"sources"` // Source formulas, in cook order
+	Nodes   []*formulaGraphNode `json:"nodes"`
+	Edges   []*formulaGraphEdge `json:"edges"`
+
+	// subgraph holds the steps as issues with relative IDs, for the
+	// bd graph renderers
+	subgraph *TemplateSubgraph
+}
+
+func runFormulaGraph(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	varFlags, _ := cmd.Flags().GetStringArray("var")
+
+	if format != "ascii" && format != "dot" && format != "mermaid" {
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (must be ascii, dot or mermaid)\n", format)
+		os.Exit(1)
+	}
+	inputVars := make(map[string]string)
+	for _, v := range varFlags {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			fmt.Fprintf(os.Stderr, "Error: invalid variable format '%s', expected 'key=value'\n", v)
+			os.Exit(1)
+		}
+		inputVars[parts[0]] = parts[1]
+	}
+
+	searchPaths := getFormulaSearchPaths()
+	path := args[0]
+	if !strings.HasSuffix(path, formula.FormulaExtTOML) && !strings.HasSuffix(path, formula.FormulaExtJSON) {
+		path = findFormulaFile(args[0], searchPaths)
+		if path == "" {
+			fmt.Fprintf(os.Stderr, "Error: formula %q not found\n", args[0])
+			fmt.Fprintf(os.Stderr, "\nSearch paths:\n")
+			for _, p := range searchPaths {
+				fmt.Fprintf(os.Stderr, "  %s\n", p)
+			}
+			os.Exit(1)
+		}
+	}
+
+	paths := append([]string{filepath.Dir(path)}, searchPaths...)
+	resolved, subgraph, vars, err := cookFormulaPreview(path, paths, inputVars)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	graph := buildFormulaGraph(resolved, subgraph, vars)
+
+	if jsonOutput {
+		outputJSON(graph)
+		return
+	}
+	switch format {
+	case "dot":
+		renderFormulaGraphDOT(graph)
+	case "mermaid":
+		renderFormulaGraphMermaid(graph)
+	default:
+		renderFormulaGraphASCII(graph)
+	}
+}
+
+// buildFormulaGraph converts a cooked subgraph to a formula graph: the root
+// epic is dropped, IDs are made relative to it and vars are substituted.
+func buildFormulaGraph(resolved *formula.Formula, subgraph *TemplateSubgraph, vars map[string]string) *formulaGraph {
+	rootPrefix := subgraph.Root.ID + "."
+	rel := func(id string) string {
+		return strings.TrimPrefix(id, rootPrefix)
+	}
+
+	// Step conditions, by issue ID (children are nested under their parent)
+	conditions := make(map[string]string)
+	var walk func(steps []*formula.Step, parentID string)
+	walk = func(steps []*formula.Step, parentID string) {
+		for _, step := range steps {
+			id := parentID + "." + step.ID
+			if step.Condition != "" {
+				conditions[id] = step.Condition
+			}
+			walk(step.Children, id)
+		}
+	}
+	walk(resolved.Steps, subgraph.Root.ID)
+
+	// Gates are attributed to the step they block
+	gated := make(map[string]string)
+	for _, dep := range subgraph.Dependencies {
+		if dep.Type == types.DepBlocks {
+			gated[dep.DependsOnID] = dep.IssueID
+		}
+	}
+
+	graph := &formulaGraph{Formula: resolved.Formula}
+	display := &TemplateSubgraph{
+		Root:     &types.Issue{ID: resolved.Formula, Title: resolved.Formula},
+		IssueMap: make(map[string]*types.Issue),
+	}
+	nodes := make(map[string]*formulaGraphNode)
+	for _, issue := range subgraph.Issues {
+		if issue.ID == subgraph.Root.ID {
+			continue
+		}
+		node := &formulaGraphNode{
+			ID:       rel(issue.ID),
+			Title:    substituteVariables(issue.Title, vars),
+			Kind:     formulaNodeStep,
+			Source:   issue.SourceFormula,
+			Location: issue.SourceLocation,
+		}
+		switch {
+		case issue.IssueType == "gate":
+			node.Kind = formulaNodeGate
+			node.Detail = strings.TrimSpace(issue.AwaitType + " " + substituteVariables(issue.AwaitID, vars))
+		case conditions[issue.ID] != "":
+			node.Kind = formulaNodeConditional
+			node.Detail = "if " + conditions[issue.ID]
+		}
+		nodes[issue.ID] = node
+		graph.Nodes = append(graph.Nodes, node)
+
+		clone := *issue
+		clone.ID = node.ID
+		clone.Title = node.Title
+		display.Issues = append(display.Issues, &clone)
+		display.IssueMap[clone.ID] = &clone
+	}
+
+	for _, dep := range subgraph.Dependencies {
+		from, to := nodes[dep.DependsOnID], nodes[dep.IssueID]
+		if from == nil || to == nil {
+			continue // Edges to the root epic
+		}
+		// Steps behind a compose.gate condition are conditional too
+		if condition, ok := strings.CutPrefix(from.Detail, conditionGateType+" "); ok && from.Kind == formulaNodeGate && to.Kind == formulaNodeStep {
+			to.Kind = formulaNodeConditional
+			to.Detail = "when " + condition
+		}
+		graph.Edges = append(graph.Edges, &formulaGraphEdge{From: from.ID, To: to.ID, Type: dep.Type})
+		display.Dependencies = append(display.Dependencies, &types.Dependency{
+			IssueID:     to.ID,
+			DependsOnID: from.ID,
+			Type:        dep.Type,
+		})
+	}
+
+	// Gates and steps without a source belong with the step they guard, or
+	// to the formula itself
+	seen := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		if node.Source == "" {
+			if target := nodes[gated[rootPrefix+node.ID]]; target != nil && target.Source != "" {
+				node.Source = target.Source
+			} else {
+				node.Source = resolved.Formula
+			}
+		}
+		if !seen[node.Source] {
+			seen[node.Source] = true
+			graph.Sources = append(graph.Sources, node.Source)
+		}
+	}
+
+	graph.subgraph = display
+	return graph
+}
+
+// renderFormulaGraphASCII renders the graph with the bd graph terminal DAG,
+// followed by the source clusters and the gate and condition details.
+func renderFormulaGraphASCII(graph *formulaGraph) {
+	// Mark conditional steps in the boxes; gate titles already say "Gate:"
+	for _, node := range graph.Nodes {
+		if node.Kind == formulaNodeConditional {
+			graph.subgraph.IssueMap[node.ID].Title = "◇ " + node.Title
+		}
+	}
+	renderGraphVisual(computeLayout(graph.subgraph), graph.subgraph)
+
+	fmt.Printf("  %s\n", ui.RenderAccent("Sources:"))
+	for _, source := range graph.Sources {
+		var ids []string
+		for _, node := range graph.Nodes {
+			if node.Source == source {
+				ids = append(ids, node.ID)
+			}
+		}
+		fmt.Printf("    %-24s %s\n", source, ui.RenderMuted(strings.Join(ids, ", ")))
+	}
+
+	var details []*formulaGraphNode
+	for _, node := range graph.Nodes {
+		if node.Detail != "" {
+			details = append(details, node)
+		}
+	}
+	if len(details) > 0 {
+		fmt.Printf("\n  %s\n", ui.RenderAccent("Gates and conditions (◇ = conditional):"))
+		for _, node := range details {
+			fmt.Printf("    %-24s %s\n", node.ID, node.Detail)
+		}
+	}
+	fmt.Println()
+}
+
+// renderFormulaGraphDOT renders the graph in Graphviz DOT format with one
+// cluster per source formula.
+func renderFormulaGraphDOT(graph *formulaGraph) {
+	fmt.Println("digraph formula {")
+	fmt.Println("  rankdir=LR;")
+	fmt.Println("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\", fontsize=11, fillcolor=\"#e8f4fd\"];")
+	fmt.Println("  edge [color=\"#666666\"];")
+	fmt.Println()
+
+	for i, source := range graph.Sources {
+		fmt.Printf("  subgraph cluster_%d {\n", i)
+		fmt.Printf("    label=\"%s\";\n", dotEscapeID(source))
+		fmt.Println("    style=\"rounded,dashed\";")
+		fmt.Println("    color=\"#999999\";")
+		fmt.Println("    fontname=\"Helvetica\";")
+		for _, node := range graph.Nodes {
+			if node.Source != source {
+				continue
+			}
+			label := node.ID + "\\n" + dotEscapeID(truncateTitle(node.Title, 40))
+			if node.Kind == formulaNodeConditional {
+				label += "\\n" + dotEscapeID(truncateTitle(node.Detail, 40))
+			}
+			attrs := ""
+			switch node.Kind {
+			case formulaNodeGate:
+				attrs = ", shape=hexagon, style=filled, fillcolor=\"#fff3cd\", fontcolor=\"#664d03\""
+			case formulaNodeConditional:
+				attrs = ", style=\"rounded,filled,dashed\", fillcolor=\"#e2e3e5\""
+			}
+			if node.Location != "" {
+				attrs += fmt.Sprintf(", tooltip=\"%s\"", dotEscapeID(node.Location))
+			}
+			fmt.Printf("    \"%s\" [label=\"%s\"%s];\n", dotEscapeID(node.ID), label, attrs)
+		}
+		fmt.Println("  }")
+	}
+	fmt.Println()
+
+	for _, edge := range graph.Edges {
+		style := dotEdgeStyle(edge.Type)
+		if edge.Type == types.DepWaitsFor {
+			style = " [style=dotted, arrowhead=odot]"
+		}
+		fmt.Printf("  \"%s\" -> \"%s\"%s;\n", dotEscapeID(edge.From), dotEscapeID(edge.To), style)
+	}
+	fmt.Println("}")
+}
+
+// renderFormulaGraphMermaid renders the graph as a Mermaid flowchart with
+// one subgraph per source formula.
+func renderFormulaGraphMermaid(graph *formulaGraph) {
+	// Mermaid IDs cannot contain dots, so nodes are numbered
+	ids := make(map[string]string)
+	for i, node := range graph.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+	label := func(s string) string {
+		return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+	}
+
+	fmt.Println("flowchart LR")
+	var gates, conditionals []string
+	for i, source := range graph.Sources {
+		fmt.Printf("  subgraph s%d[\"%s\"]\n", i, label(source))
+		for _, node := range graph.Nodes {
+			if node.Source != source {
+				continue
+			}
+			text := label(node.ID + ": " + truncateTitle(node.Title, 40))
+			if node.Kind == formulaNodeConditional {
+				text += "<br/>" + label(truncateTitle(node.Detail, 40))
+			}
+			switch node.Kind {
+			case formulaNodeGate:
+				fmt.Printf("    %s{{\"%s\"}}\n", ids[node.ID], text)
+				gates = append(gates, ids[node.ID])
+			case formulaNodeConditional:
+				fmt.Printf("    %s{\"%s\"}\n", ids[node.ID], text)
+				conditionals = append(conditionals, ids[node.ID])
+			default:
+				fmt.Printf("    %s[\"%s\"]\n", ids[node.ID], text)
+			}
+		}
+		fmt.Println("  end")
+	}
+
+	for _, edge := range graph.Edges {
+		arrow := "-->"
+		switch edge.Type {
+		case types.DepParentChild:
+			arrow = "-.->"
+		case types.DepWaitsFor:
+			arrow = "-.-o"
+		}
+		fmt.Printf("  %s %s %s\n", ids[edge.From], arrow, ids[edge.To])
+	}
+
+	fmt.Println("  classDef gate fill:#fff3cd,stroke:#664d03")
+	fmt.Println("  classDef conditional fill:#e2e3e5,stroke-dasharray:5 5")
+	if len(gates) > 0 {
+		sort.Strings(gates)
+		fmt.Printf("  class %s gate\n", strings.Join(gates, ","))
+	}
+	if len(conditionals) > 0 {
+		sort.Strings(conditionals)
+		fmt.Printf("  class %s conditional\n", strings.Join(conditionals, ","))
+	}
+}
+
+func init() {
+	formulaGraphCmd.Flags().String("format", "ascii", "Output format: ascii, dot or mermaid")
+	formulaGraphCmd.Flags().StringArray("var", []string{}, "Variable value for conditions and titles (key=value)")
+
+	formulaCmd.AddCommand(formulaGraphCmd)
+}
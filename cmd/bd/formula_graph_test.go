@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const graphTestAspect = `formula = "sec-audit"
+version = 1
+type = "aspect"
+
+[[advice]]
+target = "design"
+
+[advice.after]
+id = "{step.id}-review"
+title = "Security review of {step.id}"
+`
+
+// cookTestFormulaGraph cooks the golden test formula with a security aspect
+// woven in.
+func cookTestFormulaGraph(t *testing.T, vars map[string]string) *formulaGraph {
+	t.Helper()
+	dir := writeGoldenFormula(t)
+	path := filepath.Join(dir, "mol-golden.formula.toml")
+	withAspect := goldenTestFormula + "\n[compose]\naspects = [\"sec-audit\"]\n"
+	if err := os.WriteFile(path, []byte(withAspect), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sec-audit.formula.toml"), []byte(graphTestAspect), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, subgraph, effective, err := cookFormulaPreview(path, []string{dir}, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buildFormulaGraph(resolved, subgraph, effective)
+}
+
+func TestBuildFormulaGraph(t *testing.T) {
+	graph := cookTestFormulaGraph(t, map[string]string{"component": "auth", "env": "prod"})
+
+	byID := make(map[string]*formulaGraphNode)
+	for _, node := range graph.Nodes {
+		byID[node.ID] = node
+	}
+	if len(byID) != 5 {
+		t.Fatalf("nodes = %d, want design, design-review, build, gate-build, ship", len(byID))
+	}
+	if got := strings.Join(graph.Sources, ","); got != "mol-golden,sec-audit" {
+		t.Errorf("sources = %s, want mol-golden,sec-audit", got)
+	}
+	if review := byID["design-review"]; review.Source != "sec-audit" || review.Title != "Security review of design" {
+		t.Errorf("aspect step = %+v", review)
+	}
+	if gate := byID["gate-build"]; gate.Kind != formulaNodeGate || gate.Source != "mol-golden" || gate.Detail != "timer" {
+		t.Errorf("gate = %+v, want timer gate clustered with build", gate)
+	}
+	if ship := byID["ship"]; ship.Kind != formulaNodeConditional || ship.Detail != "if {{env}} == prod" || ship.Title != "Ship auth" {
+		t.Errorf("ship = %+v", ship)
+	}
+	if build := byID["build"]; build.Kind != formulaNodeStep || build.Location != "steps[1]" {
+		t.Errorf("build = %+v", build)
+	}
+	for _, edge := range graph.Edges {
+		if edge.From == "mol-golden" || edge.To == "mol-golden" {
+			t.Errorf("edge to the root epic: %+v", edge)
+		}
+	}
+
+	// The conditional step is dropped when its condition is false
+	graph = cookTestFormulaGraph(t, map[string]string{"component": "auth"})
+	for _, node := range graph.Nodes {
+		if node.ID == "ship" {
+			t.Error("ship should be filtered out for env=dev")
+		}
+	}
+}
+
+func TestRenderFormulaGraphDOT(t *testing.T) {
+	graph := cookTestFormulaGraph(t, map[string]string{"component": "auth", "env": "prod"})
+	output := captureGraphOutput(func() { renderFormulaGraphDOT(graph) })
+
+	for _, want := range []string{
+		"digraph formula {",
+		`label="mol-golden";`,
+		`label="sec-audit";`,
+		`"gate-build" [label="gate-build\nGate: timer", shape=hexagon`,
+		`"ship" [label="ship\nShip auth\nif {{env}} == prod", style="rounded,filled,dashed"`,
+		`"design" -> "design-review" [style=solid, arrowhead=normal];`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestRenderFormulaGraphMermaid(t *testing.T) {
+	graph := cookTestFormulaGraph(t, map[string]string{"component": "auth", "env": "prod"})
+	output := captureGraphOutput(func() { renderFormulaGraphMermaid(graph) })
+
+	for _, want := range []string{
+		"flowchart LR",
+		`subgraph s1["sec-audit"]`,
+		`{{"gate-build: Gate: timer"}}`,
+		`{"ship: Ship auth<br/>if {{env}} == prod"}`,
+		"classDef gate",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Mermaid output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Count(output, " end\n") != 2 {
+		t.Errorf("want one subgraph per source:\n%s", output)
+	}
+}
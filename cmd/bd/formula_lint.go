@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// formulaLintCmd statically checks formulas.
+var formulaLintCmd = &cobra.Command{
+	Use:   "lint [name|path...]",
+	Short: "Check formulas for errors",
+	Long: `Statically check formulas for mistakes before they are cooked or poured.
+
+Each formula is checked after extends are resolved and control flow, advice,
+expansions and aspects are applied - the same steps bd cook sees:
+
+  errors:
+    - syntax errors, extends loops, structural validation failures
+    - needs/depends_on pointing at unknown step IDs
+    - dependency cycles, and steps that can never run because they wait on one
+    - invalid step conditions, loop until conditions and loop ranges
+    - on_complete with both parallel and sequential set
+  warnings:
+    - {{vars}} used but not declared, or declared but never used
+    - steps with no dependencies among siblings that are wired together
+    - advice targets and aspect pointcuts that match no steps
+
+Issues are reported as file:line for both TOML and JSON formulas. With no
+arguments, every formula in the search paths is checked. Exits non-zero if
+any errors are found.
+
+Examples:
+  bd formula lint                          # Lint all formulas
+  bd formula lint mol-release              # Lint by name
+  bd formula lint ./mol-release.formula.toml
+  bd formula lint --json`,
+	Run: runFormulaLint,
+}
+
+// formulaLintResult is the JSON output of bd formula lint.
+type formulaLintResult struct {
+	Formulas int                  `json:"formulas"`
+	Errors   int                  `json:"errors"`
+	Warnings int                  `json:"warnings"`
+	Issues   []*formula.LintIssue `json:"issues"`
+}
+
+func runFormulaLint(cmd *cobra.Command, args []string) {
+	searchPaths := getFormulaSearchPaths()
+
+	var paths []string
+	if len(args) == 0 {
+		paths = findAllFormulaFiles(searchPaths)
+	}
+	for _, arg := range args {
+		if strings.HasSuffix(arg, formula.FormulaExtTOML) || strings.HasSuffix(arg, formula.FormulaExtJSON) {
+			paths = append(paths, arg)
+			continue
+		}
+		path := findFormulaFile(arg, searchPaths)
+		if path == "" {
+			fmt.Fprintf(os.Stderr, "Error: formula %q not found\n", arg)
+			fmt.Fprintf(os.Stderr, "\nSearch paths:\n")
+			for _, p := range searchPaths {
+				fmt.Fprintf(os.Stderr, "  %s\n", p)
+			}
+			os.Exit(1)
+		}
+		paths = append(paths, path)
+	}
+
+	result := formulaLintResult{Formulas: len(paths), Issues: []*formula.LintIssue{}}
+	cwd, _ := os.Getwd()
+	for _, path := range paths {
+		// Formulas next to the linted file resolve first (extends, expansions)
+		parser := formula.NewParser(append([]string{filepath.Dir(path)}, searchPaths...)...)
+		issues := parser.LintFile(path)
+		for _, issue := range issues {
+			issue.File = displayPath(issue.File, cwd)
+			if issue.Severity == formula.LintError {
+				result.Errors++
+			} else {
+				result.Warnings++
+			}
+		}
+		result.Issues = append(result.Issues, issues...)
+
+		if !jsonOutput && len(issues) == 0 {
+			fmt.Printf("%s %s\n", ui.RenderPass("✓"), displayPath(path, cwd))
+		}
+		if !jsonOutput {
+			for _, issue := range issues {
+				printLintIssue(issue)
+			}
+		}
+	}
+
+	if jsonOutput {
+		outputJSON(result)
+	} else if len(paths) == 0 {
+		fmt.Println("No formulas found.")
+	} else {
+		fmt.Printf("\n%d formula(s) checked: %d error(s), %d warning(s)\n", result.Formulas, result.Errors, result.Warnings)
+	}
+
+	if result.Errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// printLintIssue prints an issue as "file:line: severity: message [rule]".
+func printLintIssue(issue *formula.LintIssue) {
+	loc := issue.File
+	if issue.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, issue.Line)
+	}
+	severity := ui.RenderWarn(string(issue.Severity))
+	if issue.Severity == formula.LintError {
+		severity = ui.RenderFail(string(issue.Severity))
+	}
+	fmt.Printf("%s: %s: %s %s\n", loc, severity, issue.Message, ui.RenderMuted("["+issue.Rule+"]"))
+}
+
+// findFormulaFile returns the path of a formula by name, preferring TOML
+// over JSON like the formula loader.
+func findFormulaFile(name string, searchPaths []string) string {
+	for _, dir := range searchPaths {
+		for _, ext := range []string{formula.FormulaExtTOML, formula.FormulaExtJSON} {
+			path := filepath.Join(dir, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// findAllFormulaFiles returns every formula file in the search paths,
+// skipping formulas shadowed by one of the same name in an earlier path.
+func findAllFormulaFiles(searchPaths []string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, dir := range searchPaths {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		var names []string
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			file := entry.Name()
+			if name, ok := strings.CutSuffix(file, formula.FormulaExtTOML); ok {
+				names = append(names, name)
+			} else if name, ok := strings.CutSuffix(file, formula.FormulaExtJSON); ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			paths = append(paths, findFormulaFile(name, []string{dir}))
+		}
+	}
+	return paths
+}
+
+// displayPath shortens a path to be relative to dir when it lies within it.
+func displayPath(path, dir string) string {
+	if dir == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func init() {
+	formulaCmd.AddCommand(formulaLintCmd)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// formulaInstallCmd installs formula packages.
+var formulaInstallCmd = &cobra.Command{
+	Use:   "install [source[@version]]",
+	Short: "Install a formula package",
+	Long: `Install a versioned formula package from a git repository or local directory.
+
+A package is a directory with a formula-package.toml manifest:
+
+  name = "acme-release"
+  version = "1.4.0"
+  dir = "formulas"          # optional, where the formulas live
+
+  [dependencies.acme-base]
+  source = "https://github.com/acme/base-formulas.git"
+  version = "^2.0"
+
+Git packages are versioned by vX.Y.Z tags; the newest tag matching the version
+constraint is installed and its commit pinned in formulas.lock. Local packages
+are snapshotted and pinned by content hash. Dependencies are installed too.
+
+Packages go to .beads/packages with the lock in .beads/formulas.lock (commit
+it), or ~/.beads with --global. Formulas in a package are referenced as
+<package>/<formula> in extends, expand and compose.aspects; plain names fall
+back to installed packages when not found in the search paths.
+
+With no source, installs every package pinned in the lock file (e.g. after
+cloning).
+
+Version constraints: 1.2.3, 1.2, ^1.2, ~1.2.3, >=1.0 <2, latest
+
+Examples:
+  bd formula install https://github.com/acme/release-formulas.git
+  bd formula install https://github.com/acme/release-formulas.git@^1.2
+  bd formula install ../shared-formulas --global
+  bd formula install`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runFormulaInstall,
+}
+
+// formulaUpdateCmd updates installed formula packages.
+var formulaUpdateCmd = &cobra.Command{
+	Use:   "update [name...]",
+	Short: "Update formula packages within their version constraints",
+	Long: `Re-resolve installed formula packages against their version constraints and
+pin the newest matching versions in formulas.lock.
+
+With no names, all directly installed packages (and their dependencies) are
+updated. Use bd formula list --outdated to see what would change, and
+bd formula install <source>@<version> to move past a constraint.
+
+Examples:
+  bd formula update
+  bd formula update acme-release
+  bd formula update --global`,
+	Run: runFormulaUpdate,
+}
+
+func runFormulaInstall(cmd *cobra.Command, args []string) {
+	global, _ := cmd.Flags().GetBool("global")
+	version, _ := cmd.Flags().GetString("version")
+	installer := formulaInstaller(global)
+
+	if len(args) == 0 {
+		fetched, err := installer.Sync()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if jsonOutput {
+			if fetched == nil {
+				fetched = []*formula.LockedPackage{}
+			}
+			outputJSON(fetched)
+			return
+		}
+		if len(fetched) == 0 {
+			fmt.Printf("All packages in %s are installed.\n", installer.LockPath())
+			return
+		}
+		for _, pkg := range fetched {
+			fmt.Printf("%s Installed %s %s\n", ui.RenderPass("✓"), pkg.Name, pkg.Version)
+		}
+		return
+	}
+
+	source, constraint := formula.SplitPackageSource(args[0])
+	if version != "" {
+		if constraint != "" {
+			fmt.Fprintf(os.Stderr, "Error: version given both as %s and --version\n", args[0])
+			os.Exit(1)
+		}
+		constraint = version
+	}
+	changes, err := installer.Install(source, constraint)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	printPackageChanges(changes, installer.LockPath())
+}
+
+func runFormulaUpdate(cmd *cobra.Command, args []string) {
+	global, _ := cmd.Flags().GetBool("global")
+	installer := formulaInstaller(global)
+
+	changes, err := installer.Update(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	printPackageChanges(changes, installer.LockPath())
+}
+
+// runFormulaOutdated implements bd formula list --outdated.
+func runFormulaOutdated() {
+	var outdated []*formula.OutdatedPackage
+	for _, global := range []bool{false, true} {
+		pkgs, err := formulaInstaller(global).Outdated()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		outdated = append(outdated, pkgs...)
+	}
+
+	if jsonOutput {
+		if outdated == nil {
+			outdated = []*formula.OutdatedPackage{}
+		}
+		outputJSON(outdated)
+		return
+	}
+	if len(outdated) == 0 {
+		fmt.Println("All formula packages are up to date.")
+		return
+	}
+	fmt.Printf("%-25s %-10s %-10s %-10s %s\n", "Package", "Current", "Wanted", "Latest", "Source")
+	for _, pkg := range outdated {
+		fmt.Printf("%-25s %-10s %-10s %-10s %s\n", pkg.Name, pkg.Current, pkg.Wanted, pkg.Latest, ui.RenderMuted(pkg.Source))
+	}
+}
+
+// formulaInstaller returns the installer for the project (.beads in the
+// current directory) or the user (~/.beads).
+func formulaInstaller(global bool) *formula.PackageInstaller {
+	if global {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: cannot find home directory: %v\n", err)
+			os.Exit(1)
+		}
+		return formula.NewPackageInstaller(filepath.Join(home, ".beads"))
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return formula.NewPackageInstaller(filepath.Join(cwd, ".beads"))
+}
+
+// getFormulaPackageDirs returns the formula directories of installed packages
+// by name, project packages shadowing user ones.
+func getFormulaPackageDirs() map[string]string {
+	dirs := make(map[string]string)
+	for _, global := range []bool{true, false} {
+		lockPath := formulaInstaller(global).LockPath()
+		lock, err := formula.LoadPackageLock(lockPath)
+		if err != nil {
+			continue
+		}
+		for name, dir := range lock.PackageDirs(lockPath) {
+			dirs[name] = dir
+		}
+	}
+	return dirs
+}
+
+func printPackageChanges(changes []*formula.PackageChange, lockPath string) {
+	if jsonOutput {
+		if changes == nil {
+			changes = []*formula.PackageChange{}
+		}
+		outputJSON(changes)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Println("Formula packages are already up to date.")
+		return
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	for _, c := range changes {
+		pin := c.Pin
+		if len(pin) > 12 {
+			pin = pin[:12]
+		}
+		if c.OldVersion != "" && c.OldVersion != c.NewVersion {
+			fmt.Printf("%s %s %s → %s %s\n", ui.RenderPass("✓"), c.Name, c.OldVersion, c.NewVersion, ui.RenderMuted("("+pin+")"))
+		} else {
+			fmt.Printf("%s %s %s %s\n", ui.RenderPass("✓"), c.Name, c.NewVersion, ui.RenderMuted("("+pin+")"))
+		}
+	}
+	fmt.Printf("\nLocked in %s\n", lockPath)
+}
+
+func init() {
+	formulaInstallCmd.Flags().Bool("global", false, "Install into ~/.beads instead of the project")
+	formulaInstallCmd.Flags().String("version", "", "Version constraint (alternative to source@version)")
+	formulaUpdateCmd.Flags().Bool("global", false, "Update packages in ~/.beads instead of the project")
+
+	formulaCmd.AddCommand(formulaInstallCmd)
+	formulaCmd.AddCommand(formulaUpdateCmd)
+}
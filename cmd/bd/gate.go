@@ -12,6 +12,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/gitlab"
 	"github.com/steveyegge/beads/internal/routing"
 	"github.com/steveyegge/beads/internal/storage/dolt"
 	"github.com/steveyegge/beads/internal/types"
@@ -33,15 +35,47 @@ Gate types:
   timer   - Expires after timeout (Phase 2)
   gh:run  - Waits for GitHub workflow (Phase 3)
   gh:pr   - Waits for PR merge (Phase 3)
+  gl:pipeline - Waits for GitLab CI/CD pipeline
+  gl:mr   - Waits for GitLab merge request merge
   bead    - Waits for cross-rig bead to close (Phase 4)
+  query   - Waits for a bd query's match count to meet a threshold
+  cmd     - Waits for a shell command to exit 0
+  approval - Waits for N approvals recorded with bd approve
 
 For bead gates, await_id format is <rig>:<bead-id> (e.g., "gastown:gt-abc123").
 
+For query gates, await_id format is [<rig>:]<query> | count<op><n>, where
+<query> uses the bd query language and <op> is one of ==, !=, >=, <=, >, <.
+Without a rig the local database is queried; closed issues only count when
+the query filters on status. Examples:
+  type=bug AND priority=0 AND label=release-blocker | count==0
+  gastown:label=approval AND status=closed | count>=3
+
+For approval gates, await_id format is <n>[ of <approver>,...], where an
+approver is a name or @<role> (the names under gate.approval.roles.<role>).
+Approvers record decisions with 'bd approve <gate-id> [--reject]'; any
+rejection escalates the gate. Examples:
+  2 of alice,bob,carol
+  2 of @release-managers
+
+For GitLab gates, await_id is a pipeline ID (gl:pipeline) or MR IID (gl:mr),
+or a branch name to follow; 'bd gate discover' fills it in from the current
+branch. GitLab is queried through its API using gitlab.url, gitlab.token and
+gitlab.project_id (or GITLAB_URL, GITLAB_TOKEN, GITLAB_PROJECT_ID).
+
+For cmd gates, await_id is the command, run from the workspace root with a
+minimal environment (PATH, HOME, ... and BD_GATE_ID) and no stdin, killed
+with its children after gate.cmd.timeout (default 2m). Exit 0 resolves the
+gate; the retry exit code (75, or <code> with await type cmd:<code>, or
+gate.cmd.retry-exit-code) keeps it pending until the gate's timeout; any
+other exit code escalates. The output is recorded as a gate comment.
+
 Examples:
   bd gate list           # Show all open gates
   bd gate list --all     # Show all gates including closed
   bd gate check          # Evaluate all open gates
   bd gate check --type=bead  # Evaluate only bead gates
+  bd gate watch          # Keep evaluating gates until interrupted
   bd gate resolve <id>   # Close a gate manually`,
 }
 
@@ -272,6 +306,16 @@ This is similar to 'bd show' but validates that the issue is a gate.`,
 				fmt.Printf("    - %s\n", w)
 			}
 		}
+		if len(issue.Validations) > 0 {
+			fmt.Printf("  Validations:\n")
+			for _, v := range issue.Validations {
+				line := fmt.Sprintf("    - %s %s (%s)", v.Validator.String(), v.Outcome, v.Timestamp.Local().Format("2006-01-02 15:04"))
+				if v.Reason != "" {
+					line += ": " + v.Reason
+				}
+				fmt.Println(line)
+			}
+		}
 		if issue.Description != "" {
 			fmt.Printf("  Description: %s\n", issue.Description)
 		}
@@ -334,30 +378,60 @@ Gate types:
   gh       - Check all GitHub gates (gh:run and gh:pr)
   gh:run   - Check GitHub Actions workflow runs
   gh:pr    - Check pull request merge status
+  gl       - Check all GitLab gates (gl:pipeline and gl:mr)
+  gl:pipeline - Check GitLab CI/CD pipelines
+  gl:mr    - Check merge request merge status
   timer    - Check timer gates (auto-expire based on timeout)
   bead     - Check cross-rig bead gates
+  query    - Check query gates (shows the live match count)
+  cmd      - Run command gates (cmd and cmd:<retry-code>)
+  approval - Check approval gates (counts bd approve decisions)
   all      - Check all gate types
 
 GitHub gates use the 'gh' CLI to query status:
   - gh:run checks 'gh run view <id> --json status,conclusion'
   - gh:pr checks 'gh pr view <id> --json state,merged'
 
+GitLab gates use the GitLab API (see 'bd gitlab' for configuration).
+
 A gate is resolved when:
   - gh:run: status=completed AND conclusion=success
   - gh:pr: state=MERGED
+  - gl:pipeline: status in (success, skipped)
+  - gl:mr: state=merged
   - timer: current time > created_at + timeout
   - bead: target bead status=closed
+  - query: the number of matching issues meets the threshold
+  - cmd: the command exits 0
+  - approval: the required number of allowed approvers approved
 
 A gate is escalated when:
   - gh:run: status=completed AND conclusion in (failure, canceled)
   - gh:pr: state=CLOSED AND merged=false
+  - gl:pipeline: status in (failed, canceled)
+  - gl:mr: state=closed
+  - cmd: the command exits with a code other than 0 or the retry code,
+    or is still retrying after the gate's timeout
+  - query: the threshold is still unmet after the gate's timeout
+  - approval: an allowed approver rejected, or the quorum is still missing
+    after the gate's timeout
+
+Gates with an escalation policy (the formula's gate.escalation, or
+gate.escalation.<type> in config) also escalate when still pending at their
+timeout, and warn once past warn_at (default 80%) of it; timed human gates
+are checked for this too. With --escalate the policy's warn and escalation
+actions run, once per gate: priority:<n>, assign:<who>, wisp, notify (the
+on_gate_warn/on_gate_escalate hooks), fail (close the gate as failed) and gt.
+Gates without a policy are handed to 'gt escalate'.
 
 Examples:
   bd gate check              # Check all gates
   bd gate check --type=gh    # Check only GitHub gates
   bd gate check --type=gh:run # Check only workflow run gates
+  bd gate check --type=gl    # Check only GitLab gates
   bd gate check --type=timer # Check only timer gates
   bd gate check --type=bead  # Check only cross-rig bead gates
+  bd gate check --type=cmd   # Run only command gates
   bd gate check --dry-run    # Show what would happen without changes
   bd gate check --escalate   # Escalate expired/failed gates`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -368,32 +442,13 @@ Examples:
 		escalateFlag, _ := cmd.Flags().GetBool("escalate")
 		limit, _ := cmd.Flags().GetInt("limit")
 
-		// Get open gates
-		gateType := types.IssueType("gate")
-		filter := types.IssueFilter{
-			IssueType:     &gateType,
-			ExcludeStatus: []types.Status{types.StatusClosed},
-			Limit:         limit,
-		}
-
 		ctx := rootCtx
-		var gates []*types.Issue
-		var err error
-
-		gates, err = store.SearchIssues(ctx, "", filter)
+		filteredGates, err := listOpenGates(ctx, gateTypeFilter, limit)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Filter by type if specified
-		var filteredGates []*types.Issue
-		for _, gate := range gates {
-			if shouldCheckGate(gate, gateTypeFilter) {
-				filteredGates = append(filteredGates, gate)
-			}
-		}
-
 		if len(filteredGates) == 0 {
 			if gateTypeFilter != "" {
 				fmt.Printf("No open gates of type '%s' found.\n", gateTypeFilter)
@@ -403,106 +458,215 @@ Examples:
 			return
 		}
 
-		// Results tracking
-		type checkResult struct {
-			gate      *types.Issue
-			resolved  bool
-			escalated bool
-			reason    string
-			err       error
-		}
-		results := make([]checkResult, 0, len(filteredGates))
-
 		// Check each gate
 		now := time.Now()
+		checker := &gateChecker{}
+		checked, resolvedCount, escalatedCount, errorCount := 0, 0, 0, 0
 		for _, gate := range filteredGates {
-			result := checkResult{gate: gate}
-
-			switch {
-			case strings.HasPrefix(gate.AwaitType, "gh:run"):
-				result.resolved, result.escalated, result.reason, result.err = checkGHRun(gate)
-			case strings.HasPrefix(gate.AwaitType, "gh:pr"):
-				result.resolved, result.escalated, result.reason, result.err = checkGHPR(gate)
-			case gate.AwaitType == "timer":
-				result.resolved, result.escalated, result.reason, result.err = checkTimer(gate, now)
-			case gate.AwaitType == "bead":
-				result.resolved, result.reason = checkBeadGate(ctx, gate.AwaitID)
-			default:
-				// Skip unsupported gate types (human gates need manual resolution)
+			result, ok := checker.check(ctx, gate, now)
+			if !ok {
 				continue
 			}
-
-			results = append(results, result)
-		}
-
-		// Process results
-		resolvedCount := 0
-		escalatedCount := 0
-		errorCount := 0
-
-		for _, r := range results {
-			if r.err != nil {
-				errorCount++
-				fmt.Fprintf(os.Stderr, "%s %s: error checking - %v\n",
-					ui.RenderFail("✗"), r.gate.ID, r.err)
-				continue
-			}
-
-			if r.resolved {
+			checked++
+			switch applyGateResult(ctx, result, now, dryRun, escalateFlag) {
+			case gateOutcomeResolved:
 				resolvedCount++
-				if dryRun {
-					fmt.Printf("%s %s: would resolve - %s\n",
-						ui.RenderPass("✓"), r.gate.ID, r.reason)
-				} else {
-					// Close the gate
-					closeErr := closeGate(ctx, r.gate.ID, r.reason)
-					if closeErr != nil {
-						fmt.Fprintf(os.Stderr, "%s %s: error closing - %v\n",
-							ui.RenderFail("✗"), r.gate.ID, closeErr)
-						errorCount++
-					} else {
-						fmt.Printf("%s %s: resolved - %s\n",
-							ui.RenderPass("✓"), r.gate.ID, r.reason)
-					}
-				}
-			} else if r.escalated {
+			case gateOutcomeEscalated:
 				escalatedCount++
-				if dryRun {
-					fmt.Printf("%s %s: would escalate - %s\n",
-						ui.RenderWarn("⚠"), r.gate.ID, r.reason)
-				} else {
-					fmt.Printf("%s %s: ESCALATE - %s\n",
-						ui.RenderWarn("⚠"), r.gate.ID, r.reason)
-					// Actually escalate if flag is set
-					if escalateFlag {
-						escalateGate(r.gate, r.reason)
-					}
-				}
-			} else {
-				// Still pending
-				fmt.Printf("%s %s: pending - %s\n",
-					ui.RenderAccent("○"), r.gate.ID, r.reason)
+			case gateOutcomeError:
+				errorCount++
 			}
 		}
 
 		// Summary
-		fmt.Println()
-		fmt.Printf("Checked %d gates: %d resolved, %d escalated, %d errors\n",
-			len(results), resolvedCount, escalatedCount, errorCount)
-
 		if jsonOutput {
 			summary := map[string]interface{}{
-				"checked":   len(results),
+				"checked":   checked,
 				"resolved":  resolvedCount,
 				"escalated": escalatedCount,
 				"errors":    errorCount,
 				"dry_run":   dryRun,
 			}
 			outputJSON(summary)
+		} else {
+			fmt.Println()
+			fmt.Printf("Checked %d gates: %d resolved, %d escalated, %d errors\n",
+				checked, resolvedCount, escalatedCount, errorCount)
 		}
 	},
 }
 
+// listOpenGates returns up to limit open gates matching a type filter.
+func listOpenGates(ctx context.Context, typeFilter string, limit int) ([]*types.Issue, error) {
+	gateType := types.IssueType("gate")
+	filter := types.IssueFilter{
+		IssueType:     &gateType,
+		ExcludeStatus: []types.Status{types.StatusClosed},
+		Limit:         limit,
+	}
+	gates, err := store.SearchIssues(ctx, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*types.Issue
+	for _, gate := range gates {
+		if shouldCheckGate(gate, typeFilter) {
+			filtered = append(filtered, gate)
+		}
+	}
+	return filtered, nil
+}
+
+// gateCheckResult is the outcome of checking one gate.
+type gateCheckResult struct {
+	gate      *types.Issue
+	resolved  bool
+	escalated bool
+	reason    string
+	run       *cmdGateRun // cmd gates: the command run, recorded as a comment
+	err       error
+}
+
+// gateOutcome is what applyGateResult did with a checked gate.
+type gateOutcome int
+
+const (
+	gateOutcomePending gateOutcome = iota
+	gateOutcomeResolved
+	gateOutcomeEscalated
+	gateOutcomeError
+)
+
+// gateChecker evaluates gates, sharing API clients between them.
+type gateChecker struct {
+	glClient *gitlab.Client // Created on the first GitLab gate
+}
+
+// check evaluates a gate's condition. ok is false for gate types that are
+// not checked automatically (human gates, unless an escalation policy
+// watches their timeout).
+func (c *gateChecker) check(ctx context.Context, gate *types.Issue, now time.Time) (result gateCheckResult, ok bool) {
+	result.gate = gate
+
+	switch {
+	case strings.HasPrefix(gate.AwaitType, "gh:run"):
+		result.resolved, result.escalated, result.reason, result.err = checkGHRun(gate)
+	case strings.HasPrefix(gate.AwaitType, "gh:pr"):
+		result.resolved, result.escalated, result.reason, result.err = checkGHPR(gate)
+	case isGitLabGate(gate.AwaitType):
+		if c.glClient == nil {
+			c.glClient, result.err = newGitLabGateClient()
+		}
+		if result.err == nil {
+			result.resolved, result.escalated, result.reason, result.err = checkGitLabGate(ctx, c.glClient, gate)
+		}
+	case gate.AwaitType == "timer":
+		result.resolved, result.escalated, result.reason, result.err = checkTimer(gate, now)
+	case gate.AwaitType == "bead":
+		result.resolved, result.reason = checkBeadGate(ctx, gate.AwaitID)
+	case gate.AwaitType == queryGateType:
+		result.resolved, result.escalated, result.reason, result.err = checkQueryGate(ctx, store, gate, now)
+	case isCmdGate(gate.AwaitType):
+		result.resolved, result.escalated, result.reason, result.run, result.err = checkCmdGate(ctx, gate, now)
+	case gate.AwaitType == approvalGateType:
+		result.resolved, result.escalated, result.reason, result.err = checkApprovalGate(gate, now)
+	case gate.AwaitType == "human" && gate.Timeout > 0 && gateEscalationPolicy(gate) != nil:
+		// Human gates need manual resolution; they are only checked
+		// so their escalation policy can act as the timeout nears
+		result.reason = "awaiting manual resolution"
+	default:
+		// Skip unsupported gate types (human gates need manual resolution)
+		return result, false
+	}
+	return result, true
+}
+
+// applyGateResult acts on a checked gate and prints a line for it, unless
+// --json is set: it closes resolved gates, escalates failed or expired ones
+// (running their escalation policy, or 'gt escalate', when escalate is set)
+// and warns about pending gates nearing their timeout.
+func applyGateResult(ctx context.Context, r gateCheckResult, now time.Time, dryRun, escalate bool) gateOutcome {
+	if r.err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s: error checking - %v\n",
+			ui.RenderFail("✗"), r.gate.ID, r.err)
+		return gateOutcomeError
+	}
+
+	// Record the command output once the gate resolves or escalates
+	if r.run != nil && (r.resolved || r.escalated) && !dryRun {
+		if _, err := store.AddIssueComment(ctx, r.gate.ID, getActorWithGit(), cmdGateComment(r.gate, r.run, r.reason)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record command output on %s: %v\n", r.gate.ID, err)
+		}
+	}
+
+	// Gates with an escalation policy warn as their timeout nears and
+	// escalate once it expires, whatever their check says
+	var policy *formula.EscalationPolicy
+	stage := stageNone
+	if !r.resolved {
+		policy = gateEscalationPolicy(r.gate)
+		stage = gateEscalationStage(r.gate, policy, r.escalated, now)
+		if stage == stageEscalate && !r.escalated {
+			r.escalated = true
+			r.reason = fmt.Sprintf("timed out after %s (%s)", r.gate.Timeout, r.reason)
+		}
+	}
+
+	switch {
+	case r.resolved:
+		if dryRun {
+			if !jsonOutput {
+				fmt.Printf("%s %s: would resolve - %s\n",
+					ui.RenderPass("✓"), r.gate.ID, r.reason)
+			}
+			return gateOutcomeResolved
+		}
+		if err := closeGate(ctx, r.gate.ID, r.reason); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s: error closing - %v\n",
+				ui.RenderFail("✗"), r.gate.ID, err)
+			return gateOutcomeError
+		}
+		if !jsonOutput {
+			fmt.Printf("%s %s: resolved - %s\n",
+				ui.RenderPass("✓"), r.gate.ID, r.reason)
+		}
+		return gateOutcomeResolved
+	case r.escalated:
+		if dryRun {
+			if !jsonOutput {
+				fmt.Printf("%s %s: would escalate - %s\n",
+					ui.RenderWarn("⚠"), r.gate.ID, r.reason)
+			}
+			return gateOutcomeEscalated
+		}
+		if !jsonOutput {
+			fmt.Printf("%s %s: ESCALATE - %s\n",
+				ui.RenderWarn("⚠"), r.gate.ID, r.reason)
+		}
+		if escalate {
+			if policy == nil {
+				escalateGate(r.gate, r.reason)
+			} else {
+				reportGateEscalation(ctx, r.gate, policy, stageEscalate, r.reason)
+			}
+		}
+		return gateOutcomeEscalated
+	default:
+		if stage == stageWarn {
+			r.reason += fmt.Sprintf("; past %.0f%% of its %s timeout", policy.WarnFraction()*100, r.gate.Timeout)
+		}
+		if !jsonOutput {
+			fmt.Printf("%s %s: pending - %s\n",
+				ui.RenderAccent("○"), r.gate.ID, r.reason)
+		}
+		if stage == stageWarn && escalate && !dryRun {
+			reportGateEscalation(ctx, r.gate, policy, stageWarn, r.reason)
+		}
+		return gateOutcomePending
+	}
+}
+
 // shouldCheckGate returns true if the gate matches the type filter
 func shouldCheckGate(gate *types.Issue, typeFilter string) bool {
 	if typeFilter == "" || typeFilter == "all" {
@@ -511,6 +675,12 @@ func shouldCheckGate(gate *types.Issue, typeFilter string) bool {
 	if typeFilter == "gh" {
 		return strings.HasPrefix(gate.AwaitType, "gh:")
 	}
+	if typeFilter == "gl" {
+		return strings.HasPrefix(gate.AwaitType, "gl:")
+	}
+	if typeFilter == cmdGateType {
+		return isCmdGate(gate.AwaitType)
+	}
 	return gate.AwaitType == typeFilter
 }
 
@@ -739,21 +909,9 @@ func checkBeadGate(ctx context.Context, awaitID string) (bool, string) {
 		return false, "await_id missing rig name or bead ID"
 	}
 
-	// Resolve the target rig's beads directory
-	currentBeadsDir := beads.FindBeadsDir()
-	if currentBeadsDir == "" {
-		return false, "could not find current beads directory"
-	}
-	targetBeadsDir, _, err := routing.ResolveBeadsDirForRig(rigName, currentBeadsDir)
-	if err != nil {
-		return false, fmt.Sprintf("rig %q not found: %v", rigName, err)
-	}
-
-	// Open the target database (read-only) using storage factory
-	// This supports both Dolt and legacy SQLite backends in the target rig.
-	targetStore, err := dolt.NewFromConfigWithOptions(ctx, targetBeadsDir, &dolt.Config{ReadOnly: true})
+	targetStore, err := openRigStore(ctx, rigName)
 	if err != nil {
-		return false, fmt.Sprintf("failed to open database for rig %q: %v", rigName, err)
+		return false, err.Error()
 	}
 	defer func() { _ = targetStore.Close() }()
 
@@ -770,9 +928,30 @@ func checkBeadGate(ctx context.Context, awaitID string) (bool, string) {
 	return false, fmt.Sprintf("target bead %s status is %q (waiting for closed)", beadID, string(issue.Status))
 }
 
+// openRigStore opens a routed rig's database read-only.
+func openRigStore(ctx context.Context, rigName string) (*dolt.DoltStore, error) {
+	// Resolve the target rig's beads directory
+	currentBeadsDir := beads.FindBeadsDir()
+	if currentBeadsDir == "" {
+		return nil, fmt.Errorf("could not find current beads directory")
+	}
+	targetBeadsDir, _, err := routing.ResolveBeadsDirForRig(rigName, currentBeadsDir)
+	if err != nil {
+		return nil, fmt.Errorf("rig %q not found: %v", rigName, err)
+	}
+
+	// Open the target database (read-only) using storage factory
+	// This supports both Dolt and legacy SQLite backends in the target rig.
+	targetStore, err := dolt.NewFromConfigWithOptions(ctx, targetBeadsDir, &dolt.Config{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database for rig %q: %v", rigName, err)
+	}
+	return targetStore, nil
+}
+
 // closeGate closes a gate issue with the given reason
-func closeGate(_ interface{}, gateID, reason string) error {
-	if err := store.CloseIssue(rootCtx, gateID, reason, actor, ""); err != nil {
+func closeGate(ctx context.Context, gateID, reason string) error {
+	if err := store.CloseIssue(ctx, gateID, reason, actor, ""); err != nil {
 		return err
 	}
 	return nil
@@ -790,6 +969,9 @@ func escalateGate(gate *types.Issue, reason string) {
 	// Call gt escalate if available
 	escalateCmd := exec.Command("gt", "escalate", topic, "-s", "HIGH", "-m", message)
 	escalateCmd.Stdout = os.Stdout
+	if jsonOutput {
+		escalateCmd.Stdout = os.Stderr // Keep stdout valid JSON
+	}
 	escalateCmd.Stderr = os.Stderr
 	if err := escalateCmd.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: escalation failed for %s: %v\n", gate.ID, err)
@@ -805,9 +987,9 @@ func init() {
 	gateResolveCmd.Flags().StringP("reason", "r", "", "Reason for resolving the gate")
 
 	// gate check flags
-	gateCheckCmd.Flags().StringP("type", "t", "", "Gate type to check (gh, gh:run, gh:pr, timer, bead, all)")
+	gateCheckCmd.Flags().StringP("type", "t", "", "Gate type to check (gh, gh:run, gh:pr, gl, gl:pipeline, gl:mr, timer, bead, query, cmd, approval, all)")
 	gateCheckCmd.Flags().Bool("dry-run", false, "Show what would happen without making changes")
-	gateCheckCmd.Flags().BoolP("escalate", "e", false, "Escalate failed/expired gates")
+	gateCheckCmd.Flags().BoolP("escalate", "e", false, "Run escalation actions for failed/expired gates")
 	gateCheckCmd.Flags().IntP("limit", "l", 100, "Limit results (default 100)")
 
 	// Issue ID completions
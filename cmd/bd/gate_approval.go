@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// approvalGateType is the await type of gates that resolve once enough
+// approvers sign off with bd approve.
+const approvalGateType = "approval"
+
+// approvalGatePattern matches an approval gate await_id.
+var approvalGatePattern = regexp.MustCompile(`^(\d+)(?:\s+of\s+(.+))?$`)
+
+// approvalGateSpec is a parsed approval gate await_id:
+//
+//	<n>[ of <approver>[,<approver>...]]
+//
+// Approvers are names, or @<role> for the names listed under
+// gate.approval.roles.<role> in config, e.g. "2 of alice,bob,carol" or
+// "2 of @release-managers". Without approvers anyone may approve.
+type approvalGateSpec struct {
+	Required  int
+	Approvers []string // Allowed approvers, roles expanded; empty allows anyone
+}
+
+// parseApprovalGate parses an approval gate's await_id.
+func parseApprovalGate(awaitID string) (*approvalGateSpec, error) {
+	m := approvalGatePattern.FindStringSubmatch(strings.TrimSpace(awaitID))
+	if m == nil {
+		return nil, fmt.Errorf("invalid await_id %q: expected <n>[ of <approver>,...]", awaitID)
+	}
+	required, _ := strconv.Atoi(m[1]) // digits only, matched above
+	if required < 1 {
+		return nil, fmt.Errorf("invalid await_id %q: at least one approval must be required", awaitID)
+	}
+	spec := &approvalGateSpec{Required: required}
+
+	for _, name := range strings.Split(m[2], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		role, isRole := strings.CutPrefix(name, "@")
+		if !isRole {
+			spec.addApprover(name)
+			continue
+		}
+		members := config.GetStringSlice("gate.approval.roles." + role)
+		if len(members) == 0 {
+			return nil, fmt.Errorf("approval role %q has no members (set gate.approval.roles.%s)", role, role)
+		}
+		for _, member := range members {
+			spec.addApprover(strings.TrimSpace(member))
+		}
+	}
+
+	if len(spec.Approvers) > 0 && spec.Required > len(spec.Approvers) {
+		return nil, fmt.Errorf("invalid await_id %q: %d approvals required but only %d approver%s allowed",
+			awaitID, spec.Required, len(spec.Approvers), pluralize(len(spec.Approvers)))
+	}
+	return spec, nil
+}
+
+func (s *approvalGateSpec) addApprover(name string) {
+	if name != "" && !slices.Contains(s.Approvers, name) {
+		s.Approvers = append(s.Approvers, name)
+	}
+}
+
+// Allows reports whether name may approve or reject the gate.
+func (s *approvalGateSpec) Allows(name string) bool {
+	return len(s.Approvers) == 0 || slices.Contains(s.Approvers, name)
+}
+
+// Quorum describes the approvals required, e.g. "2 of 3".
+func (s *approvalGateSpec) Quorum() string {
+	if len(s.Approvers) == 0 {
+		return strconv.Itoa(s.Required)
+	}
+	return fmt.Sprintf("%d of %d", s.Required, len(s.Approvers))
+}
+
+// approvalTally is the standing decision of each allowed approver of a
+// gate: the latest validation they recorded.
+type approvalTally struct {
+	Approved []string
+	Rejected []string
+	Reasons  map[string]string // Rejection reasons by approver
+}
+
+// tallyApprovals counts the validations of allowed approvers, keeping each
+// approver's latest decision so a rejection can be withdrawn by approving.
+// Decisions other than accept and reject leave an approver undecided.
+func tallyApprovals(spec *approvalGateSpec, validations []types.Validation) approvalTally {
+	var order []string
+	latest := make(map[string]types.Validation)
+	for _, v := range validations {
+		name := v.Validator.String()
+		if name == "" || !spec.Allows(name) {
+			continue
+		}
+		if _, seen := latest[name]; !seen {
+			order = append(order, name)
+		}
+		latest[name] = v
+	}
+
+	tally := approvalTally{Reasons: make(map[string]string)}
+	for _, name := range order {
+		switch v := latest[name]; v.Outcome {
+		case types.ValidationAccepted:
+			tally.Approved = append(tally.Approved, name)
+		case types.ValidationRejected:
+			tally.Rejected = append(tally.Rejected, name)
+			if v.Reason != "" {
+				tally.Reasons[name] = v.Reason
+			}
+		}
+	}
+	return tally
+}
+
+// checkApprovalGate resolves an approval gate once the required number of
+// allowed approvers have approved it. Any standing rejection escalates the
+// gate, as does a quorum still missing after the gate's Timeout.
+func checkApprovalGate(gate *types.Issue, now time.Time) (resolved, escalated bool, reason string, err error) {
+	spec, err := parseApprovalGate(gate.AwaitID)
+	if err != nil {
+		return false, false, "", err
+	}
+	tally := tallyApprovals(spec, gate.Validations)
+
+	if len(tally.Rejected) > 0 {
+		var rejections []string
+		for _, name := range tally.Rejected {
+			if r := tally.Reasons[name]; r != "" {
+				rejections = append(rejections, fmt.Sprintf("%s (%s)", name, r))
+			} else {
+				rejections = append(rejections, name)
+			}
+		}
+		return false, true, "rejected by " + strings.Join(rejections, ", "), nil
+	}
+
+	count := fmt.Sprintf("%d of %d required approval%s", len(tally.Approved), spec.Required, pluralize(spec.Required))
+	if len(tally.Approved) > 0 {
+		count += fmt.Sprintf(" (%s)", strings.Join(tally.Approved, ", "))
+	}
+	if len(tally.Approved) >= spec.Required {
+		return true, false, "approved: " + count, nil
+	}
+
+	pending := count
+	if len(spec.Approvers) > 0 {
+		var waiting []string
+		for _, name := range spec.Approvers {
+			if !slices.Contains(tally.Approved, name) {
+				waiting = append(waiting, name)
+			}
+		}
+		pending += fmt.Sprintf(", waiting on %s", strings.Join(waiting, ", "))
+	}
+	if gate.Timeout > 0 {
+		deadline := gate.CreatedAt.Add(gate.Timeout)
+		if now.After(deadline) {
+			return false, true, fmt.Sprintf("%s; gate timed out %s ago", pending, now.Sub(deadline).Round(time.Second)), nil
+		}
+		pending += fmt.Sprintf("; times out in %s", deadline.Sub(now).Round(time.Second))
+	}
+	return false, false, pending, nil
+}
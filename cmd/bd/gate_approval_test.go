@@ -0,0 +1,148 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestParseApprovalGate(t *testing.T) {
+	config.ResetForTesting()
+	if err := config.Initialize(); err != nil {
+		t.Fatalf("config.Initialize() returned error: %v", err)
+	}
+	config.Set("gate.approval.roles.release", []string{"alice", "bob", "carol"})
+	t.Cleanup(config.ResetForTesting)
+
+	tests := []struct {
+		awaitID       string
+		wantRequired  int
+		wantApprovers []string
+		wantErr       string
+	}{
+		{awaitID: "1", wantRequired: 1},
+		{awaitID: "2 of alice, bob,carol", wantRequired: 2, wantApprovers: []string{"alice", "bob", "carol"}},
+		{awaitID: "2 of @release", wantRequired: 2, wantApprovers: []string{"alice", "bob", "carol"}},
+		{awaitID: "2 of dave,@release,alice", wantRequired: 2, wantApprovers: []string{"dave", "alice", "bob", "carol"}},
+		{awaitID: "0", wantErr: "at least one"},
+		{awaitID: "3 of alice,bob", wantErr: "only 2 approvers"},
+		{awaitID: "2 of @nobody", wantErr: "has no members"},
+		{awaitID: "two of alice", wantErr: "expected <n>"},
+		{awaitID: "", wantErr: "expected <n>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.awaitID, func(t *testing.T) {
+			spec, err := parseApprovalGate(tt.awaitID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if spec.Required != tt.wantRequired || !reflect.DeepEqual(spec.Approvers, tt.wantApprovers) {
+				t.Errorf("spec = %+v, want required %d approvers %v", spec, tt.wantRequired, tt.wantApprovers)
+			}
+		})
+	}
+}
+
+func approval(name, outcome, reason string) types.Validation {
+	return types.Validation{
+		Validator: &types.EntityRef{Name: name},
+		Outcome:   outcome,
+		Reason:    reason,
+	}
+}
+
+func TestTallyApprovals(t *testing.T) {
+	spec := &approvalGateSpec{Required: 2, Approvers: []string{"alice", "bob", "carol"}}
+	tally := tallyApprovals(spec, []types.Validation{
+		approval("alice", types.ValidationAccepted, ""),
+		approval("mallory", types.ValidationAccepted, ""),          // not an approver
+		approval("bob", types.ValidationRejected, "tests failing"), // withdrawn below
+		approval("alice", types.ValidationAccepted, ""),            // counted once
+		approval("carol", types.ValidationRevisionRequested, ""),   // undecided
+		approval("bob", types.ValidationAccepted, "fixed"),
+	})
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(tally.Approved, want) {
+		t.Errorf("Approved = %v, want %v", tally.Approved, want)
+	}
+	if len(tally.Rejected) != 0 {
+		t.Errorf("Rejected = %v, want none", tally.Rejected)
+	}
+}
+
+func TestCheckApprovalGate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	gate := func(awaitID string, timeout time.Duration, validations ...types.Validation) *types.Issue {
+		return &types.Issue{
+			ID:          "g1",
+			IssueType:   "gate",
+			AwaitType:   approvalGateType,
+			AwaitID:     awaitID,
+			Timeout:     timeout,
+			CreatedAt:   now.Add(-time.Hour),
+			Validations: validations,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		gate          *types.Issue
+		wantResolved  bool
+		wantEscalated bool
+		wantReason    string
+	}{
+		{
+			name:       "no decisions",
+			gate:       gate("2 of alice,bob,carol", 0),
+			wantReason: "0 of 2 required approvals, waiting on alice, bob, carol",
+		},
+		{
+			name:       "partial quorum",
+			gate:       gate("2 of alice,bob,carol", 2*time.Hour, approval("alice", types.ValidationAccepted, "")),
+			wantReason: "1 of 2 required approvals (alice), waiting on bob, carol; times out in 1h0m0s",
+		},
+		{
+			name: "quorum reached",
+			gate: gate("2 of alice,bob,carol", 0,
+				approval("alice", types.ValidationAccepted, ""),
+				approval("carol", types.ValidationAccepted, "")),
+			wantResolved: true,
+			wantReason:   "approved: 2 of 2 required approvals (alice, carol)",
+		},
+		{
+			name: "any reject escalates",
+			gate: gate("1", 0,
+				approval("alice", types.ValidationAccepted, ""),
+				approval("bob", types.ValidationRejected, "changelog missing")),
+			wantEscalated: true,
+			wantReason:    "rejected by bob (changelog missing)",
+		},
+		{
+			name:          "timed out",
+			gate:          gate("1 of alice", 30*time.Minute),
+			wantEscalated: true,
+			wantReason:    "0 of 1 required approval, waiting on alice; gate timed out 30m0s ago",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, escalated, reason, err := checkApprovalGate(tt.gate, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resolved != tt.wantResolved || escalated != tt.wantEscalated || reason != tt.wantReason {
+				t.Errorf("got (%v, %v, %q), want (%v, %v, %q)",
+					resolved, escalated, reason, tt.wantResolved, tt.wantEscalated, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+const (
+	// cmdGateType is the await type of gates that run a shell command
+	// (await_id) and resolve when it exits 0. "cmd:<code>" overrides the
+	// retry exit code.
+	cmdGateType = "cmd"
+
+	// defaultCmdGateRetryCode keeps a cmd gate pending: EX_TEMPFAIL.
+	defaultCmdGateRetryCode = 75
+
+	// defaultCmdGateTimeout bounds a single run of a cmd gate's command.
+	defaultCmdGateTimeout = 2 * time.Minute
+
+	// maxCmdGateOutput is how much command output is kept, from the end.
+	maxCmdGateOutput = 4096
+)
+
+// cmdGateEnv lists the environment variables passed through to gate
+// commands. Everything else (tokens, credentials) is withheld.
+var cmdGateEnv = []string{"PATH", "HOME", "USER", "LOGNAME", "LANG", "LC_ALL", "TMPDIR", "TEMP", "TMP", "SYSTEMROOT", "COMSPEC"}
+
+// cmdGateRun is the outcome of one run of a cmd gate's command.
+type cmdGateRun struct {
+	ExitCode int
+	TimedOut bool
+	Duration time.Duration
+	Output   string // Combined stdout and stderr, truncated to the last maxCmdGateOutput bytes
+}
+
+// isCmdGate reports whether an await type is a cmd gate (cmd or cmd:<code>).
+func isCmdGate(awaitType string) bool {
+	return awaitType == cmdGateType || strings.HasPrefix(awaitType, cmdGateType+":")
+}
+
+// cmdGateRetryCode returns the exit code that keeps a cmd gate pending: the
+// <code> of "cmd:<code>", else gate.cmd.retry-exit-code, else 75.
+func cmdGateRetryCode(awaitType string) (int, error) {
+	if code, ok := strings.CutPrefix(awaitType, cmdGateType+":"); ok {
+		n, err := strconv.Atoi(code)
+		if err != nil || n <= 0 || n > 255 {
+			return 0, fmt.Errorf("invalid retry exit code %q in await type %s (expected 1-255)", code, awaitType)
+		}
+		return n, nil
+	}
+	if n := config.GetInt("gate.cmd.retry-exit-code"); n > 0 {
+		return n, nil
+	}
+	return defaultCmdGateRetryCode, nil
+}
+
+// cmdGateTimeout returns how long one run of a gate command may take.
+func cmdGateTimeout() time.Duration {
+	if d := config.GetDuration("gate.cmd.timeout"); d > 0 {
+		return d
+	}
+	return defaultCmdGateTimeout
+}
+
+// checkCmdGate runs a cmd gate's command. Exit 0 resolves the gate; the
+// retry exit code (or a run that times out) leaves it pending until the
+// gate's Timeout, after which it escalates; any other exit code escalates.
+// The run is returned so its output can be recorded on the gate.
+func checkCmdGate(ctx context.Context, gate *types.Issue, now time.Time) (resolved, escalated bool, reason string, run *cmdGateRun, err error) {
+	if strings.TrimSpace(gate.AwaitID) == "" {
+		return false, false, "no command specified - set await_id to a shell command", nil, nil
+	}
+	retryCode, err := cmdGateRetryCode(gate.AwaitType)
+	if err != nil {
+		return false, false, "", nil, err
+	}
+
+	run, err = runGateCommand(ctx, gate.AwaitID, cmdGateDir(), cmdGateEnviron(gate), cmdGateTimeout())
+	if err != nil {
+		return false, false, "", nil, fmt.Errorf("running gate command: %w", err)
+	}
+
+	var pending string
+	switch {
+	case run.TimedOut:
+		pending = fmt.Sprintf("command timed out after %s", cmdGateTimeout())
+	case run.ExitCode == 0:
+		return true, false, fmt.Sprintf("command succeeded in %s", run.Duration.Round(time.Millisecond)), run, nil
+	case run.ExitCode == retryCode:
+		pending = fmt.Sprintf("command exited %d (retry)", run.ExitCode)
+	default:
+		return false, true, fmt.Sprintf("command failed with exit code %d", run.ExitCode), run, nil
+	}
+
+	if gate.Timeout > 0 {
+		deadline := gate.CreatedAt.Add(gate.Timeout)
+		if now.After(deadline) {
+			return false, true, fmt.Sprintf("%s; gate timed out %s ago", pending, now.Sub(deadline).Round(time.Second)), run, nil
+		}
+		pending += fmt.Sprintf("; times out in %s", deadline.Sub(now).Round(time.Second))
+	}
+	return false, false, pending, run, nil
+}
+
+// cmdGateDir returns the directory gate commands run in: the workspace root
+// (the parent of .beads), else the current directory.
+func cmdGateDir() string {
+	if beadsDir := beads.FindBeadsDir(); beadsDir != "" {
+		return filepath.Dir(beadsDir)
+	}
+	return ""
+}
+
+// cmdGateEnviron returns the environment for a gate command: the allowed
+// variables of the current environment plus BD_GATE_ID.
+func cmdGateEnviron(gate *types.Issue) []string {
+	var env []string
+	for _, name := range cmdGateEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return append(env, "BD_GATE_ID="+gate.ID)
+}
+
+// shellCommand returns the shell invocation for a command string.
+func shellCommand(command string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", command}
+	}
+	return "sh", []string{"-c", command}
+}
+
+// cmdGateComment formats a command run as a gate comment.
+func cmdGateComment(gate *types.Issue, run *cmdGateRun, reason string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Gate command: %s\n", gate.AwaitID)
+	if run.TimedOut {
+		fmt.Fprintf(&sb, "Timed out after %s\n", run.Duration.Round(time.Millisecond))
+	} else {
+		fmt.Fprintf(&sb, "Exit code: %d (%s)\n", run.ExitCode, run.Duration.Round(time.Millisecond))
+	}
+	fmt.Fprintf(&sb, "Result: %s\n", reason)
+	if output := strings.TrimRight(run.Output, "\n"); output != "" {
+		fmt.Fprintf(&sb, "\n```\n%s\n```", output)
+	}
+	return sb.String()
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = append([]byte(nil), b.buf[len(b.buf)-b.max:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	if b.truncated {
+		return "[...output truncated...]\n" + string(b.buf)
+	}
+	return string(b.buf)
+}
@@ -0,0 +1,94 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestCheckCmdGate(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name          string
+		awaitType     string
+		command       string
+		age           time.Duration // Since the gate was created
+		timeout       time.Duration // Gate timeout
+		wantResolved  bool
+		wantEscalated bool
+		wantReason    string
+	}{
+		{"exit 0 resolves", "cmd", "exit 0", 0, 0, true, false, "succeeded"},
+		{"retry code stays pending", "cmd", "exit 75", 0, time.Hour, false, false, "exited 75 (retry)"},
+		{"custom retry code", "cmd:3", "exit 3", 0, 0, false, false, "exited 3 (retry)"},
+		{"default retry code is not retry with override", "cmd:3", "exit 75", 0, 0, false, true, "exit code 75"},
+		{"other exit code escalates", "cmd", "exit 2", 0, 0, false, true, "exit code 2"},
+		{"retry after timeout escalates", "cmd", "exit 75", 2 * time.Hour, time.Hour, false, true, "gate timed out"},
+		{"success after timeout resolves", "cmd", "true", 2 * time.Hour, time.Hour, true, false, "succeeded"},
+		{"empty command", "cmd", "", 0, 0, false, false, "no command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := &types.Issue{ID: "bd-gate", AwaitType: tt.awaitType, AwaitID: tt.command, CreatedAt: now.Add(-tt.age), Timeout: tt.timeout}
+			resolved, escalated, reason, _, err := checkCmdGate(context.Background(), gate, now)
+			if err != nil {
+				t.Fatalf("checkCmdGate: %v", err)
+			}
+			if resolved != tt.wantResolved || escalated != tt.wantEscalated {
+				t.Errorf("resolved, escalated = %v, %v; want %v, %v (%s)", resolved, escalated, tt.wantResolved, tt.wantEscalated, reason)
+			}
+			if !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("reason = %q, want it to contain %q", reason, tt.wantReason)
+			}
+		})
+	}
+
+	if _, _, _, _, err := checkCmdGate(context.Background(), &types.Issue{AwaitType: "cmd:x", AwaitID: "true"}, now); err == nil {
+		t.Error("invalid retry code should fail")
+	}
+}
+
+func TestRunGateCommand(t *testing.T) {
+	t.Setenv("BD_TEST_SECRET", "hunter2")
+	gate := &types.Issue{ID: "bd-gate"}
+
+	run, err := runGateCommand(context.Background(), `echo "out $BD_GATE_ID"; echo err >&2; echo "secret=$BD_TEST_SECRET"; exit 4`, t.TempDir(), cmdGateEnviron(gate), 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.ExitCode != 4 {
+		t.Errorf("exit code = %d, want 4", run.ExitCode)
+	}
+	for _, want := range []string{"out bd-gate", "err", "secret=\n"} {
+		if !strings.Contains(run.Output, want) {
+			t.Errorf("output %q missing %q", run.Output, want)
+		}
+	}
+
+	// Times out, killing background children too
+	start := time.Now()
+	run, err = runGateCommand(context.Background(), "sleep 30 & sleep 30", t.TempDir(), cmdGateEnviron(gate), 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !run.TimedOut {
+		t.Errorf("expected timeout, got exit code %d", run.ExitCode)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timed out command took %s", elapsed)
+	}
+
+	// Output is capped to its tail
+	run, err = runGateCommand(context.Background(), "i=0; while [ $i -lt 2000 ]; do echo line-$i; i=$((i+1)); done", t.TempDir(), cmdGateEnviron(gate), 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(run.Output, "[...output truncated...]") || !strings.Contains(run.Output, "line-1999") {
+		t.Errorf("output not truncated to its tail: %d bytes", len(run.Output))
+	}
+}
@@ -0,0 +1,66 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// runGateCommand runs a gate command through the shell in its own process
+// group, with no stdin and the given environment. The whole group is killed
+// when the command exits or outlives timeout (or ctx ends), so background
+// children can neither keep the gate check hanging nor outlive it.
+func runGateCommand(ctx context.Context, command, dir string, env []string, timeout time.Duration) (*cmdGateRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	shell, args := shellCommand(command)
+	cmd := exec.Command(shell, args...) // #nosec G204 -- gate commands are configured by the project, like hooks
+	cmd.Dir = dir
+	cmd.Env = env
+	output := &tailBuffer{max: maxCmdGateOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = time.Second // Don't wait on pipes held open by background children
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	run := &cmdGateRun{}
+	var err error
+	select {
+	case <-ctx.Done():
+		if killErr := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); killErr != nil && !errors.Is(killErr, syscall.ESRCH) {
+			return nil, killErr
+		}
+		<-done
+		run.TimedOut = true
+	case err = <-done:
+		// Reap anything the command left running in the background
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	run.Duration = time.Since(start)
+	run.Output = output.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case run.TimedOut:
+		run.ExitCode = -1
+	case errors.As(err, &exitErr):
+		run.ExitCode = exitErr.ExitCode()
+	case err != nil && !errors.Is(err, exec.ErrWaitDelay):
+		return nil, err
+	}
+	return run, nil
+}
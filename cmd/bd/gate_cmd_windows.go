@@ -0,0 +1,59 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+)
+
+// runGateCommand runs a gate command through the shell with no stdin and
+// the given environment, killing it if it outlives timeout. Windows lacks
+// Unix-style process groups, so detached descendants may survive.
+func runGateCommand(ctx context.Context, command, dir string, env []string, timeout time.Duration) (*cmdGateRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	shell, args := shellCommand(command)
+	cmd := exec.Command(shell, args...) // #nosec G204 -- gate commands are configured by the project, like hooks
+	cmd.Dir = dir
+	cmd.Env = env
+	output := &tailBuffer{max: maxCmdGateOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = time.Second // Don't wait on pipes held open by background children
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	run := &cmdGateRun{}
+	var err error
+	select {
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		<-done
+		run.TimedOut = true
+	case err = <-done:
+	}
+	run.Duration = time.Since(start)
+	run.Output = output.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case run.TimedOut:
+		run.ExitCode = -1
+	case errors.As(err, &exitErr):
+		run.ExitCode = exitErr.ExitCode()
+	case err != nil && !errors.Is(err, exec.ErrWaitDelay):
+		return nil, err
+	}
+	return run, nil
+}
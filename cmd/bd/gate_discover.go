@@ -31,10 +31,11 @@ type GHWorkflowRun struct {
 	URL          string    `json:"url"`
 }
 
-// gateDiscoverCmd discovers GitHub run IDs for gh:run gates
+// gateDiscoverCmd discovers GitHub run IDs for gh:run gates and GitLab
+// pipeline IDs and MR IIDs for gl:pipeline and gl:mr gates
 var gateDiscoverCmd = &cobra.Command{
 	Use:   "discover",
-	Short: "Discover await_id for gh:run gates",
+	Short: "Discover await_id for gh:run, gl:pipeline and gl:mr gates",
 	Long: `Discovers GitHub workflow run IDs for gates awaiting CI/CD completion.
 
 This command finds open gates with await_type="gh:run" that don't have an await_id,
@@ -46,6 +47,12 @@ queries recent GitHub workflow runs, and matches them using heuristics:
 Once matched, the gate's await_id is updated with the GitHub run ID, enabling
 subsequent polling to check the run's status.
 
+GitLab gates are discovered through the GitLab API (gitlab.url, gitlab.token,
+gitlab.project_id) from the branch in await_id, else the current branch:
+  - gl:pipeline gets the newest pipeline for the current commit, else the
+    newest pipeline created within --max-age
+  - gl:mr gets the newest merge request from the branch that is not closed
+
 Examples:
   bd gate discover           # Auto-discover run IDs for all matching gates
   bd gate discover --dry-run # Preview what would be matched (no updates)
@@ -56,7 +63,7 @@ Examples:
 func init() {
 	gateDiscoverCmd.Flags().BoolP("dry-run", "n", false, "Preview mode: show matches without updating")
 	gateDiscoverCmd.Flags().StringP("branch", "b", "", "Filter runs by branch (default: current branch)")
-	gateDiscoverCmd.Flags().IntP("limit", "l", 10, "Max runs to query from GitHub")
+	gateDiscoverCmd.Flags().IntP("limit", "l", 10, "Max runs (or pipelines) to query from GitHub (or GitLab)")
 	gateDiscoverCmd.Flags().DurationP("max-age", "a", 30*time.Minute, "Max age for gate/run matching")
 
 	gateCmd.AddCommand(gateDiscoverCmd)
@@ -72,25 +79,54 @@ func runGateDiscover(cmd *cobra.Command, args []string) {
 
 	ctx := rootCtx
 
-	// Step 1: Find open gh:run gates without await_id
-	gates, err := findPendingGates()
+	// Step 1: Find open gh:run, gl:pipeline and gl:mr gates without a resolved await_id
+	gates, glGates, err := findPendingGates()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error finding gates: %v\n", err)
 		os.Exit(1)
 	}
 
-	if len(gates) == 0 {
-		fmt.Println("No pending gh:run gates found (all gates have numeric run IDs)")
+	if len(gates) == 0 && len(glGates) == 0 {
+		fmt.Println("No pending gh:run, gl:pipeline or gl:mr gates found (all gates have numeric IDs)")
 		return
 	}
 
-	fmt.Printf("%s Found %d gate(s) awaiting run ID discovery\n\n", ui.RenderAccent("🔍"), len(gates))
-
 	// Get current branch if not specified
 	if branchFilter == "" {
 		branchFilter = getGitBranchForGateDiscovery()
 	}
 
+	matchCount := 0
+	if len(gates) > 0 {
+		matchCount += discoverGitHubRunGates(ctx, gates, branchFilter, limit, maxAge, dryRun)
+	}
+
+	if len(glGates) > 0 {
+		if len(gates) > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s Found %d GitLab gate(s) awaiting discovery on branch '%s'\n\n", ui.RenderAccent("🔍"), len(glGates), branchFilter)
+		n, err := discoverGitLabGates(ctx, glGates, branchFilter, limit, maxAge, dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error querying GitLab: %v\n", err)
+			os.Exit(1)
+		}
+		matchCount += n
+	}
+
+	fmt.Println()
+	if dryRun {
+		fmt.Printf("Would update %d gate(s). Run without --dry-run to apply.\n", matchCount)
+	} else {
+		fmt.Printf("Updated %d gate(s) with discovered IDs.\n", matchCount)
+	}
+}
+
+// discoverGitHubRunGates matches gh:run gates to recent workflow runs on a
+// branch and records the run IDs. Returns the number of gates matched.
+func discoverGitHubRunGates(ctx context.Context, gates []*types.Issue, branchFilter string, limit int, maxAge time.Duration, dryRun bool) int {
+	fmt.Printf("%s Found %d gate(s) awaiting run ID discovery\n\n", ui.RenderAccent("🔍"), len(gates))
+
 	// Step 2: Query recent GitHub workflow runs
 	runs, err := queryGitHubRuns(branchFilter, limit)
 	if err != nil {
@@ -100,7 +136,7 @@ func runGateDiscover(cmd *cobra.Command, args []string) {
 
 	if len(runs) == 0 {
 		fmt.Println("No recent workflow runs found on GitHub")
-		return
+		return 0
 	}
 
 	fmt.Printf("Found %d recent workflow run(s) on branch '%s'\n\n", len(runs), branchFilter)
@@ -137,13 +173,7 @@ func runGateDiscover(cmd *cobra.Command, args []string) {
 		fmt.Printf("  %s %s → run %s (%s)\n",
 			ui.RenderPass("✓"), ui.RenderID(gate.ID), runIDStr, match.Status)
 	}
-
-	fmt.Println()
-	if dryRun {
-		fmt.Printf("Would update %d gate(s). Run without --dry-run to apply.\n", matchCount)
-	} else {
-		fmt.Printf("Updated %d gate(s) with discovered run IDs.\n", matchCount)
-	}
+	return matchCount
 }
 
 // isNumericRunID returns true if the string looks like a GitHub numeric run ID.
@@ -198,10 +228,10 @@ func workflowNameMatches(hint, workflowName, runName string) bool {
 	return false
 }
 
-// findPendingGates returns open gh:run gates that need run ID discovery.
-// This includes gates with empty AwaitID OR non-numeric AwaitID (workflow name hint).
-func findPendingGates() ([]*types.Issue, error) {
-	var gates []*types.Issue
+// findPendingGates returns open gh:run gates that need run ID discovery, and
+// open gl:pipeline and gl:mr gates that need pipeline ID or MR IID discovery.
+// This includes gates with empty AwaitID OR non-numeric AwaitID (workflow name or branch hint).
+func findPendingGates() (gates, glGates []*types.Issue, err error) {
 
 	gateType := types.IssueType("gate")
 	filter := types.IssueFilter{
@@ -211,16 +241,19 @@ func findPendingGates() ([]*types.Issue, error) {
 
 	allGates, err := store.SearchIssues(rootCtx, "", filter)
 	if err != nil {
-		return nil, fmt.Errorf("search gates: %w", err)
+		return nil, nil, fmt.Errorf("search gates: %w", err)
 	}
 
 	for _, g := range allGates {
-		if needsDiscovery(g) {
+		switch {
+		case needsDiscovery(g):
 			gates = append(gates, g)
+		case needsGitLabDiscovery(g):
+			glGates = append(glGates, g)
 		}
 	}
 
-	return gates, nil
+	return gates, glGates, nil
 }
 
 // getGitBranchForGateDiscovery returns the current git branch name
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/hooks"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+const (
+	// escalationMetadataKey holds a gate's escalation policy, set by cook
+	// from the formula's gate.escalation.
+	escalationMetadataKey = "escalation"
+
+	// escalationStageMetadataKey records the last escalation stage whose
+	// actions ran on a gate, so each stage runs once.
+	escalationStageMetadataKey = "escalation_stage"
+)
+
+// escalationStage is how far a stalled gate has progressed through its
+// escalation policy.
+type escalationStage int
+
+const (
+	stageNone escalationStage = iota
+	stageWarn
+	stageEscalate
+)
+
+func (s escalationStage) String() string {
+	switch s {
+	case stageWarn:
+		return "warned"
+	case stageEscalate:
+		return "escalated"
+	default:
+		return ""
+	}
+}
+
+// gateEscalationMetadata returns the issue metadata that records a gate's
+// escalation policy, or nil without a policy.
+func gateEscalationMetadata(policy *formula.EscalationPolicy) json.RawMessage {
+	if policy == nil {
+		return nil
+	}
+	data, err := json.Marshal(map[string]interface{}{escalationMetadataKey: policy})
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// gateEscalationPolicy returns a gate's escalation policy: the one recorded
+// on the gate, else gate.escalation.<await-type> from config, else
+// gate.escalation.<prefix> (e.g. "gh" for gh:run), else
+// gate.escalation.default. Returns nil when no policy applies.
+func gateEscalationPolicy(gate *types.Issue) *formula.EscalationPolicy {
+	if raw, ok := issueOutput(gate)[escalationMetadataKey]; ok {
+		var policy formula.EscalationPolicy
+		if data, err := json.Marshal(raw); err == nil && json.Unmarshal(data, &policy) == nil {
+			return &policy
+		}
+	}
+
+	var policies map[string]*formula.EscalationPolicy
+	if err := config.UnmarshalKey("gate.escalation", &policies); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid gate.escalation config: %v\n", err)
+		return nil
+	}
+	prefix, _, _ := strings.Cut(gate.AwaitType, ":")
+	for _, key := range []string{gate.AwaitType, prefix, "default"} {
+		policy := policies[strings.ToLower(key)]
+		if key == "" || policy == nil {
+			continue
+		}
+		if err := policy.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring gate.escalation.%s: %v\n", key, err)
+			return nil
+		}
+		return policy
+	}
+	return nil
+}
+
+// gateEscalationStage returns the stage a gate that did not resolve has
+// reached: escalate if its check escalated or it has outlived its timeout,
+// warn once it has been pending for the policy's WarnAt of its timeout.
+// Timeouts only count for gates with a policy.
+func gateEscalationStage(gate *types.Issue, policy *formula.EscalationPolicy, escalated bool, now time.Time) escalationStage {
+	if escalated {
+		return stageEscalate
+	}
+	if policy == nil || gate.Timeout <= 0 {
+		return stageNone
+	}
+	elapsed := now.Sub(gate.CreatedAt)
+	switch {
+	case elapsed >= gate.Timeout:
+		return stageEscalate
+	case elapsed >= time.Duration(float64(gate.Timeout)*policy.WarnFraction()):
+		return stageWarn
+	default:
+		return stageNone
+	}
+}
+
+// gateEscalationReached returns the last stage whose actions ran on a gate.
+func gateEscalationReached(gate *types.Issue) escalationStage {
+	switch issueOutput(gate)[escalationStageMetadataKey] {
+	case stageWarn.String():
+		return stageWarn
+	case stageEscalate.String():
+		return stageEscalate
+	default:
+		return stageNone
+	}
+}
+
+// stageActions returns the policy's actions for a stage.
+func stageActions(policy *formula.EscalationPolicy, stage escalationStage) []string {
+	if stage == stageWarn {
+		return policy.Warn
+	}
+	return policy.Actions
+}
+
+// runGateEscalation runs the actions of a gate's escalation stage, records
+// the stage on the gate and comments what was done. Actions of a stage run
+// once; a stage already reached is skipped and reported as not run. An
+// action that fails does not stop the others.
+func runGateEscalation(ctx context.Context, s *dolt.DoltStore, gate *types.Issue, policy *formula.EscalationPolicy, stage escalationStage, reason, actorName string) (ran bool, err error) {
+	if stage == stageNone || gateEscalationReached(gate) >= stage {
+		return false, nil
+	}
+
+	actions := stageActions(policy, stage)
+	var errs []error
+	for _, action := range actions {
+		if err := runEscalationAction(ctx, s, gate, stage, action, reason, actorName); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", action, err))
+		}
+	}
+
+	metadata := issueOutput(gate)
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata[escalationStageMetadataKey] = stage.String()
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return true, err
+	}
+	if err := s.UpdateIssue(ctx, gate.ID, map[string]interface{}{"metadata": string(data)}, actorName); err != nil {
+		errs = append(errs, fmt.Errorf("recording escalation stage: %w", err))
+	}
+	gate.Metadata = data
+
+	title := "Gate escalated"
+	if stage == stageWarn {
+		title = "Gate escalation warning"
+	}
+	comment := fmt.Sprintf("%s: %s", title, reason)
+	if len(actions) > 0 {
+		comment += "\nActions: " + strings.Join(actions, ", ")
+	}
+	if _, err := s.AddIssueComment(ctx, gate.ID, actorName, comment); err != nil {
+		errs = append(errs, fmt.Errorf("recording escalation comment: %w", err))
+	}
+	return true, errors.Join(errs...)
+}
+
+// runEscalationAction runs a single escalation action on a gate.
+func runEscalationAction(ctx context.Context, s *dolt.DoltStore, gate *types.Issue, stage escalationStage, action, reason, actorName string) error {
+	name, arg, err := formula.ParseEscalationAction(action)
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case formula.EscalatePriority:
+		priority, _ := strconv.Atoi(arg) // validated by ParseEscalationAction
+		if gate.Priority <= priority {
+			return nil // Only ever raise priority
+		}
+		if err := s.UpdateIssue(ctx, gate.ID, map[string]interface{}{"priority": priority}, actorName); err != nil {
+			return err
+		}
+		gate.Priority = priority
+	case formula.EscalateAssign:
+		if err := s.UpdateIssue(ctx, gate.ID, map[string]interface{}{"assignee": arg}, actorName); err != nil {
+			return err
+		}
+		gate.Assignee = arg
+	case formula.EscalateWisp:
+		return createEscalationWisp(ctx, s, gate, stage, reason, actorName)
+	case formula.EscalateNotify:
+		event := hooks.EventGateEscalate
+		if stage == stageWarn {
+			event = hooks.EventGateWarn
+		}
+		return emitHookSync(hooks.NewEnvelope(event, actorName, nil, gate))
+	case formula.EscalateFail:
+		return s.CloseIssue(ctx, gate.ID, "failed: "+reason, actorName, "")
+	case formula.EscalateGT:
+		escalateGate(gate, reason)
+	}
+	return nil
+}
+
+// createEscalationWisp creates an escalation wisp for a gate, linked to the
+// gate by a caused-by dependency.
+func createEscalationWisp(ctx context.Context, s *dolt.DoltStore, gate *types.Issue, stage escalationStage, reason, actorName string) error {
+	title := fmt.Sprintf("Gate %s needs attention", gate.ID)
+	if stage == stageWarn {
+		title = fmt.Sprintf("Gate %s is nearing its timeout", gate.ID)
+	}
+	wisp := &types.Issue{
+		Title: title,
+		Description: fmt.Sprintf("Type: %s %s\nReason: %s\nCreated: %s",
+			gate.AwaitType, gate.AwaitID, reason, gate.CreatedAt.Format(time.RFC3339)),
+		Status:    types.StatusOpen,
+		Priority:  gate.Priority,
+		IssueType: types.TypeTask,
+		Assignee:  gate.Assignee,
+		Ephemeral: true,
+		WispType:  types.WispTypeEscalation,
+		CreatedBy: actorName,
+	}
+	if err := s.CreateIssue(ctx, wisp, actorName); err != nil {
+		return err
+	}
+	return s.AddDependency(ctx, &types.Dependency{IssueID: wisp.ID, DependsOnID: gate.ID, Type: types.DepCausedBy}, actorName)
+}
+
+// reportGateEscalation runs a gate's escalation stage for bd gate check and
+// reports the actions taken.
+func reportGateEscalation(ctx context.Context, gate *types.Issue, policy *formula.EscalationPolicy, stage escalationStage, reason string) {
+	ran, err := runGateEscalation(ctx, store, gate, policy, stage, reason, getActorWithGit())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: escalation of %s incomplete: %v\n", gate.ID, err)
+	}
+	if ran && !jsonOutput {
+		if actions := stageActions(policy, stage); len(actions) > 0 {
+			fmt.Printf("    %s: %s\n", stage, strings.Join(actions, ", "))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+//go:build cgo
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestGateEscalationStage(t *testing.T) {
+	now := time.Now()
+	policy := &formula.EscalationPolicy{Actions: []string{"wisp"}}
+	gate := &types.Issue{Timeout: time.Hour}
+
+	tests := []struct {
+		name      string
+		age       time.Duration
+		policy    *formula.EscalationPolicy
+		escalated bool
+		want      escalationStage
+	}{
+		{"fresh", 10 * time.Minute, policy, false, stageNone},
+		{"past warn_at", 50 * time.Minute, policy, false, stageWarn},
+		{"timed out", 61 * time.Minute, policy, false, stageEscalate},
+		{"check escalated", time.Minute, policy, true, stageEscalate},
+		{"timed out without policy", 2 * time.Hour, nil, false, stageNone},
+	}
+	for _, tt := range tests {
+		gate.CreatedAt = now.Add(-tt.age)
+		if got := gateEscalationStage(gate, tt.policy, tt.escalated, now); got != tt.want {
+			t.Errorf("%s: stage = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunGateEscalation(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+
+	step := &formula.Step{ID: "release", Gate: &formula.Gate{
+		Type:    "human",
+		Timeout: "1h",
+		Escalation: &formula.EscalationPolicy{
+			Warn:    []string{"assign:oncall"},
+			Actions: []string{"priority:0", "wisp", "fail"},
+		},
+	}}
+	gate := createGateIssue(step, "mol")
+	gate.ID = ""
+	gate.IsTemplate = false
+	if err := s.CreateIssue(ctx, gate, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := gateEscalationPolicy(gate)
+	if policy == nil || len(policy.Actions) != 3 {
+		t.Fatalf("policy from metadata = %+v, want the formula's policy", policy)
+	}
+
+	ran, err := runGateEscalation(ctx, s, gate, policy, stageWarn, "nearing timeout", "test")
+	if err != nil || !ran {
+		t.Fatalf("warn: ran=%v err=%v", ran, err)
+	}
+	if ran, _ := runGateEscalation(ctx, s, gate, policy, stageWarn, "nearing timeout", "test"); ran {
+		t.Error("warn stage ran twice")
+	}
+
+	ran, err = runGateEscalation(ctx, s, gate, policy, stageEscalate, "timed out", "test")
+	if err != nil || !ran {
+		t.Fatalf("escalate: ran=%v err=%v", ran, err)
+	}
+
+	got, err := s.GetIssue(ctx, gate.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Assignee != "oncall" || got.Priority != 0 {
+		t.Errorf("assignee = %q, priority = %d; want oncall, 0", got.Assignee, got.Priority)
+	}
+	if got.Status != types.StatusClosed || !types.IsFailureClose(got.CloseReason) {
+		t.Errorf("status = %s, close reason = %q; want closed as failed", got.Status, got.CloseReason)
+	}
+	if gateEscalationReached(got) != stageEscalate {
+		t.Errorf("recorded stage = %v, want escalated", gateEscalationReached(got))
+	}
+
+	dependents, err := s.GetDependentsWithMetadata(ctx, gate.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dependents) != 1 || dependents[0].WispType != types.WispTypeEscalation || !dependents[0].Ephemeral {
+		t.Errorf("dependents = %+v, want one escalation wisp", dependents)
+	}
+
+	comments, err := s.GetIssueComments(ctx, gate.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 2 || !strings.HasPrefix(comments[1].Text, "Gate escalated: timed out") {
+		t.Errorf("comments = %+v, want warning and escalation comments", comments)
+	}
+}
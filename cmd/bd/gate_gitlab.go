@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/gitlab"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+const (
+	// glPipelineGateType waits for a GitLab CI/CD pipeline. await_id is the
+	// pipeline ID, or a branch name whose latest pipeline is awaited.
+	glPipelineGateType = "gl:pipeline"
+
+	// glMRGateType waits for a GitLab merge request to merge. await_id is the
+	// MR IID (optionally written !<iid>), or its source branch.
+	glMRGateType = "gl:mr"
+)
+
+// isGitLabGate reports whether an await type is a GitLab gate.
+func isGitLabGate(awaitType string) bool {
+	return strings.HasPrefix(awaitType, glPipelineGateType) || strings.HasPrefix(awaitType, glMRGateType)
+}
+
+// newGitLabGateClient returns a GitLab client configured from gitlab.url,
+// gitlab.token and gitlab.project_id (or their GITLAB_* environment variables).
+func newGitLabGateClient() (*gitlab.Client, error) {
+	cfg := getGitLabConfig()
+	if cfg.URL == "" {
+		cfg.URL = "https://gitlab.com"
+	}
+	if err := validateGitLabConfig(cfg); err != nil {
+		return nil, err
+	}
+	return gitlab.NewClient(cfg.Token, cfg.URL, cfg.ProjectID), nil
+}
+
+// checkGitLabGate checks a gl:pipeline or gl:mr gate.
+func checkGitLabGate(ctx context.Context, client *gitlab.Client, gate *types.Issue) (resolved, escalated bool, reason string, err error) {
+	if strings.HasPrefix(gate.AwaitType, glMRGateType) {
+		return checkGLMR(ctx, client, gate)
+	}
+	return checkGLPipeline(ctx, client, gate)
+}
+
+// checkGLPipeline checks a GitLab pipeline gate. A branch name in await_id is
+// replaced by the ID of that branch's latest pipeline.
+func checkGLPipeline(ctx context.Context, client *gitlab.Client, gate *types.Issue) (resolved, escalated bool, reason string, err error) {
+	if gate.AwaitID == "" {
+		return false, false, "no pipeline ID specified - set await_id to a pipeline ID or branch, or run bd gate discover", nil
+	}
+
+	id, convErr := strconv.Atoi(gate.AwaitID)
+	if convErr != nil {
+		pipelines, listErr := client.ListPipelines(ctx, gate.AwaitID, 1)
+		if listErr != nil {
+			return false, false, "", listErr
+		}
+		if len(pipelines) == 0 {
+			return false, false, fmt.Sprintf("no pipelines found for ref '%s'", gate.AwaitID), nil
+		}
+		id = pipelines[0].ID
+		if updateErr := updateGateAwaitID(nil, gate.ID, strconv.Itoa(id)); updateErr != nil {
+			return false, false, "", fmt.Errorf("failed to update gate with discovered pipeline ID: %w", updateErr)
+		}
+	}
+
+	pipeline, err := client.GetPipeline(ctx, id)
+	if err != nil {
+		if isGitLabNotFound(err) {
+			return false, true, "pipeline not found", nil
+		}
+		return false, false, "", err
+	}
+	resolved, escalated, reason = glPipelineVerdict(pipeline)
+	return resolved, escalated, reason, nil
+}
+
+// glPipelineVerdict evaluates a pipeline the way checkGHRun evaluates a
+// workflow run: success (or skipped) resolves, failed or canceled escalates,
+// anything else is still pending.
+func glPipelineVerdict(p *gitlab.Pipeline) (resolved, escalated bool, reason string) {
+	name := fmt.Sprintf("pipeline #%d on '%s'", p.ID, p.Ref)
+	switch p.Status {
+	case "success":
+		return true, false, name + " succeeded"
+	case "skipped":
+		return true, false, name + " was skipped"
+	case "failed":
+		return false, true, name + " failed"
+	case "canceled", "canceling":
+		return false, true, name + " was canceled"
+	case "manual":
+		return false, false, name + " is waiting for a manual action"
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+		return false, false, fmt.Sprintf("%s is %s", name, p.Status)
+	default:
+		return false, false, fmt.Sprintf("%s status: %s", name, p.Status)
+	}
+}
+
+// checkGLMR checks a GitLab merge request gate. A branch name in await_id is
+// replaced by the IID of the merge request from that branch.
+func checkGLMR(ctx context.Context, client *gitlab.Client, gate *types.Issue) (resolved, escalated bool, reason string, err error) {
+	if gate.AwaitID == "" {
+		return false, false, "no merge request IID specified - set await_id to an MR IID or source branch, or run bd gate discover", nil
+	}
+
+	iid, convErr := strconv.Atoi(strings.TrimPrefix(gate.AwaitID, "!"))
+	if convErr != nil {
+		mr, findErr := findMergeRequestForBranch(ctx, client, gate.AwaitID)
+		if findErr != nil {
+			return false, false, "", findErr
+		}
+		if mr == nil {
+			return false, false, fmt.Sprintf("no merge request found for branch '%s'", gate.AwaitID), nil
+		}
+		iid = mr.IID
+		if updateErr := updateGateAwaitID(nil, gate.ID, strconv.Itoa(iid)); updateErr != nil {
+			return false, false, "", fmt.Errorf("failed to update gate with discovered merge request IID: %w", updateErr)
+		}
+	}
+
+	mr, err := client.GetMergeRequest(ctx, iid)
+	if err != nil {
+		if isGitLabNotFound(err) {
+			return false, true, "merge request not found", nil
+		}
+		return false, false, "", err
+	}
+	resolved, escalated, reason = glMRVerdict(mr)
+	return resolved, escalated, reason, nil
+}
+
+// glMRVerdict evaluates a merge request the way checkGHPR evaluates a pull
+// request: merged resolves, closed escalates, open (or locked) is pending.
+func glMRVerdict(mr *gitlab.MergeRequest) (resolved, escalated bool, reason string) {
+	switch mr.State {
+	case "merged":
+		return true, false, fmt.Sprintf("MR !%d '%s' was merged", mr.IID, mr.Title)
+	case "closed":
+		return false, true, fmt.Sprintf("MR !%d '%s' was closed without merging", mr.IID, mr.Title)
+	case "opened", "locked":
+		return false, false, fmt.Sprintf("MR !%d '%s' is still open", mr.IID, mr.Title)
+	default:
+		return false, false, fmt.Sprintf("MR !%d '%s' state: %s", mr.IID, mr.Title, mr.State)
+	}
+}
+
+// findMergeRequestForBranch returns the newest merge request from a source
+// branch that is not closed, or nil if there is none.
+func findMergeRequestForBranch(ctx context.Context, client *gitlab.Client, branch string) (*gitlab.MergeRequest, error) {
+	mrs, err := client.ListMergeRequests(ctx, branch, "all")
+	if err != nil {
+		return nil, err
+	}
+	for i := range mrs {
+		if mrs[i].State != "closed" {
+			return &mrs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// isGitLabNotFound reports whether a GitLab client error is a 404.
+func isGitLabNotFound(err error) bool {
+	return strings.Contains(err.Error(), "(status 404)")
+}
+
+// needsGitLabDiscovery returns true if a gl:pipeline or gl:mr gate has no
+// numeric await_id yet.
+func needsGitLabDiscovery(g *types.Issue) bool {
+	if g.AwaitType != glPipelineGateType && g.AwaitType != glMRGateType {
+		return false
+	}
+	return g.AwaitID == "" || !isNumericID(strings.TrimPrefix(g.AwaitID, "!"))
+}
+
+// discoverGitLabGates fills in the await_id of gl:pipeline and gl:mr gates
+// from the pipelines and merge requests of a branch (the gate's own branch
+// hint, else the given branch). Returns the number of gates matched.
+func discoverGitLabGates(ctx context.Context, gates []*types.Issue, branch string, limit int, maxAge time.Duration, dryRun bool) (int, error) {
+	client, err := newGitLabGateClient()
+	if err != nil {
+		return 0, err
+	}
+
+	commit := getGitCommitForGateDiscovery()
+	matchCount := 0
+	for _, gate := range gates {
+		ref := branch
+		if gate.AwaitID != "" {
+			ref = gate.AwaitID
+		}
+
+		var awaitID, label string
+		if gate.AwaitType == glMRGateType {
+			mr, err := findMergeRequestForBranch(ctx, client, ref)
+			if err != nil {
+				return matchCount, err
+			}
+			if mr != nil {
+				awaitID = strconv.Itoa(mr.IID)
+				label = fmt.Sprintf("MR !%d (%s)", mr.IID, mr.State)
+			}
+		} else {
+			pipelines, err := client.ListPipelines(ctx, ref, limit)
+			if err != nil {
+				return matchCount, err
+			}
+			if p := matchGateToPipeline(pipelines, commit, maxAge); p != nil {
+				awaitID = strconv.Itoa(p.ID)
+				label = fmt.Sprintf("pipeline %d (%s)", p.ID, p.Status)
+			}
+		}
+
+		if awaitID == "" {
+			fmt.Printf("  %s %s - no matching %s found on '%s'\n",
+				ui.RenderFail("✗"), ui.RenderID(gate.ID), gate.AwaitType, ref)
+			continue
+		}
+
+		matchCount++
+		if dryRun {
+			fmt.Printf("  %s %s → %s [dry-run]\n", ui.RenderPass("✓"), ui.RenderID(gate.ID), label)
+			continue
+		}
+		if err := updateGateAwaitID(ctx, gate.ID, awaitID); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s - update failed: %v\n",
+				ui.RenderFail("✗"), ui.RenderID(gate.ID), err)
+			continue
+		}
+		fmt.Printf("  %s %s → %s\n", ui.RenderPass("✓"), ui.RenderID(gate.ID), label)
+	}
+	return matchCount, nil
+}
+
+// matchGateToPipeline picks the pipeline a gate is waiting for: the newest
+// one for the current commit, else the newest one created within maxAge.
+// Pipelines are expected newest first, as ListPipelines returns them.
+func matchGateToPipeline(pipelines []gitlab.Pipeline, commit string, maxAge time.Duration) *gitlab.Pipeline {
+	now := time.Now()
+	var recent *gitlab.Pipeline
+	for i := range pipelines {
+		p := &pipelines[i]
+		if p.CreatedAt != nil && now.Sub(*p.CreatedAt) > maxAge {
+			continue
+		}
+		if commit != "" && p.SHA == commit {
+			return p
+		}
+		if recent == nil {
+			recent = p
+		}
+	}
+	return recent
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/gitlab"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestGLPipelineVerdict(t *testing.T) {
+	tests := []struct {
+		status        string
+		wantResolved  bool
+		wantEscalated bool
+	}{
+		{"success", true, false},
+		{"skipped", true, false},
+		{"failed", false, true},
+		{"canceled", false, true},
+		{"running", false, false},
+		{"pending", false, false},
+		{"manual", false, false},
+		{"created", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			resolved, escalated, reason := glPipelineVerdict(&gitlab.Pipeline{ID: 7, Ref: "main", Status: tt.status})
+			if resolved != tt.wantResolved || escalated != tt.wantEscalated {
+				t.Errorf("glPipelineVerdict(%s) = %v, %v (%s); want %v, %v",
+					tt.status, resolved, escalated, reason, tt.wantResolved, tt.wantEscalated)
+			}
+		})
+	}
+}
+
+func TestGLMRVerdict(t *testing.T) {
+	tests := []struct {
+		state         string
+		wantResolved  bool
+		wantEscalated bool
+	}{
+		{"merged", true, false},
+		{"closed", false, true},
+		{"opened", false, false},
+		{"locked", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			resolved, escalated, reason := glMRVerdict(&gitlab.MergeRequest{IID: 3, Title: "Fix", State: tt.state})
+			if resolved != tt.wantResolved || escalated != tt.wantEscalated {
+				t.Errorf("glMRVerdict(%s) = %v, %v (%s); want %v, %v",
+					tt.state, resolved, escalated, reason, tt.wantResolved, tt.wantEscalated)
+			}
+		})
+	}
+}
+
+func TestCheckGitLabGate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pipelines/42"):
+			_ = json.NewEncoder(w).Encode(gitlab.Pipeline{ID: 42, Ref: "main", Status: "failed"})
+		case strings.HasSuffix(r.URL.Path, "/merge_requests/5"):
+			_ = json.NewEncoder(w).Encode(gitlab.MergeRequest{IID: 5, Title: "Add gates", State: "merged"})
+		default:
+			http.Error(w, `{"message":"404 Not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient("token", server.URL, "123")
+	ctx := context.Background()
+
+	resolved, escalated, reason, err := checkGitLabGate(ctx, client, &types.Issue{AwaitType: "gl:pipeline", AwaitID: "42"})
+	if err != nil || resolved || !escalated {
+		t.Errorf("failed pipeline: resolved=%v escalated=%v reason=%q err=%v; want escalated", resolved, escalated, reason, err)
+	}
+
+	resolved, escalated, reason, err = checkGitLabGate(ctx, client, &types.Issue{AwaitType: "gl:mr", AwaitID: "!5"})
+	if err != nil || !resolved || escalated {
+		t.Errorf("merged MR: resolved=%v escalated=%v reason=%q err=%v; want resolved", resolved, escalated, reason, err)
+	}
+
+	resolved, escalated, reason, err = checkGitLabGate(ctx, client, &types.Issue{AwaitType: "gl:mr", AwaitID: "99"})
+	if err != nil || resolved || !escalated || reason != "merge request not found" {
+		t.Errorf("missing MR: resolved=%v escalated=%v reason=%q err=%v; want escalated not found", resolved, escalated, reason, err)
+	}
+
+	resolved, escalated, _, err = checkGitLabGate(ctx, client, &types.Issue{AwaitType: "gl:pipeline"})
+	if err != nil || resolved || escalated {
+		t.Errorf("pipeline without await_id should stay pending: resolved=%v escalated=%v err=%v", resolved, escalated, err)
+	}
+}
+
+func TestNeedsGitLabDiscovery(t *testing.T) {
+	tests := []struct {
+		awaitType string
+		awaitID   string
+		want      bool
+	}{
+		{"gl:pipeline", "", true},
+		{"gl:pipeline", "feature/x", true},
+		{"gl:pipeline", "123", false},
+		{"gl:mr", "", true},
+		{"gl:mr", "!12", false},
+		{"gh:run", "", false},
+	}
+	for _, tt := range tests {
+		if got := needsGitLabDiscovery(&types.Issue{AwaitType: tt.awaitType, AwaitID: tt.awaitID}); got != tt.want {
+			t.Errorf("needsGitLabDiscovery(%s, %q) = %v, want %v", tt.awaitType, tt.awaitID, got, tt.want)
+		}
+	}
+}
+
+func TestMatchGateToPipeline(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+	pipelines := []gitlab.Pipeline{
+		{ID: 3, SHA: "ccc", CreatedAt: &now},
+		{ID: 2, SHA: "bbb", CreatedAt: &now},
+		{ID: 1, SHA: "aaa", CreatedAt: &old},
+	}
+
+	if p := matchGateToPipeline(pipelines, "bbb", time.Hour); p == nil || p.ID != 2 {
+		t.Errorf("commit match = %v, want pipeline 2", p)
+	}
+	if p := matchGateToPipeline(pipelines, "zzz", time.Hour); p == nil || p.ID != 3 {
+		t.Errorf("no commit match = %v, want newest pipeline 3", p)
+	}
+	if p := matchGateToPipeline(pipelines, "aaa", time.Hour); p == nil || p.ID != 3 {
+		t.Errorf("stale commit match = %v, want newest recent pipeline 3", p)
+	}
+	if p := matchGateToPipeline(pipelines[2:], "", time.Hour); p != nil {
+		t.Errorf("only stale pipelines = %v, want nil", p)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/query"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// queryGateType is the await type of gates that resolve when the number of
+// issues matching a bd query satisfies a threshold.
+const queryGateType = "query"
+
+var (
+	// queryGateRigPattern matches the optional "<rig>:" prefix of a query gate.
+	queryGateRigPattern = regexp.MustCompile(`^([A-Za-z0-9_.-]+):`)
+
+	// queryGateThresholdPattern matches the "count<op><n>" threshold.
+	queryGateThresholdPattern = regexp.MustCompile(`^count\s*(==|!=|>=|<=|=|>|<)\s*(\d+)$`)
+)
+
+// queryGateSpec is a parsed query gate await_id:
+//
+//	[<rig>:]<query> | count<op><n>
+//
+// e.g. "type=bug AND priority=0 AND label=release-blocker | count==0".
+type queryGateSpec struct {
+	Rig       string // Routed rig to query; empty for the local database
+	Query     string
+	Op        string // ==, !=, >=, <=, >, <
+	Threshold int
+}
+
+// parseQueryGate parses a query gate's await_id.
+func parseQueryGate(awaitID string) (*queryGateSpec, error) {
+	i := strings.LastIndex(awaitID, "|")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid await_id %q: expected [<rig>:]<query> | count<op><n>", awaitID)
+	}
+	spec := &queryGateSpec{Query: strings.TrimSpace(awaitID[:i])}
+
+	threshold := strings.TrimSpace(awaitID[i+1:])
+	m := queryGateThresholdPattern.FindStringSubmatch(threshold)
+	if m == nil {
+		return nil, fmt.Errorf("invalid threshold %q: expected count<op><n> with op one of ==, !=, >=, <=, >, <", threshold)
+	}
+	spec.Op = m[1]
+	if spec.Op == "=" {
+		spec.Op = "=="
+	}
+	spec.Threshold, _ = strconv.Atoi(m[2]) // digits only, matched above
+
+	if m := queryGateRigPattern.FindStringSubmatch(spec.Query); m != nil {
+		spec.Rig = m[1]
+		spec.Query = strings.TrimSpace(spec.Query[len(m[0]):])
+	}
+	if spec.Query == "" {
+		return nil, fmt.Errorf("invalid await_id %q: missing query", awaitID)
+	}
+	return spec, nil
+}
+
+// Condition returns the threshold as written in the await_id.
+func (s *queryGateSpec) Condition() string {
+	return fmt.Sprintf("count%s%d", s.Op, s.Threshold)
+}
+
+// Holds reports whether a match count satisfies the threshold.
+func (s *queryGateSpec) Holds(count int) bool {
+	switch s.Op {
+	case "==":
+		return count == s.Threshold
+	case "!=":
+		return count != s.Threshold
+	case ">=":
+		return count >= s.Threshold
+	case "<=":
+		return count <= s.Threshold
+	case ">":
+		return count > s.Threshold
+	case "<":
+		return count < s.Threshold
+	}
+	return false
+}
+
+// countQueryMatches returns how many issues in s match a query. As with
+// bd query, closed issues are excluded unless the query filters on status.
+func countQueryMatches(ctx context.Context, s *dolt.DoltStore, expr string) (int, error) {
+	q, err := query.ParseQuery(expr)
+	if err != nil {
+		return 0, fmt.Errorf("parsing query: %w", err)
+	}
+	if len(q.GroupBy) > 0 {
+		return 0, fmt.Errorf("GROUP BY is not supported in query gates")
+	}
+	issues, _, err := runQuery(ctx, s, q, 0, false)
+	if err != nil {
+		return 0, err
+	}
+	return len(issues), nil
+}
+
+// checkQueryGate counts the issues matching a query gate's query, in the
+// local database or the named rig, and resolves the gate once the count
+// satisfies its threshold. A pending gate escalates after its Timeout.
+func checkQueryGate(ctx context.Context, s *dolt.DoltStore, gate *types.Issue, now time.Time) (resolved, escalated bool, reason string, err error) {
+	spec, err := parseQueryGate(gate.AwaitID)
+	if err != nil {
+		return false, false, "", err
+	}
+
+	where := ""
+	if spec.Rig != "" {
+		rigStore, openErr := openRigStore(ctx, spec.Rig)
+		if openErr != nil {
+			return false, false, openErr.Error(), nil
+		}
+		defer func() { _ = rigStore.Close() }()
+		s = rigStore
+		where = fmt.Sprintf(" in rig %s", spec.Rig)
+	}
+
+	count, err := countQueryMatches(ctx, s, spec.Query)
+	if err != nil {
+		return false, false, "", err
+	}
+
+	matches := fmt.Sprintf("%d matching issue%s%s", count, pluralize(count), where)
+	if spec.Holds(count) {
+		return true, false, fmt.Sprintf("%s (%s)", matches, spec.Condition()), nil
+	}
+
+	pending := fmt.Sprintf("%s, waiting for %s", matches, spec.Condition())
+	if gate.Timeout > 0 {
+		deadline := gate.CreatedAt.Add(gate.Timeout)
+		if now.After(deadline) {
+			return false, true, fmt.Sprintf("%s; gate timed out %s ago", pending, now.Sub(deadline).Round(time.Second)), nil
+		}
+		pending += fmt.Sprintf("; times out in %s", deadline.Sub(now).Round(time.Second))
+	}
+	return false, false, pending, nil
+}
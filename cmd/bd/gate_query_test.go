@@ -0,0 +1,100 @@
+//go:build cgo
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestParseQueryGate(t *testing.T) {
+	tests := []struct {
+		awaitID   string
+		wantRig   string
+		wantQuery string
+		wantCond  string
+		wantErr   bool
+	}{
+		{"type=bug AND priority=0 | count==0", "", "type=bug AND priority=0", "count==0", false},
+		{"gastown:label=approval AND status=closed | count >= 3", "gastown", "label=approval AND status=closed", "count>=3", false},
+		{"title=\"a|b\" | count=1", "", "title=\"a|b\"", "count==1", false},
+		{"status=open", "", "", "", true},
+		{"status=open | total==0", "", "", "", true},
+		{" | count==0", "", "", "", true},
+	}
+	for _, tt := range tests {
+		spec, err := parseQueryGate(tt.awaitID)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseQueryGate(%q) should fail", tt.awaitID)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseQueryGate(%q): %v", tt.awaitID, err)
+			continue
+		}
+		if spec.Rig != tt.wantRig || spec.Query != tt.wantQuery || spec.Condition() != tt.wantCond {
+			t.Errorf("parseQueryGate(%q) = %+v, want rig %q query %q %s", tt.awaitID, spec, tt.wantRig, tt.wantQuery, tt.wantCond)
+		}
+	}
+}
+
+func TestCheckQueryGate(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+
+	for i := 0; i < 2; i++ {
+		bug := &types.Issue{Title: "Blocker", Status: types.StatusOpen, Priority: 0, IssueType: types.TypeBug}
+		if err := s.CreateIssue(ctx, bug, "test"); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddLabel(ctx, bug.ID, "release-blocker", "test"); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			if err := s.CloseIssue(ctx, bug.ID, "fixed", "test", ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	now := time.Now()
+	gate := &types.Issue{
+		ID:        "gate-1",
+		AwaitType: queryGateType,
+		AwaitID:   "type=bug AND label=release-blocker | count==0",
+		CreatedAt: now.Add(-time.Minute),
+		Timeout:   time.Hour,
+	}
+	resolved, escalated, reason, err := checkQueryGate(ctx, s, gate, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved || escalated {
+		t.Errorf("one open blocker: resolved=%v escalated=%v, want pending", resolved, escalated)
+	}
+	if !strings.HasPrefix(reason, "1 matching issue, waiting for count==0") {
+		t.Errorf("reason = %q, want the live count", reason)
+	}
+
+	// Past the timeout the gate escalates
+	if _, escalated, _, _ = checkQueryGate(ctx, s, gate, now.Add(2*time.Hour)); !escalated {
+		t.Error("expected escalation after timeout")
+	}
+
+	// Closed issues count when the query filters on status
+	gate.AwaitID = "type=bug AND status=closed | count>=1"
+	if resolved, _, reason, err = checkQueryGate(ctx, s, gate, now); err != nil || !resolved {
+		t.Errorf("closed bugs: resolved=%v reason=%q err=%v, want resolved", resolved, reason, err)
+	}
+
+	gate.AwaitID = "status=open GROUP BY assignee | count==0"
+	if _, _, _, err = checkQueryGate(ctx, s, gate, now); err == nil {
+		t.Error("GROUP BY query should be rejected")
+	}
+}
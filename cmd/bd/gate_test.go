@@ -39,6 +39,12 @@ func TestShouldCheckGate(t *testing.T) {
 		{"gh filter does not match timer", "timer", "gh", false},
 		{"gh filter does not match human", "human", "gh", false},
 		{"gh filter does not match bead", "bead", "gh", false},
+		{"gh filter does not match gl:pipeline", "gl:pipeline", "gh", false},
+
+		// "gl" filter matches all GitLab types
+		{"gl filter matches gl:pipeline", "gl:pipeline", "gl", true},
+		{"gl filter matches gl:mr", "gl:mr", "gl", true},
+		{"gl filter does not match gh:pr", "gh:pr", "gl", false},
 
 		// Exact type filters
 		{"gh:run filter matches gh:run", "gh:run", "gh:run", true},
@@ -49,6 +55,9 @@ func TestShouldCheckGate(t *testing.T) {
 		{"timer filter does not match gh:run", "gh:run", "timer", false},
 		{"bead filter matches bead", "bead", "bead", true},
 		{"bead filter does not match timer", "timer", "bead", false},
+		{"cmd filter matches cmd", "cmd", "cmd", true},
+		{"cmd filter matches cmd with retry code", "cmd:3", "cmd", true},
+		{"cmd filter does not match timer", "timer", "cmd", false},
 	}
 
 	for _, tt := range tests {
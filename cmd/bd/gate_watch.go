@@ -0,0 +1,514 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/configfile"
+	"github.com/steveyegge/beads/internal/hooks"
+	"github.com/steveyegge/beads/internal/lockfile"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+const (
+	// gateWatchLockFile keeps a single bd gate watch running per workspace.
+	gateWatchLockFile = "gate-watch.lock"
+
+	// defaultGateWatchMaxBackoff caps the backoff of remote gate checks.
+	defaultGateWatchMaxBackoff = 10 * time.Minute
+)
+
+// defaultGateWatchIntervals is how often bd gate watch checks each kind of
+// gate, keyed by gateWatchKind. Override with gate.watch.intervals.<kind>.
+var defaultGateWatchIntervals = map[string]time.Duration{
+	"timer":          15 * time.Second,
+	"bead":           30 * time.Second,
+	queryGateType:    30 * time.Second,
+	cmdGateType:      time.Minute,
+	approvalGateType: 30 * time.Second,
+	"human":          time.Minute,
+	"gh":             time.Minute,
+	"gl":             time.Minute,
+}
+
+var gateWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Evaluate gates continuously",
+	Long: `Evaluate open gates continuously, closing resolved ones and escalating
+failed or expired ones, so molecules waiting on timer and CI gates advance
+without an agent polling 'bd gate check'.
+
+Each kind of gate is checked on its own interval (gate.watch.intervals.<kind>
+in config; kinds are timer, bead, query, cmd, approval, human, gh and gl). Remote checks
+(gh and gl) back off exponentially while a gate stays pending or its check
+fails, up to gate.watch.max-backoff. Gates with a timeout are also checked as
+it, or their escalation warning, comes due.
+
+Escalation actions run as with 'bd gate check --escalate' (disable with
+--escalate=false). When a closed gate unblocks steps, each is reported and
+the on_unblocked hook runs for it.
+
+Only one watcher runs per workspace (.beads/gate-watch.lock). SIGINT or
+SIGTERM stops the watcher after the gate being checked. Use --once to check
+every open gate a single time and exit, e.g. from cron.
+
+Examples:
+  bd gate watch                  # Watch all gates until interrupted
+  bd gate watch --type=gh        # Watch only GitHub gates
+  bd gate watch --once           # Check every gate once (for cron)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("gate watch")
+
+		typeFilter, _ := cmd.Flags().GetString("type")
+		escalateFlag, _ := cmd.Flags().GetBool("escalate")
+		once, _ := cmd.Flags().GetBool("once")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		beadsDir := beads.FindBeadsDir()
+		if beadsDir == "" {
+			fmt.Fprintf(os.Stderr, "Error: no beads database found\n")
+			os.Exit(1)
+		}
+
+		lock, err := acquireGateWatchLock(beadsDir)
+		if err != nil {
+			if !lockfile.IsLocked(err) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			holder := "another process"
+			if info := readGateWatchLock(beadsDir); info != nil {
+				holder = fmt.Sprintf("pid %d, since %s", info.PID, info.StartedAt.Format(time.RFC3339))
+			}
+			if once {
+				// A running watcher already covers this cron run
+				if jsonOutput {
+					fmt.Fprintf(os.Stderr, "bd gate watch is already running (%s)\n", holder)
+				} else {
+					fmt.Printf("bd gate watch is already running (%s)\n", holder)
+				}
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Error: bd gate watch is already running (%s)\n", holder)
+			os.Exit(1)
+		}
+		defer releaseGateWatchLock(lock)
+
+		w := &gateWatcher{
+			beadsDir:   beadsDir,
+			typeFilter: typeFilter,
+			limit:      limit,
+			escalate:   escalateFlag,
+			checker:    &gateChecker{},
+			schedule:   newGateSchedule(gateWatchMaxBackoff()),
+		}
+
+		// An embedded database allows one writer at a time, so a watcher
+		// that runs between passes must not keep it open
+		if !once && !isDoltServerWorkspace(beadsDir) {
+			w.reopen = true
+			releaseStore()
+		}
+
+		if once {
+			stats := w.pass(rootCtx, time.Now())
+			if jsonOutput {
+				outputJSON(stats)
+			} else {
+				fmt.Println()
+				fmt.Printf("Checked %d gates: %d resolved, %d escalated, %d errors, %d steps unblocked\n",
+					stats.Checked, stats.Resolved, stats.Escalated, stats.Errors, len(stats.Unblocked))
+			}
+			return
+		}
+
+		fmt.Fprintf(os.Stderr, "Watching gates... (Press Ctrl+C to stop)\n")
+		w.run(rootCtx)
+		fmt.Fprintf(os.Stderr, "\nStopped watching gates.\n")
+	},
+}
+
+// gateWatchStats counts what one pass of the watcher did.
+type gateWatchStats struct {
+	Checked   int      `json:"checked"`
+	Resolved  int      `json:"resolved"`
+	Escalated int      `json:"escalated"`
+	Errors    int      `json:"errors"`
+	Unblocked []string `json:"unblocked"`
+}
+
+// gateWatcher checks open gates as they come due.
+type gateWatcher struct {
+	beadsDir   string
+	typeFilter string
+	limit      int
+	escalate   bool
+	reopen     bool // Open the store for each pass and close it after
+	checker    *gateChecker
+	schedule   *gateSchedule
+}
+
+// run checks gates until ctx is cancelled.
+func (w *gateWatcher) run(ctx context.Context) {
+	rescan := gateWatchRescanInterval()
+	for {
+		now := time.Now()
+		w.pass(ctx, now)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Sleep until the next gate comes due, rescanning for new gates
+		// at least every rescan interval
+		wake := w.schedule.nextDue(now.Add(rescan))
+		timer := time.NewTimer(time.Until(wake))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// pass checks the open gates that are due. Cancelling ctx stops the pass
+// before the next gate; the gate being checked is still acted on.
+func (w *gateWatcher) pass(ctx context.Context, now time.Time) gateWatchStats {
+	stats := gateWatchStats{Unblocked: []string{}}
+
+	// Act on results even when a shutdown interrupts the pass
+	actCtx := context.WithoutCancel(ctx)
+
+	if w.reopen {
+		if err := openGateWatchStore(actCtx, w.beadsDir); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s: %v\n", ui.RenderFail("✗"), now.Format(time.TimeOnly), err)
+			stats.Errors++
+			return stats
+		}
+		defer releaseStore()
+	}
+
+	gates, err := listOpenGates(actCtx, w.typeFilter, w.limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s: listing gates: %v\n", ui.RenderFail("✗"), now.Format(time.TimeOnly), err)
+		stats.Errors++
+		return stats
+	}
+	w.schedule.prune(gates)
+
+	for _, gate := range gates {
+		if ctx.Err() != nil {
+			break
+		}
+		if !w.schedule.due(gate, now) {
+			continue
+		}
+		result, ok := w.checker.check(ctx, gate, now)
+		if !ok {
+			continue
+		}
+		if result.err != nil && ctx.Err() != nil {
+			break // Check interrupted by shutdown
+		}
+
+		// Gates without an escalation policy are handed to 'gt escalate'
+		// once per watcher, not on every check
+		stats.Checked++
+		escalate := w.escalate && !w.schedule.escalated(gate.ID)
+		outcome := applyGateResult(actCtx, result, now, false, escalate)
+		switch outcome {
+		case gateOutcomeResolved:
+			stats.Resolved++
+		case gateOutcomeEscalated:
+			stats.Escalated++
+		case gateOutcomeError:
+			stats.Errors++
+		}
+		if outcome == gateOutcomeResolved || outcome == gateOutcomeEscalated {
+			stats.Unblocked = append(stats.Unblocked, announceUnblocked(actCtx, gate.ID)...)
+		}
+		w.schedule.record(gate, outcome, now)
+	}
+
+	// Send the webhooks this pass queued, and any retries that are due
+	deliverPendingWebhooks()
+	return stats
+}
+
+// announceUnblocked reports the steps unblocked by a gate that has been
+// closed and runs the on_unblocked hook for each. Returns their IDs, which
+// are all that is reported with --json.
+func announceUnblocked(ctx context.Context, gateID string) []string {
+	gate, err := store.GetIssue(ctx, gateID)
+	if err != nil || gate == nil || gate.Status != types.StatusClosed {
+		return nil
+	}
+	unblocked, err := store.GetNewlyUnblockedByClose(ctx, gateID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not find steps unblocked by %s: %v\n", gateID, err)
+		return nil
+	}
+
+	ids := make([]string, 0, len(unblocked))
+	for _, issue := range unblocked {
+		ids = append(ids, issue.ID)
+		if !jsonOutput {
+			fmt.Printf("  → %s unblocked: %s\n", ui.RenderID(issue.ID), issue.Title)
+		}
+		env := hooks.NewEnvelope(hooks.EventUnblocked, actor, nil, issue)
+		env.Cause = gateID
+		if err := emitHookSync(env); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: on_unblocked hook failed for %s: %v\n", issue.ID, err)
+		}
+	}
+	return ids
+}
+
+// gateSchedule tracks when each watched gate is next due.
+type gateSchedule struct {
+	entries    map[string]*gateScheduleEntry
+	maxBackoff time.Duration
+}
+
+type gateScheduleEntry struct {
+	next      time.Time
+	misses    int  // Consecutive remote checks that left the gate open
+	escalated bool // The gate has escalated during this watch
+}
+
+func newGateSchedule(maxBackoff time.Duration) *gateSchedule {
+	return &gateSchedule{entries: make(map[string]*gateScheduleEntry), maxBackoff: maxBackoff}
+}
+
+// due reports whether a gate should be checked at now. Gates not seen
+// before are due immediately.
+func (s *gateSchedule) due(gate *types.Issue, now time.Time) bool {
+	e, ok := s.entries[gate.ID]
+	return !ok || !now.Before(e.next)
+}
+
+// escalated reports whether a gate has already escalated during this watch.
+func (s *gateSchedule) escalated(gateID string) bool {
+	e, ok := s.entries[gateID]
+	return ok && e.escalated
+}
+
+// record schedules a gate's next check after a check at now. Remote gates
+// back off exponentially while they stay open or fail to check. A gate
+// with a timeout is due again no later than its escalation warning or
+// deadline.
+func (s *gateSchedule) record(gate *types.Issue, outcome gateOutcome, now time.Time) {
+	e, ok := s.entries[gate.ID]
+	if !ok {
+		e = &gateScheduleEntry{}
+		s.entries[gate.ID] = e
+	}
+
+	if outcome == gateOutcomeEscalated {
+		e.escalated = true
+	}
+
+	delay := gateWatchInterval(gateWatchKind(gate.AwaitType))
+	if isRemoteGate(gate.AwaitType) && outcome != gateOutcomeResolved {
+		delay = backoffDelay(delay, e.misses, s.maxBackoff)
+		e.misses++
+	} else {
+		e.misses = 0
+	}
+	e.next = now.Add(delay)
+
+	if gate.Timeout > 0 {
+		milestones := []time.Time{gate.CreatedAt.Add(gate.Timeout)}
+		if policy := gateEscalationPolicy(gate); policy != nil {
+			warnAt := time.Duration(float64(gate.Timeout) * policy.WarnFraction())
+			milestones = append(milestones, gate.CreatedAt.Add(warnAt))
+		}
+		for _, t := range milestones {
+			if t.After(now) && t.Before(e.next) {
+				e.next = t
+			}
+		}
+	}
+}
+
+// prune forgets gates that are no longer open.
+func (s *gateSchedule) prune(open []*types.Issue) {
+	keep := make(map[string]bool, len(open))
+	for _, gate := range open {
+		keep[gate.ID] = true
+	}
+	for id := range s.entries {
+		if !keep[id] {
+			delete(s.entries, id)
+		}
+	}
+}
+
+// nextDue returns when the next known gate is due, or limit if none is
+// due before it.
+func (s *gateSchedule) nextDue(limit time.Time) time.Time {
+	next := limit
+	for _, e := range s.entries {
+		if e.next.Before(next) {
+			next = e.next
+		}
+	}
+	return next
+}
+
+// backoffDelay doubles interval for each consecutive miss, up to max.
+func backoffDelay(interval time.Duration, misses int, max time.Duration) time.Duration {
+	delay := interval
+	for i := 0; i < misses && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// gateWatchKind returns the kind a gate is scheduled by: the await type's
+// prefix, so gh:run and gh:pr are both "gh" and cmd:<code> is "cmd".
+func gateWatchKind(awaitType string) string {
+	kind, _, _ := strings.Cut(awaitType, ":")
+	return kind
+}
+
+// isRemoteGate reports whether checking a gate calls a remote API.
+func isRemoteGate(awaitType string) bool {
+	return strings.HasPrefix(awaitType, "gh:") || isGitLabGate(awaitType)
+}
+
+// gateWatchInterval returns how often gates of a kind are checked.
+func gateWatchInterval(kind string) time.Duration {
+	if d := config.GetDuration("gate.watch.intervals." + kind); d > 0 {
+		return d
+	}
+	if d, ok := defaultGateWatchIntervals[kind]; ok {
+		return d
+	}
+	return time.Minute
+}
+
+// gateWatchRescanInterval returns how often the watcher looks for new
+// gates: the shortest of the check intervals.
+func gateWatchRescanInterval() time.Duration {
+	shortest := time.Duration(0)
+	for kind := range defaultGateWatchIntervals {
+		if d := gateWatchInterval(kind); shortest == 0 || d < shortest {
+			shortest = d
+		}
+	}
+	return shortest
+}
+
+// gateWatchMaxBackoff returns the longest delay between remote checks.
+func gateWatchMaxBackoff() time.Duration {
+	if d := config.GetDuration("gate.watch.max-backoff"); d > 0 {
+		return d
+	}
+	return defaultGateWatchMaxBackoff
+}
+
+// acquireGateWatchLock takes the workspace's gate watch lock and records this
+// process in it. Returns lockfile.ErrLocked if another watcher holds it.
+func acquireGateWatchLock(beadsDir string) (*os.File, error) {
+	lockPath := filepath.Join(beadsDir, gateWatchLockFile)
+	// #nosec G304 - controlled path
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gate watch lock: %w", err)
+	}
+	if err := lockfile.FlockExclusiveNonBlocking(f); err != nil {
+		_ = f.Close() // Best effort cleanup on error path
+		return nil, err
+	}
+
+	info := lockfile.LockInfo{
+		PID:       os.Getpid(),
+		Database:  dbPath,
+		Version:   Version,
+		StartedAt: time.Now(),
+	}
+	_ = f.Truncate(0) // Best effort: the lock info is informational
+	_ = json.NewEncoder(f).Encode(info)
+	return f, nil
+}
+
+// releaseGateWatchLock releases the gate watch lock. The file is left in
+// place; removing it could let a second watcher lock a new file while a
+// third still waits on the old one.
+func releaseGateWatchLock(f *os.File) {
+	_ = lockfile.FlockUnlock(f) // Best effort: unlock may fail if fd is bad
+	_ = f.Close()               // Best effort cleanup
+}
+
+// readGateWatchLock returns the info of the running watcher, if readable.
+func readGateWatchLock(beadsDir string) *lockfile.LockInfo {
+	// #nosec G304 - controlled path
+	data, err := os.ReadFile(filepath.Join(beadsDir, gateWatchLockFile))
+	if err != nil {
+		return nil
+	}
+	var info lockfile.LockInfo
+	if json.Unmarshal(data, &info) != nil || info.PID == 0 {
+		return nil
+	}
+	return &info
+}
+
+// isDoltServerWorkspace reports whether a workspace uses a dolt sql-server.
+func isDoltServerWorkspace(beadsDir string) bool {
+	cfg, err := configfile.Load(beadsDir)
+	return err == nil && cfg != nil && cfg.IsDoltServerMode()
+}
+
+// openGateWatchStore opens the workspace database for one watcher pass,
+// taking the same access lock as other bd commands.
+func openGateWatchStore(ctx context.Context, beadsDir string) error {
+	s, err := dolt.NewFromConfigWithOptions(ctx, beadsDir, &dolt.Config{OpenTimeout: 15 * time.Second})
+	if err != nil {
+		if errors.Is(err, lockfile.ErrLockBusy) {
+			return fmt.Errorf("database busy, skipping this pass")
+		}
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	lockStore()
+	setStore(s)
+	setStoreActive(true)
+	unlockStore()
+	return nil
+}
+
+// releaseStore closes the store so other bd processes can open the
+// database.
+func releaseStore() {
+	lockStore()
+	if store != nil {
+		_ = store.Close() // Best effort cleanup
+	}
+	setStore(nil)
+	setStoreActive(false)
+	unlockStore()
+}
+
+func init() {
+	gateWatchCmd.Flags().StringP("type", "t", "", "Gate type to watch (gh, gh:run, gh:pr, gl, gl:pipeline, gl:mr, timer, bead, query, cmd, approval, all)")
+	gateWatchCmd.Flags().BoolP("escalate", "e", true, "Run escalation actions for failed/expired gates")
+	gateWatchCmd.Flags().Bool("once", false, "Check every open gate once and exit")
+	gateWatchCmd.Flags().IntP("limit", "l", 500, "Maximum number of gates to watch")
+
+	gateCmd.AddCommand(gateWatchCmd)
+}
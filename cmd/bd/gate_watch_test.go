@@ -0,0 +1,162 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/lockfile"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		misses int
+		want   time.Duration
+	}{
+		{0, time.Minute},
+		{1, 2 * time.Minute},
+		{2, 4 * time.Minute},
+		{3, 8 * time.Minute},
+		{4, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoffDelay(time.Minute, tt.misses, 10*time.Minute); got != tt.want {
+			t.Errorf("backoffDelay(1m, %d, 10m) = %s, want %s", tt.misses, got, tt.want)
+		}
+	}
+}
+
+func TestGateWatchKind(t *testing.T) {
+	tests := map[string]string{
+		"gh:run":      "gh",
+		"gh:pr":       "gh",
+		"gl:pipeline": "gl",
+		"cmd":         "cmd",
+		"cmd:3":       "cmd",
+		"timer":       "timer",
+		"query":       "query",
+	}
+	for awaitType, want := range tests {
+		if got := gateWatchKind(awaitType); got != want {
+			t.Errorf("gateWatchKind(%q) = %q, want %q", awaitType, got, want)
+		}
+	}
+}
+
+func TestGateSchedule(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("new gates are due", func(t *testing.T) {
+		s := newGateSchedule(10 * time.Minute)
+		if !s.due(&types.Issue{ID: "g1"}, now) {
+			t.Error("unseen gate should be due")
+		}
+	})
+
+	t.Run("local gates keep their interval", func(t *testing.T) {
+		s := newGateSchedule(10 * time.Minute)
+		gate := &types.Issue{ID: "g1", AwaitType: "bead", CreatedAt: now}
+		for i := 0; i < 3; i++ {
+			s.record(gate, gateOutcomePending, now)
+		}
+		if got := s.entries["g1"].next; !got.Equal(now.Add(30 * time.Second)) {
+			t.Errorf("next = %s, want now+30s", got.Sub(now))
+		}
+		if s.due(gate, now.Add(29*time.Second)) || !s.due(gate, now.Add(30*time.Second)) {
+			t.Error("gate should come due after its interval")
+		}
+	})
+
+	t.Run("remote gates back off until resolved", func(t *testing.T) {
+		s := newGateSchedule(3 * time.Minute)
+		gate := &types.Issue{ID: "g1", AwaitType: "gh:run", CreatedAt: now}
+		var delays []time.Duration
+		for i := 0; i < 4; i++ {
+			s.record(gate, gateOutcomePending, now)
+			delays = append(delays, s.entries["g1"].next.Sub(now))
+		}
+		want := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+		for i := range want {
+			if delays[i] != want[i] {
+				t.Errorf("delays = %v, want %v", delays, want)
+				break
+			}
+		}
+
+		s.record(gate, gateOutcomeResolved, now)
+		if got := s.entries["g1"].next.Sub(now); got != time.Minute {
+			t.Errorf("delay after resolve = %s, want 1m", got)
+		}
+	})
+
+	t.Run("timeouts bring checks forward", func(t *testing.T) {
+		s := newGateSchedule(10 * time.Minute)
+		gate := &types.Issue{ID: "t1", AwaitType: "timer", CreatedAt: now.Add(-time.Minute), Timeout: time.Minute + 5*time.Second}
+		s.record(gate, gateOutcomePending, now)
+		if got := s.entries["t1"].next; !got.Equal(now.Add(5 * time.Second)) {
+			t.Errorf("next = %s, want the timer's expiry (now+5s)", got.Sub(now))
+		}
+	})
+
+	t.Run("escalated gates are remembered", func(t *testing.T) {
+		s := newGateSchedule(10 * time.Minute)
+		gate := &types.Issue{ID: "g1", AwaitType: "gh:pr", CreatedAt: now}
+		s.record(gate, gateOutcomePending, now)
+		if s.escalated("g1") {
+			t.Fatal("pending gate reported as escalated")
+		}
+		s.record(gate, gateOutcomeEscalated, now)
+		s.record(gate, gateOutcomePending, now)
+		if !s.escalated("g1") {
+			t.Error("escalation should be remembered")
+		}
+	})
+
+	t.Run("prune and nextDue", func(t *testing.T) {
+		s := newGateSchedule(10 * time.Minute)
+		a := &types.Issue{ID: "a", AwaitType: "timer", CreatedAt: now}
+		b := &types.Issue{ID: "b", AwaitType: "cmd", CreatedAt: now}
+		s.record(a, gateOutcomePending, now)
+		s.record(b, gateOutcomePending, now)
+
+		limit := now.Add(time.Hour)
+		if got := s.nextDue(limit); !got.Equal(now.Add(15 * time.Second)) {
+			t.Errorf("nextDue = %s, want now+15s", got.Sub(now))
+		}
+		s.prune([]*types.Issue{b})
+		if _, ok := s.entries["a"]; ok {
+			t.Error("closed gate was not pruned")
+		}
+		if got := s.nextDue(now.Add(10 * time.Second)); !got.Equal(now.Add(10 * time.Second)) {
+			t.Errorf("nextDue = %s, want the limit", got.Sub(now))
+		}
+	})
+}
+
+func TestGateWatchLock(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := acquireGateWatchLock(dir)
+	if err != nil {
+		t.Fatalf("acquireGateWatchLock: %v", err)
+	}
+	info := readGateWatchLock(dir)
+	if info == nil || info.PID == 0 {
+		t.Fatalf("lock info = %+v, want this process", info)
+	}
+
+	if second, err := acquireGateWatchLock(dir); !lockfile.IsLocked(err) {
+		if second != nil {
+			releaseGateWatchLock(second)
+		}
+		t.Fatalf("second acquire: err = %v, want ErrLocked", err)
+	}
+
+	releaseGateWatchLock(first)
+	again, err := acquireGateWatchLock(dir)
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	releaseGateWatchLock(again)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/steveyegge/beads/internal/hooks"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// emitHook queues an event for the webhooks that subscribe to it, then runs
+// its hooks, if hooks are enabled. Hooks configured with async: true run in
+// the background; PersistentPostRun waits for them, and sends the webhooks,
+// before the command exits.
+func emitHook(env *hooks.Envelope) {
+	enqueueWebhooks(env)
+	if hookRunner == nil {
+		return
+	}
+	hookRunner.Emit(env)
+}
+
+// emitHookSync is emitHook for long-running commands: it waits for the hooks
+// and returns the first error.
+func emitHookSync(env *hooks.Envelope) error {
+	enqueueWebhooks(env)
+	if hookRunner == nil {
+		return nil
+	}
+	return hookRunner.EmitSync(env)
+}
+
+// hookWanted reports whether a hook or webhook would receive an event, so
+// callers can skip fetching what only its envelope needs.
+func hookWanted(event string) bool {
+	return (hookRunner != nil && hookRunner.HookExists(event)) || webhookWants(event)
+}
+
+// emitIssueHook runs the hooks for an event on an issue by the current actor.
+func emitIssueHook(event string, old, updated *types.Issue) {
+	emitHook(hooks.NewEnvelope(event, actor, old, updated))
+}
+
+// emitChangeHooks runs the hooks for a change to an issue: the event itself
+// (update, close or reopen), then the finer-grained events the change
+// implies - status_changed, close or reopen for a status change, and
+// label_added or label_removed for each label.
+func emitChangeHooks(event string, old, updated *types.Issue) {
+	if updated == nil || (hookRunner == nil && len(loadWebhooks()) == 0) {
+		return
+	}
+	emitIssueHook(event, old, updated)
+	if old == nil {
+		return
+	}
+
+	if old.Status != updated.Status {
+		emitIssueHook(hooks.EventStatusChanged, old, updated)
+		implied := ""
+		switch {
+		case updated.Status == types.StatusClosed:
+			implied = hooks.EventClose
+		case old.Status == types.StatusClosed:
+			implied = hooks.EventReopen
+		}
+		if implied != "" && implied != event {
+			emitIssueHook(implied, old, updated)
+		}
+	}
+
+	for _, label := range updated.Labels {
+		if !slices.Contains(old.Labels, label) {
+			env := hooks.NewEnvelope(hooks.EventLabelAdded, actor, old, updated)
+			env.Label = label
+			emitHook(env)
+		}
+	}
+	for _, label := range old.Labels {
+		if !slices.Contains(updated.Labels, label) {
+			env := hooks.NewEnvelope(hooks.EventLabelRemoved, actor, old, updated)
+			env.Label = label
+			emitHook(env)
+		}
+	}
+}
+
+// emitDependencyHook runs the dependency_added or dependency_removed hooks
+// for a dependency, on the issue that depends.
+func emitDependencyHook(ctx context.Context, s *dolt.DoltStore, event string, dep *types.Dependency) {
+	if !hookWanted(event) {
+		return
+	}
+	issue, _ := s.GetIssue(ctx, dep.IssueID) // Best effort: the envelope still names the issue
+	env := hooks.NewEnvelope(event, actor, nil, issue)
+	env.IssueID = dep.IssueID
+	env.Dependency = dep
+	emitHook(env)
+}
+
+// emitUnblockedHooks runs the unblocked hooks for each issue unblocked by
+// closing closedID.
+func emitUnblockedHooks(ctx context.Context, s *dolt.DoltStore, closedID string) {
+	if !hookWanted(hooks.EventUnblocked) {
+		return
+	}
+	unblocked, err := s.GetNewlyUnblockedByClose(ctx, closedID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not find issues unblocked by %s: %v\n", closedID, err)
+		return
+	}
+	for _, issue := range unblocked {
+		env := hooks.NewEnvelope(hooks.EventUnblocked, actor, nil, issue)
+		env.Cause = closedID
+		emitHook(env)
+	}
+}
+
+// emitLabelHook runs the label_added or label_removed hooks for a label
+// changed by 'bd label'. old is the issue before the change, if fetched.
+func emitLabelHook(ctx context.Context, s *dolt.DoltStore, event, issueID, label string, old *types.Issue) {
+	if !hookWanted(event) {
+		return
+	}
+	updated, _ := s.GetIssue(ctx, issueID) // Best effort: the envelope still names the issue
+	env := hooks.NewEnvelope(event, actor, old, updated)
+	env.IssueID = issueID
+	env.Label = label
+	emitHook(env)
+}
+
+// emitCommentHook runs the comment_added hooks for a new comment.
+func emitCommentHook(ctx context.Context, s *dolt.DoltStore, comment *types.Comment) {
+	if comment == nil || !hookWanted(hooks.EventCommentAdded) {
+		return
+	}
+	issue, _ := s.GetIssue(ctx, comment.IssueID) // Best effort: the envelope still names the issue
+	env := hooks.NewEnvelope(hooks.EventCommentAdded, comment.Author, nil, issue)
+	env.IssueID = comment.IssueID
+	env.Comment = comment
+	emitHook(env)
+}
+
+// runBeforeHook runs the pre-operation hook for a pending change to old
+// (nil for create), which would leave the issue as pending (nil for delete).
+// It returns the hook's patch; an error means the hook vetoed the change.
+func runBeforeHook(event string, old, pending *types.Issue, updates map[string]interface{}) (map[string]interface{}, error) {
+	if hookRunner == nil || !hookRunner.HasBeforeHook(event) {
+		return nil, nil
+	}
+	env := hooks.NewEnvelope(event, actor, old, pending)
+	env.Updates = updates
+	return hookRunner.RunBefore(env)
+}
+
+// pendingUpdate returns issue as it would be after updates, for the
+// envelope of a pre-operation hook. Best effort: fields bd update names
+// differently from the issue's JSON are left as they are.
+func pendingUpdate(issue *types.Issue, updates map[string]interface{}) *types.Issue {
+	pending, err := hooks.ApplyPatch(issue, updates)
+	if err != nil {
+		return issue
+	}
+	return pending
+}
+
+// runUpdateBeforeHooks runs the before_update hook for a pending bd update,
+// then before_close if the update closes the issue. It returns the updates
+// with the hooks' patches merged in; an error means a hook vetoed them.
+// A claim is shown to the hooks as the assignee and status it sets.
+func runUpdateBeforeHooks(issue *types.Issue, updates map[string]interface{}, claim bool) (map[string]interface{}, error) {
+	merged := maps.Clone(updates)
+	for _, event := range []string{hooks.EventBeforeUpdate, hooks.EventBeforeClose} {
+		if event == hooks.EventBeforeClose &&
+			(fmt.Sprint(merged["status"]) != string(types.StatusClosed) || issue.Status == types.StatusClosed) {
+			continue
+		}
+		shown := maps.Clone(merged)
+		if claim {
+			shown["assignee"] = actor
+			shown["status"] = string(types.StatusInProgress)
+		}
+		patch, err := runBeforeHook(event, issue, pendingUpdate(issue, shown), shown)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(merged, patch)
+	}
+	return merged, nil
+}
+
+// runCloseBeforeHook runs the before_close hook for closing issue with
+// reason. It returns the close reason, which the hook may patch, and the
+// other fields the hook patched, to be set along with the close (nil if
+// none). An error means the hook vetoed the close or patched the status.
+func runCloseBeforeHook(issue *types.Issue, reason string) (string, map[string]interface{}, error) {
+	updates := map[string]interface{}{
+		"status":       string(types.StatusClosed),
+		"close_reason": reason,
+	}
+	patch, err := runBeforeHook(hooks.EventBeforeClose, issue, pendingUpdate(issue, updates), updates)
+	if err != nil {
+		return "", nil, err
+	}
+	if status, ok := patch["status"]; ok && status != string(types.StatusClosed) {
+		return "", nil, fmt.Errorf("%s hook cannot change the status of a closing issue", hooks.EventBeforeClose)
+	}
+	if patched, ok := patch["close_reason"]; ok {
+		s, isString := patched.(string)
+		if !isString {
+			return "", nil, fmt.Errorf("%s hook: close_reason must be a string", hooks.EventBeforeClose)
+		}
+		reason = s
+	}
+	delete(patch, "status")
+	delete(patch, "close_reason")
+	if len(patch) == 0 {
+		return reason, nil, nil
+	}
+	return reason, patch, nil
+}
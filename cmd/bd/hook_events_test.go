@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/hooks"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestEmitChangeHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "events")
+	if err := os.Mkdir(out, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ncat > \"$(mktemp \"" + out + "/XXXXXX\")\"\n"
+	if err := os.WriteFile(filepath.Join(dir, hooks.HookOnAny), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	oldRunner, oldActor := hookRunner, actor
+	hookRunner, actor = hooks.NewRunner(dir), "alice"
+	t.Cleanup(func() { hookRunner, actor = oldRunner, oldActor })
+
+	before := &types.Issue{ID: "bd-1", Status: types.StatusOpen, Labels: []string{"keep", "drop"}}
+	after := &types.Issue{ID: "bd-1", Status: types.StatusClosed, Labels: []string{"keep", "add"}}
+	emitChangeHooks(hooks.EventUpdate, before, after)
+	hookRunner.Wait()
+
+	files, err := filepath.Glob(filepath.Join(out, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var env hooks.Envelope
+		if err := json.Unmarshal(data, &env); err != nil {
+			t.Fatalf("bad envelope %q: %v", data, err)
+		}
+		if env.Actor != "alice" || env.Old == nil || env.New == nil {
+			t.Errorf("%s envelope = %+v", env.Event, env)
+		}
+		got = append(got, strings.TrimSuffix(env.Event+" "+env.Label, " "))
+	}
+
+	// Events run concurrently, so compare as a set
+	want := []string{"close", "label_added add", "label_removed drop", "status_changed", "update"}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestRunUpdateBeforeHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+	dir := t.TempDir()
+	write := func(name, script string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Only leads may set P0; closes always get a reason
+	write(hooks.HookBeforeUpdate, `grep -q '"actor":"alice".*"updates":{"priority":0' && { echo "only leads may set P0" >&2; exit 1; }
+echo '{"notes": "checked"}'
+`)
+	write(hooks.HookBeforeClose, `echo '{"close_reason": "closed via policy"}'`)
+
+	oldRunner, oldActor := hookRunner, actor
+	hookRunner, actor = hooks.NewRunner(dir), "alice"
+	t.Cleanup(func() { hookRunner, actor = oldRunner, oldActor })
+
+	issue := &types.Issue{ID: "bd-1", Status: types.StatusOpen, Priority: 2}
+
+	_, err := runUpdateBeforeHooks(issue, map[string]interface{}{"priority": 0}, false)
+	if err == nil || !strings.Contains(err.Error(), "only leads may set P0") {
+		t.Errorf("err = %v, want the P0 veto", err)
+	}
+
+	updates := map[string]interface{}{"status": "closed"}
+	got, err := runUpdateBeforeHooks(issue, updates, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]interface{}{"status": "closed", "notes": "checked", "close_reason": "closed via policy"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("updates = %v, want %v", got, want)
+	}
+	if len(updates) != 1 {
+		t.Errorf("caller's updates were modified: %v", updates)
+	}
+
+	write(hooks.HookBeforeClose, `echo '{"close_reason": "closed via policy", "notes": "checked"}'`)
+	reason, patched, err := runCloseBeforeHook(issue, "done")
+	if err != nil || reason != "closed via policy" {
+		t.Errorf("runCloseBeforeHook = (%q, %v), want the patched reason", reason, err)
+	}
+	if want := map[string]interface{}{"notes": "checked"}; !reflect.DeepEqual(patched, want) {
+		t.Errorf("patched = %v, want %v", patched, want)
+	}
+
+	write(hooks.HookBeforeClose, `echo '{"status": "open"}'`)
+	if _, _, err := runCloseBeforeHook(issue, "done"); err == nil {
+		t.Error("expected an error for a before_close hook that reopens the issue")
+	}
+}
@@ -186,7 +186,10 @@ The hooks ensure that:
 - post-merge: Imports updated JSONL after pull/merge
 - pre-push: Prevents pushing stale JSONL
 - post-checkout: Imports JSONL after branch checkout
-- prepare-commit-msg: Adds agent identity trailers for forensics`,
+- prepare-commit-msg: Adds agent identity trailers for forensics
+
+'bd hooks log' and 'bd hooks test' debug the event hooks in .beads/hooks
+(on_*, before_*), which bd runs when issues change.`,
 }
 
 var hooksInstallCmd = &cobra.Command{
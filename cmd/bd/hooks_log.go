@@ -0,0 +1,382 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/hooks"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// newHookRunner creates the runner for the event hooks in beadsDir/hooks,
+// configured from config.yaml and logging to beadsDir/hook-log.jsonl.
+func newHookRunner(beadsDir string) *hooks.Runner {
+	runner := hooks.NewRunner(filepath.Join(beadsDir, "hooks"))
+	runner.SetLegacyPayload(config.GetString("hooks.payload") == "issue")
+	runner.SetTimeout(config.GetDuration("hooks.timeout"))
+	runner.SetHookConfig(loadHookConfigs())
+	runner.SetLogPath(filepath.Join(beadsDir, hooks.LogFileName))
+	return runner
+}
+
+// loadHookConfigs returns the per-hook settings under hooks.<hook-name> in
+// config.yaml. Unknown hooks and invalid settings are reported and skipped.
+func loadHookConfigs() map[string]hooks.HookConfig {
+	var section map[string]interface{}
+	if err := config.UnmarshalKey("hooks", &section); err != nil {
+		return nil
+	}
+	configs := make(map[string]hooks.HookConfig)
+	for name, value := range section {
+		if _, ok := value.(map[string]interface{}); !ok {
+			continue // A setting for all hooks, such as hooks.payload
+		}
+		if !slices.Contains(hooks.HookNames(), name) {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring hooks.%s: unknown hook (see 'bd hooks test --help')\n", name)
+			continue
+		}
+		var cfg hooks.HookConfig
+		if err := config.UnmarshalKey("hooks."+name, &cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring hooks.%s: %v\n", name, err)
+			continue
+		}
+		if i := slices.IndexFunc(cfg.Env, func(kv string) bool { return !strings.Contains(kv, "=") }); i >= 0 {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring hooks.%s: env entry %q is not KEY=value\n", name, cfg.Env[i])
+			continue
+		}
+		configs[name] = cfg
+	}
+	return configs
+}
+
+// reportHookFailures warns about the post-event hooks that failed during
+// the command.
+func reportHookFailures() {
+	for _, rec := range hookRunner.Failures() {
+		subject := ""
+		if rec.IssueID != "" {
+			subject = " for " + rec.IssueID
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s hook failed%s: %s (see 'bd hooks log --failed')\n", rec.Hook, subject, rec.Error)
+	}
+}
+
+var hooksLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show recent runs of .beads/hooks event hooks",
+	Long: `Show recent runs of the event hooks in .beads/hooks (on_*, before_*),
+oldest first: when each ran, for which event and issue, how long it took and
+how it exited. Failed runs show the start of their stderr.
+
+The log is kept in .beads/hook-log.jsonl (about the last 1MB of runs), with
+up to 2KB of each run's stdout and stderr.
+
+Examples:
+  bd hooks log                  # The last 20 runs
+  bd hooks log --failed         # Only failures
+  bd hooks log --hook on_close  # Only on_close
+  bd hooks log --json -n 100    # Full records, including output`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		failedOnly, _ := cmd.Flags().GetBool("failed")
+		hookFilter, _ := cmd.Flags().GetString("hook")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		beadsDir := beads.FindBeadsDir()
+		if beadsDir == "" {
+			FatalErrorRespectJSON("no .beads directory found")
+		}
+		records, err := hooks.ReadLog(filepath.Join(beadsDir, hooks.LogFileName))
+		if err != nil {
+			FatalErrorRespectJSON("failed to read hook log: %v", err)
+		}
+		records = slices.DeleteFunc(records, func(rec hooks.RunRecord) bool {
+			return (failedOnly && !rec.Failed()) || (hookFilter != "" && rec.Hook != hookFilter)
+		})
+		if limit > 0 && len(records) > limit {
+			records = records[len(records)-limit:]
+		}
+
+		if jsonOutput {
+			if records == nil {
+				records = []hooks.RunRecord{}
+			}
+			outputJSON(records)
+			return
+		}
+		if len(records) == 0 {
+			fmt.Println("No hook runs logged")
+			return
+		}
+		for _, rec := range records {
+			printHookRun(rec)
+		}
+	},
+}
+
+func printHookRun(rec hooks.RunRecord) {
+	icon := ui.RenderPass("✓")
+	if rec.Failed() {
+		icon = ui.RenderFail("✗")
+	}
+	subject := rec.Event
+	if rec.IssueID != "" {
+		subject += " " + ui.RenderID(rec.IssueID)
+	}
+	fmt.Printf("%s %s %-22s %s  %s\n", ui.RenderMuted(rec.Time.Local().Format("2006-01-02 15:04:05")), icon, rec.Hook, subject,
+		ui.RenderMuted(fmt.Sprintf("%dms", rec.DurationMs)))
+	if !rec.Failed() {
+		return
+	}
+	fmt.Printf("    %s\n", ui.RenderFail(rec.Error))
+	lines := strings.Split(strings.TrimSpace(rec.Stderr), "\n")
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		if i == 5 {
+			fmt.Printf("    %s\n", ui.RenderMuted(fmt.Sprintf("... %d more lines (see --json)", len(lines)-i)))
+			break
+		}
+		fmt.Printf("    %s\n", ui.RenderMuted(line))
+	}
+}
+
+var hooksTestCmd = &cobra.Command{
+	Use:   "test <hook> [issue-id]",
+	Short: "Dry-run an event hook with a sample payload",
+	Long: `Run one of the event hooks in .beads/hooks with a sample event envelope
+built from an issue (or a made-up issue if none is given), and show what it
+did: exit code, duration, stdout and stderr. For before_* hooks it also says
+whether the hook would allow, reject or patch the change.
+
+The hook runs with its configured timeout and environment, even if it is
+disabled, plus BD_HOOK_DRY_RUN=1 so it can skip side effects. Nothing is
+changed in the database and the run is not logged.
+
+Hooks: ` + strings.Join(hooks.HookNames(), ", ") + `
+
+Examples:
+  bd hooks test on_close bd-42
+  bd hooks test before_update bd-42
+  bd hooks test on_any --event status_changed`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		event := hooks.EventForHook(name)
+		if name == hooks.HookOnAny {
+			event, _ = cmd.Flags().GetString("event")
+		}
+		if !slices.Contains(hooks.HookNames(), name) {
+			FatalErrorRespectJSON("unknown hook %q (hooks: %s)", name, strings.Join(hooks.HookNames(), ", "))
+		}
+
+		beadsDir := beads.FindBeadsDir()
+		if beadsDir == "" {
+			FatalErrorRespectJSON("no .beads directory found")
+		}
+		issue := sampleHookIssue()
+		if len(args) == 2 {
+			var err error
+			if issue, err = loadIssueReadOnly(beadsDir, args[1]); err != nil {
+				FatalErrorRespectJSON("%v", err)
+			}
+		}
+
+		testActor := actor
+		if testActor == "" {
+			testActor = getActorWithGit()
+		}
+		runner := newHookRunner(beadsDir)
+		env := sampleEnvelope(event, testActor, issue)
+		rec, err := runner.Test(name, env)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		cfg := runner.HookConfigFor(name)
+		verdict := hookTestVerdict(name, rec)
+
+		if jsonOutput {
+			outputJSON(map[string]interface{}{
+				"hook":     name,
+				"envelope": env,
+				"run":      rec,
+				"config":   cfg,
+				"verdict":  verdict,
+			})
+		} else {
+			printHookTest(name, env, rec, cfg, verdict)
+		}
+		if rec.Failed() && !hooks.IsBeforeHook(name) {
+			os.Exit(1)
+		}
+	},
+}
+
+// loadIssueReadOnly fetches an issue from the workspace database, opened
+// read-only for the duration of the call ('bd hooks' opens no store).
+func loadIssueReadOnly(beadsDir, id string) (*types.Issue, error) {
+	s, err := dolt.NewFromConfigWithOptions(rootCtx, beadsDir, &dolt.Config{ReadOnly: true, OpenTimeout: 15 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = s.Close() }() // Best effort cleanup
+	issue, err := s.GetIssue(rootCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if issue == nil {
+		return nil, fmt.Errorf("issue %s not found", id)
+	}
+	return issue, nil
+}
+
+// sampleHookIssue is the issue 'bd hooks test' uses when none is given.
+func sampleHookIssue() *types.Issue {
+	now := time.Now().UTC()
+	return &types.Issue{
+		ID:          "bd-example",
+		Title:       "Sample issue for bd hooks test",
+		Description: "Made up by bd hooks test.",
+		Status:      types.StatusOpen,
+		Priority:    2,
+		IssueType:   types.TypeTask,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// sampleEnvelope builds a plausible envelope for event on issue: the issue
+// as it is, and for changes, as the change would leave it.
+func sampleEnvelope(event, actorName string, issue *types.Issue) *hooks.Envelope {
+	changed := func(updates map[string]interface{}) *types.Issue {
+		return pendingUpdate(issue, updates)
+	}
+	var env *hooks.Envelope
+	var updates map[string]interface{}
+	switch event {
+	case hooks.EventDelete, hooks.EventBeforeDelete:
+		env = hooks.NewEnvelope(event, actorName, issue, nil)
+	case hooks.EventCreate, hooks.EventBeforeCreate, hooks.EventUnblocked, hooks.EventGateWarn, hooks.EventGateEscalate:
+		env = hooks.NewEnvelope(event, actorName, nil, issue)
+	case hooks.EventClose, hooks.EventBeforeClose:
+		updates = map[string]interface{}{"status": string(types.StatusClosed), "close_reason": "Done"}
+	case hooks.EventReopen:
+		updates = map[string]interface{}{"status": string(types.StatusOpen)}
+		if issue.Status != types.StatusClosed {
+			closed := *issue
+			closed.Status = types.StatusClosed
+			issue = &closed
+		}
+	case hooks.EventClaimed:
+		updates = map[string]interface{}{"assignee": actorName, "status": string(types.StatusInProgress)}
+	case hooks.EventLabelAdded:
+		updates = map[string]interface{}{"labels": append(slices.Clone(issue.Labels), "example")}
+	default: // update, status_changed, before_update and the rest
+		updates = map[string]interface{}{"status": string(types.StatusInProgress)}
+	}
+	if env == nil {
+		env = hooks.NewEnvelope(event, actorName, issue, changed(updates))
+	}
+	if hooks.IsBeforeHook(event) {
+		env.Updates = updates
+	}
+
+	switch event {
+	case hooks.EventLabelAdded, hooks.EventLabelRemoved:
+		env.Label = "example"
+	case hooks.EventDependencyAdded, hooks.EventDependencyRemoved:
+		env.Dependency = &types.Dependency{IssueID: issue.ID, DependsOnID: "bd-other", Type: types.DepBlocks, CreatedAt: env.Timestamp, CreatedBy: actorName}
+	case hooks.EventCommentAdded:
+		env.Comment = &types.Comment{IssueID: issue.ID, Author: actorName, Text: "Example comment", CreatedAt: env.Timestamp}
+	case hooks.EventUnblocked:
+		env.Cause = "bd-other"
+	}
+	return env
+}
+
+// hookTestVerdict describes what a hook's run would do to the change that
+// triggered it.
+func hookTestVerdict(name string, rec hooks.RunRecord) string {
+	if !hooks.IsBeforeHook(name) {
+		if rec.Failed() {
+			return "failed"
+		}
+		return "ok"
+	}
+	if rec.Failed() {
+		return "reject"
+	}
+	stdout := strings.TrimSpace(rec.Stdout)
+	if stdout == "" {
+		return "allow"
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &patch); err != nil || patch == nil {
+		return "reject (stdout is not a JSON object)"
+	}
+	return "patch"
+}
+
+func printHookTest(name string, env *hooks.Envelope, rec hooks.RunRecord, cfg hooks.HookConfig, verdict string) {
+	icon := ui.RenderPass("✓")
+	if rec.Failed() {
+		icon = ui.RenderFail("✗")
+	}
+	fmt.Printf("%s %s (event %s, issue %s): exit %d in %dms\n", icon, ui.RenderBold(name), env.Event, ui.RenderID(env.IssueID), rec.ExitCode, rec.DurationMs)
+	if rec.Failed() {
+		fmt.Printf("  Error:   %s\n", rec.Error)
+	}
+	if hooks.IsBeforeHook(name) {
+		fmt.Printf("  Verdict: would %s the change\n", verdict)
+	}
+
+	var notes []string
+	if cfg.Enabled != nil && !*cfg.Enabled {
+		notes = append(notes, "disabled in config.yaml, so bd does not run it")
+	}
+	if cfg.Async != nil && *cfg.Async && !hooks.IsBeforeHook(name) {
+		notes = append(notes, "runs in the background")
+	}
+	if cfg.Timeout > 0 {
+		notes = append(notes, "timeout "+cfg.Timeout.String())
+	}
+	if len(cfg.Env) > 0 {
+		notes = append(notes, fmt.Sprintf("%d env vars", len(cfg.Env)))
+	}
+	if len(notes) > 0 {
+		fmt.Printf("  Config:  %s\n", strings.Join(notes, ", "))
+	}
+
+	for _, out := range []struct{ label, text string }{{"stdout", rec.Stdout}, {"stderr", rec.Stderr}} {
+		text := strings.TrimRight(out.text, "\n")
+		if text == "" {
+			continue
+		}
+		fmt.Printf("  %s:\n", out.label)
+		for _, line := range strings.Split(text, "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
+
+func init() {
+	hooksLogCmd.Flags().Bool("failed", false, "Only show failed runs")
+	hooksLogCmd.Flags().String("hook", "", "Only show runs of this hook (e.g. on_close)")
+	hooksLogCmd.Flags().IntP("limit", "n", 20, "Number of runs to show (0 for all)")
+
+	hooksTestCmd.Flags().String("event", hooks.EventUpdate, "Event to send on_any")
+
+	hooksCmd.AddCommand(hooksLogCmd)
+	hooksCmd.AddCommand(hooksTestCmd)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/hooks"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/ui"
 	"github.com/steveyegge/beads/internal/utils"
@@ -24,13 +25,22 @@ func processBatchLabelOperation(issueIDs []string, label string, operation strin
 	storeFunc func(context.Context, string, string, string) error) {
 	ctx := rootCtx
 	results := []map[string]interface{}{}
+	event := hooks.EventLabelAdded
+	if operation == "removed" {
+		event = hooks.EventLabelRemoved
+	}
 	for _, issueID := range issueIDs {
+		var oldIssue *types.Issue
+		if hookWanted(event) {
+			oldIssue, _ = store.GetIssue(ctx, issueID) // Best effort: hooks get no old issue on failure
+		}
 		var err error
 		err = storeFunc(ctx, issueID, label, actor)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error %s label %s %s: %v\n", operation, operation, issueID, err)
 			continue
 		}
+		emitLabelHook(ctx, store, event, issueID, label, oldIssue)
 		if jsonOut {
 			results = append(results, map[string]interface{}{
 				"status":   operation,
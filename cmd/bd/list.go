@@ -15,11 +15,13 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/query"
 	"github.com/steveyegge/beads/internal/storage/dolt"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/ui"
 	"github.com/steveyegge/beads/internal/utils"
 	"github.com/steveyegge/beads/internal/validation"
+	"github.com/steveyegge/beads/internal/views"
 )
 
 // storageExecutor handles operations that need to work with both direct store and daemon mode
@@ -353,6 +355,26 @@ var listCmd = &cobra.Command{
 		// Ready filter (bd-ihu31)
 		readyFlag, _ := cmd.Flags().GetBool("ready")
 
+		// Saved view: narrows the results to the view's query
+		viewName, _ := cmd.Flags().GetString("view")
+		var view *views.View
+		var viewQuery *query.Query
+		if viewName != "" {
+			if watchMode {
+				fmt.Fprintf(os.Stderr, "Error: --view cannot be combined with --watch\n")
+				os.Exit(1)
+			}
+			view, viewQuery = mustLoadIssueView(viewName, "list")
+			if !cmd.Flags().Changed("long") && !jsonOutput && formatStr == "" {
+				switch view.Format {
+				case views.FormatLong:
+					longFormat = true
+				case views.FormatJSON:
+					jsonOutput = true
+				}
+			}
+		}
+
 		// Watch mode implies pretty format
 		if watchMode {
 			prettyFormat = true
@@ -398,7 +420,7 @@ var listCmd = &cobra.Command{
 		}
 
 		// Default to non-closed issues unless --all or explicit --status (GH#788)
-		if status == "" && !allFlag && !readyFlag {
+		if status == "" && !allFlag && !readyFlag && (viewQuery == nil || !hasExplicitStatusFilter(viewQuery.Where)) {
 			filter.ExcludeStatus = []types.Status{types.StatusClosed}
 		}
 		// Use Changed() to properly handle P0 (priority=0)
@@ -636,6 +658,14 @@ var listCmd = &cobra.Command{
 			activeStore = rigStore
 		}
 
+		// Saved view: its conditions, order and limit join the search
+		if viewQuery != nil {
+			if err := applyView(&filter, viewQuery); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
 		// Direct mode
 		issues, err := activeStore.SearchIssues(ctx, "", filter)
 		if err != nil {
@@ -643,7 +673,7 @@ var listCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// Apply sorting
+		// Apply sorting (--sort overrides a view's order)
 		sortIssues(issues, sortBy, reverse)
 
 		// Handle watch mode (GH#654) - must be before other output modes
@@ -734,6 +764,14 @@ var listCmd = &cobra.Command{
 		// Show upgrade notification if needed
 		maybeShowUpgradeNotification()
 
+		if view != nil && view.Format == views.FormatTable && !cmd.Flags().Changed("long") && !ui.IsAgentMode() {
+			displayViewTable(ctx, activeStore, issues, view.TableColumns())
+			if effectiveLimit > 0 && len(issues) == effectiveLimit {
+				fmt.Fprintf(os.Stderr, "\nShowing %d issues (use --limit 0 for all)\n", effectiveLimit)
+			}
+			return
+		}
+
 		// Load labels in bulk for display
 		issueIDs := make([]string, len(issues))
 		for i, issue := range issues {
@@ -871,6 +909,9 @@ func init() {
 	// Ready filter: show only issues ready to be worked on (bd-ihu31)
 	listCmd.Flags().Bool("ready", false, "Show only ready issues (status=open, excludes hooked/in_progress/blocked/deferred)")
 
+	// Saved views
+	listCmd.Flags().String("view", "", "Narrow results with a saved view (see bd views)")
+
 	// Cross-rig routing: query a different rig's database (bd-rgdjr)
 	listCmd.Flags().String("rig", "", "Query a different rig's database (e.g., --rig gastown, --rig gt-, --rig gt)")
 
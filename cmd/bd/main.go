@@ -323,6 +323,7 @@ var rootCmd = &cobra.Command{
 			"completion",
 			"doctor",
 			"fish",
+			"formula", // works on formula files only; never opens the database
 			"help",
 			"hook", // manages its own store lifecycle; double-open deadlocks embedded Dolt (#1719)
 			"hooks",
@@ -339,7 +340,9 @@ var rootCmd = &cobra.Command{
 			"version",
 			"zsh",
 		}
-		// Check both the command name and parent command name for subcommands
+		// The list names top-level commands; their subcommands are covered via
+		// the parent name. A nested command that shares a name (bd mol migrate)
+		// still needs the database.
 		cmdName := cmd.Name()
 		if cmd.Parent() != nil {
 			parentName := cmd.Parent().Name()
@@ -347,7 +350,7 @@ var rootCmd = &cobra.Command{
 				return
 			}
 		}
-		if slices.Contains(noDbCommands, cmdName) {
+		if cmd.Parent() == cmd.Root() && slices.Contains(noDbCommands, cmdName) {
 			return
 		}
 
@@ -610,8 +613,7 @@ var rootCmd = &cobra.Command{
 		// Initialize hook runner
 		// dbPath is .beads/something.db, so workspace root is parent of .beads
 		if dbPath != "" {
-			beadsDir := filepath.Dir(dbPath)
-			hookRunner = hooks.NewRunner(filepath.Join(beadsDir, "hooks"))
+			hookRunner = newHookRunner(filepath.Dir(dbPath))
 		}
 
 		// Warn if multiple databases detected in directory hierarchy
@@ -642,6 +644,12 @@ var rootCmd = &cobra.Command{
 			return
 		}
 
+		// Send webhook deliveries queued by this command, and retries that are
+		// due (a short best-effort pass; see deliverPendingWebhooks)
+		if !isReadOnlyCommand(cmd.Name()) && cmd.Parent() != webhookCmd {
+			deliverPendingWebhooks()
+		}
+
 		// Dolt auto-commit: after a successful write command (and after final flush),
 		// create a Dolt commit so changes don't remain only in the working set.
 		if commandDidWrite.Load() && !commandDidExplicitDoltCommit {
@@ -681,6 +689,12 @@ var rootCmd = &cobra.Command{
 			}
 		}
 
+		// Let async: true hooks finish (each is bounded by its timeout)
+		if hookRunner != nil {
+			hookRunner.Wait()
+			reportHookFailures()
+		}
+
 		// Signal that store is closing (prevents background flush from accessing closed store)
 		storeMutex.Lock()
 		storeActive = false
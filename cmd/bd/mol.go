@@ -51,6 +51,10 @@ Commands:
   pour       Instantiate proto as persistent mol (liquid phase)
   wisp       Instantiate proto as ephemeral wisp (vapor phase)
   bond       Polymorphic combine: proto+proto, proto+mol, mol+mol
+  advance    Evaluate condition gates, fan-outs and until-loops
+  migrate    Migrate a molecule to a new formula version
+  retry      Rerun a step and everything downstream of it
+  rewind     Rewind a molecule to an earlier step
   squash     Condense molecule to digest
   burn       Discard wisp
   distill    Extract proto from ad-hoc epic
@@ -0,0 +1,615 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/utils"
+)
+
+const (
+	// conditionGateType is the await type of gates created for compose.gate
+	// rules. Their await_id holds the condition expression.
+	conditionGateType = "condition"
+
+	// stepLabelPrefix labels molecule issues with their formula step ID
+	// (step:<id>) so conditions can refer to steps by ID.
+	stepLabelPrefix = "step:"
+)
+
+var molAdvanceCmd = &cobra.Command{
+	Use:   "advance <molecule-id>",
+	Short: "Evaluate a molecule's condition gates, fan-outs and until-loops",
+	Long: `Advance a molecule by evaluating its runtime control flow.
+
+Formulas can declare conditions that are only known at runtime:
+
+  compose.gate   - a condition that must hold before a step starts
+                   (e.g. "tests.status == 'complete'")
+  loop.until     - repeat a loop body until a condition holds, at most max times
+  on_complete    - when a step closes, pour one molecule per item of its output
+
+Conditions are evaluated against the molecule's live steps: a step's status
+(pending, in_progress, complete, failed) and its output, read from the
+issue's metadata (set with: bd update <id> --metadata '{"approved": true}').
+Inside a loop, body step IDs refer to the latest iteration.
+
+For each open condition gate, advance closes the gate once its condition
+holds, unblocking the gated step. For each until-loop whose latest iteration
+is closed, advance either finishes the loop (condition holds), creates the
+next iteration, or reports that max iterations were reached.
+
+bd close runs a step's on_complete fan-out itself; advance pours any fan-out
+that has not run yet (e.g. for steps closed with --force or by other tools).
+
+Run it whenever steps close, e.g. from a patrol loop or an on_close hook.
+
+Examples:
+  bd mol advance bd-mol-abc
+  bd mol advance bd-mol-abc --dry-run
+  bd mol advance bd-mol-abc --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if !dryRun {
+			CheckReadonly("mol advance")
+		}
+
+		ctx := rootCtx
+		if store == nil {
+			fmt.Fprintf(os.Stderr, "Error: no database connection\n")
+			os.Exit(1)
+		}
+
+		moleculeID, err := utils.ResolvePartialID(ctx, store, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: molecule '%s' not found\n", args[0])
+			os.Exit(1)
+		}
+
+		actions, err := advanceMolecule(ctx, store, moleculeID, dryRun, actor)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			outputJSON(map[string]interface{}{
+				"molecule_id": moleculeID,
+				"dry_run":     dryRun,
+				"actions":     actions,
+			})
+			return
+		}
+
+		if len(actions) == 0 {
+			fmt.Printf("Molecule %s has no condition gates, fan-outs or until-loops.\n", moleculeID)
+			return
+		}
+		printAdvanceActions(actions, dryRun)
+	},
+}
+
+// Advance action results.
+const (
+	advanceClosed    = "closed"    // Gate condition holds; gate closed
+	advancePending   = "pending"   // Gate condition not met, or loop iteration still open
+	advanceUnrolled  = "unrolled"  // Loop condition not met; next iteration created
+	advanceDone      = "done"      // Loop condition holds
+	advanceExhausted = "exhausted" // Loop condition not met after max iterations
+	advanceSpawned   = "spawned"   // Fan-out molecules poured
+	advanceFailed    = "failed"    // Fan-out output unusable (e.g. for_each is not an array)
+)
+
+// advanceAction describes what bd mol advance did for one gate or loop.
+type advanceAction struct {
+	Kind      string   `json:"kind"`      // "gate", "fan-out" or "loop"
+	ID        string   `json:"id"`        // Gate issue ID, fan-out step issue ID or loop step ID
+	Condition string   `json:"condition"` // Condition, or the for_each path of a fan-out
+	Result    string   `json:"result"`
+	Reason    string   `json:"reason"`
+	Iteration int      `json:"iteration,omitempty"` // Loops: latest iteration after advancing
+	Max       int      `json:"max,omitempty"`
+	Bond      string   `json:"bond,omitempty"`    // Fan-outs: formula poured per item
+	Items     int      `json:"items,omitempty"`   // Fan-outs: number of items
+	Created   []string `json:"created,omitempty"` // Loops: issues of the new iteration; fan-outs: poured molecules
+}
+
+// moleculeLoop is an until-loop found in a molecule.
+type moleculeLoop struct {
+	id        string
+	until     string
+	max       int
+	iteration int            // Latest iteration present in the molecule
+	members   []*types.Issue // Issues of the latest iteration
+}
+
+// advanceMolecule evaluates the molecule's condition gates and until-loops,
+// closing gates and creating loop iterations unless dryRun is set.
+func advanceMolecule(ctx context.Context, s *dolt.DoltStore, moleculeID string, dryRun bool, actorName string) ([]*advanceAction, error) {
+	subgraph, err := loadTemplateSubgraph(ctx, s, moleculeID)
+	if err != nil {
+		return nil, err
+	}
+	stepIDs := moleculeStepIDs(subgraph)
+	loops := findMoleculeLoops(subgraph, stepIDs)
+	condCtx := buildConditionContext(subgraph, stepIDs, loops)
+
+	var actions []*advanceAction
+
+	// Condition gates
+	for _, issue := range subgraph.Issues {
+		if issue.AwaitType != conditionGateType || issue.Status == types.StatusClosed {
+			continue
+		}
+		action := &advanceAction{Kind: "gate", ID: issue.ID, Condition: issue.AwaitID}
+		condCtx.CurrentStep = gatedStepID(subgraph, stepIDs, issue.ID)
+		result, err := formula.EvaluateCondition(issue.AwaitID, condCtx)
+		if err != nil {
+			return nil, fmt.Errorf("gate %s: %w", issue.ID, err)
+		}
+		action.Reason = result.Reason
+		action.Result = advancePending
+		if result.Satisfied {
+			action.Result = advanceClosed
+			if !dryRun {
+				if err := s.CloseIssue(ctx, issue.ID, "condition met: "+issue.AwaitID, actorName, ""); err != nil {
+					return nil, fmt.Errorf("closing gate %s: %w", issue.ID, err)
+				}
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	// On-complete fan-outs (normally run by bd close; catches up here)
+	for _, issue := range subgraph.Issues {
+		spec, done := fanOutState(issue.Labels)
+		if spec == nil || done {
+			continue
+		}
+		action, err := fanOutStep(ctx, s, subgraph, stepIDs, issue, spec, dryRun, actorName)
+		if err != nil {
+			return nil, fmt.Errorf("fan-out %s: %w", issue.ID, err)
+		}
+		actions = append(actions, action)
+	}
+
+	// Until-loops
+	for _, loop := range loops {
+		action := &advanceAction{Kind: "loop", ID: loop.id, Condition: loop.until, Iteration: loop.iteration, Max: loop.max}
+		actions = append(actions, action)
+
+		if open := openIssueIDs(loop.members); len(open) > 0 {
+			action.Result = advancePending
+			action.Reason = fmt.Sprintf("iteration %d in progress (%s open)", loop.iteration, strings.Join(open, ", "))
+			continue
+		}
+
+		condCtx.CurrentStep = stepIDs[loop.members[0].ID]
+		result, err := formula.EvaluateCondition(loop.until, condCtx)
+		if err != nil {
+			return nil, fmt.Errorf("loop %s: %w", loop.id, err)
+		}
+		action.Reason = result.Reason
+		switch {
+		case result.Satisfied:
+			action.Result = advanceDone
+		case loop.max > 0 && loop.iteration >= loop.max:
+			action.Result = advanceExhausted
+		default:
+			action.Result = advanceUnrolled
+			action.Iteration = loop.iteration + 1
+			if !dryRun {
+				created, err := unrollLoopIteration(ctx, s, subgraph, stepIDs, loop, actorName)
+				if err != nil {
+					return nil, fmt.Errorf("loop %s: %w", loop.id, err)
+				}
+				action.Created = created
+			}
+		}
+	}
+
+	return actions, nil
+}
+
+// moleculeStepIDs maps molecule issue IDs to their formula step IDs, read
+// from step:<id> labels.
+func moleculeStepIDs(subgraph *TemplateSubgraph) map[string]string {
+	stepIDs := make(map[string]string)
+	for _, issue := range subgraph.Issues {
+		for _, label := range issue.Labels {
+			if stepID, ok := strings.CutPrefix(label, stepLabelPrefix); ok {
+				stepIDs[issue.ID] = stepID
+			}
+		}
+	}
+	return stepIDs
+}
+
+// findMoleculeLoops returns the molecule's until-loops with the issues of
+// their latest iteration, ordered by loop ID.
+func findMoleculeLoops(subgraph *TemplateSubgraph, stepIDs map[string]string) []*moleculeLoop {
+	byID := make(map[string]*moleculeLoop)
+	for _, issue := range subgraph.Issues {
+		for _, label := range issue.Labels {
+			until, maxIter, ok := formula.ParseLoopLabel(label)
+			if !ok {
+				continue
+			}
+			loopID, _, _, ok := formula.ParseLoopStepID(stepIDs[issue.ID])
+			if !ok {
+				continue
+			}
+			byID[loopID] = &moleculeLoop{id: loopID, until: until, max: maxIter}
+		}
+	}
+
+	loops := make([]*moleculeLoop, 0, len(byID))
+	for _, loop := range byID {
+		for _, issue := range subgraph.Issues {
+			if _, iteration, ok := loopIteration(stepIDs[issue.ID], loop.id); ok && iteration > loop.iteration {
+				loop.iteration = iteration
+			}
+		}
+		for _, issue := range subgraph.Issues {
+			if _, iteration, ok := loopIteration(stepIDs[issue.ID], loop.id); ok && iteration == loop.iteration {
+				loop.members = append(loop.members, issue)
+			}
+		}
+		sort.Slice(loop.members, func(i, j int) bool {
+			return stepIDs[loop.members[i].ID] < stepIDs[loop.members[j].ID]
+		})
+		if len(loop.members) > 0 {
+			loops = append(loops, loop)
+		}
+	}
+	sort.Slice(loops, func(i, j int) bool { return loops[i].id < loops[j].id })
+	return loops
+}
+
+// loopIteration reports whether stepID belongs to the given loop, returning
+// the step ID relative to the iteration and the iteration number. Nested
+// loop steps belong to every enclosing loop.
+func loopIteration(stepID, loopID string) (rest string, iteration int, ok bool) {
+	after, found := strings.CutPrefix(stepID, loopID+".iter")
+	if !found {
+		return "", 0, false
+	}
+	num, rest, found := strings.Cut(after, ".")
+	if !found {
+		return "", 0, false
+	}
+	iteration, err := strconv.Atoi(num)
+	if err != nil {
+		return "", 0, false
+	}
+	return rest, iteration, true
+}
+
+// buildConditionContext builds the condition evaluation context from the
+// molecule's live step issues. Loop body steps are also reachable by their
+// body ID, resolving to the latest iteration.
+func buildConditionContext(subgraph *TemplateSubgraph, stepIDs map[string]string, loops []*moleculeLoop) *formula.ConditionContext {
+	states := make(map[string]*formula.StepState) // by issue ID
+	for _, issue := range subgraph.Issues {
+		if _, ok := stepIDs[issue.ID]; ok {
+			states[issue.ID] = &formula.StepState{
+				ID:     stepIDs[issue.ID],
+				Status: conditionStepStatus(issue),
+				Output: issueOutput(issue),
+			}
+		}
+	}
+	for _, dep := range subgraph.Dependencies {
+		child, parent := states[dep.IssueID], states[dep.DependsOnID]
+		if dep.Type == types.DepParentChild && child != nil && parent != nil {
+			parent.Children = append(parent.Children, child)
+		}
+	}
+
+	// A container step (e.g. a fanned-out molecule) completes with its children
+	settled := make(map[*formula.StepState]bool)
+	for _, state := range states {
+		settleContainerStatus(state, settled)
+	}
+
+	condCtx := &formula.ConditionContext{Steps: make(map[string]*formula.StepState)}
+	for _, state := range states {
+		condCtx.Steps[state.ID] = state
+	}
+	for _, loop := range loops {
+		for _, member := range loop.members {
+			rest, _, _ := loopIteration(stepIDs[member.ID], loop.id)
+			if _, taken := condCtx.Steps[rest]; !taken {
+				condCtx.Steps[rest] = states[member.ID]
+			}
+		}
+	}
+	return condCtx
+}
+
+// settleContainerStatus marks a step complete once all of its children are
+// complete, working bottom-up through nested children.
+func settleContainerStatus(state *formula.StepState, settled map[*formula.StepState]bool) {
+	if settled[state] {
+		return
+	}
+	settled[state] = true
+	if len(state.Children) == 0 {
+		return
+	}
+	complete := true
+	for _, child := range state.Children {
+		settleContainerStatus(child, settled)
+		if child.Status != "complete" {
+			complete = false
+		}
+	}
+	if complete && state.Status != "failed" {
+		state.Status = "complete"
+	}
+}
+
+// conditionStepStatus maps an issue status to the step status used by
+// conditions: pending, in_progress, complete or failed.
+func conditionStepStatus(issue *types.Issue) string {
+	switch issue.Status {
+	case types.StatusClosed:
+		if types.IsFailureClose(issue.CloseReason) {
+			return "failed"
+		}
+		return "complete"
+	case types.StatusInProgress, types.StatusHooked:
+		return "in_progress"
+	default:
+		return "pending"
+	}
+}
+
+// issueOutput returns the step output recorded in the issue's metadata.
+func issueOutput(issue *types.Issue) map[string]interface{} {
+	if len(issue.Metadata) == 0 {
+		return nil
+	}
+	var output map[string]interface{}
+	if err := json.Unmarshal(issue.Metadata, &output); err != nil {
+		return nil
+	}
+	return output
+}
+
+// gatedStepID returns the step ID of the issue blocked by a gate.
+func gatedStepID(subgraph *TemplateSubgraph, stepIDs map[string]string, gateID string) string {
+	for _, dep := range subgraph.Dependencies {
+		if dep.DependsOnID == gateID && dep.Type == types.DepBlocks {
+			if stepID, ok := stepIDs[dep.IssueID]; ok {
+				return stepID
+			}
+		}
+	}
+	return ""
+}
+
+// openIssueIDs returns the IDs of issues that are not closed.
+func openIssueIDs(issues []*types.Issue) []string {
+	var open []string
+	for _, issue := range issues {
+		if issue.Status != types.StatusClosed {
+			open = append(open, issue.ID)
+		}
+	}
+	return open
+}
+
+// unrollLoopIteration creates the next iteration of an until-loop by cloning
+// the issues of the latest iteration and the gates that guard them. The new
+// iteration starts after the latest one, and steps that followed the loop
+// now also wait for it.
+// Returns the IDs of the created issues.
+func unrollLoopIteration(ctx context.Context, s *dolt.DoltStore, subgraph *TemplateSubgraph, stepIDs map[string]string, loop *moleculeLoop, actorName string) ([]string, error) {
+	members := make(map[string]bool, len(loop.members))
+	for _, issue := range loop.members {
+		members[issue.ID] = true
+	}
+	inLoop := func(issueID string) bool {
+		return strings.HasPrefix(stepIDs[issueID], loop.id+".iter")
+	}
+
+	// Gates carry no step label; each belongs to the step it blocks
+	gatedStep := make(map[string]string)
+	for _, dep := range subgraph.Dependencies {
+		gate := subgraph.IssueMap[dep.DependsOnID]
+		if dep.Type == types.DepBlocks && members[dep.IssueID] && gate != nil && gate.IssueType == "gate" && stepIDs[gate.ID] == "" {
+			gatedStep[gate.ID] = dep.IssueID
+		}
+	}
+	var gates []*types.Issue
+	cloned := maps.Clone(members)
+	for _, issue := range subgraph.Issues {
+		if _, ok := gatedStep[issue.ID]; ok {
+			gates = append(gates, issue)
+			cloned[issue.ID] = true
+		}
+	}
+
+	// Iteration roots start after the iteration sinks (see chainExpandedIterations)
+	hasBlocker := make(map[string]bool)
+	hasDependent := make(map[string]bool)
+	for _, dep := range subgraph.Dependencies {
+		if dep.Type == types.DepBlocks && members[dep.IssueID] && members[dep.DependsOnID] {
+			hasBlocker[dep.IssueID] = true
+			hasDependent[dep.DependsOnID] = true
+		}
+	}
+
+	nextStepID := func(issueID string) string {
+		rest, _, _ := loopIteration(stepIDs[issueID], loop.id)
+		return formula.LoopStepID(loop.id, loop.iteration+1, rest)
+	}
+
+	idMapping := make(map[string]string)
+	var created []string
+	err := s.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		create := func(old, issue *types.Issue, labels []string) error {
+			issue.Ephemeral = old.Ephemeral
+			issue.IDPrefix = types.IDPrefixMol
+			if old.Ephemeral {
+				issue.IDPrefix = types.IDPrefixWisp
+			}
+			if err := tx.CreateIssue(ctx, issue, actorName); err != nil {
+				return fmt.Errorf("failed to create issue from %s: %w", old.ID, err)
+			}
+			idMapping[old.ID] = issue.ID
+			created = append(created, issue.ID)
+
+			for _, label := range old.Labels {
+				if isRuntimeMarkerLabel(label) || strings.HasPrefix(label, "gate:") {
+					labels = append(labels, label)
+				}
+			}
+			for _, label := range labels {
+				if err := tx.AddLabel(ctx, issue.ID, label, actorName); err != nil {
+					return fmt.Errorf("failed to add label to %s: %w", issue.ID, err)
+				}
+			}
+			return nil
+		}
+
+		for _, old := range loop.members {
+			if err := create(old, cloneLoopIssue(old), []string{stepLabelPrefix + nextStepID(old.ID)}); err != nil {
+				return err
+			}
+		}
+		for _, old := range gates {
+			stepID := nextStepID(gatedStep[old.ID])
+			var issue *types.Issue
+			if old.AwaitType == conditionGateType {
+				issue = newConditionGateIssue(stepID, old.AwaitID)
+			} else {
+				issue = cloneLoopIssue(old)
+				issue.Description = strings.ReplaceAll(old.Description, stepIDs[gatedStep[old.ID]], stepID)
+				issue.Metadata = old.Metadata // e.g. gate escalation policy
+			}
+			if err := create(old, issue, nil); err != nil {
+				return err
+			}
+		}
+
+		var deps []*types.Dependency
+		for _, dep := range subgraph.Dependencies {
+			switch {
+			case cloned[dep.IssueID] && cloned[dep.DependsOnID]:
+				deps = append(deps, &types.Dependency{IssueID: idMapping[dep.IssueID], DependsOnID: idMapping[dep.DependsOnID], Type: dep.Type})
+			case cloned[dep.IssueID] && !inLoop(dep.DependsOnID):
+				// Parent and dependencies on steps before the loop
+				deps = append(deps, &types.Dependency{IssueID: idMapping[dep.IssueID], DependsOnID: dep.DependsOnID, Type: dep.Type})
+			case members[dep.DependsOnID] && !inLoop(dep.IssueID) && !cloned[dep.IssueID] && dep.Type == types.DepBlocks:
+				// Steps after the loop wait for the new iteration too
+				deps = append(deps, &types.Dependency{IssueID: dep.IssueID, DependsOnID: idMapping[dep.DependsOnID], Type: dep.Type})
+			}
+		}
+		for _, root := range loop.members {
+			if hasBlocker[root.ID] {
+				continue
+			}
+			for _, sink := range loop.members {
+				if !hasDependent[sink.ID] {
+					deps = append(deps, &types.Dependency{IssueID: idMapping[root.ID], DependsOnID: sink.ID, Type: types.DepBlocks})
+				}
+			}
+		}
+		for _, dep := range deps {
+			if err := tx.AddDependency(ctx, dep, actorName); err != nil {
+				return fmt.Errorf("failed to create dependency: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// cloneLoopIssue returns an open copy of a loop step or gate for the next
+// iteration. Step metadata (the iteration's output) is not copied.
+func cloneLoopIssue(old *types.Issue) *types.Issue {
+	return &types.Issue{
+		Title:              old.Title,
+		Description:        old.Description,
+		Design:             old.Design,
+		AcceptanceCriteria: old.AcceptanceCriteria,
+		Notes:              old.Notes,
+		Status:             types.StatusOpen,
+		Priority:           old.Priority,
+		IssueType:          old.IssueType,
+		Assignee:           old.Assignee,
+		EstimatedMinutes:   old.EstimatedMinutes,
+		AwaitType:          old.AwaitType,
+		AwaitID:            old.AwaitID,
+		Timeout:            old.Timeout,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+}
+
+// printAdvanceActions prints the outcome of bd mol advance.
+func printAdvanceActions(actions []*advanceAction, dryRun bool) {
+	closed, unrolled, spawned := 0, 0, 0
+	for _, a := range actions {
+		switch a.Result {
+		case advanceSpawned:
+			spawned += a.Items
+			verb := "poured"
+			if dryRun {
+				verb = "would pour"
+			}
+			fmt.Printf("%s fan-out %s: %s %d %s molecule(s) - %s\n", ui.RenderPass("✓"), a.ID, verb, a.Items, a.Bond, a.Reason)
+		case advanceFailed:
+			fmt.Printf("%s fan-out %s: %s\n", ui.RenderFail("✗"), a.ID, a.Reason)
+		case advanceClosed:
+			closed++
+			verb := "closed"
+			if dryRun {
+				verb = "would close"
+			}
+			fmt.Printf("%s gate %s: %s - %s\n", ui.RenderPass("✓"), a.ID, verb, a.Condition)
+		case advanceUnrolled:
+			unrolled++
+			verb := "started"
+			if dryRun {
+				verb = "would start"
+			}
+			fmt.Printf("%s loop %s: %s iteration %d/%d - %s\n", ui.RenderAccent("↻"), a.ID, verb, a.Iteration, a.Max, a.Reason)
+		case advanceDone:
+			fmt.Printf("%s loop %s: done after %d iteration(s) - %s\n", ui.RenderPass("✓"), a.ID, a.Iteration, a.Reason)
+		case advanceExhausted:
+			fmt.Printf("%s loop %s: max %d iterations reached - %s\n", ui.RenderWarn("⚠"), a.ID, a.Max, a.Reason)
+		default:
+			fmt.Printf("%s %s %s: pending - %s\n", ui.RenderAccent("○"), a.Kind, a.ID, a.Reason)
+		}
+	}
+	if dryRun {
+		fmt.Printf("\nDry run: %d gate(s) would close, %d molecule(s) would be poured, %d loop iteration(s) would start\n", closed, spawned, unrolled)
+		return
+	}
+	fmt.Printf("\n%d gate(s) closed, %d molecule(s) poured, %d loop iteration(s) started\n", closed, spawned, unrolled)
+}
+
+func init() {
+	molAdvanceCmd.Flags().Bool("dry-run", false, "Show what would happen without changing anything")
+	molCmd.AddCommand(molAdvanceCmd)
+}
@@ -0,0 +1,375 @@
+//go:build cgo
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// pourAdvanceTestMolecule cooks a formula with a condition gate and an
+// until-loop and pours it, returning the molecule ID and the issue ID of
+// each formula step.
+func pourAdvanceTestMolecule(t *testing.T, ctx context.Context, s *dolt.DoltStore) (string, map[string]string) {
+	t.Helper()
+	f := &formula.Formula{
+		Formula: "mol-advance",
+		Version: 1,
+		Type:    formula.TypeWorkflow,
+		Steps: []*formula.Step{
+			{ID: "tests", Title: "Run tests"},
+			{
+				ID:    "review",
+				Title: "Review until approved",
+				Loop: &formula.LoopSpec{
+					Until: "check.output.approved == true",
+					Max:   2,
+					Body: []*formula.Step{
+						{ID: "fix", Title: "Fix findings"},
+						{ID: "check", Title: "Check fixes", Needs: []string{"fix"}},
+					},
+				},
+			},
+			{ID: "deploy", Title: "Deploy", Needs: []string{"review.iter1.check"}},
+		},
+		Compose: &formula.ComposeRules{
+			Gate: []*formula.GateRule{{Before: "deploy", Condition: "tests.status == 'complete'"}},
+		},
+	}
+	steps, err := formula.ApplyControlFlow(f.Steps, f.Compose)
+	if err != nil {
+		t.Fatalf("ApplyControlFlow: %v", err)
+	}
+	f.Steps = steps
+
+	subgraph, err := cookFormulaToSubgraph(f, "mol-advance")
+	if err != nil {
+		t.Fatalf("cookFormulaToSubgraph: %v", err)
+	}
+	result, err := spawnMolecule(ctx, s, subgraph, nil, "", "test", false, types.IDPrefixMol)
+	if err != nil {
+		t.Fatalf("spawnMolecule: %v", err)
+	}
+
+	issues := make(map[string]string)
+	for oldID, newID := range result.IDMapping {
+		if stepID, ok := strings.CutPrefix(oldID, "mol-advance."); ok {
+			issues[stepID] = newID
+		}
+	}
+	return result.NewEpicID, issues
+}
+
+func TestMolAdvance(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+	molID, issues := pourAdvanceTestMolecule(t, ctx, s)
+
+	gateID := issues["cond-deploy"]
+	if gateID == "" {
+		t.Fatalf("condition gate not poured: %v", issues)
+	}
+	gate, err := s.GetIssue(ctx, gateID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gate.AwaitType != conditionGateType || gate.AwaitID != "tests.status == 'complete'" {
+		t.Errorf("gate await = %q %q", gate.AwaitType, gate.AwaitID)
+	}
+
+	closeIssue := func(id string) {
+		t.Helper()
+		if err := s.CloseIssue(ctx, id, "done", "test", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	advance := func() map[string]*advanceAction {
+		t.Helper()
+		actions, err := advanceMolecule(ctx, s, molID, false, "test")
+		if err != nil {
+			t.Fatalf("advanceMolecule: %v", err)
+		}
+		byKind := make(map[string]*advanceAction)
+		for _, a := range actions {
+			byKind[a.Kind] = a
+		}
+		return byKind
+	}
+
+	// Nothing is done yet
+	actions := advance()
+	if actions["gate"].Result != advancePending || actions["loop"].Result != advancePending {
+		t.Fatalf("initial advance: gate=%+v loop=%+v", actions["gate"], actions["loop"])
+	}
+
+	// Tests complete: the gate closes
+	closeIssue(issues["tests"])
+	actions = advance()
+	if actions["gate"].Result != advanceClosed {
+		t.Errorf("gate result = %s (%s)", actions["gate"].Result, actions["gate"].Reason)
+	}
+	if gate, _ := s.GetIssue(ctx, gateID); gate.Status != types.StatusClosed {
+		t.Errorf("gate status = %s, want closed", gate.Status)
+	}
+
+	// First iteration closes without approval: a second iteration is created
+	closeIssue(issues["review.iter1.fix"])
+	closeIssue(issues["review.iter1.check"])
+	actions = advance()
+	loop := actions["loop"]
+	if loop.Result != advanceUnrolled || loop.Iteration != 2 || len(loop.Created) != 2 {
+		t.Fatalf("loop action = %+v", loop)
+	}
+	created := make(map[string]string) // step ID -> issue ID
+	for _, id := range loop.Created {
+		labels, err := s.GetLabels(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, label := range labels {
+			if stepID, ok := strings.CutPrefix(label, stepLabelPrefix); ok {
+				created[stepID] = id
+			}
+		}
+	}
+	if created["review.iter2.fix"] == "" || created["review.iter2.check"] == "" {
+		t.Fatalf("new iteration step labels = %v", created)
+	}
+	deployBlockers, err := s.GetDependencyRecords(ctx, issues["deploy"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitsForIteration := false
+	for _, dep := range deployBlockers {
+		if dep.DependsOnID == created["review.iter2.check"] && dep.Type == types.DepBlocks {
+			waitsForIteration = true
+		}
+	}
+	if !waitsForIteration {
+		t.Error("deploy should wait for the new iteration")
+	}
+	fixBlockers, err := s.GetDependencyRecords(ctx, created["review.iter2.fix"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	startsAfterPrevious := false
+	for _, dep := range fixBlockers {
+		if dep.DependsOnID == issues["review.iter1.check"] && dep.Type == types.DepBlocks {
+			startsAfterPrevious = true
+		}
+	}
+	if !startsAfterPrevious {
+		t.Error("iteration 2 should start after iteration 1")
+	}
+
+	// Second iteration approved: the loop is done
+	for _, id := range loop.Created {
+		closeIssue(id)
+	}
+	approved, _ := json.Marshal(map[string]interface{}{"approved": true})
+	if err := s.UpdateIssue(ctx, created["review.iter2.check"], map[string]interface{}{"metadata": json.RawMessage(approved)}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if actions = advance(); actions["loop"].Result != advanceDone || actions["loop"].Iteration != 2 {
+		t.Errorf("loop action = %+v", actions["loop"])
+	}
+	if _, ok := actions["gate"]; ok {
+		t.Error("closed gates should not be re-evaluated")
+	}
+}
+
+func TestMolAdvance_MaxIterations(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+	molID, issues := pourAdvanceTestMolecule(t, ctx, s)
+
+	for round := 1; round <= 2; round++ {
+		var ids []string
+		if round == 1 {
+			ids = []string{issues["review.iter1.fix"], issues["review.iter1.check"]}
+		} else {
+			actions, err := advanceMolecule(ctx, s, molID, false, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = actions[len(actions)-1].Created
+		}
+		for _, id := range ids {
+			if err := s.CloseIssue(ctx, id, "done", "test", ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Dry run reports without creating a third iteration
+	actions, err := advanceMolecule(ctx, s, molID, true, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loop := actions[len(actions)-1]; loop.Result != advanceExhausted || loop.Iteration != 2 {
+		t.Errorf("loop action = %+v", loop)
+	}
+}
+
+// TestMolAdvance_LoopGates checks that a new loop iteration gets its own
+// gates: the step's async gate and its condition gate are re-created open,
+// and the new steps wait for them instead of the previous iteration's.
+func TestMolAdvance_LoopGates(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+
+	f := &formula.Formula{
+		Formula: "mol-loop-gates",
+		Version: 1,
+		Type:    formula.TypeWorkflow,
+		Steps: []*formula.Step{{
+			ID:    "review",
+			Title: "Review until approved",
+			Loop: &formula.LoopSpec{
+				Until: "check.output.approved == true",
+				Max:   3,
+				Body: []*formula.Step{
+					{ID: "fix", Title: "Fix findings", Gate: &formula.Gate{Type: "human", ID: "lead", Timeout: "1h"}},
+					{ID: "check", Title: "Check fixes", Needs: []string{"fix"}},
+				},
+			},
+		}},
+		Compose: &formula.ComposeRules{
+			Gate: []*formula.GateRule{{Before: "review.iter1.check", Condition: "fix.status == 'complete'"}},
+		},
+	}
+	steps, err := formula.ApplyControlFlow(f.Steps, f.Compose)
+	if err != nil {
+		t.Fatalf("ApplyControlFlow: %v", err)
+	}
+	f.Steps = steps
+	subgraph, err := cookFormulaToSubgraph(f, "mol-loop-gates")
+	if err != nil {
+		t.Fatalf("cookFormulaToSubgraph: %v", err)
+	}
+	result, err := spawnMolecule(ctx, s, subgraph, nil, "", "test", false, types.IDPrefixMol)
+	if err != nil {
+		t.Fatalf("spawnMolecule: %v", err)
+	}
+	issues := make(map[string]string)
+	for oldID, newID := range result.IDMapping {
+		if stepID, ok := strings.CutPrefix(oldID, "mol-loop-gates."); ok {
+			issues[stepID] = newID
+		}
+	}
+
+	// Run iteration 1 to completion, gates included
+	for _, key := range []string{"gate-review.iter1.fix", "review.iter1.fix", "cond-review.iter1.check", "review.iter1.check"} {
+		if err := s.CloseIssue(ctx, issues[key], "done", "test", ""); err != nil {
+			t.Fatalf("close %s: %v", key, err)
+		}
+	}
+	actions, err := advanceMolecule(ctx, s, result.NewEpicID, false, "test")
+	if err != nil {
+		t.Fatalf("advanceMolecule: %v", err)
+	}
+	loop := actions[len(actions)-1]
+	if loop.Kind != "loop" || loop.Result != advanceUnrolled || len(loop.Created) != 4 {
+		t.Fatalf("loop action = %+v", loop)
+	}
+
+	steps2 := make(map[string]string) // step ID -> issue ID
+	var asyncGate, condGate *types.Issue
+	for _, id := range loop.Created {
+		issue, err := s.GetIssue(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if issue.Status != types.StatusOpen {
+			t.Errorf("%s status = %s, want open", id, issue.Status)
+		}
+		switch issue.AwaitType {
+		case "human":
+			asyncGate = issue
+		case conditionGateType:
+			condGate = issue
+		}
+		labels, err := s.GetLabels(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, label := range labels {
+			if stepID, ok := strings.CutPrefix(label, stepLabelPrefix); ok {
+				steps2[stepID] = id
+			}
+		}
+	}
+	if asyncGate == nil || asyncGate.IssueType != "gate" || asyncGate.AwaitID != "lead" || asyncGate.Timeout.String() != "1h0m0s" {
+		t.Fatalf("async gate not re-created: %+v", asyncGate)
+	}
+	if condGate == nil || condGate.IssueType != "gate" || condGate.AwaitID != "fix.status == 'complete'" {
+		t.Fatalf("condition gate not re-created: %+v", condGate)
+	}
+	if !strings.Contains(condGate.Description, "review.iter2.check") {
+		t.Errorf("condition gate description = %q", condGate.Description)
+	}
+
+	// Each new step waits for its new gate, not the closed one
+	for stepID, wantGate := range map[string]string{"review.iter2.fix": asyncGate.ID, "review.iter2.check": condGate.ID} {
+		deps, err := s.GetDependencyRecords(ctx, steps2[stepID])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var blockers []string
+		for _, dep := range deps {
+			if dep.Type == types.DepBlocks {
+				blockers = append(blockers, dep.DependsOnID)
+			}
+		}
+		if !slices.Contains(blockers, wantGate) {
+			t.Errorf("%s blockers = %v, want gate %s", stepID, blockers, wantGate)
+		}
+		for _, old := range []string{issues["gate-review.iter1.fix"], issues["cond-review.iter1.check"]} {
+			if slices.Contains(blockers, old) {
+				t.Errorf("%s still waits for iteration 1 gate %s", stepID, old)
+			}
+		}
+	}
+
+	// The new condition gate is evaluated against iteration 2
+	if err := s.CloseIssue(ctx, asyncGate.ID, "approved", "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CloseIssue(ctx, steps2["review.iter2.fix"], "done", "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	actions, err = advanceMolecule(ctx, s, result.NewEpicID, false, "test")
+	if err != nil {
+		t.Fatalf("advanceMolecule: %v", err)
+	}
+	if actions[0].Kind != "gate" || actions[0].ID != condGate.ID || actions[0].Result != advanceClosed {
+		t.Errorf("gate action = %+v", actions[0])
+	}
+}
+
+func TestConditionStepStatus(t *testing.T) {
+	tests := []struct {
+		status types.Status
+		reason string
+		want   string
+	}{
+		{types.StatusOpen, "", "pending"},
+		{types.StatusBlocked, "", "pending"},
+		{types.StatusInProgress, "", "in_progress"},
+		{types.StatusClosed, "done", "complete"},
+		{types.StatusClosed, "tests failed", "failed"},
+	}
+	for _, tt := range tests {
+		issue := &types.Issue{Status: tt.status, CloseReason: tt.reason}
+		if got := conditionStepStatus(issue); got != tt.want {
+			t.Errorf("conditionStepStatus(%s, %q) = %s, want %s", tt.status, tt.reason, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// fanOutDoneLabel marks an on_complete step whose molecules have been
+// poured, so the fan-out runs once per step.
+const fanOutDoneLabel = "fanned-out"
+
+// fanOutArm is a molecule poured for one for_each item.
+type fanOutArm struct {
+	root string   // Root issue of the poured molecule
+	work []string // Issues that must close for the arm to be complete
+}
+
+// fanOutOnClose runs the on_complete fan-out of a step that was just closed.
+// Returns nil if the issue has no pending fan-out.
+func fanOutOnClose(ctx context.Context, s *dolt.DoltStore, issueID string, actorName string) (*advanceAction, error) {
+	labels, err := s.GetLabels(ctx, issueID)
+	if err != nil {
+		return nil, err
+	}
+	spec, done := fanOutState(labels)
+	if spec == nil || done {
+		return nil, nil
+	}
+
+	moleculeID := findParentMolecule(ctx, s, issueID)
+	if moleculeID == "" {
+		return nil, fmt.Errorf("%s has on_complete but no parent molecule", issueID)
+	}
+	subgraph, err := loadTemplateSubgraph(ctx, s, moleculeID)
+	if err != nil {
+		return nil, err
+	}
+	spawner := subgraph.IssueMap[issueID]
+	if spawner == nil {
+		return nil, fmt.Errorf("%s not found in molecule %s", issueID, moleculeID)
+	}
+	return fanOutStep(ctx, s, subgraph, moleculeStepIDs(subgraph), spawner, spec, false, actorName)
+}
+
+// fanOutState returns the on_complete spec recorded in a step's labels and
+// whether its fan-out already ran.
+func fanOutState(labels []string) (spec *formula.OnCompleteSpec, done bool) {
+	for _, label := range labels {
+		if oc, ok := formula.ParseOnCompleteLabel(label); ok {
+			spec = oc
+		}
+		if label == fanOutDoneLabel {
+			done = true
+		}
+	}
+	return spec, done
+}
+
+// fanOutStep pours one spec.Bond molecule per item of the closed step's
+// for_each collection, as children of the step. Sequential arms each wait
+// for the previous arm. Steps with a waits-for dependency on the step wait
+// for every arm (all-children), or get a condition gate that bd mol advance
+// closes once the first arm completes (any-children).
+func fanOutStep(ctx context.Context, s *dolt.DoltStore, subgraph *TemplateSubgraph, stepIDs map[string]string, spawner *types.Issue, spec *formula.OnCompleteSpec, dryRun bool, actorName string) (*advanceAction, error) {
+	action := &advanceAction{Kind: "fan-out", ID: spawner.ID, Condition: spec.ForEach, Bond: spec.Bond}
+	if spawner.Status != types.StatusClosed {
+		action.Result = advancePending
+		action.Reason = "step not closed"
+		return action, nil
+	}
+
+	items, err := formula.ResolveForEach(issueOutput(spawner), spec.ForEach)
+	if err != nil {
+		action.Result = advanceFailed
+		action.Reason = err.Error()
+		return action, nil
+	}
+	itemVars := make([]map[string]string, len(items))
+	for i, item := range items {
+		if itemVars[i], err = formula.SubstituteItemVars(spec.Vars, item, i); err != nil {
+			action.Result = advanceFailed
+			action.Reason = err.Error()
+			return action, nil
+		}
+	}
+	action.Result = advanceSpawned
+	action.Items = len(items)
+	action.Reason = fmt.Sprintf("%d item(s) in %s", len(items), spec.ForEach)
+	if dryRun {
+		return action, nil
+	}
+
+	// Cook every arm before pouring any, so a bad item pours nothing
+	protos := make([]*TemplateSubgraph, len(items))
+	for i, vars := range itemVars {
+		proto, cooked, err := resolveOrCookToSubgraph(ctx, s, spec.Bond, vars)
+		if err != nil {
+			return nil, err
+		}
+		if !cooked && !isProto(proto.Root) {
+			return nil, fmt.Errorf("bond %s is not a formula or proto", spec.Bond)
+		}
+		var missing []string
+		for _, v := range extractAllVariables(proto) {
+			if _, ok := vars[v]; !ok {
+				missing = append(missing, v)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("item %d: missing variables for %s: %s (add them to on_complete.vars)", i, spec.Bond, strings.Join(missing, ", "))
+		}
+		protos[i] = proto
+	}
+
+	// Pour the arms, wire them up and mark the step done in one
+	// transaction, so a failure leaves no partial arms behind and a retry
+	// does not pour them twice
+	stepID := stepIDs[spawner.ID]
+	arms := make([]*fanOutArm, len(items))
+	err = s.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		for i, proto := range protos {
+			childRef := fmt.Sprintf("item-%d", i)
+			opts := CloneOptions{
+				Vars:      itemVars[i],
+				Actor:     actorName,
+				Ephemeral: spawner.Ephemeral,
+				ParentID:  spawner.ID,
+				ChildRef:  childRef,
+			}
+			if stepID != "" {
+				opts.StepPrefix = stepID + "." + childRef
+			}
+			result, err := cloneSubgraphInTx(ctx, tx, proto, opts)
+			if err != nil {
+				return fmt.Errorf("pouring %s for item %d: %w", spec.Bond, i, err)
+			}
+			arm := &fanOutArm{root: result.NewEpicID}
+			for _, id := range result.IDMapping {
+				if id != arm.root {
+					arm.work = append(arm.work, id)
+				}
+			}
+			if len(arm.work) == 0 {
+				arm.work = []string{arm.root}
+			}
+			sort.Strings(arm.work)
+			arms[i] = arm
+		}
+
+		var deps []*types.Dependency
+		for i, arm := range arms {
+			deps = append(deps, &types.Dependency{IssueID: arm.root, DependsOnID: spawner.ID, Type: types.DepParentChild})
+			if stepID != "" {
+				if err := tx.AddLabel(ctx, arm.root, stepLabelPrefix+stepID+fmt.Sprintf(".item-%d", i), actorName); err != nil {
+					return fmt.Errorf("failed to add label to %s: %w", arm.root, err)
+				}
+			}
+			if spec.Sequential && i > 0 {
+				for _, id := range arms[i-1].work {
+					deps = append(deps, &types.Dependency{IssueID: arm.root, DependsOnID: id, Type: types.DepBlocks})
+				}
+			}
+		}
+
+		for _, dep := range subgraph.Dependencies {
+			waiter := subgraph.IssueMap[dep.IssueID]
+			if dep.Type != types.DepWaitsFor || dep.DependsOnID != spawner.ID || waiter.Status == types.StatusClosed {
+				continue
+			}
+			if waitsForGate(dep) == types.WaitsForAnyChildren && stepID != "" {
+				if len(arms) == 0 {
+					continue
+				}
+				gateDeps, err := createFanOutGate(ctx, tx, subgraph, waiter, spawner, stepID, actorName)
+				if err != nil {
+					return err
+				}
+				deps = append(deps, gateDeps...)
+				continue
+			}
+			for _, arm := range arms {
+				for _, id := range arm.work {
+					deps = append(deps, &types.Dependency{IssueID: waiter.ID, DependsOnID: id, Type: types.DepBlocks})
+				}
+			}
+		}
+
+		for _, dep := range deps {
+			if err := tx.AddDependency(ctx, dep, actorName); err != nil {
+				return fmt.Errorf("failed to create dependency: %w", err)
+			}
+		}
+		return tx.AddLabel(ctx, spawner.ID, fanOutDoneLabel, actorName)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fanning out %s: %w", spawner.ID, err)
+	}
+	for _, arm := range arms {
+		action.Created = append(action.Created, arm.root)
+	}
+	return action, nil
+}
+
+// waitsForGate returns the gate type of a waits-for dependency, defaulting
+// to all-children.
+func waitsForGate(dep *types.Dependency) string {
+	var meta types.WaitsForMeta
+	if dep.Metadata != "" && json.Unmarshal([]byte(dep.Metadata), &meta) == nil && meta.Gate != "" {
+		return meta.Gate
+	}
+	return types.WaitsForAllChildren
+}
+
+// createFanOutGate creates a condition gate blocking waiter until the first
+// arm fanned out by the spawner step completes. Returns the dependencies
+// wiring the gate beside the waiter.
+func createFanOutGate(ctx context.Context, tx storage.Transaction, subgraph *TemplateSubgraph, waiter, spawner *types.Issue, stepID, actorName string) ([]*types.Dependency, error) {
+	condition := fmt.Sprintf("children(%s).any(status == 'complete')", stepID)
+	prefix := types.IDPrefixMol
+	if spawner.Ephemeral {
+		prefix = types.IDPrefixWisp
+	}
+	gate := &types.Issue{
+		Title:       fmt.Sprintf("Gate: %s", condition),
+		Description: fmt.Sprintf("Waits for the first molecule fanned out by %s (evaluated by bd mol advance)", spawner.ID),
+		Status:      types.StatusOpen,
+		Priority:    2,
+		IssueType:   "gate",
+		AwaitType:   conditionGateType,
+		AwaitID:     condition,
+		Ephemeral:   spawner.Ephemeral,
+		IDPrefix:    prefix,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := tx.CreateIssue(ctx, gate, actorName); err != nil {
+		return nil, fmt.Errorf("failed to create gate for %s: %w", waiter.ID, err)
+	}
+
+	deps := []*types.Dependency{{IssueID: waiter.ID, DependsOnID: gate.ID, Type: types.DepBlocks}}
+	for _, dep := range subgraph.Dependencies {
+		if dep.IssueID == waiter.ID && dep.Type == types.DepParentChild {
+			deps = append(deps, &types.Dependency{IssueID: gate.ID, DependsOnID: dep.DependsOnID, Type: types.DepParentChild})
+		}
+	}
+	return deps, nil
+}
@@ -0,0 +1,293 @@
+//go:build cgo
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// pourFanOutTestMolecule cooks a worker-arm proto and pours a molecule whose
+// survey step fans out over output.workers, returning the molecule ID and
+// the issue ID of each formula step.
+func pourFanOutTestMolecule(t *testing.T, ctx context.Context, s *dolt.DoltStore, sequential bool) (string, map[string]string) {
+	t.Helper()
+	arm := &formula.Formula{
+		Formula: "mol-fanout-arm",
+		Version: 1,
+		Type:    formula.TypeWorkflow,
+		Steps: []*formula.Step{
+			{ID: "capture", Title: "Capture {{name}}"},
+			{ID: "report", Title: "Report {{name}}", Needs: []string{"capture"}},
+		},
+	}
+	if _, err := cookFormula(ctx, s, arm, "mol-fanout-arm"); err != nil {
+		t.Fatalf("cookFormula: %v", err)
+	}
+
+	f := &formula.Formula{
+		Formula: "mol-fanout",
+		Version: 1,
+		Type:    formula.TypeWorkflow,
+		Steps: []*formula.Step{
+			{
+				ID:    "survey",
+				Title: "Survey workers",
+				OnComplete: &formula.OnCompleteSpec{
+					ForEach:    "output.workers",
+					Bond:       "mol-fanout-arm",
+					Vars:       map[string]string{"name": "{item.name}"},
+					Sequential: sequential,
+				},
+			},
+			{ID: "aggregate", Title: "Aggregate", Needs: []string{"survey"}, WaitsFor: "all-children"},
+			{ID: "first", Title: "Use first result", Needs: []string{"survey"}, WaitsFor: "any-children"},
+		},
+	}
+	subgraph, err := cookFormulaToSubgraph(f, "mol-fanout")
+	if err != nil {
+		t.Fatalf("cookFormulaToSubgraph: %v", err)
+	}
+	result, err := spawnMolecule(ctx, s, subgraph, nil, "", "test", false, types.IDPrefixMol)
+	if err != nil {
+		t.Fatalf("spawnMolecule: %v", err)
+	}
+
+	issues := make(map[string]string)
+	for oldID, newID := range result.IDMapping {
+		if stepID, ok := strings.CutPrefix(oldID, "mol-fanout."); ok {
+			issues[stepID] = newID
+		}
+	}
+	return result.NewEpicID, issues
+}
+
+// closeSurvey records the survey output and closes the step.
+func closeSurvey(t *testing.T, ctx context.Context, s *dolt.DoltStore, surveyID string, output string) {
+	t.Helper()
+	if err := s.UpdateIssue(ctx, surveyID, map[string]interface{}{"metadata": json.RawMessage(output)}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CloseIssue(ctx, surveyID, "done", "test", ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// blockerIDs returns the IDs an issue is blocked by (blocks dependencies).
+func blockerIDs(t *testing.T, ctx context.Context, s *dolt.DoltStore, issueID string) map[string]bool {
+	t.Helper()
+	deps, err := s.GetDependencyRecords(ctx, issueID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockers := make(map[string]bool)
+	for _, dep := range deps {
+		if dep.Type == types.DepBlocks {
+			blockers[dep.DependsOnID] = true
+		}
+	}
+	return blockers
+}
+
+func TestFanOutOnClose(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+	molID, issues := pourFanOutTestMolecule(t, ctx, s, false)
+
+	// Open steps have nothing to fan out yet
+	actions, err := advanceMolecule(ctx, s, molID, true, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 || actions[0].Kind != "fan-out" || actions[0].Result != advancePending {
+		t.Fatalf("advance before close = %+v", actions)
+	}
+
+	surveyID := issues["survey"]
+	closeSurvey(t, ctx, s, surveyID, `{"workers": [{"name": "ace"}, {"name": "nux"}]}`)
+	action, err := fanOutOnClose(ctx, s, surveyID, "test")
+	if err != nil {
+		t.Fatalf("fanOutOnClose: %v", err)
+	}
+	if action == nil || action.Result != advanceSpawned || action.Items != 2 {
+		t.Fatalf("fan-out action = %+v", action)
+	}
+
+	// Arms are poured under the survey step with item vars substituted
+	armID := surveyID + ".item-1"
+	if action.Created[1] != armID {
+		t.Errorf("created = %v, want %s second", action.Created, armID)
+	}
+	capture, err := s.GetIssue(ctx, armID+".capture")
+	if err != nil || capture == nil {
+		t.Fatalf("arm step not poured: %v", err)
+	}
+	if capture.Title != "Capture nux" {
+		t.Errorf("arm step title = %q", capture.Title)
+	}
+	if parent := findParentMolecule(ctx, s, capture.ID); parent != molID {
+		t.Errorf("arm belongs to %q, want molecule %s", parent, molID)
+	}
+
+	// all-children: aggregate waits for every arm step
+	blockers := blockerIDs(t, ctx, s, issues["aggregate"])
+	for _, arm := range []string{surveyID + ".item-0", armID} {
+		if !blockers[arm+".capture"] || !blockers[arm+".report"] {
+			t.Errorf("aggregate should wait for %s steps, blockers = %v", arm, blockers)
+		}
+	}
+
+	// any-children: first waits for a condition gate
+	var gateID string
+	for id := range blockerIDs(t, ctx, s, issues["first"]) {
+		if gate, _ := s.GetIssue(ctx, id); gate != nil && gate.AwaitType == conditionGateType {
+			gateID = id
+		}
+	}
+	if gateID == "" {
+		t.Fatal("first should be blocked by a condition gate")
+	}
+
+	// The fan-out runs once
+	if action, err := fanOutOnClose(ctx, s, surveyID, "test"); err != nil || action != nil {
+		t.Errorf("second fan-out = %+v, %v", action, err)
+	}
+
+	// Completing one arm opens the any-children gate
+	for _, step := range []string{"capture", "report"} {
+		if err := s.CloseIssue(ctx, armID+"."+step, "done", "test", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	actions, err = advanceMolecule(ctx, s, molID, false, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range actions {
+		if a.ID == gateID && a.Result != advanceClosed {
+			t.Errorf("gate action = %+v", a)
+		}
+		if a.Kind == "fan-out" {
+			t.Errorf("fan-out should not run again: %+v", a)
+		}
+	}
+	if gate, _ := s.GetIssue(ctx, gateID); gate.Status != types.StatusClosed {
+		t.Errorf("gate status = %s, want closed", gate.Status)
+	}
+}
+
+func TestFanOutSequential(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+	molID, issues := pourFanOutTestMolecule(t, ctx, s, true)
+
+	surveyID := issues["survey"]
+	closeSurvey(t, ctx, s, surveyID, `{"workers": [{"name": "ace"}, {"name": "nux"}, {"name": "toast"}]}`)
+
+	// bd mol advance catches up on fan-outs bd close did not run
+	actions, err := advanceMolecule(ctx, s, molID, false, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created []string
+	for _, a := range actions {
+		if a.Kind == "fan-out" {
+			created = a.Created
+		}
+	}
+	if len(created) != 3 {
+		t.Fatalf("created = %v, want 3 arms", created)
+	}
+	for i := 1; i < len(created); i++ {
+		blockers := blockerIDs(t, ctx, s, created[i])
+		if !blockers[created[i-1]+".report"] {
+			t.Errorf("arm %d should wait for arm %d, blockers = %v", i, i-1, blockers)
+		}
+	}
+	if blockers := blockerIDs(t, ctx, s, created[0]); len(blockers) != 0 {
+		t.Errorf("first arm should not wait, blockers = %v", blockers)
+	}
+}
+
+func TestFanOutBadOutput(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+	_, issues := pourFanOutTestMolecule(t, ctx, s, false)
+
+	surveyID := issues["survey"]
+	closeSurvey(t, ctx, s, surveyID, `{"workers": "ace"}`)
+	action, err := fanOutOnClose(ctx, s, surveyID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action.Result != advanceFailed || !strings.Contains(action.Reason, "expected an array") {
+		t.Errorf("fan-out action = %+v", action)
+	}
+	labels, _ := s.GetLabels(ctx, surveyID)
+	if _, done := fanOutState(labels); done {
+		t.Error("failed fan-out should not be marked done")
+	}
+}
+
+// TestFanOutFailureRollsBack checks that a fan-out that fails partway leaves
+// no arms behind and is not marked done, so a retry pours every arm once.
+func TestFanOutFailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+	molID, issues := pourFanOutTestMolecule(t, ctx, s, false)
+
+	// An issue in the way of the second arm's root makes its pour fail
+	// after the first arm was poured
+	surveyID := issues["survey"]
+	squatter := &types.Issue{ID: surveyID + ".item-1", Title: "In the way", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	if err := s.CreateIssue(ctx, squatter, "test"); err != nil {
+		t.Fatal(err)
+	}
+	closeSurvey(t, ctx, s, surveyID, `{"workers": [{"name": "ace"}, {"name": "nux"}]}`)
+	if _, err := fanOutOnClose(ctx, s, surveyID, "test"); err == nil {
+		t.Fatal("fanOutOnClose succeeded with an arm ID taken")
+	}
+	if arm, _ := s.GetIssue(ctx, surveyID+".item-0"); arm != nil {
+		t.Error("first arm was left behind by the failed fan-out")
+	}
+	labels, _ := s.GetLabels(ctx, surveyID)
+	if _, done := fanOutState(labels); done {
+		t.Error("failed fan-out should not be marked done")
+	}
+
+	if err := s.DeleteIssue(ctx, squatter.ID); err != nil {
+		t.Fatal(err)
+	}
+	actions, err := advanceMolecule(ctx, s, molID, false, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created []string
+	for _, a := range actions {
+		if a.Kind == "fan-out" {
+			created = a.Created
+		}
+	}
+	if len(created) != 2 {
+		t.Fatalf("retry created %v, want 2 arms", created)
+	}
+	dependents, err := s.GetDependents(ctx, surveyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var arms int
+	for _, dep := range dependents {
+		if strings.HasPrefix(dep.ID, surveyID+".item-") {
+			arms++
+		}
+	}
+	if arms != 2 {
+		t.Errorf("survey has %d arms after retry, want 2", arms)
+	}
+}
@@ -0,0 +1,607 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/utils"
+)
+
+var molMigrateCmd = &cobra.Command{
+	Use:   "migrate <molecule-id> --to <formula>[@version]",
+	Short: "Migrate an in-flight molecule to a new formula version",
+	Long: `Reshape a poured molecule to match the current version of a formula,
+without burning and re-pouring it.
+
+The formula is cooked with --var values (pass the ones the molecule was poured
+with) and its steps are matched against the molecule's steps by step ID
+(the step:<id> label pour records), falling back to the title under the same
+parent for older molecules. Gates are matched through the step they guard.
+Then:
+
+  add      steps new in the formula are created
+  update   open steps whose title or description changed are updated
+  retire   open steps no longer in the formula are closed
+  keep     closed steps are left as history, even if removed
+  rewire   dependencies between the remaining steps follow the formula
+
+Loop iterations and fan-out molecules created at runtime by bd mol advance
+are left alone. Closed steps keep their dependencies.
+
+The plan is always shown first. Confirm it, or use --dry-run to only show it
+and --force to apply it without asking. With @version, the formula's version
+field must match.
+
+Examples:
+  bd mol migrate bd-mol-abc --to mol-patrol --dry-run
+  bd mol migrate bd-mol-abc --to mol-patrol@3 --var rig=gastown
+  bd mol migrate bd-mol-abc --to mol-patrol --force --json`,
+	Args: cobra.ExactArgs(1),
+	Run:  runMolMigrate,
+}
+
+// Migration step actions.
+const (
+	migrateKeep    = "keep"    // Matched; unchanged
+	migrateUpdate  = "update"  // Matched open step; title or description changed
+	migrateAdd     = "add"     // New in the formula
+	migrateRetire  = "retire"  // Open step no longer in the formula; closed
+	migrateHistory = "history" // Closed step no longer in the formula; kept
+	migrateRuntime = "runtime" // Created at runtime (loop iteration, fan-out); kept
+)
+
+// migrateStep is one step of a migration plan.
+type migrateStep struct {
+	Action  string `json:"action"`
+	StepID  string `json:"step_id,omitempty"`
+	IssueID string `json:"issue_id,omitempty"` // Empty for added steps in a dry run
+	Title   string `json:"title"`
+	Reason  string `json:"reason,omitempty"`
+
+	cookedID string
+	updates  map[string]interface{}
+	label    bool // Matched by title: record the step:<id> label
+}
+
+// migrateDep is a dependency added or removed by a migration.
+type migrateDep struct {
+	Action      string               `json:"action"` // "add" or "remove"
+	IssueID     string               `json:"issue_id"`
+	DependsOnID string               `json:"depends_on_id"`
+	Type        types.DependencyType `json:"type"`
+}
+
+// migratePlan is the result of diffing a molecule against a cooked formula.
+type migratePlan struct {
+	MoleculeID string         `json:"molecule_id"`
+	Formula    string         `json:"formula"`
+	Version    int            `json:"version"`
+	DryRun     bool           `json:"dry_run"`
+	Steps      []*migrateStep `json:"steps"`
+	Deps       []*migrateDep  `json:"dependencies"`
+
+	cooked    *TemplateSubgraph
+	vars      map[string]string
+	ephemeral bool
+}
+
+// counts returns the number of plan steps per action.
+func (p *migratePlan) counts() map[string]int {
+	counts := make(map[string]int)
+	for _, step := range p.Steps {
+		counts[step.Action]++
+	}
+	return counts
+}
+
+// changes reports whether applying the plan would change anything.
+func (p *migratePlan) changes() bool {
+	c := p.counts()
+	return c[migrateAdd]+c[migrateUpdate]+c[migrateRetire] > 0 || len(p.Deps) > 0 || p.relabels()
+}
+
+func (p *migratePlan) relabels() bool {
+	for _, step := range p.Steps {
+		if step.label {
+			return true
+		}
+	}
+	return false
+}
+
+func runMolMigrate(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	to, _ := cmd.Flags().GetString("to")
+	varFlags, _ := cmd.Flags().GetStringArray("var")
+	if !dryRun {
+		CheckReadonly("mol migrate")
+	}
+
+	ctx := rootCtx
+	if store == nil {
+		fmt.Fprintf(os.Stderr, "Error: no database connection\n")
+		os.Exit(1)
+	}
+	if to == "" {
+		fmt.Fprintf(os.Stderr, "Error: --to <formula> is required\n")
+		os.Exit(1)
+	}
+
+	formulaName, version := to, 0
+	if i := strings.LastIndex(to, "@"); i > 0 {
+		formulaName = to[:i]
+		v, err := strconv.Atoi(strings.TrimPrefix(to[i+1:], "v"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid formula version %q (expected the formula's version number)\n", to[i+1:])
+			os.Exit(1)
+		}
+		version = v
+	}
+	inputVars := make(map[string]string)
+	for _, v := range varFlags {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			fmt.Fprintf(os.Stderr, "Error: invalid variable format '%s', expected 'key=value'\n", v)
+			os.Exit(1)
+		}
+		inputVars[parts[0]] = parts[1]
+	}
+
+	moleculeID, err := utils.ResolvePartialID(ctx, store, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: molecule '%s' not found\n", args[0])
+		os.Exit(1)
+	}
+
+	resolved, cooked, vars, err := cookFormulaPreview(formulaName, nil, inputVars)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if version != 0 && resolved.Version != version {
+		fmt.Fprintf(os.Stderr, "Error: formula %s is version %d, not %d\n", resolved.Formula, resolved.Version, version)
+		os.Exit(1)
+	}
+
+	mol, err := loadTemplateSubgraph(ctx, store, moleculeID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading molecule: %v\n", err)
+		os.Exit(1)
+	}
+	plan := planMigration(mol, cooked, vars)
+	plan.Formula = resolved.Formula
+	plan.Version = resolved.Version
+	plan.DryRun = dryRun
+
+	if !jsonOutput {
+		printMigratePlan(plan)
+	}
+	if dryRun || !plan.changes() {
+		if jsonOutput {
+			outputJSON(plan)
+		}
+		return
+	}
+
+	if !force && !jsonOutput {
+		fmt.Printf("\nApply this migration to %s? [y/N] ", moleculeID)
+		var response string
+		_, _ = fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			fmt.Println("Canceled.")
+			return
+		}
+	}
+
+	if err := applyMigration(ctx, store, plan, actor); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if jsonOutput {
+		outputJSON(plan)
+		return
+	}
+	c := plan.counts()
+	fmt.Printf("\n%s Migrated %s to %s v%d: %d added, %d updated, %d retired, %d dependency change(s)\n",
+		ui.RenderPass("✓"), moleculeID, plan.Formula, plan.Version, c[migrateAdd], c[migrateUpdate], c[migrateRetire], len(plan.Deps))
+}
+
+// runtimeStepPattern matches step IDs of issues bd mol advance creates: loop
+// iterations after the first and on_complete fan-out molecules.
+var runtimeStepPattern = regexp.MustCompile(`(^|\.)(iter([2-9]|\d{2,})|item-\d+)(\.|$)`)
+
+// planMigration diffs a molecule against a freshly cooked formula subgraph.
+func planMigration(mol, cooked *TemplateSubgraph, vars map[string]string) *migratePlan {
+	plan := &migratePlan{MoleculeID: mol.Root.ID, cooked: cooked, vars: vars, ephemeral: mol.Root.Ephemeral}
+
+	cookedParent := parentsOf(cooked)
+	molParent := parentsOf(mol)
+	molStepIDs := moleculeStepIDs(mol)
+
+	// Cooked steps by step ID, named {parent}.{step-id} by cook
+	cookedSteps := make(map[string]*types.Issue)
+	cookedStepIDs := make(map[string]string)
+	for _, issue := range cooked.Issues {
+		if issue.ID == cooked.Root.ID || issue.IssueType == "gate" {
+			continue
+		}
+		if stepID, ok := strings.CutPrefix(issue.ID, cookedParent[issue.ID]+"."); ok {
+			cookedSteps[stepID] = issue
+			cookedStepIDs[issue.ID] = stepID
+		}
+	}
+
+	toMol := map[string]string{cooked.Root.ID: mol.Root.ID} // cooked ID -> molecule ID
+	fromMol := map[string]string{mol.Root.ID: cooked.Root.ID}
+	match := func(cookedID, molID string) {
+		toMol[cookedID] = molID
+		fromMol[molID] = cookedID
+	}
+	byTitle := make(map[string]bool)
+
+	// Steps by step:<id> label, then by title under the same parent
+	for _, issue := range mol.Issues {
+		if c := cookedSteps[molStepIDs[issue.ID]]; c != nil && toMol[c.ID] == "" {
+			match(c.ID, issue.ID)
+		}
+	}
+	for _, issue := range mol.Issues {
+		if fromMol[issue.ID] != "" || molStepIDs[issue.ID] != "" || issue.IssueType == "gate" {
+			continue
+		}
+		for _, c := range cooked.Issues {
+			if cookedStepIDs[c.ID] == "" || toMol[c.ID] != "" || substituteVariables(c.Title, vars) != issue.Title {
+				continue
+			}
+			if toMol[cookedParent[c.ID]] == molParent[issue.ID] {
+				match(c.ID, issue.ID)
+				byTitle[issue.ID] = true
+				break
+			}
+		}
+	}
+
+	// Gates through the step they guard
+	cookedGuards := guardsOf(cooked)
+	for _, gate := range mol.Issues {
+		if gate.IssueType != "gate" {
+			continue
+		}
+		for _, guarded := range guardsOf(mol)[gate.ID] {
+			cookedStep := fromMol[guarded]
+			if cookedStep == "" {
+				continue
+			}
+			for _, c := range cooked.Issues {
+				if c.IssueType == "gate" && toMol[c.ID] == "" && c.AwaitType == gate.AwaitType && containsString(cookedGuards[c.ID], cookedStep) {
+					match(c.ID, gate.ID)
+					break
+				}
+			}
+			if fromMol[gate.ID] != "" {
+				break
+			}
+		}
+	}
+
+	// Molecule steps: matched, runtime-created, or gone from the formula
+	runtime := make(map[string]bool)
+	for _, issue := range mol.Issues {
+		if issue.ID == mol.Root.ID {
+			continue
+		}
+		stepID := molStepIDs[issue.ID]
+		if c := fromMol[issue.ID]; stepID == "" && c != "" {
+			stepID = strings.TrimPrefix(c, cookedParent[c]+".")
+		}
+		step := &migrateStep{StepID: stepID, IssueID: issue.ID, Title: issue.Title, cookedID: fromMol[issue.ID]}
+
+		// Runtime issues and everything under them
+		for id := issue.ID; id != "" && id != mol.Root.ID; id = molParent[id] {
+			if runtime[id] || (fromMol[id] == "" && runtimeStepPattern.MatchString(molStepIDs[id])) {
+				runtime[issue.ID] = true
+				break
+			}
+		}
+
+		switch {
+		case step.cookedID != "":
+			c := cooked.IssueMap[step.cookedID]
+			step.Action = migrateKeep
+			step.label = byTitle[issue.ID] && cookedStepIDs[c.ID] != ""
+			if issue.Status != types.StatusClosed {
+				updates := make(map[string]interface{})
+				if title := substituteVariables(c.Title, vars); title != issue.Title {
+					updates["title"] = title
+					step.Reason = fmt.Sprintf("title was %q", issue.Title)
+					step.Title = title
+				}
+				if desc := substituteVariables(c.Description, vars); desc != issue.Description {
+					updates["description"] = desc
+					if step.Reason == "" {
+						step.Reason = "description changed"
+					}
+				}
+				if len(updates) > 0 {
+					step.Action = migrateUpdate
+					step.updates = updates
+				}
+			}
+		case runtime[issue.ID]:
+			step.Action = migrateRuntime
+			step.Reason = "created at runtime"
+		case issue.Status == types.StatusClosed:
+			step.Action = migrateHistory
+			step.Reason = "no longer in the formula; closed history kept"
+		default:
+			step.Action = migrateRetire
+			step.Reason = "no longer in the formula"
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	// Cooked steps not in the molecule are added
+	for _, c := range cooked.Issues {
+		if c.ID == cooked.Root.ID || toMol[c.ID] != "" {
+			continue
+		}
+		stepID := cookedStepIDs[c.ID]
+		if stepID == "" {
+			stepID = strings.TrimPrefix(c.ID, cookedParent[c.ID]+".")
+		}
+		plan.Steps = append(plan.Steps, &migrateStep{
+			Action:   migrateAdd,
+			StepID:   stepID,
+			Title:    substituteVariables(c.Title, vars),
+			cookedID: c.ID,
+		})
+	}
+
+	// Rewire dependencies between matched and added steps. Added steps are
+	// referred to by their cooked ID until they exist.
+	molID := func(cookedID string) string {
+		if id := toMol[cookedID]; id != "" {
+			return id
+		}
+		return cookedID
+	}
+	closed := make(map[string]bool)
+	for _, issue := range mol.Issues {
+		closed[issue.ID] = issue.Status == types.StatusClosed
+	}
+	type depKey struct {
+		from, to string
+		typ      types.DependencyType
+	}
+	want := make(map[depKey]bool)
+	var adds []*migrateDep
+	for _, dep := range cooked.Dependencies {
+		key := depKey{molID(dep.IssueID), molID(dep.DependsOnID), dep.Type}
+		want[key] = true
+		if !closed[key.from] {
+			adds = append(adds, &migrateDep{Action: "add", IssueID: key.from, DependsOnID: key.to, Type: dep.Type})
+		}
+	}
+	have := make(map[depKey]bool)
+	for _, dep := range mol.Dependencies {
+		if fromMol[dep.IssueID] == "" || runtime[dep.DependsOnID] {
+			continue // Retired, runtime and history steps keep their dependencies
+		}
+		if fromMol[dep.DependsOnID] == "" && mol.IssueMap[dep.DependsOnID] == nil {
+			continue // Outside the molecule
+		}
+		key := depKey{dep.IssueID, dep.DependsOnID, dep.Type}
+		have[key] = true
+		if !want[key] && !closed[dep.IssueID] {
+			plan.Deps = append(plan.Deps, &migrateDep{Action: "remove", IssueID: dep.IssueID, DependsOnID: dep.DependsOnID, Type: dep.Type})
+		}
+	}
+	for _, dep := range adds {
+		if !have[depKey{dep.IssueID, dep.DependsOnID, dep.Type}] {
+			plan.Deps = append(plan.Deps, dep)
+		}
+	}
+	return plan
+}
+
+// parentsOf maps each issue to its parent (parent-child dependencies).
+func parentsOf(subgraph *TemplateSubgraph) map[string]string {
+	parents := make(map[string]string)
+	for _, dep := range subgraph.Dependencies {
+		if dep.Type == types.DepParentChild {
+			parents[dep.IssueID] = dep.DependsOnID
+		}
+	}
+	return parents
+}
+
+// guardsOf maps each gate to the steps it blocks.
+func guardsOf(subgraph *TemplateSubgraph) map[string][]string {
+	guards := make(map[string][]string)
+	for _, dep := range subgraph.Dependencies {
+		if dep.Type == types.DepBlocks {
+			if blocker := subgraph.IssueMap[dep.DependsOnID]; blocker != nil && blocker.IssueType == "gate" {
+				guards[dep.DependsOnID] = append(guards[dep.DependsOnID], dep.IssueID)
+			}
+		}
+	}
+	return guards
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// applyMigration carries out a migration plan in one transaction.
+func applyMigration(ctx context.Context, s *dolt.DoltStore, plan *migratePlan, actorName string) error {
+	prefix := types.IDPrefixMol
+	if plan.ephemeral {
+		prefix = types.IDPrefixWisp
+	}
+	labels := formulaRuntimeLabels(plan.cooked, true)
+	reason := fmt.Sprintf("Retired: no longer in formula %s v%d", plan.Formula, plan.Version)
+
+	return s.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		created := make(map[string]string) // cooked ID -> new issue ID
+		for _, step := range plan.Steps {
+			switch step.Action {
+			case migrateAdd:
+				c := plan.cooked.IssueMap[step.cookedID]
+				issue := &types.Issue{
+					Title:              substituteVariables(c.Title, plan.vars),
+					Description:        substituteVariables(c.Description, plan.vars),
+					Design:             substituteVariables(c.Design, plan.vars),
+					AcceptanceCriteria: substituteVariables(c.AcceptanceCriteria, plan.vars),
+					Notes:              substituteVariables(c.Notes, plan.vars),
+					Status:             types.StatusOpen,
+					Priority:           c.Priority,
+					IssueType:          c.IssueType,
+					Assignee:           c.Assignee,
+					EstimatedMinutes:   c.EstimatedMinutes,
+					Ephemeral:          plan.ephemeral,
+					IDPrefix:           prefix,
+					AwaitType:          c.AwaitType,
+					AwaitID:            substituteVariables(c.AwaitID, plan.vars),
+					Timeout:            c.Timeout,
+					Metadata:           c.Metadata,
+					CreatedAt:          time.Now(),
+					UpdatedAt:          time.Now(),
+				}
+				if err := tx.CreateIssue(ctx, issue, actorName); err != nil {
+					return fmt.Errorf("failed to create step %s: %w", step.StepID, err)
+				}
+				for _, label := range labels[c.ID] {
+					if err := tx.AddLabel(ctx, issue.ID, label, actorName); err != nil {
+						return fmt.Errorf("failed to add label to %s: %w", issue.ID, err)
+					}
+				}
+				created[c.ID] = issue.ID
+				step.IssueID = issue.ID
+			case migrateUpdate:
+				if err := tx.UpdateIssue(ctx, step.IssueID, step.updates, actorName); err != nil {
+					return fmt.Errorf("failed to update %s: %w", step.IssueID, err)
+				}
+			case migrateRetire:
+				if err := tx.CloseIssue(ctx, step.IssueID, reason, actorName, ""); err != nil {
+					return fmt.Errorf("failed to retire %s: %w", step.IssueID, err)
+				}
+			}
+			if step.label {
+				if err := tx.AddLabel(ctx, step.IssueID, stepLabelPrefix+step.StepID, actorName); err != nil {
+					return fmt.Errorf("failed to add label to %s: %w", step.IssueID, err)
+				}
+			}
+		}
+
+		for _, dep := range plan.Deps {
+			if id := created[dep.IssueID]; id != "" {
+				dep.IssueID = id
+			}
+			if id := created[dep.DependsOnID]; id != "" {
+				dep.DependsOnID = id
+			}
+			if dep.Action == "remove" {
+				if err := tx.RemoveDependency(ctx, dep.IssueID, dep.DependsOnID, actorName); err != nil {
+					return fmt.Errorf("failed to remove dependency %s -> %s: %w", dep.IssueID, dep.DependsOnID, err)
+				}
+				continue
+			}
+			if err := tx.AddDependency(ctx, &types.Dependency{IssueID: dep.IssueID, DependsOnID: dep.DependsOnID, Type: dep.Type}, actorName); err != nil {
+				return fmt.Errorf("failed to add dependency %s -> %s: %w", dep.IssueID, dep.DependsOnID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// printMigratePlan prints a migration plan grouped by action.
+func printMigratePlan(plan *migratePlan) {
+	fmt.Printf("\n%s Migration plan: %s → %s v%d\n", ui.RenderAccent("📋"), plan.MoleculeID, plan.Formula, plan.Version)
+
+	icons := map[string]string{
+		migrateAdd:     ui.RenderPass("+"),
+		migrateUpdate:  ui.RenderAccent("~"),
+		migrateRetire:  ui.RenderFail("-"),
+		migrateKeep:    ui.RenderMuted("="),
+		migrateHistory: ui.RenderMuted("✓"),
+		migrateRuntime: ui.RenderMuted("↻"),
+	}
+	steps := append([]*migrateStep(nil), plan.Steps...)
+	order := map[string]int{migrateAdd: 0, migrateUpdate: 1, migrateRetire: 2, migrateKeep: 3, migrateHistory: 4, migrateRuntime: 5}
+	sort.SliceStable(steps, func(i, j int) bool { return order[steps[i].Action] < order[steps[j].Action] })
+
+	fmt.Println()
+	for _, step := range steps {
+		id := step.IssueID
+		if id == "" {
+			id = "(new)"
+		}
+		line := fmt.Sprintf("  %s %-8s %-24s %s", icons[step.Action], step.Action, step.StepID, step.Title)
+		if step.Action != migrateAdd {
+			line += " " + ui.RenderMuted(id)
+		}
+		if step.Reason != "" && step.Action != migrateKeep {
+			line += ui.RenderMuted(" - " + step.Reason)
+		}
+		fmt.Println(line)
+	}
+
+	if len(plan.Deps) > 0 {
+		fmt.Printf("\n  Dependencies:\n")
+		for _, dep := range plan.Deps {
+			sign := ui.RenderPass("+")
+			if dep.Action == "remove" {
+				sign = ui.RenderFail("-")
+			}
+			fmt.Printf("  %s %s depends on %s (%s)\n", sign, migrateDepLabel(plan, dep.IssueID), migrateDepLabel(plan, dep.DependsOnID), dep.Type)
+		}
+	}
+
+	c := plan.counts()
+	fmt.Printf("\n  %d to add, %d to update, %d to retire, %d kept, %d closed kept as history, %d runtime\n",
+		c[migrateAdd], c[migrateUpdate], c[migrateRetire], c[migrateKeep], c[migrateHistory], c[migrateRuntime])
+	if !plan.changes() {
+		fmt.Printf("\nMolecule %s already matches %s v%d.\n", plan.MoleculeID, plan.Formula, plan.Version)
+	} else if plan.DryRun {
+		fmt.Printf("\nDry run: no changes made.\n")
+	}
+}
+
+// migrateDepLabel names a dependency endpoint by step ID where known.
+func migrateDepLabel(plan *migratePlan, id string) string {
+	if id == plan.MoleculeID {
+		return "(root)"
+	}
+	for _, step := range plan.Steps {
+		if (step.IssueID == id || step.cookedID == id) && step.StepID != "" {
+			return step.StepID
+		}
+	}
+	return id
+}
+
+func init() {
+	molMigrateCmd.Flags().String("to", "", "Formula to migrate to, optionally with @version (required)")
+	molMigrateCmd.Flags().StringArray("var", []string{}, "Variable substitution (key=value), as when pouring")
+	molMigrateCmd.Flags().Bool("dry-run", false, "Show the migration plan without applying it")
+	molMigrateCmd.Flags().Bool("force", false, "Apply without asking for confirmation")
+
+	molCmd.AddCommand(molMigrateCmd)
+}
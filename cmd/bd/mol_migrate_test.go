@@ -0,0 +1,135 @@
+//go:build cgo
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func cookMigrateTestFormula(t *testing.T, version int, steps []*formula.Step) *TemplateSubgraph {
+	t.Helper()
+	f := &formula.Formula{Formula: "mol-migrate", Version: version, Type: formula.TypeWorkflow, Steps: steps}
+	formula.SetSourceInfo(f)
+	subgraph, err := cookFormulaToSubgraph(f, "mol-migrate")
+	if err != nil {
+		t.Fatalf("cookFormulaToSubgraph: %v", err)
+	}
+	return subgraph
+}
+
+func TestMolMigrate(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+
+	v1 := cookMigrateTestFormula(t, 1, []*formula.Step{
+		{ID: "build", Title: "Build"},
+		{ID: "lint", Title: "Lint"},
+		{ID: "smoke", Title: "Smoke test", Needs: []string{"build"}},
+		{ID: "deploy", Title: "Deploy", Needs: []string{"smoke", "lint"}},
+	})
+	result, err := spawnMolecule(ctx, s, v1, nil, "", "test", false, types.IDPrefixMol)
+	if err != nil {
+		t.Fatalf("spawnMolecule: %v", err)
+	}
+	issues := make(map[string]string)
+	for oldID, newID := range result.IDMapping {
+		if stepID, ok := strings.CutPrefix(oldID, "mol-migrate."); ok {
+			issues[stepID] = newID
+		}
+	}
+	for _, step := range []string{"build", "lint"} {
+		if err := s.CloseIssue(ctx, issues[step], "done", "test", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// v2 drops lint (closed) and smoke (open), renames deploy and adds verify
+	// between build and deploy.
+	v2 := cookMigrateTestFormula(t, 2, []*formula.Step{
+		{ID: "build", Title: "Build"},
+		{ID: "verify", Title: "Verify", Needs: []string{"build"}},
+		{ID: "deploy", Title: "Deploy to prod", Needs: []string{"verify"}},
+	})
+	mol, err := loadTemplateSubgraph(ctx, s, result.NewEpicID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := planMigration(mol, v2, nil)
+	plan.Formula, plan.Version = "mol-migrate", 2
+
+	actions := make(map[string]string)
+	for _, step := range plan.Steps {
+		actions[step.StepID] = step.Action
+	}
+	want := map[string]string{
+		"build":  migrateKeep,
+		"lint":   migrateHistory,
+		"smoke":  migrateRetire,
+		"deploy": migrateUpdate,
+		"verify": migrateAdd,
+	}
+	for step, action := range want {
+		if actions[step] != action {
+			t.Errorf("step %s: action = %q, want %q (plan: %v)", step, actions[step], action, actions)
+		}
+	}
+
+	if err := applyMigration(ctx, s, plan, "test"); err != nil {
+		t.Fatalf("applyMigration: %v", err)
+	}
+
+	smoke, _ := s.GetIssue(ctx, issues["smoke"])
+	if smoke.Status != types.StatusClosed {
+		t.Errorf("smoke status = %s, want closed", smoke.Status)
+	}
+	deploy, _ := s.GetIssue(ctx, issues["deploy"])
+	if deploy.Title != "Deploy to prod" {
+		t.Errorf("deploy title = %q", deploy.Title)
+	}
+
+	var verifyID string
+	for _, step := range plan.Steps {
+		if step.StepID == "verify" {
+			verifyID = step.IssueID
+		}
+	}
+	if verifyID == "" {
+		t.Fatal("verify step not created")
+	}
+	labels, _ := s.GetLabels(ctx, verifyID)
+	if !containsString(labels, "step:verify") {
+		t.Errorf("verify labels = %v, want step:verify", labels)
+	}
+
+	deps, err := s.GetDependencyRecords(ctx, issues["deploy"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockers := make(map[string]bool)
+	for _, dep := range deps {
+		if dep.Type == types.DepBlocks {
+			blockers[dep.DependsOnID] = true
+		}
+	}
+	if !blockers[verifyID] || blockers[issues["smoke"]] || blockers[issues["lint"]] || len(blockers) != 1 {
+		t.Errorf("deploy blockers = %v, want only verify %s", blockers, verifyID)
+	}
+
+	// Migrating again is a no-op
+	mol, err = loadTemplateSubgraph(ctx, s, result.NewEpicID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again := planMigration(mol, v2, nil)
+	if again.changes() {
+		for _, step := range again.Steps {
+			t.Logf("%s %s %s", step.Action, step.StepID, step.Reason)
+		}
+		t.Errorf("second migration not a no-op: deps %v", again.Deps)
+	}
+}
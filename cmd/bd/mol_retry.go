@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/storage"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/utils"
+)
+
+// attemptsMetadataKey is the metadata field counting how many times a
+// molecule step has run. Absent means once.
+const attemptsMetadataKey = "attempts"
+
+var molRetryCmd = &cobra.Command{
+	Use:   "retry <step-id>",
+	Short: "Rerun a molecule step and everything downstream of it",
+	Long: `Reopen a step that ran (usually one that failed) together with every step of
+its molecule that depends on it, directly or transitively, and already ran.
+
+Reset steps go back to open and their attempt count is recorded in metadata
+("attempts", usable in conditions as <step>.output.attempts). Also reset:
+
+  - children of reset steps that ran (e.g. fanned-out molecules)
+  - closed parents of reset steps, up to the molecule root
+  - condition gates in front of reset dependents, so bd mol advance
+    re-evaluates them against the new run
+  - failure branches: molecules bonded with conditional-blocks on the
+    molecule or a reset step that already ran, since the failure that
+    triggered them is being retried
+
+A formula step can cap its retries with max_retries; retrying past the limit
+needs --force.
+
+Examples:
+  bd mol retry bd-mol-abc.deploy
+  bd mol retry bd-mol-xyz --reason "flaky network" --dry-run
+  bd mol retry bd-mol-xyz --force --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := rootCtx
+		if store == nil {
+			fmt.Fprintf(os.Stderr, "Error: no database connection\n")
+			os.Exit(1)
+		}
+		stepID, err := utils.ResolvePartialID(ctx, store, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: step '%s' not found\n", args[0])
+			os.Exit(1)
+		}
+		moleculeID := findParentMolecule(ctx, store, stepID)
+		if moleculeID == "" || moleculeID == stepID {
+			fmt.Fprintf(os.Stderr, "Error: %s is not a step of a molecule\n", stepID)
+			os.Exit(1)
+		}
+		runMolRewind(cmd, moleculeID, stepID, true)
+	},
+}
+
+var molRewindCmd = &cobra.Command{
+	Use:   "rewind <molecule-id> --to <step>",
+	Short: "Rewind a molecule to an earlier step",
+	Long: `Rewind a molecule so it runs again from the given step: the step and every
+step that depends on it and already ran are reopened, as with bd mol retry.
+
+The step is a formula step ID (e.g. build, review.iter1.check) or an issue ID.
+Unlike retry, the step itself need not have run.
+
+Examples:
+  bd mol rewind bd-mol-abc --to build
+  bd mol rewind bd-mol-abc --to build --dry-run --json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := rootCtx
+		if store == nil {
+			fmt.Fprintf(os.Stderr, "Error: no database connection\n")
+			os.Exit(1)
+		}
+		to, _ := cmd.Flags().GetString("to")
+		if to == "" {
+			fmt.Fprintf(os.Stderr, "Error: --to <step> is required\n")
+			os.Exit(1)
+		}
+		moleculeID, err := utils.ResolvePartialID(ctx, store, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: molecule '%s' not found\n", args[0])
+			os.Exit(1)
+		}
+		stepID, err := resolveMoleculeStep(ctx, store, moleculeID, to)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		runMolRewind(cmd, moleculeID, stepID, false)
+	},
+}
+
+// Reasons a step is reset by retry or rewind.
+const (
+	rewindTarget    = "target"    // The retried step
+	rewindDependent = "dependent" // Depends on a reset step, or is a child of one
+	rewindParent    = "parent"    // Closed parent of a reset step
+	rewindGate      = "gate"      // Condition gate in front of a reset dependent
+	rewindBranch    = "branch"    // Failure branch bonded with conditional-blocks
+)
+
+// rewindStep is an issue reset by retry or rewind.
+type rewindStep struct {
+	ID      string       `json:"id"`
+	StepID  string       `json:"step_id,omitempty"`
+	Title   string       `json:"title"`
+	Status  types.Status `json:"status"` // Status before the reset
+	Reason  string       `json:"reason"`
+	Attempt int          `json:"attempt,omitempty"` // Run about to start (steps only)
+}
+
+// rewindResult is the outcome of bd mol retry or rewind.
+type rewindResult struct {
+	MoleculeID string        `json:"molecule_id"`
+	StepID     string        `json:"step_id"`
+	Attempt    int           `json:"attempt"`
+	MaxRetries int           `json:"max_retries,omitempty"`
+	DryRun     bool          `json:"dry_run"`
+	Reset      []*rewindStep `json:"reset"`
+}
+
+func runMolRewind(cmd *cobra.Command, moleculeID, stepID string, retry bool) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	force, _ := cmd.Flags().GetBool("force")
+	reason, _ := cmd.Flags().GetString("reason")
+	if !dryRun {
+		CheckReadonly("mol " + cmd.Name())
+	}
+
+	ctx := rootCtx
+	result, err := rewindMolecule(ctx, store, moleculeID, stepID, retry, force, dryRun, reason, actor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		outputJSON(result)
+		return
+	}
+	verb := "Reset"
+	if dryRun {
+		verb = "Would reset"
+	}
+	limit := ""
+	if result.MaxRetries > 0 {
+		limit = fmt.Sprintf(" (max_retries %d)", result.MaxRetries)
+	}
+	fmt.Printf("%s %s %s for attempt %d%s\n", ui.RenderAccent("↻"), verb, result.StepID, result.Attempt, limit)
+	for _, step := range result.Reset {
+		name := step.Title
+		if step.StepID != "" {
+			name = step.StepID + ": " + step.Title
+		}
+		fmt.Printf("  %-10s %s %s %s\n", step.Reason, step.ID, name, ui.RenderMuted("(was "+string(step.Status)+")"))
+	}
+	if dryRun {
+		fmt.Printf("\nDry run: %d issue(s) would be reopened\n", len(result.Reset))
+		return
+	}
+	fmt.Printf("\n%d issue(s) reopened. Run bd mol advance %s to re-evaluate condition gates.\n", len(result.Reset), moleculeID)
+}
+
+// resolveMoleculeStep resolves a formula step ID or issue ID to a step issue
+// of the molecule.
+func resolveMoleculeStep(ctx context.Context, s *dolt.DoltStore, moleculeID, ref string) (string, error) {
+	subgraph, err := loadTemplateSubgraph(ctx, s, moleculeID)
+	if err != nil {
+		return "", err
+	}
+	for issueID, stepID := range moleculeStepIDs(subgraph) {
+		if stepID == ref {
+			return issueID, nil
+		}
+	}
+	if id, err := utils.ResolvePartialID(ctx, s, ref); err == nil && subgraph.IssueMap[id] != nil {
+		return id, nil
+	}
+	return "", fmt.Errorf("step %q not found in molecule %s", ref, moleculeID)
+}
+
+// stepHasRun reports whether a step was started or finished.
+func stepHasRun(issue *types.Issue) bool {
+	switch issue.Status {
+	case types.StatusClosed, types.StatusInProgress, types.StatusHooked:
+		return true
+	}
+	return false
+}
+
+// stepAttempts returns how many times a step has run, from its metadata.
+func stepAttempts(issue *types.Issue) int {
+	if n, ok := issueOutput(issue)[attemptsMetadataKey].(float64); ok && n >= 1 {
+		return int(n)
+	}
+	return 1
+}
+
+// stepMaxRetries returns the retry limit recorded on a step by cook.
+func stepMaxRetries(issue *types.Issue) int {
+	for _, label := range issue.Labels {
+		if n, ok := formula.ParseMaxRetriesLabel(label); ok {
+			return n
+		}
+	}
+	return 0
+}
+
+// rewindMolecule reopens the step and every issue of the molecule that must
+// run again after it (see bd mol retry). With retry set, the step must have
+// run. Unless dryRun is set, all issues are reset in one transaction.
+func rewindMolecule(ctx context.Context, s *dolt.DoltStore, moleculeID, stepID string, retry, force, dryRun bool, reason, actorName string) (*rewindResult, error) {
+	subgraph, err := loadTemplateSubgraph(ctx, s, moleculeID)
+	if err != nil {
+		return nil, err
+	}
+	target := subgraph.IssueMap[stepID]
+	if target == nil {
+		return nil, fmt.Errorf("%s is not a step of molecule %s", stepID, moleculeID)
+	}
+	if target.ID == subgraph.Root.ID {
+		return nil, fmt.Errorf("%s is the molecule root; retry or rewind to one of its steps", stepID)
+	}
+	if retry && !stepHasRun(target) {
+		return nil, fmt.Errorf("%s has not run yet (status %s); nothing to retry", stepID, target.Status)
+	}
+
+	result := &rewindResult{MoleculeID: moleculeID, StepID: stepID, DryRun: dryRun, Attempt: stepAttempts(target)}
+	if stepHasRun(target) {
+		result.Attempt++
+	}
+	result.MaxRetries = stepMaxRetries(target)
+	if result.MaxRetries > 0 && result.Attempt-1 > result.MaxRetries && !force {
+		return nil, fmt.Errorf("%s has been retried %d time(s), its max_retries; use --force to retry anyway", stepID, result.MaxRetries)
+	}
+
+	stepIDs := moleculeStepIDs(subgraph)
+	parents := parentsOf(subgraph)
+	reset := make(map[string]bool)
+	add := func(issue *types.Issue, why string, attempt int) {
+		if reset[issue.ID] {
+			return
+		}
+		reset[issue.ID] = true
+		result.Reset = append(result.Reset, &rewindStep{
+			ID: issue.ID, StepID: stepIDs[issue.ID], Title: issue.Title, Status: issue.Status, Reason: why, Attempt: attempt,
+		})
+	}
+	if stepHasRun(target) {
+		add(target, rewindTarget, result.Attempt)
+	}
+
+	// Everything downstream of the step, in dependency order
+	downstream := []string{target.ID}
+	seen := map[string]bool{target.ID: true}
+	for i := 0; i < len(downstream); i++ {
+		for _, dep := range subgraph.Dependencies {
+			if dep.DependsOnID != downstream[i] || !dep.Type.AffectsReadyWork() || seen[dep.IssueID] || dep.IssueID == subgraph.Root.ID {
+				continue
+			}
+			seen[dep.IssueID] = true
+			downstream = append(downstream, dep.IssueID)
+		}
+	}
+	for _, id := range downstream[1:] {
+		if issue := subgraph.IssueMap[id]; stepHasRun(issue) && issue.IssueType != "gate" {
+			add(issue, rewindDependent, stepAttempts(issue)+1)
+		}
+	}
+
+	// Condition gates in front of dependents see the new run
+	for _, dep := range subgraph.Dependencies {
+		gate := subgraph.IssueMap[dep.DependsOnID]
+		if dep.Type != types.DepBlocks || dep.IssueID == target.ID || !seen[dep.IssueID] || gate == nil {
+			continue
+		}
+		if gate.AwaitType == conditionGateType && gate.Status == types.StatusClosed {
+			add(gate, rewindGate, 0)
+		}
+	}
+
+	// Closed parents reopen with their children
+	for _, step := range append([]*rewindStep(nil), result.Reset...) {
+		for id := parents[step.ID]; id != ""; id = parents[id] {
+			if parent := subgraph.IssueMap[id]; parent != nil && parent.Status == types.StatusClosed {
+				add(parent, rewindParent, 0)
+			}
+		}
+	}
+
+	// Failure branches bonded on anything being rerun
+	for _, step := range append([]*rewindStep(nil), result.Reset...) {
+		dependents, err := s.GetDependentsWithMetadata(ctx, step.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dependents of %s: %w", step.ID, err)
+		}
+		for _, dependent := range dependents {
+			if dependent.DependencyType != types.DepConditionalBlocks || subgraph.IssueMap[dependent.ID] != nil || reset[dependent.ID] {
+				continue
+			}
+			branch, err := loadTemplateSubgraph(ctx, s, dependent.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, issue := range branch.Issues {
+				if stepHasRun(issue) {
+					add(issue, rewindBranch, stepAttempts(issue)+1)
+				}
+			}
+		}
+	}
+
+	if dryRun || len(result.Reset) == 0 {
+		return result, nil
+	}
+
+	err = s.RunInTransaction(ctx, func(tx storage.Transaction) error {
+		for _, step := range result.Reset {
+			updates := map[string]interface{}{
+				"status":       string(types.StatusOpen),
+				"closed_at":    nil,
+				"close_reason": "",
+			}
+			if step.Attempt > 0 {
+				issue, err := tx.GetIssue(ctx, step.ID)
+				if err != nil {
+					return fmt.Errorf("failed to get %s: %w", step.ID, err)
+				}
+				metadata := make(map[string]interface{})
+				if len(issue.Metadata) > 0 {
+					if err := json.Unmarshal(issue.Metadata, &metadata); err != nil {
+						return fmt.Errorf("%s: metadata is not a JSON object; cannot record attempts", step.ID)
+					}
+				}
+				metadata[attemptsMetadataKey] = step.Attempt
+				data, _ := json.Marshal(metadata)
+				updates["metadata"] = string(data)
+			}
+			if err := tx.UpdateIssue(ctx, step.ID, updates, actorName); err != nil {
+				return fmt.Errorf("failed to reopen %s: %w", step.ID, err)
+			}
+		}
+		if reason != "" {
+			comment := fmt.Sprintf("Attempt %d: %s", result.Attempt, reason)
+			if err := tx.AddComment(ctx, stepID, actorName, comment); err != nil {
+				return fmt.Errorf("failed to add comment to %s: %w", stepID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{molRetryCmd, molRewindCmd} {
+		cmd.Flags().Bool("dry-run", false, "Show what would be reset without changing anything")
+		cmd.Flags().Bool("force", false, "Retry even past the step's max_retries")
+		cmd.Flags().StringP("reason", "r", "", "Reason, added as a comment on the step")
+		molCmd.AddCommand(cmd)
+	}
+	molRewindCmd.Flags().String("to", "", "Step to rewind to: formula step ID or issue ID (required)")
+}
@@ -0,0 +1,131 @@
+//go:build cgo
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/formula"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestMolRetry(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t, t.TempDir()+"/test.db")
+
+	f := &formula.Formula{
+		Formula: "mol-retry",
+		Version: 1,
+		Type:    formula.TypeWorkflow,
+		Steps: []*formula.Step{
+			{ID: "build", Title: "Build"},
+			{ID: "test", Title: "Test", Needs: []string{"build"}, MaxRetries: 1},
+			{ID: "deploy", Title: "Deploy", Needs: []string{"test"}},
+		},
+		Compose: &formula.ComposeRules{
+			Gate: []*formula.GateRule{{Before: "deploy", Condition: "test.status == 'complete'"}},
+		},
+	}
+	steps, err := formula.ApplyControlFlow(f.Steps, f.Compose)
+	if err != nil {
+		t.Fatalf("ApplyControlFlow: %v", err)
+	}
+	f.Steps = steps
+	subgraph, err := cookFormulaToSubgraph(f, "mol-retry")
+	if err != nil {
+		t.Fatalf("cookFormulaToSubgraph: %v", err)
+	}
+	result, err := spawnMolecule(ctx, s, subgraph, nil, "", "test", false, types.IDPrefixMol)
+	if err != nil {
+		t.Fatalf("spawnMolecule: %v", err)
+	}
+	molID := result.NewEpicID
+	issues := make(map[string]string)
+	for oldID, newID := range result.IDMapping {
+		if stepID, ok := strings.CutPrefix(oldID, "mol-retry."); ok {
+			issues[stepID] = newID
+		}
+	}
+
+	// A failure branch that ran after test failed
+	branch := &types.Issue{Title: "Page on-call", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	if err := s.CreateIssue(ctx, branch, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddDependency(ctx, &types.Dependency{IssueID: branch.ID, DependsOnID: issues["test"], Type: types.DepConditionalBlocks}, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{issues["build"], issues["test"], issues["cond-deploy"], issues["deploy"], branch.ID, molID} {
+		if err := s.CloseIssue(ctx, id, "done", "test", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := rewindMolecule(ctx, s, molID, issues["test"], true, false, false, "flaky", "test")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if res.Attempt != 2 || res.MaxRetries != 1 {
+		t.Errorf("attempt = %d, max_retries = %d; want 2, 1", res.Attempt, res.MaxRetries)
+	}
+	reasons := make(map[string]string)
+	for _, step := range res.Reset {
+		reasons[step.ID] = step.Reason
+	}
+	want := map[string]string{
+		issues["test"]:        rewindTarget,
+		issues["deploy"]:      rewindDependent,
+		issues["cond-deploy"]: rewindGate,
+		molID:                 rewindParent,
+		branch.ID:             rewindBranch,
+	}
+	for id, reason := range want {
+		if reasons[id] != reason {
+			t.Errorf("%s: reason = %q, want %q", id, reasons[id], reason)
+		}
+	}
+	if _, ok := reasons[issues["build"]]; ok {
+		t.Errorf("build was reset, but it runs before test")
+	}
+
+	for id := range want {
+		issue, err := s.GetIssue(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if issue.Status != types.StatusOpen {
+			t.Errorf("%s status = %s, want open", id, issue.Status)
+		}
+	}
+	build, _ := s.GetIssue(ctx, issues["build"])
+	if build.Status != types.StatusClosed {
+		t.Errorf("build status = %s, want closed", build.Status)
+	}
+	test, _ := s.GetIssue(ctx, issues["test"])
+	if got := stepAttempts(test); got != 2 {
+		t.Errorf("test attempts = %d, want 2", got)
+	}
+
+	// The second retry exceeds max_retries
+	if err := s.CloseIssue(ctx, issues["test"], "failed", "test", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rewindMolecule(ctx, s, molID, issues["test"], true, false, false, "", "test"); err == nil || !strings.Contains(err.Error(), "max_retries") {
+		t.Errorf("retry past max_retries: err = %v", err)
+	}
+	res, err = rewindMolecule(ctx, s, molID, issues["test"], true, true, false, "", "test")
+	if err != nil {
+		t.Fatalf("forced retry: %v", err)
+	}
+	if res.Attempt != 3 {
+		t.Errorf("forced retry attempt = %d, want 3", res.Attempt)
+	}
+
+	// Retry needs a step that ran
+	if _, err := rewindMolecule(ctx, s, molID, issues["deploy"], true, false, true, "", "test"); err == nil {
+		t.Error("retrying an open step should fail")
+	}
+}
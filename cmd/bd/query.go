@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/beads/internal/query"
+	"github.com/steveyegge/beads/internal/storage/dolt"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/ui"
 )
@@ -36,6 +38,22 @@ Boolean operators (case-insensitive):
   NOT expr          Negates the condition
   (expr)            Grouping with parentheses
 
+Ordering and limits (optional, after the filter expression):
+  ORDER BY field [ASC|DESC], ...   Sort results (overrides --sort)
+  LIMIT n                          Cap result count (overrides --limit)
+  Sortable fields: priority, created, updated, closed, due, defer, estimate,
+  status, id, title, type, assignee, owner
+
+Aggregation (optional, after the filter expression; the filter may be omitted):
+  GROUP BY dim, ..., agg(field), ...
+  Dimensions: status, priority, type, assignee, owner, label, mol_type,
+  wisp_type, source_system, or a quoted state dimension such as "patrol:*"
+  (issues with several matching labels count in each group)
+  Aggregates: count(), sum/avg/min/max(estimate|priority|age),
+  min/max(created|updated|closed|due|defer); count() is the default
+  ORDER BY and LIMIT then apply to groups, e.g. ORDER BY count DESC
+  (aggregate columns are named count, sum_estimate, avg_age, ...)
+
 Supported fields:
   status            Issue status (open, in_progress, blocked, deferred, closed)
   priority          Priority level (0-4)
@@ -56,9 +74,32 @@ Supported fields:
   template          Boolean (true/false)
   parent            Parent issue ID
   mol_type          Molecule type (swarm, patrol, work)
+  wisp_type         Wisp type (heartbeat, ping, patrol, gc_report, ...)
+  due               Due date/time ("none" for no due date)
+  defer             Defer-until date/time ("none" for not deferred)
+  estimate          Estimated minutes (also 2h, 1d, 1w; "none" for unset)
+  external_ref      External reference (supports wildcards: gh-*)
+  source_system     Federation source system ("none" for local)
+  comments          Number of comments
+  blocked           Boolean: has an open blocking dependency
+  ready             Boolean: claimable work, as listed by bd ready (open or in
+                    progress, unblocked, not deferred, pinned or ephemeral)
+  has_deps          Boolean: has at least one dependency that affects readiness
+                    (blocks, parent-child, conditional-blocks, waits-for)
+
+Boolean fields may be written bare: "ready" means "ready=true".
+
+Graph predicates (combine with AND/OR/NOT like any comparison):
+  blocked_by(ID)          Issues with a blocking dependency on ID
+  blocks(ID)              Issues that ID has a blocking dependency on
+  descendant_of(ID)       Children of ID, recursively (parent-child)
+  related_to(ID)          Issues linked to ID as related (either direction)
+  depends_on_type(TYPE)   Issues with a dependency of TYPE (e.g., discovered-from)
+  in_cycle()              Issues on a blocking dependency cycle
 
 Date values:
   Relative durations: 7d (7 days ago), 24h (24 hours ago), 2w (2 weeks ago)
+  Future durations (due/defer): +3d (3 days from now), +12h
   Absolute dates: 2025-01-15, 2025-01-15T10:00:00Z
   Natural language: tomorrow, "next monday", "in 3 days"
 
@@ -71,7 +112,16 @@ Examples:
   bd query "assignee=none AND type=task"
   bd query "created>30d AND status!=closed"
   bd query "label=frontend OR label=backend"
-  bd query "title=authentication AND priority=0"`,
+  bd query "title=authentication AND priority=0"
+  bd query "due<+3d AND status!=closed ORDER BY due"
+  bd query "ready AND estimate<=30"
+  bd query "descendant_of(bd-epic1) AND status=open AND NOT blocked"
+  bd query "blocked_by(bd-42) OR in_cycle()"
+  bd query "GROUP BY status" --all
+  bd query "type=bug GROUP BY assignee, count(), sum(estimate) ORDER BY count DESC"
+  bd query 'GROUP BY "patrol:*", avg(age), max(updated)'
+  bd query "priority=1 AND type=bug ORDER BY created ASC LIMIT 3"
+  bd query "status=open ORDER BY priority ASC, updated DESC LIMIT 10"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get query from args
 		if len(args) == 0 {
@@ -93,36 +143,17 @@ Examples:
 		parseOnly, _ := cmd.Flags().GetBool("parse-only")
 
 		// Parse the query
-		node, err := query.Parse(queryStr)
+		q, err := query.ParseQuery(queryStr)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing query: %v\n", err)
 			os.Exit(1)
 		}
-
 		// If --parse-only, just show the parsed AST
 		if parseOnly {
-			fmt.Printf("Parsed query: %s\n", node.String())
+			fmt.Printf("Parsed query: %s\n", q.String())
 			return
 		}
 
-		// Evaluate the query to get filter and/or predicate
-		eval := query.NewEvaluator(time.Now())
-		result, err := eval.Evaluate(node)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error evaluating query: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Apply limit if specified
-		if limit > 0 && !result.RequiresPredicate {
-			result.Filter.Limit = limit
-		}
-
-		// By default exclude closed issues unless --all is specified or query explicitly filters by status
-		if !allFlag && result.Filter.Status == nil && !hasExplicitStatusFilter(node) {
-			result.Filter.ExcludeStatus = append(result.Filter.ExcludeStatus, types.StatusClosed)
-		}
-
 		ctx := rootCtx
 
 		// Direct mode
@@ -131,54 +162,27 @@ Examples:
 			os.Exit(1)
 		}
 
-		// If we need predicate filtering, we may need to fetch more results
-		// to ensure we get enough after filtering
-		searchFilter := result.Filter
-		if result.RequiresPredicate && limit > 0 {
-			// Fetch more to account for predicate filtering
-			searchFilter.Limit = limit * 3
-			if searchFilter.Limit < 100 {
-				searchFilter.Limit = 100
+		if len(q.GroupBy) > 0 {
+			result, err := queryFilter(q, 0, allFlag)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
 			}
+			outputQueryGroups(ctx, store, result.Filter, q)
+			return
 		}
 
-		issues, err := store.SearchIssues(ctx, "", searchFilter)
+		issues, result, err := runQuery(ctx, store, q, limit, allFlag)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Apply predicate filter if needed (for OR queries)
-		if result.RequiresPredicate && result.Predicate != nil {
-			// For predicate filtering, we need labels populated on issues
-			if store != nil {
-				issueIDs := make([]string, len(issues))
-				for i, issue := range issues {
-					issueIDs[i] = issue.ID
-				}
-				labelsMap, _ := store.GetLabelsForIssues(ctx, issueIDs) // Best effort: display gracefully degrades with empty data
-				for _, issue := range issues {
-					issue.Labels = labelsMap[issue.ID]
-				}
-			}
-
-			filtered := make([]*types.Issue, 0, len(issues))
-			for _, issue := range issues {
-				if result.Predicate(issue) {
-					filtered = append(filtered, issue)
-				}
-			}
-			issues = filtered
-
-			// Apply limit after predicate filtering
-			if limit > 0 && len(issues) > limit {
-				issues = issues[:limit]
-			}
+		// Apply sorting (an ORDER BY clause in the query takes precedence)
+		if len(result.OrderBy) == 0 {
+			sortIssues(issues, sortBy, reverse)
 		}
 
-		// Apply sorting
-		sortIssues(issues, sortBy, reverse)
-
 		// Output results
 		if jsonOutput {
 			// Get labels and dependency counts
@@ -216,7 +220,7 @@ Examples:
 		}
 
 		// Load labels for display
-		if store != nil && !result.RequiresPredicate {
+		if store != nil {
 			issueIDs := make([]string, len(issues))
 			for i, issue := range issues {
 				issueIDs[i] = issue.ID
@@ -231,6 +235,101 @@ Examples:
 	},
 }
 
+// queryFilter evaluates a parsed query into the filter to search with. A
+// LIMIT clause takes precedence over limit; GROUP BY queries get no limit
+// (their LIMIT caps groups, see query.Query.Grouping). Closed issues are
+// excluded unless includeClosed is set or the query filters on status
+// explicitly. The whole query, including OR and NOT, is evaluated in SQL.
+func queryFilter(q *query.Query, limit int, includeClosed bool) (*query.QueryResult, error) {
+	eval := query.NewEvaluator(time.Now())
+	result, err := eval.EvaluateQuery(q)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating query: %w", err)
+	}
+
+	if len(q.GroupBy) > 0 {
+		limit = 0
+	} else if result.Limit > 0 {
+		limit = result.Limit
+	}
+	result.Filter.Limit = limit
+
+	// By default exclude closed issues unless requested or the query explicitly filters by status
+	if !includeClosed && result.Filter.Status == nil && !hasExplicitStatusFilter(q.Where) {
+		result.Filter.ExcludeStatus = append(result.Filter.ExcludeStatus, types.StatusClosed)
+	}
+	return result, nil
+}
+
+// runQuery evaluates a parsed query against the store (see queryFilter) and
+// returns the matching issues with ORDER BY and LIMIT applied.
+func runQuery(ctx context.Context, s *dolt.DoltStore, q *query.Query, limit int, includeClosed bool) ([]*types.Issue, *query.QueryResult, error) {
+	result, err := queryFilter(q, limit, includeClosed)
+	if err != nil {
+		return nil, nil, err
+	}
+	issues, err := s.SearchIssues(ctx, "", result.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return issues, result, nil
+}
+
+// outputQueryGroups runs a GROUP BY query over the issues matching filter,
+// grouping and aggregating in SQL, and prints the groups as a table (or JSON).
+func outputQueryGroups(ctx context.Context, s *dolt.DoltStore, filter types.IssueFilter, q *query.Query) {
+	grouping, err := q.Grouping(time.Now())
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	groups, total, err := s.GroupIssues(ctx, filter, grouping)
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	agg := query.NewAggregation(q, groups, total)
+
+	if jsonOutput {
+		outputJSON(agg)
+		return
+	}
+
+	if len(agg.Rows) == 0 {
+		fmt.Printf("No issues found matching query: %s\n", q.String())
+		return
+	}
+
+	headers := agg.Headers()
+	cells := make([][]string, len(agg.Rows))
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+	}
+	for r, row := range agg.Rows {
+		cells[r] = make([]string, 0, len(headers))
+		cells[r] = append(cells[r], row.Keys...)
+		for _, v := range row.Values {
+			cells[r] = append(cells[r], query.FormatAggregateValue(v))
+		}
+		for i, c := range cells[r] {
+			widths[i] = max(widths[i], len([]rune(c)))
+		}
+	}
+
+	line := func(values []string) string {
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = padRight(v, widths[i])
+		}
+		return strings.TrimRight(strings.Join(parts, "  "), " ")
+	}
+
+	fmt.Println(ui.RenderBold(line(headers)))
+	for _, row := range cells {
+		fmt.Println(line(row))
+	}
+	fmt.Printf("\n%d issues in %d groups\n", agg.Total, len(agg.Rows))
+}
+
 // hasExplicitStatusFilter checks if the query contains an explicit status comparison
 func hasExplicitStatusFilter(node query.Node) bool {
 	switch n := node.(type) {
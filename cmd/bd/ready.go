@@ -8,10 +8,13 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/query"
+	"github.com/steveyegge/beads/internal/scoring"
 	"github.com/steveyegge/beads/internal/storage/dolt"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/ui"
 	"github.com/steveyegge/beads/internal/utils"
+	"github.com/steveyegge/beads/internal/views"
 )
 
 var readyCmd = &cobra.Command{
@@ -27,6 +30,11 @@ Note: 'bd list --ready' is NOT equivalent - it only filters by status=open.
 Use --mol to filter to a specific molecule's steps:
   bd ready --mol bd-patrol   # Show ready steps within molecule
 
+Use --sort score to rank by weighted cost-of-delay (WSJF-style) scoring:
+  bd ready --sort score            # Priority, age, due date, unblocked work, estimate
+  bd ready --sort score --explain  # Show each issue's score breakdown
+Weights are configured under ready.score.* in config.yaml.
+
 Use --gated to find molecules ready for gate-resume dispatch:
   bd ready --gated           # Find molecules where a gate closed
 
@@ -61,6 +69,8 @@ This is useful for agents executing molecules to see which steps can run next.`,
 		includeDeferred, _ := cmd.Flags().GetBool("include-deferred")
 		includeEphemeral, _ := cmd.Flags().GetBool("include-ephemeral")
 		rigOverride, _ := cmd.Flags().GetString("rig")
+		explain, _ := cmd.Flags().GetBool("explain")
+		viewName, _ := cmd.Flags().GetString("view")
 		var molType *types.MolType
 		if molTypeStr != "" {
 			mt := types.MolType(molTypeStr)
@@ -110,9 +120,43 @@ This is useful for agents executing molecules to see which steps can run next.`,
 		}
 		// Validate sort policy
 		if !filter.SortPolicy.IsValid() {
-			fmt.Fprintf(os.Stderr, "Error: invalid sort policy '%s'. Valid values: hybrid, priority, oldest\n", sortPolicy)
+			fmt.Fprintf(os.Stderr, "Error: invalid sort policy '%s'. Valid values: hybrid, priority, oldest, score\n", sortPolicy)
+			os.Exit(1)
+		}
+		if explain && filter.SortPolicy != types.SortPolicyScore {
+			fmt.Fprintf(os.Stderr, "Error: --explain requires --sort score\n")
 			os.Exit(1)
 		}
+
+		// Saved view: narrows the ready set to the view's query
+		var view *views.View
+		var viewQuery *query.Query
+		if viewName != "" {
+			view, viewQuery = mustLoadIssueView(viewName, "ready")
+			if cmd.Flags().Changed("sort") {
+				// An explicit --sort wins over the view's order
+				unordered := *viewQuery
+				unordered.OrderBy = nil
+				viewQuery = &unordered
+			}
+			if view.Format == views.FormatJSON {
+				jsonOutput = true
+			}
+		}
+		// A view's LIMIT caps the ready set itself, so it never counts as truncation
+		viewLimit := 0
+		if viewQuery != nil {
+			where, order, limit, err := viewFilter(viewQuery)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			filter.Where = where
+			filter.OrderBy = order
+			filter.Limit = minLimit(filter.Limit, limit)
+			viewLimit = limit
+		}
+
 		// Direct mode
 		ctx := rootCtx
 
@@ -129,10 +173,28 @@ This is useful for agents executing molecules to see which steps can run next.`,
 		} else {
 		}
 
-		issues, err := activeStore.GetReadyWork(ctx, filter)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		var issues []*types.Issue
+		var scores map[string]scoring.Breakdown
+		scoredTotal := 0
+		if filter.SortPolicy == types.SortPolicyScore {
+			ranked, total, err := rankReadyWork(ctx, activeStore, filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			scoredTotal = total
+			scores = make(map[string]scoring.Breakdown, len(ranked))
+			for _, r := range ranked {
+				issues = append(issues, r.Issue)
+				scores[r.Issue.ID] = r.Score
+			}
+		} else {
+			var err error
+			issues, err = activeStore.GetReadyWork(ctx, filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
 		}
 		if jsonOutput {
 			// Always output array, even if empty
@@ -151,6 +213,14 @@ This is useful for agents executing molecules to see which steps can run next.`,
 					CommentCount: commentCounts[issue.ID],
 				}
 			}
+			if explain {
+				scored := make([]*ReadyScoredIssue, len(issuesWithCounts))
+				for i, iwc := range issuesWithCounts {
+					scored[i] = &ReadyScoredIssue{IssueWithCounts: iwc, Score: scores[iwc.ID]}
+				}
+				outputJSON(scored)
+				return
+			}
 			outputJSON(issuesWithCounts)
 			return
 		}
@@ -176,10 +246,14 @@ This is useful for agents executing molecules to see which steps can run next.`,
 		// Check if results were truncated by the limit
 		totalReady := len(issues)
 		truncated := false
-		if filter.Limit > 0 && len(issues) == filter.Limit {
+		if scores != nil {
+			// Score ranking already loaded the full ready set
+			totalReady = minLimit(scoredTotal, viewLimit)
+			truncated = totalReady > len(issues)
+		} else if filter.Limit > 0 && len(issues) == filter.Limit {
 			// Re-query without limit to get total count
 			countFilter := filter
-			countFilter.Limit = 0
+			countFilter.Limit = viewLimit
 			allIssues, countErr := activeStore.GetReadyWork(ctx, countFilter)
 			if countErr == nil && len(allIssues) > len(issues) {
 				totalReady = len(allIssues)
@@ -191,8 +265,11 @@ This is useful for agents executing molecules to see which steps can run next.`,
 		parentEpicMap := buildParentEpicMap(ctx, activeStore, issues)
 
 		// Determine display mode: --plain or --pretty=false triggers plain format
-		usePlain := plainFormat || !prettyFormat
-		if usePlain {
+		usePlain := plainFormat || !prettyFormat || (view != nil && view.Format == views.FormatLong)
+		if view != nil && view.Format == views.FormatTable {
+			displayViewTable(ctx, activeStore, issues, view.TableColumns())
+			fmt.Println()
+		} else if usePlain {
 			fmt.Printf("\n%s Ready work (%d issues with no blockers):\n\n", ui.RenderAccent("📋"), len(issues))
 			for i, issue := range issues {
 				fmt.Printf("%d. [%s] [%s] %s: %s\n", i+1,
@@ -205,10 +282,13 @@ This is useful for agents executing molecules to see which steps can run next.`,
 				if issue.Assignee != "" {
 					fmt.Printf("   Assignee: %s\n", issue.Assignee)
 				}
+				if explain {
+					printScoreBreakdown(scores[issue.ID])
+				}
 			}
 			fmt.Println()
 		} else {
-			displayReadyList(issues, parentEpicMap)
+			displayReadyListWithScores(issues, parentEpicMap, scores, explain)
 		}
 
 		// Show truncation footer if results were limited
@@ -321,12 +401,21 @@ func buildParentEpicMap(ctx context.Context, s *dolt.DoltStore, issues []*types.
 
 // displayReadyList displays ready issues in pretty format with optional parent epic context
 func displayReadyList(issues []*types.Issue, parentEpicMap map[string]string) {
+	displayReadyListWithScores(issues, parentEpicMap, nil, false)
+}
+
+// displayReadyListWithScores is displayReadyList with an optional score
+// breakdown printed under each issue (bd ready --sort score --explain).
+func displayReadyListWithScores(issues []*types.Issue, parentEpicMap map[string]string, scores map[string]scoring.Breakdown, explain bool) {
 	for _, issue := range issues {
 		epicTitle := ""
 		if parentEpicMap != nil {
 			epicTitle = parentEpicMap[issue.ID]
 		}
 		fmt.Println(formatPrettyIssueWithContext(issue, epicTitle))
+		if explain {
+			printScoreBreakdown(scores[issue.ID])
+		}
 	}
 
 	// Summary footer
@@ -470,7 +559,8 @@ func init() {
 	readyCmd.Flags().IntP("priority", "p", 0, "Filter by priority")
 	readyCmd.Flags().StringP("assignee", "a", "", "Filter by assignee")
 	readyCmd.Flags().BoolP("unassigned", "u", false, "Show only unassigned issues")
-	readyCmd.Flags().StringP("sort", "s", "priority", "Sort policy: priority (default), hybrid, oldest")
+	readyCmd.Flags().StringP("sort", "s", "priority", "Sort policy: priority (default), hybrid, oldest, score")
+	readyCmd.Flags().Bool("explain", false, "Show each issue's score breakdown (requires --sort score)")
 	readyCmd.Flags().StringSliceP("label", "l", []string{}, "Filter by labels (AND: must have ALL). Can combine with --label-any")
 	readyCmd.Flags().StringSlice("label-any", []string{}, "Filter by labels (OR: must have AT LEAST ONE). Can combine with --label")
 	readyCmd.Flags().StringP("type", "t", "", "Filter by issue type (task, bug, feature, epic, decision, merge-request). Aliases: mr→merge-request, feat→feature, mol→molecule, dec/adr→decision")
@@ -482,6 +572,7 @@ func init() {
 	readyCmd.Flags().Bool("include-deferred", false, "Include issues with future defer_until timestamps")
 	readyCmd.Flags().Bool("include-ephemeral", false, "Include ephemeral issues (wisps) in results")
 	readyCmd.Flags().Bool("gated", false, "Find molecules ready for gate-resume dispatch")
+	readyCmd.Flags().String("view", "", "Narrow ready work with a saved view (see bd views)")
 	readyCmd.Flags().String("rig", "", "Query a different rig's database (e.g., --rig gastown, --rig gt-, --rig gt)")
 	rootCmd.AddCommand(readyCmd)
 	blockedCmd.Flags().String("parent", "", "Filter to descendants of this bead/epic")
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/scoring"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+)
+
+// ReadyScoredIssue is the JSON output for bd ready --sort score --explain
+type ReadyScoredIssue struct {
+	*types.IssueWithCounts
+	Score scoring.Breakdown `json:"score"`
+}
+
+// readyScoreWeights returns the scoring weights from config (ready.score.*).
+func readyScoreWeights() scoring.Weights {
+	return scoring.Weights{
+		Priority: config.GetFloat64("ready.score.priority"),
+		Age:      config.GetFloat64("ready.score.age"),
+		Due:      config.GetFloat64("ready.score.due"),
+		Unblocks: config.GetFloat64("ready.score.unblocks"),
+		Estimate: config.GetFloat64("ready.score.estimate"),
+	}
+}
+
+// rankReadyWork scores all ready issues matching filter and returns them in
+// descending score order, truncated to filter.Limit. The second return value is
+// the total number of ready issues before truncation.
+//
+// Scoring needs the full ready set (the best-scored issue may not be in the
+// first page of the priority-ordered SQL result), so the limit is applied here.
+func rankReadyWork(ctx context.Context, s *dolt.DoltStore, filter types.WorkFilter) ([]scoring.Scored, int, error) {
+	unlimited := filter
+	unlimited.Limit = 0
+	issues, err := s.GetReadyWork(ctx, unlimited)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(issues) == 0 {
+		return nil, 0, nil
+	}
+
+	unblocks, err := countReadyUnblocks(ctx, s, issues)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count unblocked dependents: %w", err)
+	}
+
+	ranked := scoring.Rank(issues, unblocks, readyScoreWeights(), time.Now())
+	total := len(ranked)
+	if filter.Limit > 0 && len(ranked) > filter.Limit {
+		ranked = ranked[:filter.Limit]
+	}
+	return ranked, total, nil
+}
+
+// countReadyUnblocks returns how many open issues each ready issue transitively unblocks.
+func countReadyUnblocks(ctx context.Context, s *dolt.DoltStore, issues []*types.Issue) (map[string]int, error) {
+	deps, err := s.GetAllDependencyRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(issues))
+	for i, issue := range issues {
+		ids[i] = issue.ID
+	}
+
+	// Find every issue reachable through blocking edges (ignoring status) so we
+	// only hydrate the part of the graph that can contribute to a count.
+	reachable := scoring.CountUnblocked(ids, deps, func(string) bool { return true })
+	candidates := make(map[string]bool)
+	for issueID, records := range deps {
+		for _, dep := range records {
+			if dep.Type.AffectsReadyWork() && dep.Type != types.DepParentChild {
+				candidates[issueID] = true
+			}
+		}
+	}
+	anyReachable := false
+	for _, n := range reachable {
+		if n > 0 {
+			anyReachable = true
+			break
+		}
+	}
+	if !anyReachable {
+		return reachable, nil
+	}
+
+	candidateIDs := make([]string, 0, len(candidates))
+	for id := range candidates {
+		candidateIDs = append(candidateIDs, id)
+	}
+	candidateIssues, err := s.GetIssuesByIDs(ctx, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	openSet := make(map[string]bool, len(candidateIssues))
+	for _, issue := range candidateIssues {
+		if issue.Status != types.StatusClosed {
+			openSet[issue.ID] = true
+		}
+	}
+
+	return scoring.CountUnblocked(ids, deps, func(id string) bool { return openSet[id] }), nil
+}
+
+// printScoreBreakdown prints the per-factor score contributions under an issue line.
+func printScoreBreakdown(b scoring.Breakdown) {
+	fmt.Printf("   %s %.2f = priority %.2f + age %.2f + due %.2f + unblocks %.2f (%d) + estimate %.2f\n",
+		ui.RenderMuted("score"), b.Total, b.Priority, b.Age, b.Due, b.Unblocks, b.UnblockCount, b.Estimate)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/hooks"
 	"github.com/steveyegge/beads/internal/types"
 	"github.com/steveyegge/beads/internal/ui"
 	"github.com/steveyegge/beads/internal/utils"
@@ -40,6 +41,7 @@ This is more explicit than 'bd update --status open' and emits a Reopened event.
 				fmt.Fprintf(os.Stderr, "Error resolving %s: %v\n", id, err)
 				continue
 			}
+			oldIssue, _ := store.GetIssue(ctx, fullID) // Best effort: hooks get no old issue on failure
 			// UpdateIssue automatically clears closed_at when status changes from closed
 			updates := map[string]interface{}{
 				"status": string(types.StatusOpen),
@@ -54,8 +56,9 @@ This is more explicit than 'bd update --status open' and emits a Reopened event.
 					fmt.Fprintf(os.Stderr, "Warning: failed to add comment to %s: %v\n", fullID, err)
 				}
 			}
+			issue, _ := store.GetIssue(ctx, fullID)
+			emitChangeHooks(hooks.EventReopen, oldIssue, issue)
 			if jsonOutput {
-				issue, _ := store.GetIssue(ctx, fullID)
 				if issue != nil {
 					reopenedIssues = append(reopenedIssues, issue)
 				}
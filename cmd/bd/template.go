@@ -51,6 +51,10 @@ type CloneOptions struct {
 	// Dynamic bonding fields (for Christmas Ornament pattern)
 	ParentID string // Parent molecule ID to bond under (e.g., "patrol-x7k")
 	ChildRef string // Child reference with variables (e.g., "arm-{{polecat_name}}")
+
+	// StepPrefix namespaces step:<id> runtime labels (e.g., "survey.item-0"
+	// for molecules fanned out by an on_complete step)
+	StepPrefix string
 }
 
 // bondedIDPattern validates bonded IDs (alphanumeric, dash, underscore, dot)
@@ -354,6 +358,19 @@ func loadTemplateSubgraph(ctx context.Context, s *dolt.DoltStore, templateID str
 		}
 	}
 
+	// Load labels (formula runtime labels are carried over when cloning)
+	issueIDs := make([]string, len(subgraph.Issues))
+	for i, issue := range subgraph.Issues {
+		issueIDs[i] = issue.ID
+	}
+	labelsMap, err := s.GetLabelsForIssues(ctx, issueIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels: %w", err)
+	}
+	for _, issue := range subgraph.Issues {
+		issue.Labels = labelsMap[issue.ID]
+	}
+
 	return subgraph, nil
 }
 
@@ -721,80 +738,99 @@ func cloneSubgraph(ctx context.Context, s *dolt.DoltStore, subgraph *TemplateSub
 		return nil, fmt.Errorf("no database connection")
 	}
 
-	// Generate new IDs and create mapping
-	idMapping := make(map[string]string)
-
 	// Use transaction for atomicity
+	var result *InstantiateResult
 	err := s.RunInTransaction(ctx, func(tx storage.Transaction) error {
-		// First pass: create all issues with new IDs
-		for _, oldIssue := range subgraph.Issues {
-			// Determine assignee: use override for root epic, otherwise keep template's
-			issueAssignee := oldIssue.Assignee
-			if oldIssue.ID == subgraph.Root.ID && opts.Assignee != "" {
-				issueAssignee = opts.Assignee
-			}
-
-			newIssue := &types.Issue{
-				// ID will be set below based on bonding options
-				Title:              substituteVariables(oldIssue.Title, opts.Vars),
-				Description:        substituteVariables(oldIssue.Description, opts.Vars),
-				Design:             substituteVariables(oldIssue.Design, opts.Vars),
-				AcceptanceCriteria: substituteVariables(oldIssue.AcceptanceCriteria, opts.Vars),
-				Notes:              substituteVariables(oldIssue.Notes, opts.Vars),
-				Status:             types.StatusOpen, // Always start fresh
-				Priority:           oldIssue.Priority,
-				IssueType:          oldIssue.IssueType,
-				Assignee:           issueAssignee,
-				EstimatedMinutes:   oldIssue.EstimatedMinutes,
-				Ephemeral:          opts.Ephemeral, // mark for cleanup when closed
-				IDPrefix:           opts.Prefix,    // distinct prefixes for mols/wisps
-				// Gate fields (for async coordination)
-				AwaitType: oldIssue.AwaitType,
-				AwaitID:   substituteVariables(oldIssue.AwaitID, opts.Vars),
-				Timeout:   oldIssue.Timeout,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}
-
-			// Generate custom ID for dynamic bonding if ParentID is set
-			if opts.ParentID != "" {
-				bondedID, err := generateBondedID(oldIssue.ID, subgraph.Root.ID, opts)
-				if err != nil {
-					return fmt.Errorf("failed to generate bonded ID for %s: %w", oldIssue.ID, err)
-				}
-				newIssue.ID = bondedID
-			}
+		var err error
+		result, err = cloneSubgraphInTx(ctx, tx, subgraph, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-			if err := tx.CreateIssue(ctx, newIssue, opts.Actor); err != nil {
-				return fmt.Errorf("failed to create issue from %s: %w", oldIssue.ID, err)
+// cloneSubgraphInTx is cloneSubgraph within the caller's transaction, for
+// callers that pour several subgraphs or wire them up atomically.
+func cloneSubgraphInTx(ctx context.Context, tx storage.Transaction, subgraph *TemplateSubgraph, opts CloneOptions) (*InstantiateResult, error) {
+	// Generate new IDs and create mapping
+	idMapping := make(map[string]string)
+	runtimeLabels := formulaRuntimeLabels(subgraph, opts.StepPrefix != "")
+
+	// First pass: create all issues with new IDs
+	for _, oldIssue := range subgraph.Issues {
+		// Determine assignee: use override for root epic, otherwise keep template's
+		issueAssignee := oldIssue.Assignee
+		if oldIssue.ID == subgraph.Root.ID && opts.Assignee != "" {
+			issueAssignee = opts.Assignee
+		}
+
+		newIssue := &types.Issue{
+			// ID will be set below based on bonding options
+			Title:              substituteVariables(oldIssue.Title, opts.Vars),
+			Description:        substituteVariables(oldIssue.Description, opts.Vars),
+			Design:             substituteVariables(oldIssue.Design, opts.Vars),
+			AcceptanceCriteria: substituteVariables(oldIssue.AcceptanceCriteria, opts.Vars),
+			Notes:              substituteVariables(oldIssue.Notes, opts.Vars),
+			Status:             types.StatusOpen, // Always start fresh
+			Priority:           oldIssue.Priority,
+			IssueType:          oldIssue.IssueType,
+			Assignee:           issueAssignee,
+			EstimatedMinutes:   oldIssue.EstimatedMinutes,
+			Ephemeral:          opts.Ephemeral, // mark for cleanup when closed
+			IDPrefix:           opts.Prefix,    // distinct prefixes for mols/wisps
+			// Gate fields (for async coordination)
+			AwaitType: oldIssue.AwaitType,
+			AwaitID:   substituteVariables(oldIssue.AwaitID, opts.Vars),
+			Timeout:   oldIssue.Timeout,
+			Metadata:  oldIssue.Metadata, // e.g. gate escalation policy
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		// Generate custom ID for dynamic bonding if ParentID is set
+		if opts.ParentID != "" {
+			bondedID, err := generateBondedID(oldIssue.ID, subgraph.Root.ID, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate bonded ID for %s: %w", oldIssue.ID, err)
 			}
+			newIssue.ID = bondedID
+		}
 
-			idMapping[oldIssue.ID] = newIssue.ID
+		if err := tx.CreateIssue(ctx, newIssue, opts.Actor); err != nil {
+			return nil, fmt.Errorf("failed to create issue from %s: %w", oldIssue.ID, err)
 		}
 
-		// Second pass: recreate dependencies with new IDs
-		for _, dep := range subgraph.Dependencies {
-			newFromID, ok1 := idMapping[dep.IssueID]
-			newToID, ok2 := idMapping[dep.DependsOnID]
-			if !ok1 || !ok2 {
-				continue // Skip if either end is outside the subgraph
-			}
+		idMapping[oldIssue.ID] = newIssue.ID
 
-			newDep := &types.Dependency{
-				IssueID:     newFromID,
-				DependsOnID: newToID,
-				Type:        dep.Type,
+		for _, label := range runtimeLabels[oldIssue.ID] {
+			if stepID, ok := strings.CutPrefix(label, stepLabelPrefix); ok && opts.StepPrefix != "" {
+				label = stepLabelPrefix + opts.StepPrefix + "." + stepID
 			}
-			if err := tx.AddDependency(ctx, newDep, opts.Actor); err != nil {
-				return fmt.Errorf("failed to create dependency: %w", err)
+			if err := tx.AddLabel(ctx, newIssue.ID, label, opts.Actor); err != nil {
+				return nil, fmt.Errorf("failed to add label to %s: %w", newIssue.ID, err)
 			}
 		}
+	}
 
-		return nil
-	})
+	// Second pass: recreate dependencies with new IDs
+	for _, dep := range subgraph.Dependencies {
+		newFromID, ok1 := idMapping[dep.IssueID]
+		newToID, ok2 := idMapping[dep.DependsOnID]
+		if !ok1 || !ok2 {
+			continue // Skip if either end is outside the subgraph
+		}
 
-	if err != nil {
-		return nil, err
+		newDep := &types.Dependency{
+			IssueID:     newFromID,
+			DependsOnID: newToID,
+			Type:        dep.Type,
+			Metadata:    dep.Metadata, // e.g. waits-for gate type
+		}
+		if err := tx.AddDependency(ctx, newDep, opts.Actor); err != nil {
+			return nil, fmt.Errorf("failed to create dependency: %w", err)
+		}
 	}
 
 	return &InstantiateResult{
@@ -804,6 +840,58 @@ func cloneSubgraph(ctx context.Context, s *dolt.DoltStore, subgraph *TemplateSub
 	}, nil
 }
 
+// formulaRuntimeLabels returns, per template issue, the labels that
+// 'bd mol advance' needs on the spawned molecule: until-loop and on_complete
+// markers and a step:<id> label naming the formula step, so conditions can
+// refer to steps by ID. Steps cooked from a formula (source-traced) always
+// get step labels, which 'bd mol migrate' matches steps by. Other subgraphs
+// without condition gates, until-loops or on_complete fan-outs get none,
+// unless withSteps is set.
+func formulaRuntimeLabels(subgraph *TemplateSubgraph, withSteps bool) map[string][]string {
+	result := make(map[string][]string)
+	needed := withSteps
+	for _, issue := range subgraph.Issues {
+		if issue.AwaitType == conditionGateType || issue.SourceFormula != "" {
+			needed = true
+		}
+		for _, label := range issue.Labels {
+			if isRuntimeMarkerLabel(label) {
+				result[issue.ID] = append(result[issue.ID], label)
+				needed = true
+			}
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	// Step issues are named {parent}.{step-id} by cook
+	for _, dep := range subgraph.Dependencies {
+		issue := subgraph.IssueMap[dep.IssueID]
+		if dep.Type != types.DepParentChild || issue == nil || issue.IssueType == "gate" {
+			continue
+		}
+		if stepID, ok := strings.CutPrefix(issue.ID, dep.DependsOnID+"."); ok {
+			result[issue.ID] = append(result[issue.ID], stepLabelPrefix+stepID)
+		}
+	}
+	return result
+}
+
+// isRuntimeMarkerLabel reports whether a label carries control flow that
+// 'bd mol advance' or 'bd mol retry' acts on: an until-loop, an on_complete
+// fan-out or a retry policy.
+func isRuntimeMarkerLabel(label string) bool {
+	if _, _, ok := formula.ParseLoopLabel(label); ok {
+		return true
+	}
+	if _, ok := formula.ParseMaxRetriesLabel(label); ok {
+		return true
+	}
+	_, ok := formula.ParseOnCompleteLabel(label)
+	return ok
+}
+
 // printTemplateTree prints the template structure as a tree
 func printTemplateTree(subgraph *TemplateSubgraph, parentID string, depth int, isRoot bool) {
 	indent := strings.Repeat("  ", depth)
@@ -272,6 +272,14 @@ create, update, show, or close operation).`,
 				continue
 			}
 
+			// Pre-operation hooks may veto or patch the update
+			issueUpdates, err := runUpdateBeforeHooks(issue, updates, claimFlag)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				result.Close()
+				continue
+			}
+
 			// Handle claim operation atomically using compare-and-swap semantics
 			if claimFlag {
 				if err := issueStore.ClaimIssue(ctx, result.ResolvedID, actor); err != nil {
@@ -283,13 +291,13 @@ create, update, show, or close operation).`,
 
 			// Apply regular field updates if any
 			regularUpdates := make(map[string]interface{})
-			for k, v := range updates {
+			for k, v := range issueUpdates {
 				if k != "add_labels" && k != "remove_labels" && k != "set_labels" && k != "parent" && k != "append_notes" {
 					regularUpdates[k] = v
 				}
 			}
 			// Handle append_notes: combine existing notes with new content
-			if appendNotes, ok := updates["append_notes"].(string); ok {
+			if appendNotes, ok := issueUpdates["append_notes"].(string); ok {
 				combined := issue.Notes
 				if combined != "" {
 					combined += "\n"
@@ -307,13 +315,13 @@ create, update, show, or close operation).`,
 
 			// Handle label operations
 			var setLabels, addLabels, removeLabels []string
-			if v, ok := updates["set_labels"].([]string); ok {
+			if v, ok := issueUpdates["set_labels"].([]string); ok {
 				setLabels = v
 			}
-			if v, ok := updates["add_labels"].([]string); ok {
+			if v, ok := issueUpdates["add_labels"].([]string); ok {
 				addLabels = v
 			}
-			if v, ok := updates["remove_labels"].([]string); ok {
+			if v, ok := issueUpdates["remove_labels"].([]string); ok {
 				removeLabels = v
 			}
 			if len(setLabels) > 0 || len(addLabels) > 0 || len(removeLabels) > 0 {
@@ -325,7 +333,7 @@ create, update, show, or close operation).`,
 			}
 
 			// Handle parent reparenting
-			if newParent, ok := updates["parent"].(string); ok {
+			if newParent, ok := issueUpdates["parent"].(string); ok {
 				// Validate new parent exists (unless empty string to remove parent)
 				if newParent != "" {
 					parentIssue, err := issueStore.GetIssue(ctx, newParent)
@@ -372,10 +380,16 @@ create, update, show, or close operation).`,
 				}
 			}
 
-			// Run update hook
+			// Run update hooks
 			updatedIssue, _ := issueStore.GetIssue(ctx, result.ResolvedID) // Best effort: nil issue handled by subsequent nil check
-			if updatedIssue != nil && hookRunner != nil {
-				hookRunner.Run(hooks.EventUpdate, updatedIssue)
+			if updatedIssue != nil {
+				emitChangeHooks(hooks.EventUpdate, issue, updatedIssue)
+				if claimFlag {
+					emitIssueHook(hooks.EventClaimed, issue, updatedIssue)
+				}
+				if updatedIssue.Status == types.StatusClosed && issue.Status != types.StatusClosed {
+					emitUnblockedHooks(ctx, issueStore, result.ResolvedID)
+				}
 			}
 
 			if jsonOutput {
@@ -432,7 +446,7 @@ func init() {
 	updateCmd.Flags().String("due", "", "Due date/time (empty to clear). Formats: +6h, +1d, +2w, tomorrow, next monday, 2025-01-15")
 	updateCmd.Flags().String("defer", "", "Defer until date (empty to clear). Issue hidden from bd ready until then")
 	// Gate fields (bd-z6kw)
-	updateCmd.Flags().String("await-id", "", "Set gate await_id (e.g., GitHub run ID for gh:run gates)")
+	updateCmd.Flags().String("await-id", "", "Set gate await_id (e.g., GitHub run ID for gh:run gates, GitLab pipeline ID for gl:pipeline gates)")
 	// Ephemeral/persistent flags
 	updateCmd.Flags().Bool("ephemeral", false, "Mark issue as ephemeral (wisp) - not exported to JSONL")
 	updateCmd.Flags().Bool("persistent", false, "Mark issue as persistent (promote wisp to regular issue)")
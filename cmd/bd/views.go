@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/beads"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/query"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/views"
+)
+
+var viewsCmd = &cobra.Command{
+	Use:     "views",
+	GroupID: "views",
+	Short:   "Manage saved views (named queries for list, ready and count)",
+	Long: `Manage saved views.
+
+A view bundles a query (see 'bd help query'), an optional sort, table columns
+and an output format under a name. Use it with --view on bd list, bd ready and
+bd count; the view narrows each command's normal results.
+
+Views are stored in .beads/views/<name>.toml so they can be committed and
+shared with the team. Personal views can also be defined in config.yaml:
+
+  views:
+    mine:
+      query: "assignee=alice"
+      sort: "priority, updated desc"
+
+A view file overrides a config view with the same name.
+
+Examples:
+  bd views save triage "status=open AND assignee=none" --sort priority
+  bd views save mine "assignee=alice" --columns id,priority,title,due --format table
+  bd views save by-owner "GROUP BY owner, count(), sum(estimate)"
+  bd list --view triage
+  bd ready --view mine
+  bd count --view by-owner
+  bd views list
+  bd views rm triage`,
+}
+
+var viewSaveCmd = &cobra.Command{
+	Use:   "save <name> <query>",
+	Short: "Save a named view",
+	Long: `Save a named view to .beads/views/<name>.toml.
+
+The query is compiled when saved, so invalid fields or syntax are rejected.
+--sort takes ORDER BY terms (e.g. "priority, updated desc").
+--format is one of compact (default), long, table or json.
+--columns applies to the table format: id, title, status, priority, type,
+assignee, owner, labels, created, updated, closed, due, defer, estimate.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		sortTerms, _ := cmd.Flags().GetString("sort")
+		columns, _ := cmd.Flags().GetStringSlice("columns")
+		format, _ := cmd.Flags().GetString("format")
+		description, _ := cmd.Flags().GetString("description")
+		force, _ := cmd.Flags().GetBool("force")
+
+		v := &views.View{
+			Name:        args[0],
+			Description: description,
+			Query:       strings.Join(args[1:], " "),
+			Sort:        sortTerms,
+			Columns:     columns,
+			Format:      format,
+		}
+
+		beadsDir := beads.FindBeadsDir()
+		if beadsDir == "" {
+			FatalErrorRespectJSON("no .beads directory found")
+		}
+		existing, err := loadViews()
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		if old, ok := existing[v.Name]; ok && old.Source != views.SourceConfig && !force {
+			FatalErrorRespectJSON("view %s already exists (use --force to overwrite)", v.Name)
+		}
+
+		path, err := views.Save(beadsDir, v)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		v.Source = path
+
+		if jsonOutput {
+			outputJSON(v)
+			return
+		}
+		fmt.Printf("%s Saved view %s to %s\n", ui.RenderPass("✓"), v.Name, path)
+	},
+}
+
+var viewListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved views",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, err := loadViews()
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		names := views.Names(all)
+
+		if jsonOutput {
+			list := make([]*views.View, len(names))
+			for i, name := range names {
+				list[i] = all[name]
+			}
+			outputJSON(list)
+			return
+		}
+		if len(names) == 0 {
+			fmt.Println("No saved views (create one with: bd views save <name> <query>)")
+			return
+		}
+		width := 0
+		for _, name := range names {
+			width = max(width, len(name))
+		}
+		for _, name := range names {
+			v := all[name]
+			fmt.Printf("%s  %s\n", padRight(name, width), v.QueryString())
+			if v.Description != "" {
+				fmt.Printf("%s  %s\n", strings.Repeat(" ", width), ui.RenderMuted(v.Description))
+			}
+		}
+	},
+}
+
+var viewShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a saved view",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		v, q := mustLoadView(args[0])
+
+		if jsonOutput {
+			outputJSON(v)
+			return
+		}
+		fmt.Printf("%s %s\n", ui.RenderBold("View:"), v.Name)
+		if v.Description != "" {
+			fmt.Printf("  Description: %s\n", v.Description)
+		}
+		fmt.Printf("  Query:       %s\n", v.Query)
+		if v.Sort != "" {
+			fmt.Printf("  Sort:        %s\n", v.Sort)
+		}
+		if v.Format != "" {
+			fmt.Printf("  Format:      %s\n", v.Format)
+		}
+		if len(v.Columns) > 0 {
+			fmt.Printf("  Columns:     %s\n", strings.Join(v.Columns, ", "))
+		}
+		fmt.Printf("  Compiled:    %s\n", q.String())
+		fmt.Printf("  Source:      %s\n", v.Source)
+	},
+}
+
+var viewRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Remove a saved view",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		all, err := loadViews()
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		v, ok := all[name]
+		if !ok {
+			FatalErrorRespectJSON("unknown view: %s", name)
+		}
+		if v.Source == views.SourceConfig {
+			FatalErrorRespectJSON("view %s is defined in config.yaml; remove it there", name)
+		}
+
+		path, err := views.Remove(beads.FindBeadsDir(), name)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+
+		if jsonOutput {
+			outputJSON(map[string]string{"removed": name, "path": path})
+			return
+		}
+		fmt.Printf("%s Removed view %s (%s)\n", ui.RenderPass("✓"), name, path)
+	},
+}
+
+// loadViews returns all saved views from config.yaml and .beads/views.
+func loadViews() (map[string]*views.View, error) {
+	var fromConfig map[string]*views.View
+	if err := config.UnmarshalKey("views", &fromConfig); err != nil {
+		return nil, fmt.Errorf("invalid views in config.yaml: %w", err)
+	}
+	return views.Load(beads.FindBeadsDir(), fromConfig)
+}
+
+// mustLoadView looks up and compiles a view, exiting on error.
+func mustLoadView(name string) (*views.View, *query.Query) {
+	all, err := loadViews()
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	v, ok := all[name]
+	if !ok {
+		FatalErrorRespectJSON("unknown view: %s (see bd views list)", name)
+	}
+	q, err := v.Compile()
+	if err != nil {
+		FatalErrorRespectJSON("%v", err)
+	}
+	return v, q
+}
+
+// mustLoadIssueView is mustLoadView for commands that list issues, which
+// cannot display aggregating (GROUP BY) views.
+func mustLoadIssueView(name, command string) (*views.View, *query.Query) {
+	v, q := mustLoadView(name)
+	if len(q.GroupBy) > 0 {
+		FatalErrorRespectJSON("view %s aggregates with GROUP BY; use it with bd count, not bd %s", name, command)
+	}
+	return v, q
+}
+
+// viewFilter evaluates a view's query for merging into a command's filter,
+// so the command's conditions and the view's run as one search. It returns
+// the view's conditions, its ORDER BY and its LIMIT. GROUP BY views supply
+// only their conditions: their ORDER BY and LIMIT apply to the groups.
+func viewFilter(q *query.Query) (*types.FilterExpr, []types.IssueOrder, int, error) {
+	result, err := query.NewEvaluator(time.Now()).EvaluateQuery(q)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("view query: %w", err)
+	}
+	match := result.Filter
+	match.OrderBy, match.Limit = nil, 0
+	where := &types.FilterExpr{Match: &match}
+	if len(q.GroupBy) > 0 {
+		return where, nil, 0, nil
+	}
+	return where, result.OrderBy, result.Limit, nil
+}
+
+// andWhere combines two optional filter expressions.
+func andWhere(a, b *types.FilterExpr) *types.FilterExpr {
+	if a == nil {
+		return b
+	}
+	return &types.FilterExpr{And: []*types.FilterExpr{a, b}}
+}
+
+// minLimit returns the tighter of two limits, where 0 means unlimited.
+func minLimit(a, b int) int {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// applyView narrows filter to a view's query. The view's ORDER BY replaces
+// the default order and its LIMIT caps filter.Limit.
+func applyView(filter *types.IssueFilter, q *query.Query) error {
+	where, order, limit, err := viewFilter(q)
+	if err != nil {
+		return err
+	}
+	filter.Where = andWhere(filter.Where, where)
+	if len(order) > 0 {
+		filter.OrderBy = order
+	}
+	filter.Limit = minLimit(filter.Limit, limit)
+	return nil
+}
+
+// displayViewTable prints issues as aligned columns.
+func displayViewTable(ctx context.Context, s *dolt.DoltStore, issues []*types.Issue, columns []string) {
+	for _, col := range columns {
+		if col == "labels" {
+			issueIDs := make([]string, len(issues))
+			for i, issue := range issues {
+				issueIDs[i] = issue.ID
+			}
+			labelsMap, _ := s.GetLabelsForIssues(ctx, issueIDs) // Best effort: display gracefully degrades with empty data
+			for _, issue := range issues {
+				issue.Labels = labelsMap[issue.ID]
+			}
+			break
+		}
+	}
+
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		widths[i] = len(col)
+	}
+	rows := make([][]string, len(issues))
+	for r, issue := range issues {
+		rows[r] = make([]string, len(columns))
+		for i, col := range columns {
+			value := views.ColumnValue(issue, col)
+			if col == "title" {
+				value = truncateTitle(value, 60)
+			}
+			rows[r][i] = value
+			widths[i] = max(widths[i], len([]rune(value)))
+		}
+	}
+
+	line := func(values []string) string {
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = padRight(v, widths[i])
+		}
+		return strings.TrimRight(strings.Join(parts, "  "), " ")
+	}
+	fmt.Println(ui.RenderBold(line(strings.Split(strings.ToUpper(strings.Join(columns, "\x00")), "\x00"))))
+	for _, row := range rows {
+		fmt.Println(line(row))
+	}
+}
+
+func init() {
+	viewSaveCmd.Flags().String("sort", "", "Sort terms (ORDER BY syntax, e.g. \"priority, updated desc\")")
+	viewSaveCmd.Flags().StringSlice("columns", nil, "Columns for table format (comma-separated)")
+	viewSaveCmd.Flags().String("format", "", "Output format: compact, long, table, json")
+	viewSaveCmd.Flags().String("description", "", "Short description shown in bd views list")
+	viewSaveCmd.Flags().BoolP("force", "f", false, "Overwrite an existing view")
+
+	viewsCmd.AddCommand(viewSaveCmd)
+	viewsCmd.AddCommand(viewListCmd)
+	viewsCmd.AddCommand(viewShowCmd)
+	viewsCmd.AddCommand(viewRmCmd)
+	rootCmd.AddCommand(viewsCmd)
+
+}
@@ -0,0 +1,84 @@
+//go:build cgo
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/query"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// TestApplyView checks that a view's conditions, order and limit apply to
+// the command's own results in the same search, so a LIMIT never drops
+// issues the command matched in favour of ones it did not.
+func TestApplyView(t *testing.T) {
+	tmpDir := t.TempDir()
+	s := newTestStore(t, filepath.Join(tmpDir, ".beads", "beads.db"))
+	ctx := context.Background()
+
+	for i, title := range []string{"alpha", "bravo", "charlie", "delta"} {
+		issue := &types.Issue{Title: title, Priority: i, IssueType: types.TypeTask, Status: types.StatusOpen}
+		if err := s.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("CreateIssue: %v", err)
+		}
+	}
+
+	titles := func(issues []*types.Issue) string {
+		var out []string
+		for _, issue := range issues {
+			out = append(out, issue.Title)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		view string
+		want string
+	}{
+		{"status=open ORDER BY priority LIMIT 2", "charlie,delta"},
+		{"status=open ORDER BY priority LIMIT 1", "charlie"},
+		{"status=open ORDER BY priority DESC", "delta,charlie"},
+		{"status=open LIMIT 1", "charlie"},
+		{"title=alpha OR title=delta", "delta"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.view, func(t *testing.T) {
+			q, err := query.ParseQuery(tt.view)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+
+			// The command's own filter: the two lowest-priority issues, which
+			// are not among the view's first two matches by priority
+			minPriority := 2
+			filter := types.IssueFilter{PriorityMin: &minPriority}
+			if err := applyView(&filter, q); err != nil {
+				t.Fatalf("applyView: %v", err)
+			}
+			got, err := s.SearchIssues(ctx, "", filter)
+			if err != nil {
+				t.Fatalf("SearchIssues: %v", err)
+			}
+			if titles(got) != tt.want {
+				t.Errorf("list = %s, want %s", titles(got), tt.want)
+			}
+
+			where, order, limit, err := viewFilter(q)
+			if err != nil {
+				t.Fatalf("viewFilter: %v", err)
+			}
+			work := types.WorkFilter{Where: andWhere(&types.FilterExpr{Match: &types.IssueFilter{PriorityMin: &minPriority}}, where), OrderBy: order, Limit: limit}
+			got, err = s.GetReadyWork(ctx, work)
+			if err != nil {
+				t.Fatalf("GetReadyWork: %v", err)
+			}
+			if titles(got) != tt.want {
+				t.Errorf("ready = %s, want %s", titles(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/hooks"
+	"github.com/steveyegge/beads/internal/storage/dolt"
+	"github.com/steveyegge/beads/internal/types"
+	"github.com/steveyegge/beads/internal/ui"
+	"github.com/steveyegge/beads/internal/webhooks"
+)
+
+// webhookTestEvent is the event 'bd webhook test' sends.
+const webhookTestEvent = "test"
+
+// webhookBatchSize is how many due deliveries one pass sends at most.
+const webhookBatchSize = 50
+
+// webhookInlineTimeout bounds the best-effort delivery pass at the end of a
+// command, so a slow endpoint never holds up the command for long.
+const webhookInlineTimeout = 2 * time.Second
+
+var (
+	webhooksOnce       sync.Once
+	configuredWebhooks map[string]*webhooks.Webhook
+)
+
+// loadWebhooks returns the valid webhooks from config.yaml, keyed by name.
+// Invalid entries are reported once and skipped.
+func loadWebhooks() map[string]*webhooks.Webhook {
+	webhooksOnce.Do(func() {
+		var raw map[string]*webhooks.Webhook
+		if err := config.UnmarshalKey("webhooks", &raw); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid webhooks config: %v\n", err)
+			return
+		}
+		configuredWebhooks = make(map[string]*webhooks.Webhook, len(raw))
+		for name, w := range raw {
+			if w == nil {
+				continue
+			}
+			w.Name = name
+			if err := w.Validate(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: ignoring webhooks.%s: %v\n", name, err)
+				continue
+			}
+			configuredWebhooks[name] = w
+		}
+	})
+	return configuredWebhooks
+}
+
+// webhookNames returns the configured webhook names in order.
+func webhookNames() []string {
+	all := loadWebhooks()
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// webhookWants reports whether any configured webhook subscribes to event.
+func webhookWants(event string) bool {
+	for _, w := range loadWebhooks() {
+		if w.Wants(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueWebhooks queues an event in the outbox for each webhook that
+// subscribes to it. Queuing is synchronous, so the deliveries survive the
+// command exiting before they are sent.
+func enqueueWebhooks(env *hooks.Envelope) {
+	if store == nil {
+		return
+	}
+	var payload []byte
+	for _, name := range webhookNames() {
+		if !loadWebhooks()[name].Wants(env.Event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(env); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not encode %s webhook payload: %v\n", env.Event, err)
+				return
+			}
+		}
+		d := &types.WebhookDelivery{Webhook: name, Event: env.Event, IssueID: env.IssueID, Payload: string(payload)}
+		if err := store.EnqueueWebhookDelivery(rootCtx, d); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not queue %s webhook %s: %v\n", env.Event, name, err)
+		}
+	}
+}
+
+// webhookRetryPolicy returns the retry schedule from config.
+func webhookRetryPolicy() webhooks.RetryPolicy {
+	return webhooks.RetryPolicy{
+		MaxAttempts: config.GetInt("webhook.max-attempts"),
+		Backoff:     config.GetDuration("webhook.backoff"),
+		MaxBackoff:  config.GetDuration("webhook.max-backoff"),
+	}
+}
+
+func webhookClient() *http.Client {
+	return &http.Client{Timeout: config.GetDuration("webhook.timeout")}
+}
+
+// deliverWebhooks makes one delivery pass over the outbox.
+func deliverWebhooks(ctx context.Context, s *dolt.DoltStore) (webhooks.Result, error) {
+	return webhooks.Deliver(ctx, s, loadWebhooks(), webhookClient(), webhookRetryPolicy(), webhookBatchSize)
+}
+
+// deliverPendingWebhooks makes a short best-effort pass over the outbox at
+// the end of a command, including retries of earlier failures. What it does
+// not get to within webhookInlineTimeout, and failures, are left in the
+// outbox for a later command or 'bd webhook deliver'.
+func deliverPendingWebhooks() {
+	if store == nil || readonlyMode || len(loadWebhooks()) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(rootCtx, webhookInlineTimeout)
+	defer cancel()
+	result, err := deliverWebhooks(ctx, store)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		fmt.Fprintf(os.Stderr, "Warning: webhook delivery: %v\n", err)
+		return
+	}
+	if result.Retrying > 0 || result.Dead > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d webhook deliveries failed (%d will be retried, %d dead); see 'bd webhook list'\n",
+			result.Retrying+result.Dead, result.Retrying, result.Dead)
+	}
+}
+
+var webhookCmd = &cobra.Command{
+	Use:     "webhook",
+	GroupID: "advanced",
+	Short:   "Manage outbound webhooks and their delivery outbox",
+	Long: `Manage outbound webhooks.
+
+Webhooks POST bd's hook events (see 'Beads Event Hooks' in docs/messaging.md)
+to HTTP endpoints configured in config.yaml:
+
+  webhooks:
+    ci:
+      url: https://ci.example.com/beads
+      events: [close, status_changed]   # omit for every event
+      secret_env: CI_WEBHOOK_SECRET     # or secret: <value>
+
+Each event is queued in an outbox in the database, and bd makes a short
+best-effort attempt to send it at the end of the command. The body is the event envelope as JSON, signed with
+X-Beads-Signature: sha256=<HMAC-SHA256 of the body>. A failed delivery is
+retried with exponential backoff by later commands (or 'bd webhook deliver'),
+and moves to the dead-letter list after webhook.max-attempts attempts. Each
+delivery is claimed before it is sent, so concurrent bd commands never send
+the same one.
+
+Examples:
+  bd webhook list                 # Webhooks, and deliveries not yet sent
+  bd webhook list --status dead   # The dead-letter list
+  bd webhook test ci              # Send a test event to ci
+  bd webhook replay 42            # Send delivery 42 again
+  bd webhook replay --dead        # Retry every dead delivery
+  bd webhook deliver              # Send what is due (e.g. from cron)`,
+}
+
+var webhookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhooks and their deliveries",
+	Long: `List the configured webhooks and the deliveries in the outbox.
+
+By default only deliveries not yet sent (pending and dead) are shown; use
+--status to pick one state, or --all to include delivered ones.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		status, _ := cmd.Flags().GetString("status")
+		webhook, _ := cmd.Flags().GetString("webhook")
+		all, _ := cmd.Flags().GetBool("all")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		if status != "" && !types.WebhookDeliveryStatus(status).IsValid() {
+			FatalErrorRespectJSON("invalid --status %q (pending, delivered or dead)", status)
+		}
+		deliveries, err := store.ListWebhookDeliveries(rootCtx, types.WebhookDeliveryStatus(status), webhook, limit)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		if status == "" && !all {
+			deliveries = slices.DeleteFunc(deliveries, func(d *types.WebhookDelivery) bool {
+				return d.Status == types.WebhookDelivered
+			})
+		}
+
+		configured := make([]*webhooks.Webhook, 0)
+		for _, name := range webhookNames() {
+			configured = append(configured, loadWebhooks()[name])
+		}
+		if jsonOutput {
+			outputJSON(map[string]interface{}{
+				"webhooks":   configured,
+				"deliveries": deliveries,
+			})
+			return
+		}
+
+		if len(configured) == 0 {
+			fmt.Println("No webhooks configured (add them under webhooks: in config.yaml)")
+		}
+		for _, w := range configured {
+			events := "all events"
+			if len(w.Events) > 0 {
+				events = strings.Join(w.Events, ", ")
+			}
+			signed := ""
+			if w.SigningSecret() == "" {
+				signed = ui.RenderWarn(" (unsigned)")
+			}
+			fmt.Printf("%s  %s  %s%s\n", ui.RenderBold(w.Name), w.URL, ui.RenderMuted(events), signed)
+		}
+
+		if len(deliveries) == 0 {
+			if len(configured) > 0 {
+				fmt.Println("\nNo deliveries to show")
+			}
+			return
+		}
+		fmt.Println()
+		for _, d := range deliveries {
+			printWebhookDelivery(d)
+		}
+	},
+}
+
+func printWebhookDelivery(d *types.WebhookDelivery) {
+	var state string
+	switch d.Status {
+	case types.WebhookDelivered:
+		state = ui.RenderPass(string(d.Status))
+	case types.WebhookDead:
+		state = ui.RenderFail(string(d.Status))
+	default:
+		state = ui.RenderWarn(string(d.Status))
+	}
+	subject := d.Event
+	if d.IssueID != "" {
+		subject += " " + ui.RenderID(d.IssueID)
+	}
+	fmt.Printf("#%-5d %s  %s  %s  attempts %d", d.ID, state, d.Webhook, subject, d.Attempts)
+	if d.Status == types.WebhookPending && d.Attempts > 0 {
+		fmt.Printf(", next in %s", time.Until(d.NextAttemptAt).Round(time.Second))
+	}
+	fmt.Println()
+	if d.LastError != "" {
+		fmt.Printf("       %s\n", ui.RenderMuted(d.LastError))
+	}
+}
+
+var webhookTestCmd = &cobra.Command{
+	Use:   "test <name>",
+	Short: "Send a test event to a webhook",
+	Long: `Send a test event to a configured webhook and report the response.
+
+The delivery is signed and recorded in the outbox like any other, but it is
+not retried if it fails; use 'bd webhook replay' to send it again.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("webhook test")
+		w := loadWebhooks()[args[0]]
+		if w == nil {
+			FatalErrorRespectJSON("unknown webhook: %s (see bd webhook list)", args[0])
+		}
+
+		payload, err := json.Marshal(hooks.NewEnvelope(webhookTestEvent, actor, nil, nil))
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		d := &types.WebhookDelivery{Webhook: w.Name, Event: webhookTestEvent, Payload: string(payload)}
+		if err := store.EnqueueWebhookDelivery(rootCtx, d); err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		// Claim it so no concurrent delivery pass sends it too
+		client := webhookClient()
+		if _, err := store.ClaimWebhookDelivery(rootCtx, d.ID, time.Now(), webhooks.LeaseFor(client)); err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		status, sendErr := webhooks.Send(rootCtx, client, w, d)
+		webhooks.RetryPolicy{MaxAttempts: 1}.RecordAttempt(d, status, sendErr, time.Now().UTC())
+		if err := store.UpdateWebhookDelivery(rootCtx, d); err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+
+		if jsonOutput {
+			outputJSON(d)
+		} else if sendErr == nil {
+			fmt.Printf("%s Delivered test event #%d to %s (HTTP %d)\n", ui.RenderPass("✓"), d.ID, w.Name, status)
+		} else {
+			fmt.Printf("%s Test event #%d to %s failed: %v\n", ui.RenderFail("✗"), d.ID, w.Name, sendErr)
+		}
+		if sendErr != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var webhookReplayCmd = &cobra.Command{
+	Use:   "replay [delivery-id...]",
+	Short: "Send deliveries again",
+	Long: `Queue deliveries to be sent again, with a fresh set of attempts, then send
+everything due. Replays keep their delivery ID (the X-Beads-Delivery header),
+so receivers can tell them apart from new events.
+
+Use --dead to replay the whole dead-letter list, optionally only for one
+webhook with --webhook.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("webhook replay")
+		dead, _ := cmd.Flags().GetBool("dead")
+		webhook, _ := cmd.Flags().GetString("webhook")
+		if dead == (len(args) > 0) {
+			FatalErrorRespectJSON("specify delivery IDs or --dead")
+		}
+
+		var deliveries []*types.WebhookDelivery
+		if dead {
+			var err error
+			deliveries, err = store.ListWebhookDeliveries(rootCtx, types.WebhookDead, webhook, 1000)
+			if err != nil {
+				FatalErrorRespectJSON("%v", err)
+			}
+		}
+		for _, arg := range args {
+			id, err := strconv.ParseInt(strings.TrimPrefix(arg, "#"), 10, 64)
+			if err != nil {
+				FatalErrorRespectJSON("invalid delivery ID %q", arg)
+			}
+			d, err := store.GetWebhookDelivery(rootCtx, id)
+			if err != nil {
+				FatalErrorRespectJSON("%v", err)
+			}
+			if d == nil {
+				FatalErrorRespectJSON("webhook delivery %d not found", id)
+			}
+			deliveries = append(deliveries, d)
+		}
+
+		now := time.Now().UTC()
+		for _, d := range deliveries {
+			d.Status = types.WebhookPending
+			d.Attempts = 0
+			d.NextAttemptAt = now
+			d.LastError = ""
+			d.DeliveredAt = nil
+			if err := store.UpdateWebhookDelivery(rootCtx, d); err != nil {
+				FatalErrorRespectJSON("%v", err)
+			}
+		}
+		result, err := deliverWebhooks(rootCtx, store)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		printWebhookResult(fmt.Sprintf("Replayed %d deliveries", len(deliveries)), result)
+	},
+}
+
+var webhookDeliverCmd = &cobra.Command{
+	Use:   "deliver",
+	Short: "Send the deliveries that are due",
+	Long: `Send the deliveries in the outbox that are due, including retries of
+earlier failures. bd makes a short pass at the end of every write command;
+run this from cron to keep retrying while bd is idle, or to send what a slow
+endpoint held up.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		CheckReadonly("webhook deliver")
+		result, err := deliverWebhooks(rootCtx, store)
+		if err != nil {
+			FatalErrorRespectJSON("%v", err)
+		}
+		printWebhookResult("Delivery pass complete", result)
+	},
+}
+
+func printWebhookResult(summary string, result webhooks.Result) {
+	if jsonOutput {
+		outputJSON(map[string]int{
+			"delivered": result.Delivered,
+			"retrying":  result.Retrying,
+			"dead":      result.Dead,
+		})
+		return
+	}
+	fmt.Printf("%s %s: %d delivered, %d will be retried, %d dead\n",
+		ui.RenderPass("✓"), summary, result.Delivered, result.Retrying, result.Dead)
+}
+
+func init() {
+	webhookListCmd.Flags().String("status", "", "Only show deliveries with this status (pending, delivered, dead)")
+	webhookListCmd.Flags().String("webhook", "", "Only show deliveries to this webhook")
+	webhookListCmd.Flags().Bool("all", false, "Include delivered deliveries")
+	webhookListCmd.Flags().Int("limit", 50, "Maximum number of deliveries to show")
+
+	webhookReplayCmd.Flags().Bool("dead", false, "Replay every dead delivery")
+	webhookReplayCmd.Flags().String("webhook", "", "With --dead, only replay deliveries to this webhook")
+
+	webhookCmd.AddCommand(webhookListCmd)
+	webhookCmd.AddCommand(webhookTestCmd)
+	webhookCmd.AddCommand(webhookReplayCmd)
+	webhookCmd.AddCommand(webhookDeliverCmd)
+	rootCmd.AddCommand(webhookCmd)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -227,6 +229,15 @@ func TestSummarizeTier1_CancelledContext(t *testing.T) {
 
 func TestSummarizeTier1_WithAuditEnabled(t *testing.T) {
 	t.Setenv("ANTHROPIC_API_KEY", "")
+	// The audit log goes to .beads/interactions.jsonl: keep it out of the repo's .beads.
+	beadsDir := filepath.Join(t.TempDir(), ".beads")
+	if err := os.MkdirAll(beadsDir, 0750); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(beadsDir, "issues.jsonl"), []byte{}, 0644); err != nil {
+		t.Fatalf("write issues.jsonl: %v", err)
+	}
+	t.Setenv("BEADS_DIR", beadsDir)
 	client, err := newHaikuClient("test-key-fake")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -247,6 +258,9 @@ func TestSummarizeTier1_WithAuditEnabled(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error from cancelled context")
 	}
+	if _, err := os.Stat(filepath.Join(beadsDir, "interactions.jsonl")); err != nil {
+		t.Errorf("audit log was not written to BEADS_DIR: %v", err)
+	}
 }
 
 func TestCallWithRetry_ImmediateContextCancel(t *testing.T) {
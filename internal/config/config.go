@@ -184,6 +184,35 @@ func Initialize() error {
 	// Maps directory patterns to labels for automatic filtering in monorepos
 	v.SetDefault("directory.labels", map[string]string{})
 
+	// Weighted ready-work scoring (bd ready --sort score)
+	// Each factor is normalized to [0,1]; a weight of 0 disables it.
+	// Keep in sync with scoring.DefaultWeights.
+	v.SetDefault("ready.score.priority", 3.0)
+	v.SetDefault("ready.score.age", 1.0)
+	v.SetDefault("ready.score.due", 2.0)
+	v.SetDefault("ready.score.unblocks", 3.0)
+	v.SetDefault("ready.score.estimate", 1.0)
+
+	// Command gates (await_type cmd): per-run timeout and the exit code
+	// that keeps the gate pending (75 = EX_TEMPFAIL)
+	v.SetDefault("gate.cmd.timeout", "2m")
+	v.SetDefault("gate.cmd.retry-exit-code", 75)
+
+	// bd gate watch: remote gate checks back off up to this delay
+	v.SetDefault("gate.watch.max-backoff", "10m")
+
+	// .beads/hooks scripts get an event envelope on stdin ("issue" for the bare issue)
+	v.SetDefault("hooks.payload", "envelope")
+	// .beads/hooks scripts are killed after this long (per hook: hooks.<hook>.timeout)
+	v.SetDefault("hooks.timeout", "10s")
+
+	// Webhooks (configured under webhooks.<name>): per-request timeout and
+	// retry schedule for deliveries from the outbox
+	v.SetDefault("webhook.timeout", "5s")
+	v.SetDefault("webhook.max-attempts", 8)
+	v.SetDefault("webhook.backoff", "30s")
+	v.SetDefault("webhook.max-backoff", "1h")
+
 	// AI configuration defaults
 	v.SetDefault("ai.model", "claude-haiku-4-5-20251001")
 
@@ -445,6 +474,14 @@ func GetInt(key string) int {
 	return v.GetInt(key)
 }
 
+// GetFloat64 retrieves a float configuration value
+func GetFloat64(key string) float64 {
+	if v == nil {
+		return 0
+	}
+	return v.GetFloat64(key)
+}
+
 // GetDuration retrieves a duration configuration value
 func GetDuration(key string) time.Duration {
 	if v == nil {
@@ -511,6 +548,15 @@ func GetStringMapString(key string) map[string]string {
 	return v.GetStringMapString(key)
 }
 
+// UnmarshalKey decodes a nested configuration section (e.g., "views") into out.
+// out is left untouched when the key is not set.
+func UnmarshalKey(key string, out interface{}) error {
+	if v == nil || !v.IsSet(key) {
+		return nil
+	}
+	return v.UnmarshalKey(key, out)
+}
+
 // GetDirectoryLabels returns labels for the current working directory based on config.
 // It checks directory.labels config for matching patterns.
 // Returns nil if no labels are configured for the current directory.
@@ -68,6 +68,8 @@ func TestDefaults(t *testing.T) {
 		{"db", "", func(k string) interface{} { return GetString(k) }},
 		{"actor", "", func(k string) interface{} { return GetString(k) }},
 		{"flush-debounce", 30 * time.Second, func(k string) interface{} { return GetDuration(k) }},
+		{"ready.score.priority", 3.0, func(k string) interface{} { return GetFloat64(k) }},
+		{"ready.score.unblocks", 3.0, func(k string) interface{} { return GetFloat64(k) }},
 	}
 
 	for _, tt := range tests {
@@ -666,6 +668,50 @@ external_projects:
 	}
 }
 
+func TestUnmarshalKeyFromConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	configContent := `
+views:
+  mine:
+    query: "assignee=alice"
+    columns: [id, title]
+`
+	beadsDir := filepath.Join(tmpDir, ".beads")
+	if err := os.MkdirAll(beadsDir, 0750); err != nil {
+		t.Fatalf("failed to create .beads directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(beadsDir, "config.yaml"), []byte(configContent), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize() returned error: %v", err)
+	}
+
+	type view struct {
+		Query   string   `mapstructure:"query"`
+		Columns []string `mapstructure:"columns"`
+	}
+	var got map[string]*view
+	if err := UnmarshalKey("views", &got); err != nil {
+		t.Fatalf("UnmarshalKey(views) returned error: %v", err)
+	}
+	if got["mine"] == nil || got["mine"].Query != "assignee=alice" {
+		t.Fatalf("UnmarshalKey(views) = %+v, want mine with query", got)
+	}
+	if strings.Join(got["mine"].Columns, ",") != "id,title" {
+		t.Errorf("columns = %v, want [id title]", got["mine"].Columns)
+	}
+
+	// Unset keys leave the target untouched
+	var missing map[string]*view
+	if err := UnmarshalKey("no-such-section", &missing); err != nil || missing != nil {
+		t.Errorf("UnmarshalKey(unset) = %v, %v; want nil, nil", missing, err)
+	}
+}
+
 func TestResolveExternalProjectPath(t *testing.T) {
 	// Create a temporary directory structure
 	tmpDir := t.TempDir()
@@ -70,6 +70,29 @@ func ApplyAdvice(steps []*Step, advice []*AdviceRule) []*Step {
 	return applyAdviceWithGuard(steps, advice, originalIDs)
 }
 
+// ApplyAspect applies an aspect formula's advice to steps. The inserted steps
+// are attributed to the aspect (SourceFormula) rather than the step they
+// advise, so source tracing shows where woven-in steps came from.
+func ApplyAspect(steps []*Step, aspect *Formula) []*Step {
+	if len(aspect.Advice) == 0 {
+		return steps
+	}
+	originalIDs := collectStepIDs(steps)
+	result := ApplyAdvice(steps, aspect.Advice)
+
+	var mark func([]*Step)
+	mark = func(steps []*Step) {
+		for _, step := range steps {
+			if !originalIDs[step.ID] && step.SourceLocation == "advice" {
+				step.SourceFormula = aspect.Formula
+			}
+			mark(step.Children)
+		}
+	}
+	mark(result)
+	return result
+}
+
 // ApplyAdviceToOriginalOnly applies advice rules but only matches steps
 // whose IDs are in the originalIDs set. This prevents aspects from matching
 // steps they themselves inserted.
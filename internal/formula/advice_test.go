@@ -373,3 +373,28 @@ func getStepIDs(steps []*Step) []string {
 	}
 	return ids
 }
+
+func TestApplyAspect_SourceFormula(t *testing.T) {
+	steps := []*Step{
+		{ID: "design", Title: "Design", SourceFormula: "mol-feature", SourceLocation: "steps[0]"},
+	}
+	aspect := &Formula{
+		Formula: "security-audit",
+		Type:    TypeAspect,
+		Advice: []*AdviceRule{
+			{Target: "design", After: &AdviceStep{ID: "{step.id}-review", Title: "Review {step.id}"}},
+		},
+	}
+
+	result := ApplyAspect(steps, aspect)
+
+	if len(result) != 2 {
+		t.Fatalf("ApplyAspect() produced %d steps, want 2. Got IDs: %v", len(result), getStepIDs(result))
+	}
+	if result[0].SourceFormula != "mol-feature" {
+		t.Errorf("advised step source = %q, want mol-feature", result[0].SourceFormula)
+	}
+	if result[1].SourceFormula != "security-audit" || result[1].SourceLocation != "advice" {
+		t.Errorf("inserted step source = %q@%q, want security-audit@advice", result[1].SourceFormula, result[1].SourceLocation)
+	}
+}
@@ -104,7 +104,7 @@ var (
 	fieldPattern = regexp.MustCompile(`^(\w+(?:\.\w+)*)\s*([=!<>]+)\s*(.+)$`)
 
 	// children(step).all(status == 'complete')
-	aggregatePattern = regexp.MustCompile(`^(children|descendants|steps)\((\w+)\)\.(all|any|count)\((.+)\)(.*)$`)
+	aggregatePattern = regexp.MustCompile(`^(children|descendants|steps)\(([\w.-]+)\)\.(all|any|count)\((.+)\)(.*)$`)
 
 	// file.exists('go.mod')
 	fileExistsPattern = regexp.MustCompile(`^file\.exists\(['"](.+)['"]\)$`)
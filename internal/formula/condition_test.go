@@ -88,6 +88,12 @@ func TestParseCondition_AggregateConditions(t *testing.T) {
 			wantFunc: "any",
 			wantOver: "children",
 		},
+		{
+			name:     "children of dashed step ID",
+			expr:     "children(survey-workers).any(status == 'complete')",
+			wantFunc: "any",
+			wantOver: "children",
+		},
 		{
 			name:     "steps count",
 			expr:     "steps.complete >= 3",
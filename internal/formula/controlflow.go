@@ -12,6 +12,8 @@ package formula
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -181,7 +183,7 @@ func expandLoopWithVars(step *Step, vars map[string]string) ([]*Step, error) {
 		}
 	} else {
 		// Conditional loop: expand once with loop metadata
-		// The runtime executor will re-run until condition is met or max reached
+		// bd mol advance creates further iterations until the condition is met or max reached
 		iterSteps, err := expandLoopIteration(step, 1, nil)
 		if err != nil {
 			return nil, err
@@ -222,7 +224,7 @@ func expandLoopIteration(step *Step, iteration int, iterVars map[string]string)
 
 	for _, bodyStep := range step.Loop.Body {
 		// Create unique ID for this iteration
-		iterID := fmt.Sprintf("%s.iter%d.%s", step.ID, iteration, bodyStep.ID)
+		iterID := LoopStepID(step.ID, iteration, bodyStep.ID)
 
 		// Substitute loop variables in title and description
 		title := substituteLoopVars(bodyStep.Title, iterVars)
@@ -241,6 +243,7 @@ func expandLoopIteration(step *Step, iteration int, iterVars map[string]string)
 			Gate:           bodyStep.Gate,
 			Loop:           cloneLoopSpec(bodyStep.Loop), // Support nested loops
 			OnComplete:     cloneOnComplete(bodyStep.OnComplete),
+			MaxRetries:     bodyStep.MaxRetries,
 			SourceFormula:  bodyStep.SourceFormula,                                       // Preserve source
 			SourceLocation: fmt.Sprintf("%s.iter%d", bodyStep.SourceLocation, iteration), // Track iteration
 		}
@@ -317,7 +320,7 @@ func rewriteLoopDependencies(deps []string, loopID string, iteration int, bodySt
 	for i, dep := range deps {
 		if bodyStepIDs[dep] {
 			// Internal dependency - prefix with iteration context
-			result[i] = fmt.Sprintf("%s.iter%d.%s", loopID, iteration, dep)
+			result[i] = LoopStepID(loopID, iteration, dep)
 		} else {
 			// External dependency - preserve as-is
 			result[i] = dep
@@ -332,7 +335,7 @@ func expandLoopChildren(children []*Step, loopID string, iteration int, bodyStep
 	result := make([]*Step, len(children))
 	for i, child := range children {
 		clone := cloneStepDeep(child)
-		clone.ID = fmt.Sprintf("%s.iter%d.%s", loopID, iteration, child.ID)
+		clone.ID = LoopStepID(loopID, iteration, child.ID)
 		clone.DependsOn = rewriteLoopDependencies(child.DependsOn, loopID, iteration, bodyStepIDs)
 		clone.Needs = rewriteLoopDependencies(child.Needs, loopID, iteration, bodyStepIDs)
 
@@ -484,7 +487,8 @@ func applyBranchesWithMap(stepMap map[string]*Step, compose *ComposeRules) error
 // ApplyGates adds gate conditions to steps.
 // For each gate rule:
 //   - The target step gets a "gate:condition" label
-//   - At runtime, the patrol executor evaluates the condition
+//   - At cook time, the step gets a condition gate issue; bd mol advance
+//     closes it once the condition holds
 //
 // Returns a new steps slice with gate labels added.
 // The original steps slice is not modified.
@@ -543,6 +547,61 @@ func applyGatesWithMap(stepMap map[string]*Step, compose *ComposeRules) error {
 	return nil
 }
 
+// ParseGateLabel returns the condition of a "gate:{...}" label added by
+// ApplyGates. Other labels (including waits_for "gate:all-children") return
+// ok=false.
+func ParseGateLabel(label string) (condition string, ok bool) {
+	if !strings.HasPrefix(label, "gate:{") {
+		return "", false
+	}
+	var meta struct {
+		Condition string `json:"condition"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(label, "gate:")), &meta); err != nil || meta.Condition == "" {
+		return "", false
+	}
+	return meta.Condition, true
+}
+
+// ParseLoopLabel returns the until condition and max iterations of a
+// "loop:{...}" label added by ApplyLoops to the first step of a conditional
+// loop.
+func ParseLoopLabel(label string) (until string, maxIter int, ok bool) {
+	if !strings.HasPrefix(label, "loop:{") {
+		return "", 0, false
+	}
+	var meta struct {
+		Until string `json:"until"`
+		Max   int    `json:"max"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(label, "loop:")), &meta); err != nil || meta.Until == "" {
+		return "", 0, false
+	}
+	return meta.Until, meta.Max, true
+}
+
+// ParseLoopStepID splits the ID of an expanded loop body step
+// ("<loop>.iter<N>.<body>") into the loop ID, iteration and body step ID.
+// For nested loops the innermost loop is returned.
+func ParseLoopStepID(stepID string) (loopID string, iteration int, bodyID string, ok bool) {
+	m := loopStepIDPattern.FindStringSubmatch(stepID)
+	if m == nil {
+		return "", 0, "", false
+	}
+	iteration, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, "", false
+	}
+	return m[1], iteration, m[3], true
+}
+
+// LoopStepID returns the ID of a loop body step in the given iteration.
+func LoopStepID(loopID string, iteration int, bodyID string) string {
+	return fmt.Sprintf("%s.iter%d.%s", loopID, iteration, bodyID)
+}
+
+var loopStepIDPattern = regexp.MustCompile(`^(.+)\.iter(\d+)\.([^.]+(?:\.[^.]+)*)$`)
+
 // ApplyControlFlow applies all control flow operators in the correct order:
 // 1. Loops (expand iterations)
 // 2. Branches (wire fork-join dependencies)
@@ -978,3 +978,69 @@ func TestValidateLoopSpec_Range(t *testing.T) {
 		})
 	}
 }
+
+func TestRuntimeLabels_RoundTrip(t *testing.T) {
+	steps := []*Step{
+		{
+			ID:    "review-loop",
+			Title: "Review until approved",
+			Loop: &LoopSpec{
+				Until: "review.output.approved == true",
+				Max:   3,
+				Body: []*Step{
+					{ID: "review", Title: "Review"},
+					{ID: "fix", Title: "Fix", Needs: []string{"review"}},
+				},
+			},
+		},
+		{ID: "deploy", Title: "Deploy"},
+	}
+	compose := &ComposeRules{
+		Gate: []*GateRule{{Before: "deploy", Condition: "review.status == 'complete'"}},
+	}
+
+	result, err := ApplyControlFlow(steps, compose)
+	if err != nil {
+		t.Fatalf("ApplyControlFlow failed: %v", err)
+	}
+	stepMap := buildStepMap(result)
+
+	review := stepMap["review-loop.iter1.review"]
+	if review == nil {
+		t.Fatalf("loop body not expanded: %v", getStepIDs(result))
+	}
+	var until string
+	var maxIter int
+	for _, label := range review.Labels {
+		if u, m, ok := ParseLoopLabel(label); ok {
+			until, maxIter = u, m
+		}
+	}
+	if until != "review.output.approved == true" || maxIter != 3 {
+		t.Errorf("ParseLoopLabel = %q, %d", until, maxIter)
+	}
+
+	var condition string
+	for _, label := range stepMap["deploy"].Labels {
+		if c, ok := ParseGateLabel(label); ok {
+			condition = c
+		}
+	}
+	if condition != "review.status == 'complete'" {
+		t.Errorf("ParseGateLabel = %q", condition)
+	}
+	if _, ok := ParseGateLabel("gate:all-children"); ok {
+		t.Error("waits_for gate label should not parse as a condition")
+	}
+
+	loopID, iteration, bodyID, ok := ParseLoopStepID("outer.iter2.inner.iter3.fix")
+	if !ok || loopID != "outer.iter2.inner" || iteration != 3 || bodyID != "fix" {
+		t.Errorf("ParseLoopStepID = %q, %d, %q, %v", loopID, iteration, bodyID, ok)
+	}
+	if LoopStepID(loopID, iteration+1, bodyID) != "outer.iter2.inner.iter4.fix" {
+		t.Errorf("LoopStepID round trip failed")
+	}
+	if _, _, _, ok := ParseLoopStepID("deploy"); ok {
+		t.Error("plain step ID should not parse as a loop step")
+	}
+}
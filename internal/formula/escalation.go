@@ -0,0 +1,103 @@
+package formula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Escalation actions. Actions are written "<name>" or "<name>:<arg>".
+const (
+	// EscalatePriority raises the gate's priority: "priority:<0-4>".
+	EscalatePriority = "priority"
+
+	// EscalateAssign reassigns the gate: "assign:<assignee>".
+	EscalateAssign = "assign"
+
+	// EscalateWisp creates an escalation wisp pointing at the gate.
+	EscalateWisp = "wisp"
+
+	// EscalateNotify runs the gate hooks (on_gate_warn, on_gate_escalate)
+	// so they can notify the gate's waiters.
+	EscalateNotify = "notify"
+
+	// EscalateFail closes the gate with a failure reason, so
+	// conditional-blocks branches behind it fire.
+	EscalateFail = "fail"
+
+	// EscalateGT hands the gate to 'gt escalate' (the behaviour of gates
+	// without a policy).
+	EscalateGT = "gt"
+)
+
+// DefaultEscalationWarnAt is the fraction of a gate's timeout after which
+// the Warn actions of its escalation policy run.
+const DefaultEscalationWarnAt = 0.8
+
+// EscalationPolicy defines what happens to a gate that stalls. The Warn
+// actions run once the gate has been pending for WarnAt of its timeout; the
+// Actions run once it escalates: its check fails, or it is still pending
+// when the timeout expires. Each stage runs once per gate.
+type EscalationPolicy struct {
+	// WarnAt is the fraction of the timeout at which to warn (default 0.8).
+	WarnAt float64 `json:"warn_at,omitempty" toml:"warn_at,omitempty" mapstructure:"warn_at"`
+
+	// Warn lists the actions run at the warning stage.
+	Warn []string `json:"warn,omitempty" toml:"warn,omitempty" mapstructure:"warn"`
+
+	// Actions lists the actions run when the gate escalates.
+	Actions []string `json:"actions,omitempty" toml:"actions,omitempty" mapstructure:"actions"`
+}
+
+// WarnFraction returns WarnAt, or DefaultEscalationWarnAt when unset.
+func (p *EscalationPolicy) WarnFraction() float64 {
+	if p.WarnAt > 0 {
+		return p.WarnAt
+	}
+	return DefaultEscalationWarnAt
+}
+
+// Validate checks the warning fraction and every action.
+func (p *EscalationPolicy) Validate() error {
+	if p.WarnAt < 0 || p.WarnAt >= 1 {
+		return fmt.Errorf("warn_at must be between 0 and 1 (got %v)", p.WarnAt)
+	}
+	for _, action := range p.Warn {
+		name, _, err := ParseEscalationAction(action)
+		if err != nil {
+			return err
+		}
+		if name == EscalateFail {
+			return fmt.Errorf("escalation action %q is not allowed in warn", action)
+		}
+	}
+	for _, action := range p.Actions {
+		if _, _, err := ParseEscalationAction(action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseEscalationAction splits an escalation action into its name and
+// argument and checks both.
+func ParseEscalationAction(action string) (name, arg string, err error) {
+	name, arg, _ = strings.Cut(strings.TrimSpace(action), ":")
+	switch name {
+	case EscalatePriority:
+		if n, convErr := strconv.Atoi(arg); convErr != nil || n < 0 || n > 4 {
+			return "", "", fmt.Errorf("invalid escalation action %q: expected priority:<0-4>", action)
+		}
+	case EscalateAssign:
+		if arg == "" {
+			return "", "", fmt.Errorf("invalid escalation action %q: expected assign:<assignee>", action)
+		}
+	case EscalateWisp, EscalateNotify, EscalateFail, EscalateGT:
+		if arg != "" {
+			return "", "", fmt.Errorf("invalid escalation action %q: %s takes no argument", action, name)
+		}
+	default:
+		return "", "", fmt.Errorf("unknown escalation action %q (valid: priority:<n>, assign:<who>, wisp, notify, fail, gt)", action)
+	}
+	return name, arg, nil
+}
@@ -0,0 +1,71 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEscalationAction(t *testing.T) {
+	valid := map[string][2]string{
+		"priority:0":    {EscalatePriority, "0"},
+		"assign:oncall": {EscalateAssign, "oncall"},
+		"wisp":          {EscalateWisp, ""},
+		" notify ":      {EscalateNotify, ""},
+		"fail":          {EscalateFail, ""},
+		"gt":            {EscalateGT, ""},
+	}
+	for action, want := range valid {
+		name, arg, err := ParseEscalationAction(action)
+		if err != nil || name != want[0] || arg != want[1] {
+			t.Errorf("ParseEscalationAction(%q) = %q, %q, %v; want %q, %q", action, name, arg, err, want[0], want[1])
+		}
+	}
+
+	for _, action := range []string{"priority", "priority:9", "assign", "wisp:x", "page"} {
+		if _, _, err := ParseEscalationAction(action); err == nil {
+			t.Errorf("ParseEscalationAction(%q) should fail", action)
+		}
+	}
+}
+
+func TestEscalationPolicy_Parse(t *testing.T) {
+	p := NewParser()
+	f, err := p.ParseTOML([]byte(`
+formula = "mol-release"
+version = 1
+type = "workflow"
+
+[[steps]]
+id = "release"
+title = "Release"
+[steps.gate]
+type = "gh:run"
+timeout = "2h"
+[steps.gate.escalation]
+warn_at = 0.5
+warn = ["notify"]
+actions = ["priority:0", "fail"]
+
+[[steps.children]]
+id = "verify"
+title = "Verify"
+[steps.children.gate]
+type = "human"
+[steps.children.gate.escalation]
+warn = ["fail"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := f.Steps[0].Gate.Escalation
+	if policy == nil || policy.WarnFraction() != 0.5 || len(policy.Actions) != 2 {
+		t.Fatalf("Escalation = %+v, want warn_at 0.5 and 2 actions", policy)
+	}
+	err = f.Validate()
+	if err == nil || !strings.Contains(err.Error(), `(verify): gate.escalation: escalation action "fail" is not allowed in warn`) {
+		t.Errorf("Validate() = %v, want fail-in-warn error", err)
+	}
+	if (&EscalationPolicy{}).WarnFraction() != DefaultEscalationWarnAt {
+		t.Error("WarnFraction() should default to DefaultEscalationWarnAt")
+	}
+}
@@ -206,6 +206,7 @@ func expandStep(target *Step, template []*Step, depth int, vars map[string]strin
 			Type:           tmpl.Type,
 			Priority:       tmpl.Priority,
 			Assignee:       substituteVars(tmpl.Assignee, vars),
+			MaxRetries:     tmpl.MaxRetries,
 			SourceFormula:  tmpl.SourceFormula,  // Preserve source from template
 			SourceLocation: tmpl.SourceLocation, // Preserve source location
 		}
@@ -0,0 +1,191 @@
+package formula
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitPackageSource(t *testing.T) {
+	tests := []struct {
+		arg, source, constraint string
+	}{
+		{arg: "https://example.com/acme.git", source: "https://example.com/acme.git"},
+		{arg: "https://example.com/acme.git@^1.2", source: "https://example.com/acme.git", constraint: "^1.2"},
+		{arg: "git@github.com:acme/formulas.git", source: "git@github.com:acme/formulas.git"},
+		{arg: "git@github.com:acme/formulas.git@1.0.0", source: "git@github.com:acme/formulas.git", constraint: "1.0.0"},
+		{arg: "../shared@~2.0", source: "../shared", constraint: "~2.0"},
+		{arg: "/srv/me@home/formulas", source: "/srv/me@home/formulas"},
+	}
+	for _, tt := range tests {
+		source, constraint := SplitPackageSource(tt.arg)
+		if source != tt.source || constraint != tt.constraint {
+			t.Errorf("SplitPackageSource(%q) = %q, %q", tt.arg, source, constraint)
+		}
+	}
+}
+
+func TestInstallLocalPackage(t *testing.T) {
+	root := t.TempDir()
+	beadsDir := filepath.Join(root, "project", ".beads")
+	release := filepath.Join(root, "release")
+	base := filepath.Join(root, "base")
+
+	writeTestFile(t, filepath.Join(release, PackageManifestFile), `name = "release"
+version = "1.0.0"
+dir = "formulas"
+
+[dependencies.base]
+source = "../base"
+version = "^2"
+`)
+	writeTestFile(t, filepath.Join(release, "formulas", "mol-ship.formula.toml"), "formula = \"mol-ship\"\nversion = 1\n")
+	writeTestFile(t, filepath.Join(base, PackageManifestFile), "name = \"base\"\nversion = \"2.3.0\"\n")
+	writeTestFile(t, filepath.Join(base, "mol-base.formula.toml"), "formula = \"mol-base\"\nversion = 1\n")
+
+	in := NewPackageInstaller(beadsDir)
+	changes, err := in.Install(release, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v, want release and base", changes)
+	}
+
+	lock, err := LoadPackageLock(in.LockPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, dep := lock.Find("release"), lock.Find("base")
+	if rel == nil || !rel.Direct || rel.Hash == "" || strings.Join(rel.Dependencies, ",") != "base" {
+		t.Fatalf("release = %+v", rel)
+	}
+	if dep == nil || dep.Direct || dep.Version != "2.3.0" || dep.Constraint != "^2" {
+		t.Fatalf("base = %+v", dep)
+	}
+	dirs := lock.PackageDirs(in.LockPath())
+	if _, err := os.Stat(filepath.Join(dirs["release"], "mol-ship.formula.toml")); err != nil {
+		t.Errorf("release formulas not in cache: %v", err)
+	}
+
+	// The cached snapshot is independent of the source
+	writeTestFile(t, filepath.Join(base, "mol-base.formula.toml"), "formula = \"mol-base\"\nversion = 2\n")
+	cached, _ := os.ReadFile(filepath.Join(dirs["base"], "mol-base.formula.toml"))
+	if !strings.Contains(string(cached), "version = 1") {
+		t.Errorf("cached formula changed with its source: %s", cached)
+	}
+
+	// Reinstalling from the lock refuses a changed local source
+	if err := os.RemoveAll(filepath.Join(beadsDir, PackageCacheDir)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Sync(); err == nil || !strings.Contains(err.Error(), "changed since it was locked") {
+		t.Errorf("sync error = %v", err)
+	}
+
+	// Update picks up the change; a dependency outside its constraint fails
+	changes, err = in.Update(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Name != "base" || changes[0].OldVersion != "2.3.0" {
+		t.Errorf("update changes = %+v, want base re-pinned", changes)
+	}
+	writeTestFile(t, filepath.Join(base, PackageManifestFile), "name = \"base\"\nversion = \"3.0.0\"\n")
+	if _, err := in.Update(nil); err == nil || !strings.Contains(err.Error(), "does not satisfy ^2") {
+		t.Errorf("constraint error = %v", err)
+	}
+}
+
+func TestInstallGitPackage(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := runGit(repo, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(out)
+	}
+	release := func(version string, annotated bool) string {
+		t.Helper()
+		writeTestFile(t, filepath.Join(repo, PackageManifestFile), "name = \"acme\"\nversion = \""+version+"\"\n")
+		writeTestFile(t, filepath.Join(repo, "mol-acme.formula.toml"), "formula = \"mol-acme\"\nversion = 1\ndescription = \""+version+"\"\n")
+		git("add", "-A")
+		git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", version)
+		if annotated {
+			git("-c", "user.name=test", "-c", "user.email=test@example.com", "tag", "-a", "-m", version, "v"+version)
+		} else {
+			git("tag", "v"+version)
+		}
+		return git("rev-parse", "HEAD")
+	}
+	if err := os.MkdirAll(repo, 0750); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	v100 := release("1.0.0", false)
+	v110 := release("1.1.0", true)
+	release("2.0.0", false)
+	url := "file://" + repo
+
+	in := NewPackageInstaller(filepath.Join(root, ".beads"))
+	if _, err := in.Install(url, "^1.0"); err != nil {
+		t.Fatal(err)
+	}
+	lock, _ := LoadPackageLock(in.LockPath())
+	pkg := lock.Find("acme")
+	if pkg == nil || pkg.Version != "1.1.0" || pkg.Commit != v110 {
+		t.Fatalf("acme = %+v, want 1.1.0 at %s", pkg, v110)
+	}
+	data, err := os.ReadFile(filepath.Join(lock.PackageDirs(in.LockPath())["acme"], "mol-acme.formula.toml"))
+	if err != nil || !strings.Contains(string(data), `"1.1.0"`) {
+		t.Fatalf("cached formula = %s, %v", data, err)
+	}
+
+	outdated, err := in.Outdated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outdated) != 1 || outdated[0].Wanted != "1.1.0" || outdated[0].Latest != "2.0.0" {
+		t.Errorf("outdated = %+v", outdated)
+	}
+
+	// Pinning an exact version, then restoring a deleted cache from the lock
+	if _, err := in.Install(url, "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(in.BeadsDir, PackageCacheDir)); err != nil {
+		t.Fatal(err)
+	}
+	fetched, err := in.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, _ = LoadPackageLock(in.LockPath())
+	if len(fetched) != 1 || lock.Find("acme").Commit != v100 {
+		t.Errorf("sync fetched %+v, lock %+v", fetched, lock.Find("acme"))
+	}
+
+	if _, err := in.Install(url, "^3"); err == nil || !strings.Contains(err.Error(), "no version") {
+		t.Errorf("unsatisfiable constraint error = %v", err)
+	}
+
+	// A commit-pinned lock entry whose source is not a git URL is not fetched
+	lock.Find("acme").Source = repo
+	if err := lock.Save(in.LockPath()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(in.BeadsDir, PackageCacheDir)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Sync(); err == nil || !strings.Contains(err.Error(), "is not a git URL") {
+		t.Errorf("sync of a non-git source error = %v", err)
+	}
+}
@@ -0,0 +1,633 @@
+package formula
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// LintSeverity is the severity of a lint finding.
+type LintSeverity string
+
+const (
+	// LintError marks a formula that will fail to cook or pour correctly.
+	LintError LintSeverity = "error"
+
+	// LintWarning marks a likely mistake that does not break cooking.
+	LintWarning LintSeverity = "warning"
+)
+
+// Lint rules reported in LintIssue.Rule.
+const (
+	LintRuleParse              = "parse"
+	LintRuleExtendsCycle       = "extends-cycle"
+	LintRuleResolve            = "resolve"
+	LintRuleInvalid            = "invalid"
+	LintRuleTransform          = "transform"
+	LintRuleUnknownStep        = "unknown-step"
+	LintRuleDependencyCycle    = "dependency-cycle"
+	LintRuleUnreachableStep    = "unreachable-step"
+	LintRuleOrphanStep         = "orphan-step"
+	LintRuleUndeclaredVar      = "undeclared-var"
+	LintRuleUnusedVar          = "unused-var"
+	LintRuleInvalidCondition   = "invalid-condition"
+	LintRuleInvalidRange       = "invalid-range"
+	LintRuleParallelSequential = "parallel-sequential"
+	LintRuleUnmatchedPointcut  = "unmatched-pointcut"
+)
+
+// LintIssue is a single problem found by Lint.
+type LintIssue struct {
+	Severity LintSeverity `json:"severity"`
+	Rule     string       `json:"rule"`
+	Message  string       `json:"message"`
+	Step     string       `json:"step,omitempty"`
+	File     string       `json:"file,omitempty"`
+	Line     int          `json:"line,omitempty"`
+}
+
+// String formats the issue as "file:line: severity: message [rule]".
+func (i *LintIssue) String() string {
+	loc := i.File
+	if loc != "" && i.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, i.Line)
+	}
+	if loc != "" {
+		loc += ": "
+	}
+	return fmt.Sprintf("%s%s: %s [%s]", loc, i.Severity, i.Message, i.Rule)
+}
+
+// LintFile parses the formula file at path and lints it. A file that fails
+// to parse yields a single parse issue located at the syntax error.
+func (p *Parser) LintFile(path string) []*LintIssue {
+	f, err := p.ParseFile(path)
+	if err != nil {
+		return []*LintIssue{{
+			Severity: LintError,
+			Rule:     LintRuleParse,
+			Message:  err.Error(),
+			File:     path,
+			Line:     parseErrorLine(path, err),
+		}}
+	}
+	return p.Lint(f)
+}
+
+// Lint statically checks a formula the way bd cook will see it: after
+// extends are resolved and control flow, advice, expansions and aspects are
+// applied. It never modifies f. Issues are sorted by file and line.
+func (p *Parser) Lint(f *Formula) []*LintIssue {
+	l := &linter{p: p, root: f, files: make(map[string][]string)}
+	l.run()
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return l.issues
+}
+
+// linter holds the state of a single Lint run.
+type linter struct {
+	p      *Parser
+	root   *Formula
+	issues []*LintIssue
+	files  map[string][]string // Source lines by path, for locating issues
+}
+
+func (l *linter) run() {
+	resolved, err := l.p.resolve(l.root, false)
+	if err != nil {
+		rule := LintRuleResolve
+		if errors.Is(err, errCircularExtends) {
+			rule = LintRuleExtendsCycle
+		}
+		l.reportAt(LintError, rule, l.root.Source, l.findLine(l.root.Source, extendsLinePattern), "", err.Error())
+		return
+	}
+
+	l.checkValidate(resolved)
+	staticErrors := l.checkExpressions(resolved)
+
+	// Transform a copy so the parser's cached formulas stay untouched
+	steps := resolved.Steps
+	if !staticErrors && resolved.Type != TypeExpansion && resolved.Type != TypeAspect {
+		work := *resolved
+		if err := l.transform(&work); err != nil {
+			l.reportAt(LintError, LintRuleTransform, l.root.Source, 0, "", err.Error())
+		} else {
+			steps = work.Steps
+		}
+	}
+
+	l.checkVars(resolved, steps)
+	l.checkGraph(steps)
+	if resolved.Type != TypeAspect {
+		l.checkPointcuts(resolved, steps)
+	}
+}
+
+// transform applies the cook-time transformations, in the order bd cook
+// applies them.
+func (l *linter) transform(f *Formula) error {
+	steps, err := ApplyControlFlow(f.Steps, f.Compose)
+	if err != nil {
+		return fmt.Errorf("applying control flow: %w", err)
+	}
+	if len(f.Advice) > 0 {
+		steps = ApplyAdvice(steps, f.Advice)
+	}
+	if steps, err = ApplyInlineExpansions(steps, l.p); err != nil {
+		return fmt.Errorf("applying inline expansions: %w", err)
+	}
+	if f.Compose != nil && (len(f.Compose.Expand) > 0 || len(f.Compose.Map) > 0) {
+		if steps, err = ApplyExpansions(steps, f.Compose, l.p); err != nil {
+			return fmt.Errorf("applying expansions: %w", err)
+		}
+	}
+	if f.Compose != nil {
+		for _, name := range f.Compose.Aspects {
+			aspect, err := l.p.LoadByName(name)
+			if err != nil {
+				return fmt.Errorf("loading aspect %q: %w", name, err)
+			}
+			if aspect.Type != TypeAspect {
+				return fmt.Errorf("%q is not an aspect formula (type=%s)", name, aspect.Type)
+			}
+			steps = ApplyAspect(steps, aspect)
+		}
+	}
+	f.Steps = steps
+	return nil
+}
+
+// validateStepPattern extracts the step ID from a Validate error line.
+var validateStepPattern = regexp.MustCompile(`^\S+ \(([^)]+)\)`)
+
+// checkValidate reports Validate failures not covered by a dedicated rule.
+func (l *linter) checkValidate(f *Formula) {
+	err := f.Validate()
+	if err == nil {
+		return
+	}
+	msgs := strings.Split(strings.TrimPrefix(err.Error(), "formula validation failed:\n  - "), "\n  - ")
+	steps := stepIndex(f.Steps)
+	for _, msg := range msgs {
+		if strings.Contains(msg, "depends_on references unknown step") ||
+			strings.Contains(msg, "needs references unknown step") ||
+			strings.Contains(msg, "cannot set both parallel and sequential") {
+			continue
+		}
+		if m := validateStepPattern.FindStringSubmatch(msg); m != nil && steps[m[1]] != nil {
+			l.report(LintError, LintRuleInvalid, steps[m[1]], msg)
+			continue
+		}
+		l.reportAt(LintError, LintRuleInvalid, l.root.Source, 0, "", msg)
+	}
+}
+
+// checkExpressions checks step conditions, loop conditions and ranges,
+// compose gate conditions and on_complete modes. Returns true if any error
+// was reported, since the transformations would fail on the same input.
+func (l *linter) checkExpressions(f *Formula) bool {
+	before := len(l.issues)
+	walkSteps(f.Steps, func(step *Step) {
+		if step.Condition != "" {
+			if _, err := EvaluateStepCondition(step.Condition, nil); err != nil {
+				l.report(LintError, LintRuleInvalidCondition, step, fmt.Sprintf("step %q: %v", step.ID, err))
+			}
+		}
+		if step.Loop != nil {
+			if step.Loop.Until != "" {
+				if _, err := ParseCondition(step.Loop.Until); err != nil {
+					l.report(LintError, LintRuleInvalidCondition, step, fmt.Sprintf("step %q: loop until: %v", step.ID, err))
+				}
+			}
+			if step.Loop.Range != "" {
+				if err := ValidateRange(step.Loop.Range); err != nil {
+					l.report(LintError, LintRuleInvalidRange, step, fmt.Sprintf("step %q: loop range %q: %v", step.ID, step.Loop.Range, err))
+				}
+			}
+		}
+		if oc := step.OnComplete; oc != nil && oc.Parallel && oc.Sequential {
+			l.report(LintError, LintRuleParallelSequential, step, fmt.Sprintf("step %q: on_complete cannot set both parallel and sequential", step.ID))
+		}
+	})
+	if f.Compose != nil {
+		for _, gate := range f.Compose.Gate {
+			if _, err := ParseCondition(gate.Condition); err != nil {
+				line := l.findLine(l.root.Source, literalPattern(gate.Condition))
+				l.reportAt(LintError, LintRuleInvalidCondition, l.root.Source, line, gate.Before, fmt.Sprintf("compose gate before %q: %v", gate.Before, err))
+			}
+		}
+	}
+	for _, issue := range l.issues[before:] {
+		if issue.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVars reports {{vars}} that are used but not declared, and declared
+// vars that nothing uses.
+func (l *linter) checkVars(f *Formula, steps []*Step) {
+	used := make(map[string]bool)
+	var order []string
+	use := func(s string) {
+		for _, m := range varPattern.FindAllStringSubmatch(s, -1) {
+			if !used[m[1]] {
+				used[m[1]] = true
+				order = append(order, m[1])
+			}
+		}
+	}
+	use(f.Description)
+	visit := func(step *Step) {
+		use(step.Title)
+		use(step.Description)
+		use(step.Assignee)
+		use(step.Condition)
+		for _, label := range step.Labels {
+			use(label)
+		}
+		for _, v := range step.ExpandVars {
+			use(v)
+		}
+		if step.Loop != nil {
+			use(step.Loop.Until)
+			for _, m := range rangeVarPattern.FindAllStringSubmatch(step.Loop.Range, -1) {
+				if f.Vars[m[1]] != nil {
+					used[m[1]] = true
+				}
+			}
+		}
+		if step.OnComplete != nil {
+			for _, v := range step.OnComplete.Vars {
+				use(v)
+			}
+		}
+	}
+	walkSteps(steps, visit)
+	walkSteps(f.Template, visit)
+	if f.Compose != nil {
+		for _, gate := range f.Compose.Gate {
+			use(gate.Condition)
+		}
+	}
+
+	for _, name := range order {
+		if _, ok := f.Vars[name]; ok {
+			continue
+		}
+		line := l.findLine(l.root.Source, regexp.MustCompile(regexp.QuoteMeta("{{"+name+"}}")))
+		l.reportAt(LintWarning, LintRuleUndeclaredVar, l.root.Source, line, "", fmt.Sprintf("variable {{%s}} is used but not declared in vars", name))
+	}
+
+	var declared []string
+	for name := range f.Vars {
+		if !used[name] {
+			declared = append(declared, name)
+		}
+	}
+	sort.Strings(declared)
+	for _, name := range declared {
+		line := l.findLine(l.root.Source, varDeclPattern(name))
+		l.reportAt(LintWarning, LintRuleUnusedVar, l.root.Source, line, "", fmt.Sprintf("variable %q is declared but never used", name))
+	}
+}
+
+// checkGraph checks the dependency graph of the cooked steps: references to
+// unknown steps, cycles, steps stuck behind a cycle, and steps with no
+// dependencies in a group whose siblings are otherwise wired together.
+func (l *linter) checkGraph(steps []*Step) {
+	index := stepIndex(steps)
+	deps := make(map[string][]string)
+	linked := make(map[string]bool)
+	var ids []string
+	walkSteps(steps, func(step *Step) {
+		ids = append(ids, step.ID)
+		refs := append(append([]string{}, step.Needs...), step.DependsOn...)
+		if spec := ParseWaitsFor(step.WaitsFor); spec != nil && spec.SpawnerID != "" {
+			refs = append(refs, spec.SpawnerID)
+		}
+		for _, ref := range refs {
+			if index[ref] == nil {
+				l.report(LintError, LintRuleUnknownStep, step, fmt.Sprintf("step %q depends on unknown step %q", step.ID, ref))
+				continue
+			}
+			deps[step.ID] = append(deps[step.ID], ref)
+			linked[step.ID] = true
+			linked[ref] = true
+		}
+		if step.Gate != nil || step.WaitsFor != "" {
+			linked[step.ID] = true
+		}
+	})
+
+	// Cycles: depth-first search, reporting each distinct cycle once
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	inCycle := make(map[string]bool)
+	seenCycles := make(map[string]bool)
+	var stack []string
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, dep := range deps[id] {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != dep {
+					start--
+				}
+				cycle := append(append([]string{}, stack[start:]...), dep)
+				members := append([]string{}, cycle[:len(cycle)-1]...)
+				for _, m := range members {
+					inCycle[m] = true
+				}
+				sort.Strings(members)
+				if key := strings.Join(members, "\x00"); !seenCycles[key] {
+					seenCycles[key] = true
+					l.report(LintError, LintRuleDependencyCycle, index[dep], "dependency cycle: "+strings.Join(cycle, " -> "))
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+	}
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	// Steps outside a cycle that wait on one can never become ready
+	if len(inCycle) > 0 {
+		blockedBy := make(map[string]string)
+		var cycleReached func(id string) string
+		cycleReached = func(id string) string {
+			if inCycle[id] {
+				return id
+			}
+			if via, ok := blockedBy[id]; ok {
+				return via
+			}
+			blockedBy[id] = ""
+			for _, dep := range deps[id] {
+				if via := cycleReached(dep); via != "" {
+					blockedBy[id] = via
+					break
+				}
+			}
+			return blockedBy[id]
+		}
+		for _, id := range ids {
+			if inCycle[id] {
+				continue
+			}
+			if via := cycleReached(id); via != "" {
+				l.report(LintError, LintRuleUnreachableStep, index[id], fmt.Sprintf("step %q can never become ready: it waits on the dependency cycle through %q", id, via))
+			}
+		}
+	}
+
+	// Orphans: unwired steps among siblings that are otherwise wired together
+	var checkGroup func(group []*Step)
+	checkGroup = func(group []*Step) {
+		wired := 0
+		for _, step := range group {
+			if linked[step.ID] {
+				wired++
+			}
+		}
+		if len(group) > 2 && wired > 0 {
+			for _, step := range group {
+				if !linked[step.ID] && len(step.Children) == 0 {
+					l.report(LintWarning, LintRuleOrphanStep, step, fmt.Sprintf("step %q has no dependencies and nothing depends on it", step.ID))
+				}
+			}
+		}
+		for _, step := range group {
+			checkGroup(step.Children)
+		}
+	}
+	checkGroup(steps)
+}
+
+// checkPointcuts reports advice targets and aspect pointcuts that match no
+// cooked step.
+func (l *linter) checkPointcuts(f *Formula, steps []*Step) {
+	var all []*Step
+	walkSteps(steps, func(step *Step) { all = append(all, step) })
+	matchesGlob := func(pattern string) bool {
+		for _, step := range all {
+			if MatchGlob(pattern, step.ID) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, rule := range f.Advice {
+		if !matchesGlob(rule.Target) {
+			line := l.findLine(l.root.Source, targetPattern(rule.Target))
+			l.reportAt(LintWarning, LintRuleUnmatchedPointcut, l.root.Source, line, "", fmt.Sprintf("advice target %q matches no steps", rule.Target))
+		}
+	}
+	if f.Compose == nil {
+		return
+	}
+	for _, name := range f.Compose.Aspects {
+		aspect, err := l.p.LoadByName(name)
+		if err != nil || aspect.Type != TypeAspect {
+			continue // Reported by the transform stage
+		}
+		for _, rule := range aspect.Advice {
+			if !matchesGlob(rule.Target) {
+				line := l.findLine(aspect.Source, targetPattern(rule.Target))
+				l.reportAt(LintWarning, LintRuleUnmatchedPointcut, aspect.Source, line, "", fmt.Sprintf("aspect %q advice target %q matches no steps of %s", name, rule.Target, f.Formula))
+			}
+		}
+		for _, pc := range aspect.Pointcuts {
+			matched := false
+			for _, step := range all {
+				if MatchPointcut(pc, step) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				l.reportAt(LintWarning, LintRuleUnmatchedPointcut, aspect.Source, 0, "", fmt.Sprintf("aspect %q pointcut %s matches no steps of %s", name, describePointcut(pc), f.Formula))
+			}
+		}
+	}
+}
+
+// describePointcut renders a pointcut for messages.
+func describePointcut(pc *Pointcut) string {
+	var parts []string
+	if pc.Glob != "" {
+		parts = append(parts, "glob="+pc.Glob)
+	}
+	if pc.Type != "" {
+		parts = append(parts, "type="+pc.Type)
+	}
+	if pc.Label != "" {
+		parts = append(parts, "label="+pc.Label)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// report records an issue located at the definition of step.
+func (l *linter) report(severity LintSeverity, rule string, step *Step, msg string) {
+	file, line := l.stepLocation(step)
+	id := ""
+	if step != nil {
+		id = step.ID
+	}
+	l.reportAt(severity, rule, file, line, id, msg)
+}
+
+// reportAt records an issue at an explicit location.
+func (l *linter) reportAt(severity LintSeverity, rule, file string, line int, step, msg string) {
+	l.issues = append(l.issues, &LintIssue{
+		Severity: severity,
+		Rule:     rule,
+		Message:  msg,
+		Step:     step,
+		File:     file,
+		Line:     line,
+	})
+}
+
+// stepLocation finds the file and line where a step is defined. Steps
+// expanded from loop bodies are located at their body step.
+func (l *linter) stepLocation(step *Step) (string, int) {
+	if step == nil {
+		return l.root.Source, 0
+	}
+	file := l.root.Source
+	if step.SourceFormula != "" && step.SourceFormula != l.root.Formula {
+		if src, ok := l.p.cache[step.SourceFormula]; ok && src.Source != "" {
+			file = src.Source
+		}
+	}
+	id := step.ID
+	for {
+		if line := l.findLine(file, stepIDPattern(id)); line > 0 {
+			return file, line
+		}
+		_, _, body, ok := ParseLoopStepID(id)
+		if !ok {
+			return file, 0
+		}
+		id = body
+	}
+}
+
+// findLine returns the first line of file matching any of the patterns, or
+// 0 if none does.
+func (l *linter) findLine(file string, patterns ...*regexp.Regexp) int {
+	if file == "" {
+		return 0
+	}
+	lines, ok := l.files[file]
+	if !ok {
+		// #nosec G304 -- file is a formula source the parser already read
+		if data, err := os.ReadFile(file); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		l.files[file] = lines
+	}
+	for i, line := range lines {
+		for _, pattern := range patterns {
+			if pattern.MatchString(line) {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// extendsLinePattern matches the extends key in JSON and TOML sources.
+var extendsLinePattern = regexp.MustCompile(`^\s*"?extends"?\s*[:=]`)
+
+// stepIDPattern matches the line defining a step ID in JSON or TOML.
+func stepIDPattern(id string) *regexp.Regexp {
+	q := regexp.QuoteMeta(id)
+	return regexp.MustCompile(`"id"\s*:\s*"` + q + `"|^\s*id\s*=\s*["']` + q + `["']`)
+}
+
+// targetPattern matches the line defining an advice target.
+func targetPattern(target string) *regexp.Regexp {
+	q := regexp.QuoteMeta(target)
+	return regexp.MustCompile(`"target"\s*:\s*"` + q + `"|^\s*target\s*=\s*["']` + q + `["']`)
+}
+
+// varDeclPattern matches the declaration of a var in JSON or TOML.
+func varDeclPattern(name string) *regexp.Regexp {
+	q := regexp.QuoteMeta(name)
+	return regexp.MustCompile(`^\s*\[vars\.` + q + `\]|^\s*` + q + `\s*=|^\s*"` + q + `"\s*:`)
+}
+
+// literalPattern matches a string literal anywhere on a line.
+func literalPattern(s string) *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(s))
+}
+
+// walkSteps calls fn for every step, its children and its loop body.
+func walkSteps(steps []*Step, fn func(*Step)) {
+	for _, step := range steps {
+		fn(step)
+		walkSteps(step.Children, fn)
+		if step.Loop != nil {
+			walkSteps(step.Loop.Body, fn)
+		}
+	}
+}
+
+// stepIndex maps step IDs to steps, including children and loop bodies.
+func stepIndex(steps []*Step) map[string]*Step {
+	index := make(map[string]*Step)
+	walkSteps(steps, func(step *Step) {
+		if _, exists := index[step.ID]; !exists {
+			index[step.ID] = step
+		}
+	})
+	return index
+}
+
+// parseErrorLine returns the line of a TOML or JSON syntax error, or 0.
+func parseErrorLine(path string, err error) int {
+	var tomlErr toml.ParseError
+	if errors.As(err, &tomlErr) {
+		return tomlErr.Position.Line
+	}
+	var jsonErr *json.SyntaxError
+	if errors.As(err, &jsonErr) {
+		// #nosec G304 -- path is the formula file that failed to parse
+		data, readErr := os.ReadFile(path)
+		if readErr != nil || jsonErr.Offset > int64(len(data)) {
+			return 0
+		}
+		return strings.Count(string(data[:jsonErr.Offset]), "\n") + 1
+	}
+	return 0
+}
@@ -0,0 +1,329 @@
+package formula
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lintSource writes formula files into a temp search path and lints the
+// first one. Files are given as name -> contents.
+func lintSource(t *testing.T, files map[string]string, target string) []*LintIssue {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewParser(dir).LintFile(filepath.Join(dir, target))
+}
+
+// findIssue returns the first issue with the given rule, or nil.
+func findIssue(issues []*LintIssue, rule string) *LintIssue {
+	for _, issue := range issues {
+		if issue.Rule == rule {
+			return issue
+		}
+	}
+	return nil
+}
+
+func TestLint_Clean(t *testing.T) {
+	issues := lintSource(t, map[string]string{"mol-ok.formula.toml": `
+formula = "mol-ok"
+version = 1
+
+[vars.component]
+required = true
+
+[[steps]]
+id = "design"
+title = "Design {{component}}"
+
+[[steps]]
+id = "build"
+title = "Build"
+needs = ["design"]
+`}, "mol-ok.formula.toml")
+	if len(issues) != 0 {
+		t.Errorf("clean formula has issues: %v", issues)
+	}
+}
+
+func TestLint_Rules(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		rule     string
+		severity LintSeverity
+		line     int
+		message  string
+	}{
+		{
+			name: "unknown step",
+			source: `formula = "mol-x"
+version = 1
+
+[[steps]]
+id = "a"
+title = "A"
+
+[[steps]]
+id = "b"
+title = "B"
+needs = ["nope"]
+`,
+			rule: LintRuleUnknownStep, severity: LintError, line: 9, message: `unknown step "nope"`,
+		},
+		{
+			name: "dependency cycle",
+			source: `formula = "mol-x"
+version = 1
+
+[[steps]]
+id = "a"
+title = "A"
+needs = ["b"]
+
+[[steps]]
+id = "b"
+title = "B"
+needs = ["a"]
+`,
+			rule: LintRuleDependencyCycle, severity: LintError, line: 5, message: "a -> b -> a",
+		},
+		{
+			name: "orphan step",
+			source: `formula = "mol-x"
+version = 1
+
+[[steps]]
+id = "a"
+title = "A"
+
+[[steps]]
+id = "b"
+title = "B"
+needs = ["a"]
+
+[[steps]]
+id = "stray"
+title = "Stray"
+`,
+			rule: LintRuleOrphanStep, severity: LintWarning, line: 14, message: `"stray"`,
+		},
+		{
+			name: "undeclared var",
+			source: `formula = "mol-x"
+version = 1
+
+[[steps]]
+id = "a"
+title = "Deploy {{env}}"
+`,
+			rule: LintRuleUndeclaredVar, severity: LintWarning, line: 6, message: "{{env}}",
+		},
+		{
+			name: "unused var",
+			source: `formula = "mol-x"
+version = 1
+
+[vars]
+spare = "x"
+
+[[steps]]
+id = "a"
+title = "A"
+`,
+			rule: LintRuleUnusedVar, severity: LintWarning, line: 5, message: `"spare"`,
+		},
+		{
+			name: "invalid condition",
+			source: `formula = "mol-x"
+version = 1
+
+[vars]
+env = "prod"
+
+[[steps]]
+id = "a"
+title = "A {{env}}"
+condition = "env is prod"
+`,
+			rule: LintRuleInvalidCondition, severity: LintError, line: 8,
+		},
+		{
+			name: "invalid range",
+			source: `formula = "mol-x"
+version = 1
+
+[[steps]]
+id = "moves"
+title = "Moves"
+
+[steps.loop]
+range = "1...x"
+
+[[steps.loop.body]]
+id = "move"
+title = "Move"
+`,
+			rule: LintRuleInvalidRange, severity: LintError, line: 5,
+		},
+		{
+			name: "parallel and sequential",
+			source: `formula = "mol-x"
+version = 1
+
+[[steps]]
+id = "survey"
+title = "Survey"
+
+[steps.on_complete]
+for_each = "output.items"
+bond = "mol-arm"
+parallel = true
+sequential = true
+`,
+			rule: LintRuleParallelSequential, severity: LintError, line: 5,
+		},
+		{
+			name: "unmatched advice target",
+			source: `formula = "mol-x"
+version = 1
+
+[[steps]]
+id = "a"
+title = "A"
+
+[[advice]]
+target = "deploy.*"
+
+[advice.before]
+id = "check"
+title = "Check"
+`,
+			rule: LintRuleUnmatchedPointcut, severity: LintWarning, line: 9, message: `"deploy.*"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := lintSource(t, map[string]string{"mol-x.formula.toml": tt.source}, "mol-x.formula.toml")
+			issue := findIssue(issues, tt.rule)
+			if issue == nil {
+				t.Fatalf("no %s issue in %v", tt.rule, issues)
+			}
+			if issue.Severity != tt.severity {
+				t.Errorf("severity = %s, want %s", issue.Severity, tt.severity)
+			}
+			if issue.Line != tt.line {
+				t.Errorf("line = %d, want %d (%s)", issue.Line, tt.line, issue)
+			}
+			if !strings.Contains(issue.Message, tt.message) {
+				t.Errorf("message = %q, want it to contain %q", issue.Message, tt.message)
+			}
+			if !strings.HasSuffix(issue.File, "mol-x.formula.toml") {
+				t.Errorf("file = %q", issue.File)
+			}
+		})
+	}
+}
+
+func TestLint_CycleBlocksDependents(t *testing.T) {
+	issues := lintSource(t, map[string]string{"mol-x.formula.json": `{
+  "formula": "mol-x",
+  "version": 1,
+  "steps": [
+    {"id": "a", "title": "A", "needs": ["b"]},
+    {"id": "b", "title": "B", "needs": ["a"]},
+    {"id": "c", "title": "C", "needs": ["b"]}
+  ]
+}`}, "mol-x.formula.json")
+	issue := findIssue(issues, LintRuleUnreachableStep)
+	if issue == nil || issue.Step != "c" || issue.Line != 7 {
+		t.Errorf("unreachable issue = %v, want step c at line 7 (all: %v)", issue, issues)
+	}
+}
+
+func TestLint_ExtendsCycle(t *testing.T) {
+	issues := lintSource(t, map[string]string{
+		"mol-a.formula.toml": "formula = \"mol-a\"\nversion = 1\nextends = [\"mol-b\"]\n",
+		"mol-b.formula.toml": "formula = \"mol-b\"\nversion = 1\nextends = [\"mol-a\"]\n",
+	}, "mol-a.formula.toml")
+	if len(issues) != 1 || issues[0].Rule != LintRuleExtendsCycle {
+		t.Fatalf("issues = %v, want one extends-cycle", issues)
+	}
+	if issues[0].Line != 3 || !strings.Contains(issues[0].Message, "mol-a -> mol-b -> mol-a") {
+		t.Errorf("issue = %s", issues[0])
+	}
+}
+
+func TestLint_InheritedStepLocation(t *testing.T) {
+	issues := lintSource(t, map[string]string{
+		"mol-base.formula.toml":  "formula = \"mol-base\"\nversion = 1\n\n[[steps]]\nid = \"base\"\ntitle = \"Base\"\nneeds = [\"gone\"]\n",
+		"mol-child.formula.toml": "formula = \"mol-child\"\nversion = 1\nextends = [\"mol-base\"]\n\n[[steps]]\nid = \"extra\"\ntitle = \"Extra\"\nneeds = [\"base\"]\n",
+	}, "mol-child.formula.toml")
+	issue := findIssue(issues, LintRuleUnknownStep)
+	if issue == nil {
+		t.Fatalf("no unknown-step issue in %v", issues)
+	}
+	if !strings.HasSuffix(issue.File, "mol-base.formula.toml") || issue.Line != 5 {
+		t.Errorf("issue located at %s:%d, want mol-base.formula.toml:5", issue.File, issue.Line)
+	}
+}
+
+func TestLint_LoopBodyLocation(t *testing.T) {
+	issues := lintSource(t, map[string]string{"mol-x.formula.toml": `formula = "mol-x"
+version = 1
+
+[[steps]]
+id = "retry"
+title = "Retry"
+
+[steps.loop]
+count = 2
+
+[[steps.loop.body]]
+id = "attempt"
+title = "Attempt"
+needs = ["missing"]
+`}, "mol-x.formula.toml")
+	issue := findIssue(issues, LintRuleUnknownStep)
+	if issue == nil || issue.Line != 12 {
+		t.Errorf("loop body issue = %v, want line 12 (all: %v)", issue, issues)
+	}
+}
+
+func TestLint_ParseError(t *testing.T) {
+	tests := []struct {
+		file   string
+		source string
+		line   int
+	}{
+		{"mol-x.formula.toml", "formula = \"mol-x\"\n\nversion = = 1\n", 3},
+		{"mol-x.formula.json", "{\n  \"formula\": \"mol-x\",\n  \"version\": 1,\n  \"steps\": [,]\n}\n", 4},
+	}
+	for _, tt := range tests {
+		issues := lintSource(t, map[string]string{tt.file: tt.source}, tt.file)
+		if len(issues) != 1 || issues[0].Rule != LintRuleParse {
+			t.Errorf("%s: issues = %v, want one parse issue", tt.file, issues)
+			continue
+		}
+		if issues[0].Line != tt.line {
+			t.Errorf("%s: line = %d, want %d", tt.file, issues[0].Line, tt.line)
+		}
+	}
+}
+
+func TestLintIssue_String(t *testing.T) {
+	issue := &LintIssue{Severity: LintWarning, Rule: LintRuleUnusedVar, Message: "unused", File: "f.toml", Line: 3}
+	if got := issue.String(); got != "f.toml:3: warning: unused [unused-var]" {
+		t.Errorf("String() = %q", got)
+	}
+	issue.Line = 0
+	if got := issue.String(); got != "f.toml: warning: unused [unused-var]" {
+		t.Errorf("String() without line = %q", got)
+	}
+}
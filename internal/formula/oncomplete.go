@@ -0,0 +1,123 @@
+package formula
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Runtime support for on_complete for-each fan-out.
+//
+// Cooking records a step's OnCompleteSpec in an "on_complete:{...}" label on
+// the step issue. When the step closes, the for_each path is resolved against
+// the output recorded in the issue's metadata, and one Bond molecule is poured
+// per item with OnCompleteSpec.Vars substituted for that item.
+
+// onCompleteLabelPrefix marks the label carrying a step's OnCompleteSpec.
+const onCompleteLabelPrefix = "on_complete:"
+
+// maxLabelLength is the longest label the issue store accepts.
+const maxLabelLength = 255
+
+// OnCompleteLabel returns the "on_complete:{...}" label recording oc on a
+// cooked step issue.
+func OnCompleteLabel(oc *OnCompleteSpec) string {
+	data, _ := json.Marshal(oc)
+	return onCompleteLabelPrefix + string(data)
+}
+
+// ParseOnCompleteLabel returns the OnCompleteSpec recorded by OnCompleteLabel.
+// Labels without a for_each fan-out return ok=false.
+func ParseOnCompleteLabel(label string) (*OnCompleteSpec, bool) {
+	if !strings.HasPrefix(label, onCompleteLabelPrefix+"{") {
+		return nil, false
+	}
+	var oc OnCompleteSpec
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(label, onCompleteLabelPrefix)), &oc); err != nil {
+		return nil, false
+	}
+	if oc.ForEach == "" || oc.Bond == "" {
+		return nil, false
+	}
+	return &oc, true
+}
+
+// ResolveForEach resolves a for_each path ("output.<field>[.<nested>]")
+// against a step's output and returns the collection to iterate.
+func ResolveForEach(output map[string]interface{}, path string) ([]interface{}, error) {
+	fieldPath, ok := strings.CutPrefix(path, "output.")
+	if !ok || fieldPath == "" {
+		return nil, fmt.Errorf("for_each must start with 'output.' (got %q)", path)
+	}
+
+	var value interface{} = output
+	for _, field := range strings.Split(fieldPath, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: %q is not an object", path, field)
+		}
+		if value, ok = m[field]; !ok {
+			return nil, fmt.Errorf("%s: step output has no field %q", path, field)
+		}
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected an array, got %T", path, value)
+	}
+	return items, nil
+}
+
+// itemPlaceholderPattern matches {item}, {item.field[.nested]} and {index}.
+var itemPlaceholderPattern = regexp.MustCompile(`\{(item(?:\.\w+)*|index)\}`)
+
+// SubstituteItemVars returns the for-each variable bindings for one item,
+// replacing {item}, {item.field} and {index} placeholders. Referencing a
+// field the item does not have is an error.
+func SubstituteItemVars(vars map[string]string, item interface{}, index int) (map[string]string, error) {
+	result := make(map[string]string, len(vars))
+	for name, tmpl := range vars {
+		var missing string
+		result[name] = itemPlaceholderPattern.ReplaceAllStringFunc(tmpl, func(match string) string {
+			ref := match[1 : len(match)-1]
+			if ref == "index" {
+				return strconv.Itoa(index)
+			}
+			value := item
+			for _, field := range strings.Split(ref, ".")[1:] {
+				m, ok := value.(map[string]interface{})
+				if !ok {
+					missing = ref
+					return match
+				}
+				if value, ok = m[field]; !ok {
+					missing = ref
+					return match
+				}
+			}
+			return formatItemValue(value)
+		})
+		if missing != "" {
+			return nil, fmt.Errorf("var %s: item %d has no %s", name, index, missing)
+		}
+	}
+	return result, nil
+}
+
+// formatItemValue renders an output value as a variable value: strings as
+// is, numbers without trailing zeros, and anything else as JSON.
+func formatItemValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,133 @@
+package formula
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOnCompleteLabel_RoundTrip(t *testing.T) {
+	oc := &OnCompleteSpec{
+		ForEach:    "output.workers",
+		Bond:       "mol-worker-arm",
+		Vars:       map[string]string{"name": "{item.name}"},
+		Sequential: true,
+	}
+	label := OnCompleteLabel(oc)
+	got, ok := ParseOnCompleteLabel(label)
+	if !ok {
+		t.Fatalf("ParseOnCompleteLabel(%q) failed", label)
+	}
+	if !reflect.DeepEqual(got, oc) {
+		t.Errorf("round trip = %+v, want %+v", got, oc)
+	}
+
+	for _, label := range []string{"on_complete:", "on_complete:{}", "loop:{\"max\":2}", "template"} {
+		if _, ok := ParseOnCompleteLabel(label); ok {
+			t.Errorf("ParseOnCompleteLabel(%q) should fail", label)
+		}
+	}
+}
+
+func TestResolveForEach(t *testing.T) {
+	var output map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+		"workers": [{"name": "ace"}, {"name": "nux"}],
+		"survey": {"rigs": ["a", "b", "c"]},
+		"count": 3
+	}`), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    int
+		wantErr string
+	}{
+		{"output.workers", 2, ""},
+		{"output.survey.rigs", 3, ""},
+		{"output.missing", 0, "no field"},
+		{"output.count", 0, "expected an array"},
+		{"output.count.x", 0, "not an object"},
+		{"workers", 0, "must start with 'output.'"},
+	}
+	for _, tt := range tests {
+		items, err := ResolveForEach(output, tt.path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ResolveForEach(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ResolveForEach(%q): %v", tt.path, err)
+			continue
+		}
+		if len(items) != tt.want {
+			t.Errorf("ResolveForEach(%q) = %d items, want %d", tt.path, len(items), tt.want)
+		}
+	}
+
+	if _, err := ResolveForEach(nil, "output.workers"); err == nil {
+		t.Error("ResolveForEach on empty output should fail")
+	}
+}
+
+func TestSubstituteItemVars(t *testing.T) {
+	var item interface{}
+	if err := json.Unmarshal([]byte(`{"name": "ace", "rig": {"id": "gastown"}, "slots": 2, "tags": ["x"]}`), &item); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := SubstituteItemVars(map[string]string{
+		"name":  "{item.name}",
+		"ref":   "{item.rig.id}/{item.name}-{index}",
+		"slots": "{item.slots}",
+		"tags":  "{item.tags}",
+		"plain": "{{kept}}",
+	}, item, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"name":  "ace",
+		"ref":   "gastown/ace-4",
+		"slots": "2",
+		"tags":  `["x"]`,
+		"plain": "{{kept}}",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SubstituteItemVars = %v, want %v", got, want)
+	}
+
+	got, err = SubstituteItemVars(map[string]string{"v": "{item}"}, "plain", 0)
+	if err != nil || got["v"] != "plain" {
+		t.Errorf("primitive item = %v, %v", got, err)
+	}
+
+	if _, err := SubstituteItemVars(map[string]string{"v": "{item.missing}"}, item, 0); err == nil {
+		t.Error("missing item field should fail")
+	}
+}
+
+func TestValidate_OnComplete_TooLong(t *testing.T) {
+	f := &Formula{
+		Formula: "mol-long",
+		Version: 1,
+		Type:    TypeWorkflow,
+		Steps: []*Step{{
+			ID:    "survey",
+			Title: "Survey",
+			OnComplete: &OnCompleteSpec{
+				ForEach: "output.items",
+				Bond:    "mol-arm",
+				Vars:    map[string]string{"description": strings.Repeat("x", maxLabelLength)},
+			},
+		}},
+	}
+	err := f.Validate()
+	if err == nil || !strings.Contains(err.Error(), "too long") {
+		t.Errorf("Validate error = %v, want too long", err)
+	}
+}
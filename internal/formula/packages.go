@@ -0,0 +1,337 @@
+package formula
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Formula packages bundle formulas under a versioned name so they can be
+// shared across repositories. Packages are installed into a cache next to a
+// lock file (.beads/packages and .beads/formulas.lock for a project,
+// ~/.beads/... for the user) that pins each package to an exact commit or
+// content hash. The parser resolves "<package>/<formula>" names against the
+// pinned versions, and falls back to installed packages for plain names
+// not found in the search paths.
+
+const (
+	// PackageManifestFile is the manifest at the root of a formula package.
+	PackageManifestFile = "formula-package.toml"
+
+	// PackageLockFile is the lock file name, stored in a .beads directory.
+	PackageLockFile = "formulas.lock"
+
+	// PackageCacheDir is the directory, next to the lock file, holding
+	// installed package contents.
+	PackageCacheDir = "packages"
+)
+
+// PackageManifest describes a formula package (formula-package.toml).
+//
+//	name = "acme-release"
+//	version = "1.4.0"
+//	description = "Release workflows"
+//	dir = "formulas"                 # optional, defaults to the package root
+//
+//	[dependencies.acme-base]
+//	source = "https://github.com/acme/base-formulas.git"
+//	version = "^2.0"
+type PackageManifest struct {
+	Name         string                        `toml:"name" json:"name"`
+	Version      string                        `toml:"version" json:"version"`
+	Description  string                        `toml:"description,omitempty" json:"description,omitempty"`
+	Dir          string                        `toml:"dir,omitempty" json:"dir,omitempty"`
+	Dependencies map[string]*PackageDependency `toml:"dependencies,omitempty" json:"dependencies,omitempty"`
+}
+
+// PackageDependency is a package another package depends on.
+type PackageDependency struct {
+	// Source is a git URL or a local path (relative to the depending package).
+	Source string `toml:"source" json:"source"`
+
+	// Version is a version constraint (see Constraint). Empty means latest.
+	Version string `toml:"version,omitempty" json:"version,omitempty"`
+}
+
+// packageNamePattern restricts package names to path- and ID-safe characters.
+var packageNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// pinPattern matches the pins in a lock file: full git commit SHAs (SHA-1
+// or SHA-256) and content hashes.
+var pinPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// LoadPackageManifest reads and validates the manifest in a package directory.
+func LoadPackageManifest(dir string) (*PackageManifest, error) {
+	path := filepath.Join(dir, PackageManifestFile)
+	// #nosec G304 -- path is inside a package directory chosen by the user
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s not found in %s: not a formula package", PackageManifestFile, dir)
+		}
+		return nil, err
+	}
+	var m PackageManifest
+	if _, err := toml.Decode(string(data), &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Validate checks the manifest's name, version, dir and dependencies.
+func (m *PackageManifest) Validate() error {
+	if !packageNamePattern.MatchString(m.Name) {
+		return fmt.Errorf("invalid package name %q (lowercase letters, digits, '.', '_' and '-')", m.Name)
+	}
+	if _, err := ParseVersion(m.Version); err != nil {
+		return fmt.Errorf("package %s: %w", m.Name, err)
+	}
+	if m.Dir != "" && (filepath.IsAbs(m.Dir) || strings.HasPrefix(filepath.Clean(m.Dir), "..")) {
+		return fmt.Errorf("package %s: dir %q must be inside the package", m.Name, m.Dir)
+	}
+	for name, dep := range m.Dependencies {
+		if !packageNamePattern.MatchString(name) {
+			return fmt.Errorf("package %s: invalid dependency name %q", m.Name, name)
+		}
+		if dep == nil || dep.Source == "" {
+			return fmt.Errorf("package %s: dependency %s needs a source", m.Name, name)
+		}
+		if _, err := ParseConstraint(dep.Version); err != nil {
+			return fmt.Errorf("package %s: dependency %s: %w", m.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// PackageLock is the contents of a formulas.lock file.
+type PackageLock struct {
+	Version  int              `toml:"version" json:"version"`
+	Packages []*LockedPackage `toml:"package" json:"packages"`
+}
+
+// LockedPackage pins an installed package.
+type LockedPackage struct {
+	Name    string `toml:"name" json:"name"`
+	Version string `toml:"version" json:"version"`
+
+	// Source is where the package was installed from: a git URL or an
+	// absolute local path.
+	Source string `toml:"source" json:"source"`
+
+	// Constraint is the version constraint the package was requested with.
+	Constraint string `toml:"constraint,omitempty" json:"constraint,omitempty"`
+
+	// Commit pins git packages; Hash pins local packages by content.
+	Commit string `toml:"commit,omitempty" json:"commit,omitempty"`
+	Hash   string `toml:"hash,omitempty" json:"hash,omitempty"`
+
+	// Path is the package's formula directory, relative to the lock file.
+	Path string `toml:"path" json:"path"`
+
+	// Direct is true for packages installed explicitly rather than as a
+	// dependency of another package.
+	Direct bool `toml:"direct,omitempty" json:"direct,omitempty"`
+
+	// Dependencies lists the names of the packages this one depends on.
+	Dependencies []string `toml:"dependencies,omitempty" json:"dependencies,omitempty"`
+}
+
+// LoadPackageLock reads a lock file. A missing file is an empty lock.
+func LoadPackageLock(path string) (*PackageLock, error) {
+	lock := &PackageLock{Version: 1}
+	// #nosec G304 -- path is a lock file in a .beads directory
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := toml.Decode(string(data), lock); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, pkg := range lock.Packages {
+		if err := pkg.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return lock, nil
+}
+
+// Validate checks the fields of a locked package that are used as paths or
+// passed to git. Lock files are committed, so they are not trusted.
+func (pkg *LockedPackage) Validate() error {
+	if !packageNamePattern.MatchString(pkg.Name) {
+		return fmt.Errorf("invalid package name %q (lowercase letters, digits, '.', '_' and '-')", pkg.Name)
+	}
+	if (pkg.Commit == "") == (pkg.Hash == "") {
+		return fmt.Errorf("package %s: needs exactly one of commit and hash", pkg.Name)
+	}
+	if pkg.Commit != "" && !pinPattern.MatchString(pkg.Commit) {
+		return fmt.Errorf("package %s: commit %q is not a full hex SHA", pkg.Name, pkg.Commit)
+	}
+	if pkg.Hash != "" && !pinPattern.MatchString(pkg.Hash) {
+		return fmt.Errorf("package %s: hash %q is not a hex digest", pkg.Name, pkg.Hash)
+	}
+	if strings.HasPrefix(pkg.Source, "-") {
+		return fmt.Errorf("package %s: invalid source %q", pkg.Name, pkg.Source)
+	}
+	if pkg.Path == "" || filepath.IsAbs(pkg.Path) || strings.HasPrefix(filepath.Clean(filepath.FromSlash(pkg.Path)), "..") {
+		return fmt.Errorf("package %s: path %q must be inside the lock file's directory", pkg.Name, pkg.Path)
+	}
+	for _, dep := range pkg.Dependencies {
+		if !packageNamePattern.MatchString(dep) {
+			return fmt.Errorf("package %s: invalid dependency name %q", pkg.Name, dep)
+		}
+	}
+	return nil
+}
+
+// Save writes the lock file, packages sorted by name.
+func (l *PackageLock) Save(path string) error {
+	sort.Slice(l.Packages, func(i, j int) bool { return l.Packages[i].Name < l.Packages[j].Name })
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by bd formula install. Do not edit by hand.\n")
+	encoder := toml.NewEncoder(&buf)
+	encoder.Indent = ""
+	if err := encoder.Encode(l); err != nil {
+		return fmt.Errorf("encoding lock file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// Find returns the locked package with the given name, or nil.
+func (l *PackageLock) Find(name string) *LockedPackage {
+	for _, pkg := range l.Packages {
+		if pkg.Name == name {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// Put adds or replaces a locked package.
+func (l *PackageLock) Put(pkg *LockedPackage) {
+	for i, existing := range l.Packages {
+		if existing.Name == pkg.Name {
+			l.Packages[i] = pkg
+			return
+		}
+	}
+	l.Packages = append(l.Packages, pkg)
+}
+
+// PackageDirs returns the formula directories of the locked packages, by
+// package name, resolved against the lock file's directory.
+func (l *PackageLock) PackageDirs(lockPath string) map[string]string {
+	dirs := make(map[string]string)
+	for _, pkg := range l.Packages {
+		dirs[pkg.Name] = filepath.Join(filepath.Dir(lockPath), filepath.FromSlash(pkg.Path))
+	}
+	return dirs
+}
+
+// installedPackage is a package the parser can load formulas from.
+type installedPackage struct {
+	name string
+	dir  string
+}
+
+// defaultPackages returns the packages pinned by the project, user and
+// orchestrator lock files, in that order. A package pinned in an earlier
+// lock file shadows one of the same name in a later one.
+func defaultPackages() []installedPackage {
+	var lockPaths []string
+	if cwd, err := os.Getwd(); err == nil {
+		lockPaths = append(lockPaths, filepath.Join(cwd, ".beads", PackageLockFile))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		lockPaths = append(lockPaths, filepath.Join(home, ".beads", PackageLockFile))
+	}
+	if gtRoot := os.Getenv("GT_ROOT"); gtRoot != "" {
+		lockPaths = append(lockPaths, filepath.Join(gtRoot, ".beads", PackageLockFile))
+	}
+
+	seen := make(map[string]bool)
+	var pkgs []installedPackage
+	for _, lockPath := range lockPaths {
+		lock, err := LoadPackageLock(lockPath)
+		if err != nil {
+			continue // A broken lock file is reported by bd formula install
+		}
+		dirs := lock.PackageDirs(lockPath)
+		for _, pkg := range lock.Packages {
+			if seen[pkg.Name] {
+				continue
+			}
+			seen[pkg.Name] = true
+			pkgs = append(pkgs, installedPackage{name: pkg.Name, dir: dirs[pkg.Name]})
+		}
+	}
+	return pkgs
+}
+
+// SetPackages replaces the installed packages the parser resolves against,
+// as package name -> formula directory. Plain names fall back to packages in
+// name order.
+func (p *Parser) SetPackages(dirs map[string]string) {
+	p.packages = nil
+	for name, dir := range dirs {
+		p.packages = append(p.packages, installedPackage{name: name, dir: dir})
+	}
+	sort.Slice(p.packages, func(i, j int) bool { return p.packages[i].name < p.packages[j].name })
+}
+
+// SplitPackageRef splits a "<package>/<formula>" reference. ok is false for
+// plain formula names.
+func SplitPackageRef(ref string) (pkg, name string, ok bool) {
+	pkg, name, ok = strings.Cut(ref, "/")
+	if !ok || !packageNamePattern.MatchString(pkg) || name == "" || strings.Contains(name, "/") {
+		return "", ref, false
+	}
+	return pkg, name, true
+}
+
+// findPackageFormula looks a formula up in the installed packages. A
+// qualified "<package>/<formula>" name only looks in that package.
+func (p *Parser) findPackageFormula(ref string) (string, error) {
+	pkgName, name, qualified := SplitPackageRef(ref)
+	for _, pkg := range p.packages {
+		if qualified && pkg.name != pkgName {
+			continue
+		}
+		if path := findFormulaInDir(pkg.dir, name); path != "" {
+			return path, nil
+		}
+		if qualified {
+			return "", fmt.Errorf("formula %q not found in package %s (%s)", name, pkgName, pkg.dir)
+		}
+	}
+	if qualified {
+		return "", fmt.Errorf("formula %q: package %q is not installed (see bd formula install)", ref, pkgName)
+	}
+	return "", nil
+}
+
+// findFormulaInDir returns the TOML or JSON file for a formula in dir.
+func findFormulaInDir(dir, name string) string {
+	for _, ext := range []string{FormulaExtTOML, FormulaExtJSON} {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
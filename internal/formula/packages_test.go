@@ -0,0 +1,237 @@
+package formula
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPackageManifestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       PackageManifest
+		wantErr string
+	}{
+		{name: "valid", m: PackageManifest{Name: "acme-release", Version: "1.0.0", Dir: "formulas"}},
+		{name: "bad name", m: PackageManifest{Name: "Acme", Version: "1.0.0"}, wantErr: "invalid package name"},
+		{name: "bad version", m: PackageManifest{Name: "acme", Version: "1.0"}, wantErr: "invalid version"},
+		{name: "dir escapes", m: PackageManifest{Name: "acme", Version: "1.0.0", Dir: "../x"}, wantErr: "inside the package"},
+		{name: "dependency without source", m: PackageManifest{Name: "acme", Version: "1.0.0",
+			Dependencies: map[string]*PackageDependency{"base": {Version: "^1"}}}, wantErr: "needs a source"},
+		{name: "bad dependency constraint", m: PackageManifest{Name: "acme", Version: "1.0.0",
+			Dependencies: map[string]*PackageDependency{"base": {Source: "../base", Version: "^x"}}}, wantErr: "invalid version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.m.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPackageLockRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".beads", PackageLockFile)
+
+	lock, err := LoadPackageLock(path)
+	if err != nil || lock.Version != 1 || len(lock.Packages) != 0 {
+		t.Fatalf("missing lock = %+v, %v", lock, err)
+	}
+	commit := strings.Repeat("deadbeef", 5)
+	lock.Put(&LockedPackage{Name: "zeta", Version: "1.0.0", Source: "/src/zeta", Hash: strings.Repeat("a", 64), Path: "packages/zeta/aaaaaaaaaaaa"})
+	lock.Put(&LockedPackage{Name: "alpha", Version: "2.1.0", Source: "https://example.com/alpha.git", Commit: commit,
+		Path: "packages/alpha/deadbeefdead/formulas", Direct: true, Dependencies: []string{"zeta"}})
+	lock.Put(&LockedPackage{Name: "zeta", Version: "1.1.0", Source: "/src/zeta", Hash: strings.Repeat("d", 64), Path: "packages/zeta/dddddddddddd"})
+	if err := lock.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPackageLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Packages) != 2 || loaded.Packages[0].Name != "alpha" {
+		t.Fatalf("packages = %+v, want alpha then zeta", loaded.Packages)
+	}
+	if zeta := loaded.Find("zeta"); zeta == nil || zeta.Version != "1.1.0" {
+		t.Errorf("zeta = %+v, want replaced 1.1.0", zeta)
+	}
+	if alpha := loaded.Find("alpha"); !alpha.Direct || alpha.Commit != commit || len(alpha.Dependencies) != 1 {
+		t.Errorf("alpha = %+v", alpha)
+	}
+	dirs := loaded.PackageDirs(path)
+	if want := filepath.Join(filepath.Dir(path), "packages", "alpha", "deadbeefdead", "formulas"); dirs["alpha"] != want {
+		t.Errorf("alpha dir = %s, want %s", dirs["alpha"], want)
+	}
+}
+
+func TestLoadPackageLockRejectsUnsafeEntries(t *testing.T) {
+	commit := strings.Repeat("ab", 20)
+	tests := []struct {
+		name    string
+		entry   string
+		wantErr string
+	}{
+		{"traversal name", `name = "../../evil"
+commit = "` + commit + `"
+source = "https://example.com/x.git"
+path = "packages/x"`, "invalid package name"},
+		{"option commit", `name = "x"
+commit = "--upload-pack=touch pwned"
+source = "https://example.com/x.git"
+path = "packages/x"`, "not a full hex SHA"},
+		{"short commit", `name = "x"
+commit = "deadbeef"
+source = "https://example.com/x.git"
+path = "packages/x"`, "not a full hex SHA"},
+		{"option source", `name = "x"
+commit = "` + commit + `"
+source = "--upload-pack=touch pwned;.git"
+path = "packages/x"`, "invalid source"},
+		{"escaping path", `name = "x"
+commit = "` + commit + `"
+source = "https://example.com/x.git"
+path = "../../etc"`, "must be inside"},
+		{"no pin", `name = "x"
+source = "/src/x"
+path = "packages/x"`, "exactly one of commit and hash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), PackageLockFile)
+			if err := os.WriteFile(path, []byte("version = 1\n\n[[package]]\n"+tt.entry+"\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadPackageLock(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPackageLock error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitPackageRef(t *testing.T) {
+	tests := []struct {
+		ref, pkg, name string
+		ok             bool
+	}{
+		{ref: "acme/mol-release", pkg: "acme", name: "mol-release", ok: true},
+		{ref: "mol-release", name: "mol-release"},
+		{ref: "a/b/c", name: "a/b/c"},
+		{ref: "Acme/x", name: "Acme/x"},
+		{ref: "acme/", name: "acme/"},
+	}
+	for _, tt := range tests {
+		pkg, name, ok := SplitPackageRef(tt.ref)
+		if pkg != tt.pkg || name != tt.name || ok != tt.ok {
+			t.Errorf("SplitPackageRef(%q) = %q, %q, %v", tt.ref, pkg, name, ok)
+		}
+	}
+}
+
+func TestParserResolvesPackageFormulas(t *testing.T) {
+	root := t.TempDir()
+	local := filepath.Join(root, "formulas")
+	acme := filepath.Join(root, "acme")
+	other := filepath.Join(root, "other")
+
+	writeTestFile(t, filepath.Join(acme, "mol-base.formula.toml"), `formula = "mol-base"
+version = 1
+
+[[steps]]
+id = "acme-setup"
+title = "Acme setup"
+`)
+	writeTestFile(t, filepath.Join(other, "mol-base.formula.toml"), `formula = "mol-base"
+version = 1
+
+[[steps]]
+id = "other-setup"
+title = "Other setup"
+`)
+	writeTestFile(t, filepath.Join(local, "mol-qualified.formula.toml"), `formula = "mol-qualified"
+version = 1
+extends = ["other/mol-base"]
+
+[[steps]]
+id = "work"
+title = "Work"
+needs = ["other-setup"]
+`)
+	writeTestFile(t, filepath.Join(local, "mol-plain.formula.toml"), `formula = "mol-plain"
+version = 1
+extends = ["mol-base"]
+
+[[steps]]
+id = "work"
+title = "Work"
+`)
+	writeTestFile(t, filepath.Join(local, "mol-missing.formula.toml"), `formula = "mol-missing"
+version = 1
+extends = ["nope/mol-base"]
+
+[[steps]]
+id = "work"
+title = "Work"
+`)
+
+	newParser := func() *Parser {
+		p := NewParser(local)
+		p.SetPackages(map[string]string{"acme": acme, "other": other})
+		return p
+	}
+
+	// A qualified name only looks in its package
+	p := newParser()
+	f, err := p.LoadByName("mol-qualified")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := p.Resolve(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Steps[0].ID != "other-setup" {
+		t.Errorf("first step = %s, want other-setup", resolved.Steps[0].ID)
+	}
+
+	// Plain names fall back to packages in name order
+	p = newParser()
+	f, err = p.LoadByName("mol-plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved, err = p.Resolve(f); err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Steps[0].ID != "acme-setup" {
+		t.Errorf("first step = %s, want acme-setup", resolved.Steps[0].ID)
+	}
+
+	p = newParser()
+	f, err = p.LoadByName("mol-missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p.Resolve(f); err == nil || !strings.Contains(err.Error(), `package "nope" is not installed`) {
+		t.Errorf("missing package error = %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package formula
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -35,6 +36,9 @@ type Parser struct {
 
 	// resolvingChain tracks the order of formulas being resolved (for error messages).
 	resolvingChain []string
+
+	// packages are the installed formula packages, searched after searchPaths.
+	packages []installedPackage
 }
 
 // NewParser creates a new formula parser.
@@ -50,6 +54,7 @@ func NewParser(searchPaths ...string) *Parser {
 		cache:          make(map[string]*Formula),
 		resolvingSet:   make(map[string]bool),
 		resolvingChain: nil,
+		packages:       defaultPackages(),
 	}
 }
 
@@ -88,6 +93,22 @@ func (p *Parser) ParseFile(path string) (*Formula, error) {
 		return cached, nil
 	}
 
+	formula, err := p.parseFile(path, absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	p.cache[absPath] = formula
+
+	// Also cache by name for extends resolution
+	p.cache[formula.Formula] = formula
+
+	return formula, nil
+}
+
+// parseFile reads and parses a formula file without caching it. path is
+// used in error messages.
+func (p *Parser) parseFile(path, absPath string) (*Formula, error) {
 	// Read and parse the file
 	// #nosec G304 -- absPath comes from controlled search paths or explicit user input
 	data, err := os.ReadFile(absPath)
@@ -111,11 +132,6 @@ func (p *Parser) ParseFile(path string) (*Formula, error) {
 	// Set source tracing info on all steps (gt-8tmz.18)
 	SetSourceInfo(formula)
 
-	p.cache[absPath] = formula
-
-	// Also cache by name for extends resolution
-	p.cache[formula.Formula] = formula
-
 	return formula, nil
 }
 
@@ -155,14 +171,23 @@ func (p *Parser) ParseTOML(data []byte) (*Formula, error) {
 	return &formula, nil
 }
 
+// errCircularExtends is returned (wrapped) by Resolve for extends loops.
+var errCircularExtends = errors.New("circular extends detected")
+
 // Resolve fully resolves a formula, processing extends and expansions.
 // Returns a new formula with all inheritance applied.
 func (p *Parser) Resolve(formula *Formula) (*Formula, error) {
+	return p.resolve(formula, true)
+}
+
+// resolve implements Resolve. With validate=false the merged formula is
+// returned without structural validation, for Lint to report on.
+func (p *Parser) resolve(formula *Formula, validate bool) (*Formula, error) {
 	// Check for cycles
 	if p.resolvingSet[formula.Formula] {
 		// Build the cycle chain for a clear error message
 		chain := append(p.resolvingChain, formula.Formula)
-		return nil, fmt.Errorf("circular extends detected: %s", strings.Join(chain, " -> "))
+		return nil, fmt.Errorf("%w: %s", errCircularExtends, strings.Join(chain, " -> "))
 	}
 	p.resolvingSet[formula.Formula] = true
 	p.resolvingChain = append(p.resolvingChain, formula.Formula)
@@ -173,8 +198,10 @@ func (p *Parser) Resolve(formula *Formula) (*Formula, error) {
 
 	// If no extends, just validate and return
 	if len(formula.Extends) == 0 {
-		if err := formula.Validate(); err != nil {
-			return nil, err
+		if validate {
+			if err := formula.Validate(); err != nil {
+				return nil, err
+			}
 		}
 		return formula, nil
 	}
@@ -199,7 +226,7 @@ func (p *Parser) Resolve(formula *Formula) (*Formula, error) {
 		}
 
 		// Resolve parent recursively
-		parent, err = p.Resolve(parent)
+		parent, err = p.resolve(parent, validate)
 		if err != nil {
 			return nil, fmt.Errorf("resolve parent %s: %w", parentName, err)
 		}
@@ -230,8 +257,10 @@ func (p *Parser) Resolve(formula *Formula) (*Formula, error) {
 		merged.Description = formula.Description
 	}
 
-	if err := merged.Validate(); err != nil {
-		return nil, err
+	if validate {
+		if err := merged.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
 	return merged, nil
@@ -245,6 +274,16 @@ func (p *Parser) loadFormula(name string) (*Formula, error) {
 		return cached, nil
 	}
 
+	// Package references ("<package>/<formula>") resolve against the
+	// pinned package only
+	if _, _, ok := SplitPackageRef(name); ok {
+		path, err := p.findPackageFormula(name)
+		if err != nil {
+			return nil, err
+		}
+		return p.parsePackageFile(path, name)
+	}
+
 	// Search for the formula file - try TOML first, then JSON
 	extensions := []string{FormulaExtTOML, FormulaExtJSON}
 	for _, dir := range p.searchPaths {
@@ -256,9 +295,35 @@ func (p *Parser) loadFormula(name string) (*Formula, error) {
 		}
 	}
 
+	// Fall back to installed packages
+	if path, err := p.findPackageFormula(name); err == nil && path != "" {
+		return p.ParseFile(path)
+	}
+
 	return nil, fmt.Errorf("formula %q not found in search paths", name)
 }
 
+// parsePackageFile parses a formula loaded by package reference and caches
+// it under the reference. The plain name is left to the search paths, so a
+// pinned package formula does not shadow a local one of the same name.
+func (p *Parser) parsePackageFile(path, ref string) (*Formula, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve path: %w", err)
+	}
+	if cached, ok := p.cache[absPath]; ok {
+		p.cache[ref] = cached
+		return cached, nil
+	}
+	formula, err := p.parseFile(path, absPath)
+	if err != nil {
+		return nil, err
+	}
+	p.cache[absPath] = formula
+	p.cache[ref] = formula
+	return formula, nil
+}
+
 // LoadByName loads a formula by name from search paths.
 // This is the public API for loading formulas used by expansion operators.
 func (p *Parser) LoadByName(name string) (*Formula, error) {
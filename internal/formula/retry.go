@@ -0,0 +1,29 @@
+package formula
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxRetriesLabelPrefix marks the label recording a step's MaxRetries on the
+// cooked step issue, read back by bd mol retry.
+const maxRetriesLabelPrefix = "max_retries:"
+
+// MaxRetriesLabel returns the "max_retries:<n>" label for a step's retry
+// policy.
+func MaxRetriesLabel(maxRetries int) string {
+	return maxRetriesLabelPrefix + strconv.Itoa(maxRetries)
+}
+
+// ParseMaxRetriesLabel returns the retry limit recorded by MaxRetriesLabel.
+func ParseMaxRetriesLabel(label string) (int, bool) {
+	value, ok := strings.CutPrefix(label, maxRetriesLabelPrefix)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
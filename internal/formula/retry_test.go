@@ -0,0 +1,49 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaxRetriesLabel_RoundTrip(t *testing.T) {
+	label := MaxRetriesLabel(3)
+	got, ok := ParseMaxRetriesLabel(label)
+	if !ok || got != 3 {
+		t.Errorf("ParseMaxRetriesLabel(%q) = %d, %v; want 3, true", label, got, ok)
+	}
+
+	for _, label := range []string{"max_retries:", "max_retries:x", "max_retries:-1", "retries:2"} {
+		if _, ok := ParseMaxRetriesLabel(label); ok {
+			t.Errorf("ParseMaxRetriesLabel(%q) should fail", label)
+		}
+	}
+}
+
+func TestMaxRetries_Parse(t *testing.T) {
+	p := NewParser()
+	f, err := p.ParseTOML([]byte(`
+formula = "mol-retry"
+version = 1
+type = "workflow"
+
+[[steps]]
+id = "deploy"
+title = "Deploy"
+max_retries = 2
+
+[[steps.children]]
+id = "verify"
+title = "Verify"
+max_retries = -1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Steps[0].MaxRetries != 2 {
+		t.Errorf("MaxRetries = %d, want 2", f.Steps[0].MaxRetries)
+	}
+	err = f.Validate()
+	if err == nil || !strings.Contains(err.Error(), "(verify): max_retries must not be negative") {
+		t.Errorf("Validate() = %v, want negative max_retries error", err)
+	}
+}
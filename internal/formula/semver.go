@@ -0,0 +1,254 @@
+package formula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version (major.minor.patch[-prerelease]) of a
+// formula package.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+// ParseVersion parses a full semantic version, with an optional "v" prefix.
+// Build metadata (+...) is ignored.
+func ParseVersion(s string) (Version, error) {
+	v, parts, err := parsePartialVersion(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if parts != 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected major.minor.patch", s)
+	}
+	return v, nil
+}
+
+// parsePartialVersion parses "1", "1.2" or "1.2.3[-pre]", returning how many
+// numeric parts were given.
+func parsePartialVersion(s string) (Version, int, error) {
+	var v Version
+	str := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(str, '+'); i >= 0 {
+		str = str[:i]
+	}
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		v.Prerelease = str[i+1:]
+		str = str[:i]
+		if v.Prerelease == "" {
+			return Version{}, 0, fmt.Errorf("invalid version %q: empty prerelease", s)
+		}
+	}
+	fields := strings.Split(str, ".")
+	if len(fields) > 3 || str == "" {
+		return Version{}, 0, fmt.Errorf("invalid version %q", s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return Version{}, 0, fmt.Errorf("invalid version %q", s)
+		}
+		*nums[i] = n
+	}
+	if v.Prerelease != "" && len(fields) != 3 {
+		return Version{}, 0, fmt.Errorf("invalid version %q: prerelease needs major.minor.patch", s)
+	}
+	return v, len(fields), nil
+}
+
+// String formats the version without a "v" prefix.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v is lower than, equal to or higher than o.
+// A prerelease sorts before its release.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// comparePrerelease compares dot-separated prerelease identifiers, numeric
+// identifiers numerically.
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// Constraint is a set of version bounds, all of which must hold.
+//
+// Supported forms (combine with spaces or commas):
+//
+//	"" / "*" / "latest"   any release
+//	1.2.3 / =1.2.3        exactly 1.2.3
+//	1.2 / 1               1.2.x / 1.x.x
+//	^1.2.3                >=1.2.3 <2.0.0 (^0.2.3 is <0.3.0)
+//	~1.2.3                >=1.2.3 <1.3.0
+//	>=1.0.0 >1 <=2 <2.1   comparisons
+type Constraint struct {
+	raw    string
+	bounds []versionBound
+	exact  bool // Pinned to one version; allows prereleases
+}
+
+type versionBound struct {
+	op string // ">=", ">", "<=", "<", "="
+	v  Version
+}
+
+// ParseConstraint parses a version constraint.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" || c.raw == "*" || c.raw == "latest" {
+		return c, nil
+	}
+	for _, term := range strings.FieldsFunc(c.raw, func(r rune) bool { return r == ',' || r == ' ' }) {
+		bounds, exact, err := parseConstraintTerm(term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		c.bounds = append(c.bounds, bounds...)
+		c.exact = c.exact || exact
+	}
+	return c, nil
+}
+
+func parseConstraintTerm(term string) ([]versionBound, bool, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, prefix) {
+			op = prefix
+			term = strings.TrimPrefix(term, prefix)
+			break
+		}
+	}
+	v, parts, err := parsePartialVersion(term)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// upper returns the first version past the given number of fixed parts
+	upper := func(fixed int) Version {
+		switch fixed {
+		case 1:
+			return Version{Major: v.Major + 1}
+		case 2:
+			return Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	lower := versionBound{">=", Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, Prerelease: v.Prerelease}}
+
+	switch op {
+	case ">=", ">", "<=", "<":
+		return []versionBound{{op, v}}, false, nil
+	case "^":
+		fixed := 1
+		if v.Major == 0 {
+			fixed = 2
+			if v.Minor == 0 && parts == 3 {
+				fixed = 3
+			}
+		}
+		if parts < fixed {
+			fixed = parts
+		}
+		return []versionBound{lower, {"<", upper(fixed)}}, false, nil
+	case "~":
+		fixed := 2
+		if parts == 1 {
+			fixed = 1
+		}
+		return []versionBound{lower, {"<", upper(fixed)}}, false, nil
+	}
+	// Bare or "=" version: exact when complete, otherwise a wildcard
+	if parts == 3 {
+		return []versionBound{{"=", v}}, true, nil
+	}
+	return []versionBound{lower, {"<", upper(parts)}}, false, nil
+}
+
+// Match reports whether v satisfies the constraint. Prereleases only match
+// exact constraints.
+func (c *Constraint) Match(v Version) bool {
+	if v.Prerelease != "" && !c.exact {
+		return false
+	}
+	for _, b := range c.bounds {
+		cmp := v.Compare(b.v)
+		ok := false
+		switch b.op {
+		case "=":
+			ok = cmp == 0
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the constraint as written, or "*" for any version.
+func (c *Constraint) String() string {
+	if c.raw == "" {
+		return "*"
+	}
+	return c.raw
+}
@@ -0,0 +1,94 @@
+package formula
+
+import (
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1.2.3", want: "1.2.3"},
+		{in: "v1.2.3", want: "1.2.3"},
+		{in: "1.2.3-rc.1", want: "1.2.3-rc.1"},
+		{in: "1.2.3+build.5", want: "1.2.3"},
+		{in: "1.2", wantErr: true},
+		{in: "1.2.x", wantErr: true},
+		{in: "1.2.3-", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := ParseVersion(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if err == nil && v.String() != tt.want {
+				t.Errorf("ParseVersion(%q) = %s, want %s", tt.in, v, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	ordered := []string{"0.9.0", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0", "1.0.1", "1.10.0", "2.0.0"}
+	for i := 0; i+1 < len(ordered); i++ {
+		a, _ := ParseVersion(ordered[i])
+		b, _ := ParseVersion(ordered[i+1])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("expected %s < %s", a, b)
+		}
+		if a.Compare(a) != 0 {
+			t.Errorf("expected %s == %s", a, a)
+		}
+	}
+}
+
+func TestConstraintMatch(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{constraint: "", match: []string{"0.1.0", "9.9.9"}, noMatch: []string{"1.0.0-rc.1"}},
+		{constraint: "latest", match: []string{"2.0.0"}},
+		{constraint: "1.2.3", match: []string{"1.2.3"}, noMatch: []string{"1.2.4"}},
+		{constraint: "=1.2.3-rc.1", match: []string{"1.2.3-rc.1"}, noMatch: []string{"1.2.3"}},
+		{constraint: "1.2", match: []string{"1.2.0", "1.2.9"}, noMatch: []string{"1.3.0", "1.1.9"}},
+		{constraint: "1", match: []string{"1.0.0", "1.9.0"}, noMatch: []string{"2.0.0"}},
+		{constraint: "^1.2.3", match: []string{"1.2.3", "1.9.0"}, noMatch: []string{"1.2.2", "2.0.0"}},
+		{constraint: "^0.2.3", match: []string{"0.2.3", "0.2.9"}, noMatch: []string{"0.3.0"}},
+		{constraint: "^0.0.3", match: []string{"0.0.3"}, noMatch: []string{"0.0.4"}},
+		{constraint: "^1.2", match: []string{"1.2.0", "1.5.0"}, noMatch: []string{"2.0.0"}},
+		{constraint: "~1.2.3", match: []string{"1.2.3", "1.2.9"}, noMatch: []string{"1.3.0"}},
+		{constraint: "~1", match: []string{"1.5.0"}, noMatch: []string{"2.0.0"}},
+		{constraint: ">=1.0 <2", match: []string{"1.0.0", "1.9.9"}, noMatch: []string{"0.9.0", "2.0.0"}},
+		{constraint: ">1.0.0, <=1.2.0", match: []string{"1.0.1", "1.2.0"}, noMatch: []string{"1.0.0", "1.2.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.match {
+				if v, _ := ParseVersion(s); !c.Match(v) {
+					t.Errorf("%q should match %s", tt.constraint, s)
+				}
+			}
+			for _, s := range tt.noMatch {
+				if v, _ := ParseVersion(s); c.Match(v) {
+					t.Errorf("%q should not match %s", tt.constraint, s)
+				}
+			}
+		})
+	}
+
+	for _, bad := range []string{"^", ">=x", "1.2.3.4", "~1.2-rc"} {
+		if _, err := ParseConstraint(bad); err == nil {
+			t.Errorf("ParseConstraint(%q) should fail", bad)
+		}
+	}
+}
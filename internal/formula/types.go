@@ -242,6 +242,10 @@ type Step struct {
 	// Used for runtime expansion over step output (the for-each construct).
 	OnComplete *OnCompleteSpec `json:"on_complete,omitempty" toml:"on_complete,omitempty"`
 
+	// MaxRetries caps how many times bd mol retry (or rewind) may rerun this
+	// step once it has run. 0 means no limit.
+	MaxRetries int `json:"max_retries,omitempty" toml:"max_retries,omitempty"`
+
 	// Source tracing fields: track where this step came from.
 	// These are set during parsing/transformation and copied to Issues during cooking.
 
@@ -258,14 +262,21 @@ type Step struct {
 // When a step has a Gate, bd cook creates a gate issue that blocks the step.
 // The gate must be closed (manually or via watchers) to unblock the step.
 type Gate struct {
-	// Type is the condition type: gh:run, gh:pr, timer, human, mail.
+	// Type is the condition type: gh:run, gh:pr, gl:pipeline, gl:mr, timer,
+	// human, mail, bead, query, cmd, approval.
 	Type string `json:"type"`
 
-	// ID is the condition identifier (e.g., workflow name for gh:run).
+	// ID is the condition identifier (e.g., workflow name for gh:run, the
+	// shell command for cmd, "<query> | count<op><n>" for query, "<n> of
+	// <approver>,..." for approval).
 	ID string `json:"id,omitempty"`
 
 	// Timeout is how long to wait before escalation (e.g., "1h", "24h").
 	Timeout string `json:"timeout,omitempty"`
+
+	// Escalation is what happens when the gate stalls or fails; it overrides
+	// the gate.escalation config for the gate's type.
+	Escalation *EscalationPolicy `json:"escalation,omitempty"`
 }
 
 // LoopSpec defines iteration over a body of steps.
@@ -357,7 +368,8 @@ type BranchRule struct {
 }
 
 // GateRule defines a condition that must be satisfied before a step proceeds.
-// Gates are evaluated at runtime by the patrol executor.
+// Cooking creates a condition gate blocking the step; bd mol advance closes
+// it once the condition holds.
 type GateRule struct {
 	// Before is the step ID that the gate applies to.
 	// The condition must be satisfied before this step can start.
@@ -571,6 +583,14 @@ func (f *Formula) Validate() error {
 		if step.Priority != nil && (*step.Priority < 0 || *step.Priority > 4) {
 			errs = append(errs, fmt.Sprintf("%s (%s): priority must be 0-4", prefix, step.ID))
 		}
+		if step.MaxRetries < 0 {
+			errs = append(errs, fmt.Sprintf("%s (%s): max_retries must not be negative", prefix, step.ID))
+		}
+		if step.Gate != nil && step.Gate.Escalation != nil {
+			if err := step.Gate.Escalation.Validate(); err != nil {
+				errs = append(errs, fmt.Sprintf("%s (%s): gate.escalation: %v", prefix, step.ID, err))
+			}
+		}
 
 		// Collect child IDs (for dependency validation)
 		collectChildIDs(step.Children, stepIDLocations, &errs, prefix)
@@ -665,6 +685,14 @@ func collectChildIDs(children []*Step, idLocations map[string]string, errs *[]st
 		if child.Priority != nil && (*child.Priority < 0 || *child.Priority > 4) {
 			*errs = append(*errs, fmt.Sprintf("%s (%s): priority must be 0-4", childPrefix, child.ID))
 		}
+		if child.MaxRetries < 0 {
+			*errs = append(*errs, fmt.Sprintf("%s (%s): max_retries must not be negative", childPrefix, child.ID))
+		}
+		if child.Gate != nil && child.Gate.Escalation != nil {
+			if err := child.Gate.Escalation.Validate(); err != nil {
+				*errs = append(*errs, fmt.Sprintf("%s (%s): gate.escalation: %v", childPrefix, child.ID, err))
+			}
+		}
 
 		collectChildIDs(child.Children, idLocations, errs, childPrefix)
 	}
@@ -780,6 +808,11 @@ func validateOnComplete(oc *OnCompleteSpec, errs *[]string, prefix string) {
 	if oc.Parallel && oc.Sequential {
 		*errs = append(*errs, fmt.Sprintf("%s.on_complete: cannot set both parallel and sequential", prefix))
 	}
+
+	// The spec is stored in a label on the cooked step issue
+	if oc.ForEach != "" && len(OnCompleteLabel(oc)) > maxLabelLength {
+		*errs = append(*errs, fmt.Sprintf("%s.on_complete: spec too long to store (max %d bytes as JSON); shorten vars", prefix, maxLabelLength))
+	}
 }
 
 // GetRequiredVars returns the names of all required variables.
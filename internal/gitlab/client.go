@@ -357,3 +357,93 @@ func (c *Client) CreateIssueLink(ctx context.Context, sourceIID, targetIID int,
 
 	return &link, nil
 }
+
+// GetPipeline retrieves a single CI/CD pipeline by ID.
+func (c *Client) GetPipeline(ctx context.Context, id int) (*Pipeline, error) {
+	urlStr := c.buildURL("/projects/"+c.projectPath()+"/pipelines/"+strconv.Itoa(id), nil)
+	respBody, _, err := c.doRequest(ctx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pipeline %d: %w", id, err)
+	}
+
+	var pipeline Pipeline
+	if err := json.Unmarshal(respBody, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline response: %w", err)
+	}
+
+	return &pipeline, nil
+}
+
+// ListPipelines retrieves the most recent pipelines, newest first.
+// ref filters by branch or tag name; an empty ref lists pipelines for all refs.
+func (c *Client) ListPipelines(ctx context.Context, ref string, limit int) ([]Pipeline, error) {
+	if limit <= 0 || limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	params := map[string]string{
+		"order_by": "id",
+		"sort":     "desc",
+		"per_page": strconv.Itoa(limit),
+	}
+	if ref != "" {
+		params["ref"] = ref
+	}
+
+	urlStr := c.buildURL("/projects/"+c.projectPath()+"/pipelines", params)
+	respBody, _, err := c.doRequest(ctx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipelines: %w", err)
+	}
+
+	var pipelines []Pipeline
+	if err := json.Unmarshal(respBody, &pipelines); err != nil {
+		return nil, fmt.Errorf("failed to parse pipelines response: %w", err)
+	}
+
+	return pipelines, nil
+}
+
+// GetMergeRequest retrieves a single merge request by its project-scoped IID.
+func (c *Client) GetMergeRequest(ctx context.Context, iid int) (*MergeRequest, error) {
+	urlStr := c.buildURL("/projects/"+c.projectPath()+"/merge_requests/"+strconv.Itoa(iid), nil)
+	respBody, _, err := c.doRequest(ctx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merge request !%d: %w", iid, err)
+	}
+
+	var mr MergeRequest
+	if err := json.Unmarshal(respBody, &mr); err != nil {
+		return nil, fmt.Errorf("failed to parse merge request response: %w", err)
+	}
+
+	return &mr, nil
+}
+
+// ListMergeRequests retrieves merge requests from a source branch, newest first.
+// state can be: "opened", "closed", "locked", "merged", or "all".
+func (c *Client) ListMergeRequests(ctx context.Context, sourceBranch, state string) ([]MergeRequest, error) {
+	params := map[string]string{
+		"order_by": "created_at",
+		"sort":     "desc",
+		"per_page": strconv.Itoa(MaxPageSize),
+	}
+	if sourceBranch != "" {
+		params["source_branch"] = sourceBranch
+	}
+	if state != "" {
+		params["state"] = state
+	}
+
+	urlStr := c.buildURL("/projects/"+c.projectPath()+"/merge_requests", params)
+	respBody, _, err := c.doRequest(ctx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+
+	var mrs []MergeRequest
+	if err := json.Unmarshal(respBody, &mrs); err != nil {
+		return nil, fmt.Errorf("failed to parse merge requests response: %w", err)
+	}
+
+	return mrs, nil
+}
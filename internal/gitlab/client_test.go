@@ -897,3 +897,102 @@ func TestFetchIssuesSince_ContextCancellation(t *testing.T) {
 	// was caught by our loop check (returns partial) or by doRequest (returns nil)
 	t.Logf("Loop stopped after %d requests, %d issues returned, error: %v", requestCount.Load(), len(issues), err)
 }
+
+// TestGetPipeline_Success verifies fetching a single pipeline.
+func TestGetPipeline_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.EscapedPath(); got != "/api/v4/projects/group%2Fproject/pipelines/77" {
+			t.Errorf("URL path = %s, want /api/v4/projects/group%%2Fproject/pipelines/77", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Pipeline{ID: 77, Status: "running", Ref: "main"})
+	}))
+	defer server.Close()
+
+	client := NewClient("token", server.URL, "group/project")
+	pipeline, err := client.GetPipeline(context.Background(), 77)
+	if err != nil {
+		t.Fatalf("GetPipeline() error = %v", err)
+	}
+	if pipeline.ID != 77 || pipeline.Status != "running" {
+		t.Errorf("GetPipeline() = %+v, want ID 77 status running", pipeline)
+	}
+}
+
+// TestListPipelines_FiltersByRef verifies the ref filter and newest-first ordering.
+func TestListPipelines_FiltersByRef(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("ref") != "feature/x" {
+			t.Errorf("ref = %q, want %q", q.Get("ref"), "feature/x")
+		}
+		if q.Get("order_by") != "id" || q.Get("sort") != "desc" {
+			t.Errorf("order = %s %s, want id desc", q.Get("order_by"), q.Get("sort"))
+		}
+		if q.Get("per_page") != "5" {
+			t.Errorf("per_page = %q, want %q", q.Get("per_page"), "5")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]Pipeline{{ID: 2, Ref: "feature/x"}, {ID: 1, Ref: "feature/x"}})
+	}))
+	defer server.Close()
+
+	client := NewClient("token", server.URL, "123")
+	pipelines, err := client.ListPipelines(context.Background(), "feature/x", 5)
+	if err != nil {
+		t.Fatalf("ListPipelines() error = %v", err)
+	}
+	if len(pipelines) != 2 || pipelines[0].ID != 2 {
+		t.Errorf("ListPipelines() = %+v, want 2 pipelines newest first", pipelines)
+	}
+}
+
+// TestGetMergeRequest_Success verifies fetching a merge request by IID.
+func TestGetMergeRequest_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/projects/123/merge_requests/9") {
+			t.Errorf("URL path = %s, want suffix /projects/123/merge_requests/9", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(MergeRequest{IID: 9, Title: "Add gates", State: "merged"})
+	}))
+	defer server.Close()
+
+	client := NewClient("token", server.URL, "123")
+	mr, err := client.GetMergeRequest(context.Background(), 9)
+	if err != nil {
+		t.Fatalf("GetMergeRequest() error = %v", err)
+	}
+	if mr.IID != 9 || mr.State != "merged" {
+		t.Errorf("GetMergeRequest() = %+v, want IID 9 state merged", mr)
+	}
+}
+
+// TestListMergeRequests_FiltersBySourceBranch verifies the branch and state filters.
+func TestListMergeRequests_FiltersBySourceBranch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("source_branch") != "feature/x" {
+			t.Errorf("source_branch = %q, want %q", q.Get("source_branch"), "feature/x")
+		}
+		if q.Get("state") != "all" {
+			t.Errorf("state = %q, want %q", q.Get("state"), "all")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]MergeRequest{{IID: 4, SourceBranch: "feature/x", State: "opened"}})
+	}))
+	defer server.Close()
+
+	client := NewClient("token", server.URL, "123")
+	mrs, err := client.ListMergeRequests(context.Background(), "feature/x", "all")
+	if err != nil {
+		t.Fatalf("ListMergeRequests() error = %v", err)
+	}
+	if len(mrs) != 1 || mrs[0].IID != 4 {
+		t.Errorf("ListMergeRequests() = %+v, want one MR with IID 4", mrs)
+	}
+}
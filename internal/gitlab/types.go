@@ -294,3 +294,35 @@ func getStatusFromLabel(value string) string {
 func getTypeFromLabel(value string) string {
 	return typeMapping[strings.ToLower(value)]
 }
+
+// Pipeline represents a CI/CD pipeline from the GitLab API.
+type Pipeline struct {
+	ID        int        `json:"id"`
+	IID       int        `json:"iid"`
+	ProjectID int        `json:"project_id"`
+	Status    string     `json:"status"` // "created", "pending", "running", "success", "failed", "canceled", "skipped", "manual", ...
+	Source    string     `json:"source,omitempty"`
+	Ref       string     `json:"ref"`
+	SHA       string     `json:"sha"`
+	Name      string     `json:"name,omitempty"`
+	WebURL    string     `json:"web_url"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// MergeRequest represents a merge request from the GitLab API.
+type MergeRequest struct {
+	ID           int        `json:"id"`
+	IID          int        `json:"iid"`
+	ProjectID    int        `json:"project_id"`
+	Title        string     `json:"title"`
+	State        string     `json:"state"` // "opened", "closed", "locked", "merged"
+	SourceBranch string     `json:"source_branch"`
+	TargetBranch string     `json:"target_branch"`
+	SHA          string     `json:"sha"`
+	WebURL       string     `json:"web_url"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+	MergedAt     *time.Time `json:"merged_at,omitempty"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+}
@@ -0,0 +1,207 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// Pre-operation events. Their hooks run synchronously before the change is
+// made and can veto or patch it; the catch-all hook does not run for them.
+const (
+	EventBeforeCreate = "before_create"
+	EventBeforeUpdate = "before_update"
+	EventBeforeClose  = "before_close"
+	EventBeforeDelete = "before_delete"
+)
+
+// Pre-operation hook file names
+const (
+	HookBeforeCreate = "before_create"
+	HookBeforeUpdate = "before_update"
+	HookBeforeClose  = "before_close"
+	HookBeforeDelete = "before_delete"
+)
+
+// VetoError is returned by RunBefore when a hook rejects a change.
+type VetoError struct {
+	Hook    string // Hook file name, e.g. before_close
+	IssueID string
+	Message string // The hook's stderr, or why it failed
+}
+
+func (e *VetoError) Error() string {
+	if e.IssueID == "" {
+		return fmt.Sprintf("%s hook rejected the change: %s", e.Hook, e.Message)
+	}
+	return fmt.Sprintf("%s hook rejected the change to %s: %s", e.Hook, e.IssueID, e.Message)
+}
+
+// RunBefore runs the pre-operation hook for an event, if it exists, and
+// waits for it. The hook gets the Envelope on stdin: Old is the issue as it
+// is (nil for create) and New the issue as it would be after the change (nil
+// for delete), with the pending field changes in Updates.
+//
+// A hook that exits non-zero, or outlives the runner's timeout, vetoes the
+// change with a *VetoError carrying its stderr. A hook that succeeds may
+// print a JSON object on stdout: a patch to the pending change, returned for
+// the caller to apply.
+func (r *Runner) RunBefore(env *Envelope) (map[string]interface{}, error) {
+	hookName := beforeEventToHook(env.Event)
+	if hookName == "" {
+		return nil, nil
+	}
+	if !r.HasBeforeHook(env.Event) {
+		return nil, nil // No executable hook, nothing to check
+	}
+
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+	rec, stdout, stderr, err := r.exec(filepath.Join(r.hooksDir, hookName), env, payload)
+	r.appendLog(rec)
+	if err != nil {
+		veto := &VetoError{Hook: hookName, IssueID: env.IssueID, Message: strings.TrimSpace(string(stderr))}
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			veto.Message = rec.Error
+		case veto.Message == "":
+			veto.Message = err.Error()
+		}
+		return nil, veto
+	}
+
+	stdout = bytes.TrimSpace(stdout)
+	if len(stdout) == 0 {
+		return nil, nil
+	}
+	patch, err := decodePatch(stdout)
+	if err != nil {
+		return nil, &VetoError{Hook: hookName, IssueID: env.IssueID, Message: fmt.Sprintf("invalid patch on stdout: %v", err)}
+	}
+	return patch, nil
+}
+
+// HasBeforeHook reports whether an enabled, executable pre-operation hook
+// exists for an event, so callers can skip building its envelope.
+func (r *Runner) HasBeforeHook(event string) bool {
+	hookName := beforeEventToHook(event)
+	if hookName == "" || !r.enabled(hookName) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(r.hooksDir, hookName))
+	return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+}
+
+func beforeEventToHook(event string) string {
+	switch event {
+	case EventBeforeCreate:
+		return HookBeforeCreate
+	case EventBeforeUpdate:
+		return HookBeforeUpdate
+	case EventBeforeClose:
+		return HookBeforeClose
+	case EventBeforeDelete:
+		return HookBeforeDelete
+	default:
+		return ""
+	}
+}
+
+// decodePatch decodes a hook's JSON patch. Whole numbers decode as int and
+// string arrays as []string, matching what bd itself puts in an update.
+func decodePatch(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var patch map[string]interface{}
+	if err := dec.Decode(&patch); err != nil {
+		return nil, err
+	}
+	if patch == nil {
+		return nil, errors.New("expected a JSON object")
+	}
+	for key, value := range patch {
+		patch[key] = normalizePatchValue(value)
+	}
+	return patch, nil
+}
+
+func normalizePatchValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return v
+			}
+			strs = append(strs, s)
+		}
+		return strs
+	default:
+		return v
+	}
+}
+
+// ApplyPatch returns a copy of issue with a JSON merge patch (RFC 7386)
+// applied, keyed by the issue's JSON field names.
+func ApplyPatch(issue *types.Issue, patch map[string]interface{}) (*types.Issue, error) {
+	data, err := json.Marshal(issue)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	mergePatch(fields, patch)
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var patched types.Issue
+	if err := json.Unmarshal(data, &patched); err != nil {
+		return nil, fmt.Errorf("patch does not fit an issue: %w", err)
+	}
+
+	// Keep the internal fields JSON leaves out, such as SourceRepo
+	from, to := reflect.ValueOf(issue).Elem(), reflect.ValueOf(&patched).Elem()
+	for i := 0; i < from.NumField(); i++ {
+		if from.Type().Field(i).Tag.Get("json") == "-" {
+			to.Field(i).Set(from.Field(i))
+		}
+	}
+	return &patched, nil
+}
+
+func mergePatch(target, patch map[string]interface{}) {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if sub, ok := value.(map[string]interface{}); ok {
+			if existing, ok := target[key].(map[string]interface{}); ok {
+				mergePatch(existing, sub)
+				continue
+			}
+		}
+		target[key] = value
+	}
+}
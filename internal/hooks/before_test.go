@@ -0,0 +1,149 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func writeHook(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("Failed to create hook file: %v", err)
+	}
+}
+
+func TestRunBefore_NoHook(t *testing.T) {
+	runner := NewRunner(t.TempDir())
+	patch, err := runner.RunBefore(NewEnvelope(EventBeforeClose, "alice", &types.Issue{ID: "bd-1"}, nil))
+	if patch != nil || err != nil {
+		t.Errorf("RunBefore = (%v, %v), want nothing", patch, err)
+	}
+	if runner.HasBeforeHook(EventBeforeClose) {
+		t.Error("HasBeforeHook returned true for non-existent hook")
+	}
+}
+
+func TestRunBefore_Veto(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	writeHook(t, tmpDir, HookBeforeClose, "echo 'bugs need reproduction steps' >&2\nexit 1\n")
+
+	runner := NewRunner(tmpDir)
+	_, err := runner.RunBefore(NewEnvelope(EventBeforeClose, "alice", &types.Issue{ID: "bd-1"}, nil))
+	veto, ok := err.(*VetoError)
+	if !ok {
+		t.Fatalf("err = %v, want a *VetoError", err)
+	}
+	if veto.Message != "bugs need reproduction steps" {
+		t.Errorf("Message = %q, want the hook's stderr", veto.Message)
+	}
+	if want := "before_close hook rejected the change to bd-1: bugs need reproduction steps"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestRunBefore_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	writeHook(t, tmpDir, HookBeforeDelete, "sleep 5\n")
+
+	runner := NewRunner(tmpDir)
+	runner.timeout = 200 * time.Millisecond
+	_, err := runner.RunBefore(NewEnvelope(EventBeforeDelete, "alice", &types.Issue{ID: "bd-1"}, nil))
+	if err == nil || !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Errorf("err = %v, want a timeout veto", err)
+	}
+}
+
+func TestRunBefore_Patch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	stdinFile := filepath.Join(tmpDir, "stdin.json")
+	writeHook(t, tmpDir, HookBeforeUpdate,
+		"cat > \""+stdinFile+"\"\necho '{\"priority\": 1, \"add_labels\": [\"triage\"], \"notes\": \"downgraded\"}'\n")
+
+	runner := NewRunner(tmpDir)
+	old := &types.Issue{ID: "bd-1", Priority: 2}
+	env := NewEnvelope(EventBeforeUpdate, "bob", old, &types.Issue{ID: "bd-1", Priority: 0})
+	env.Updates = map[string]interface{}{"priority": 0}
+	patch, err := runner.RunBefore(env)
+	if err != nil {
+		t.Fatalf("RunBefore returned error: %v", err)
+	}
+	want := map[string]interface{}{"priority": 1, "add_labels": []string{"triage"}, "notes": "downgraded"}
+	if !reflect.DeepEqual(patch, want) {
+		t.Errorf("patch = %#v, want %#v", patch, want)
+	}
+
+	data, err := os.ReadFile(stdinFile)
+	if err != nil {
+		t.Fatalf("Failed to read stdin file: %v", err)
+	}
+	var got Envelope
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("stdin is not an envelope: %v", err)
+	}
+	if got.Event != EventBeforeUpdate || got.Actor != "bob" || got.Updates["priority"] != float64(0) {
+		t.Errorf("envelope = %+v", got)
+	}
+}
+
+func TestRunBefore_InvalidPatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	writeHook(t, tmpDir, HookBeforeCreate, "echo 'looks fine to me'\n")
+
+	runner := NewRunner(tmpDir)
+	_, err := runner.RunBefore(NewEnvelope(EventBeforeCreate, "alice", nil, &types.Issue{Title: "New"}))
+	if err == nil || !strings.Contains(err.Error(), "before_create hook rejected the change: invalid patch") {
+		t.Errorf("err = %v, want an invalid patch veto", err)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	issue := &types.Issue{
+		ID:         "bd-1",
+		Title:      "Crash on save",
+		Priority:   0,
+		Assignee:   "alice",
+		Labels:     []string{"bug"},
+		SourceRepo: "../other",
+	}
+	patched, err := ApplyPatch(issue, map[string]interface{}{
+		"priority": 2,
+		"assignee": nil,
+		"labels":   []string{"bug", "needs-repro"},
+	})
+	if err != nil {
+		t.Fatalf("ApplyPatch returned error: %v", err)
+	}
+	if patched.Priority != 2 || patched.Assignee != "" || !reflect.DeepEqual(patched.Labels, []string{"bug", "needs-repro"}) {
+		t.Errorf("patched = %+v", patched)
+	}
+	if patched.Title != issue.Title || patched.SourceRepo != "../other" {
+		t.Errorf("unpatched fields lost: %+v", patched)
+	}
+	if issue.Priority != 0 || issue.Assignee != "alice" {
+		t.Error("ApplyPatch modified the original issue")
+	}
+
+	if _, err := ApplyPatch(issue, map[string]interface{}{"priority": "high"}); err == nil {
+		t.Error("expected an error for a patch of the wrong type")
+	}
+}
@@ -0,0 +1,150 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HookConfig is the configuration for one hook, from hooks.<hook-name> in
+// config.yaml:
+//
+//	hooks:
+//	  on_close:
+//	    timeout: 30s
+//	    async: true
+//	    env: ["SLACK_CHANNEL=#builds"]
+//	  on_any:
+//	    enabled: false
+type HookConfig struct {
+	Enabled *bool         `json:"enabled,omitempty" mapstructure:"enabled"` // Default true
+	Async   *bool         `json:"async,omitempty" mapstructure:"async"`     // Default false; before_* hooks always run synchronously
+	Timeout time.Duration `json:"timeout,omitempty" mapstructure:"timeout"` // Default: the runner's timeout
+	Env     []string      `json:"env,omitempty" mapstructure:"env"`         // KEY=value pairs added to the hook's environment
+}
+
+// postEvents are the events that run on_* hooks, in the order of their
+// constants.
+var postEvents = []string{
+	EventCreate, EventUpdate, EventClose, EventReopen, EventDelete,
+	EventStatusChanged, EventLabelAdded, EventLabelRemoved,
+	EventDependencyAdded, EventDependencyRemoved, EventCommentAdded, EventClaimed,
+	EventGateWarn, EventGateEscalate, EventUnblocked,
+}
+
+// beforeEvents are the events that run before_* hooks.
+var beforeEvents = []string{EventBeforeCreate, EventBeforeUpdate, EventBeforeClose, EventBeforeDelete}
+
+// HookNames returns the names of all hooks bd runs, post-event hooks first.
+func HookNames() []string {
+	names := make([]string, 0, len(postEvents)+len(beforeEvents)+1)
+	for _, event := range postEvents {
+		names = append(names, eventToHook(event))
+	}
+	names = append(names, HookOnAny)
+	for _, event := range beforeEvents {
+		names = append(names, beforeEventToHook(event))
+	}
+	return names
+}
+
+// EventForHook returns the event a hook runs for, or "" for on_any and
+// unknown names.
+func EventForHook(hookName string) string {
+	for _, event := range postEvents {
+		if eventToHook(event) == hookName {
+			return event
+		}
+	}
+	for _, event := range beforeEvents {
+		if beforeEventToHook(event) == hookName {
+			return event
+		}
+	}
+	return ""
+}
+
+// IsBeforeHook reports whether a hook is a pre-operation hook.
+func IsBeforeHook(hookName string) bool {
+	for _, event := range beforeEvents {
+		if beforeEventToHook(event) == hookName {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTimeout sets the timeout for hooks without one of their own. Zero
+// keeps the default.
+func (r *Runner) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		r.timeout = timeout
+	}
+}
+
+// SetHookConfig sets the per-hook configuration, keyed by hook name.
+func (r *Runner) SetHookConfig(configs map[string]HookConfig) {
+	r.configs = configs
+}
+
+// HookConfigFor returns the configuration for a hook.
+func (r *Runner) HookConfigFor(hookName string) HookConfig {
+	return r.configs[hookName]
+}
+
+// enabled reports whether a hook is enabled.
+func (r *Runner) enabled(hookName string) bool {
+	enabled := r.configs[hookName].Enabled
+	return enabled == nil || *enabled
+}
+
+// async reports whether a post-event hook runs in the background (async:
+// true). The process still waits for it before exiting (see Wait).
+func (r *Runner) async(hookName string) bool {
+	async := r.configs[hookName].Async
+	return async != nil && *async
+}
+
+// timeoutFor returns a hook's timeout.
+func (r *Runner) timeoutFor(hookName string) time.Duration {
+	if timeout := r.configs[hookName].Timeout; timeout > 0 {
+		return timeout
+	}
+	return r.timeout
+}
+
+// hookName returns the file name of a hook path.
+func hookName(hookPath string) string {
+	return filepath.Base(hookPath)
+}
+
+// DryRunEnv is set to 1 in the environment of a hook run by Test, so the
+// hook can skip its side effects.
+const DryRunEnv = "BD_HOOK_DRY_RUN"
+
+// Test runs a hook once with env on stdin and waits for it, whatever its
+// async and enabled settings, for 'bd hooks test'. The run is not logged.
+func (r *Runner) Test(hookName string, env *Envelope) (RunRecord, error) {
+	hookPath := filepath.Join(r.hooksDir, hookName)
+	info, err := os.Stat(hookPath)
+	if err != nil {
+		return RunRecord{}, fmt.Errorf("no hook %s in %s", hookName, r.hooksDir)
+	}
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return RunRecord{}, fmt.Errorf("%s is not executable (chmod +x %s)", hookName, hookPath)
+	}
+
+	var payload []byte
+	if IsBeforeHook(hookName) {
+		payload, err = json.Marshal(env)
+	} else {
+		payload, err = r.payload(env)
+	}
+	if err != nil {
+		return RunRecord{}, err
+	}
+	rec, _, _, _ := r.exec(hookPath, env, payload, DryRunEnv+"=1")
+	return rec, nil
+}
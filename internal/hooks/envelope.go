@@ -0,0 +1,125 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// EnvelopeVersion is the version of the Envelope format passed to hooks on
+// stdin. It is bumped when fields are removed or change meaning; new fields
+// may be added without a bump.
+const EnvelopeVersion = 1
+
+// Envelope is the event payload a hook receives as JSON on stdin:
+//
+//	{
+//	  "version": 1,
+//	  "event": "status_changed",
+//	  "issue_id": "bd-42",
+//	  "actor": "alice",
+//	  "timestamp": "2026-01-01T12:00:00Z",
+//	  "old": {...},
+//	  "new": {...},
+//	  "changed": ["status"]
+//	}
+//
+// Old is the issue before the change and New after it, so Old is absent for
+// create and New for delete. Changed lists the JSON names of the fields that
+// differ between the two. Events about a label, dependency or comment carry
+// it in the matching field.
+type Envelope struct {
+	Version   int          `json:"version"`
+	Event     string       `json:"event"`
+	IssueID   string       `json:"issue_id"`
+	Actor     string       `json:"actor,omitempty"`
+	Timestamp time.Time    `json:"timestamp"`
+	Old       *types.Issue `json:"old,omitempty"`
+	New       *types.Issue `json:"new,omitempty"`
+	Changed   []string     `json:"changed,omitempty"`
+
+	// Updates holds the pending field changes for before_update and
+	// before_close, keyed by field name as bd update takes them.
+	Updates map[string]interface{} `json:"updates,omitempty"`
+
+	Label      string            `json:"label,omitempty"`      // label_added, label_removed
+	Dependency *types.Dependency `json:"dependency,omitempty"` // dependency_added, dependency_removed
+	Comment    *types.Comment    `json:"comment,omitempty"`    // comment_added
+	Cause      string            `json:"cause,omitempty"`      // unblocked: the issue whose close unblocked it
+}
+
+// NewEnvelope builds the envelope for an event on an issue, filling in the
+// version, timestamp and changed fields. Either old or new may be nil.
+func NewEnvelope(event, actor string, old, new *types.Issue) *Envelope {
+	env := &Envelope{
+		Version:   EnvelopeVersion,
+		Event:     event,
+		Actor:     actor,
+		Timestamp: time.Now().UTC(),
+		Old:       old,
+		New:       new,
+		Changed:   ChangedFields(old, new),
+	}
+	if new != nil {
+		env.IssueID = new.ID
+	} else if old != nil {
+		env.IssueID = old.ID
+	}
+	return env
+}
+
+// issue returns the issue the event is about: the new version, or the old
+// one for deletes.
+func (e *Envelope) issue() *types.Issue {
+	if e.New != nil {
+		return e.New
+	}
+	return e.Old
+}
+
+// ChangedFields returns the sorted JSON names of the issue fields that differ
+// between old and new, ignoring updated_at. It returns nil unless both are
+// set.
+func ChangedFields(old, new *types.Issue) []string {
+	if old == nil || new == nil {
+		return nil
+	}
+	oldFields, err := issueFields(old)
+	if err != nil {
+		return nil
+	}
+	newFields, err := issueFields(new)
+	if err != nil {
+		return nil
+	}
+
+	var changed []string
+	for name, value := range newFields {
+		if !bytes.Equal(oldFields[name], value) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range oldFields {
+		if _, ok := newFields[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func issueFields(issue *types.Issue) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(issue)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "updated_at")
+	return fields, nil
+}
@@ -0,0 +1,153 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestChangedFields(t *testing.T) {
+	old := &types.Issue{ID: "bd-1", Title: "Fix it", Status: types.StatusOpen, Labels: []string{"a"}}
+	updated := &types.Issue{ID: "bd-1", Title: "Fix it", Status: types.StatusInProgress, Assignee: "alice", Labels: []string{"a", "b"}}
+
+	got := ChangedFields(old, updated)
+	want := []string{"assignee", "labels", "status"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedFields = %v, want %v", got, want)
+	}
+
+	if got := ChangedFields(old, old); len(got) != 0 {
+		t.Errorf("ChangedFields(same) = %v, want none", got)
+	}
+	if got := ChangedFields(nil, updated); got != nil {
+		t.Errorf("ChangedFields(nil, new) = %v, want nil", got)
+	}
+}
+
+func TestNewEnvelope(t *testing.T) {
+	issue := &types.Issue{ID: "bd-1", Title: "Gone"}
+
+	env := NewEnvelope(EventDelete, "alice", issue, nil)
+	if env.Version != EnvelopeVersion || env.Event != EventDelete || env.Actor != "alice" {
+		t.Errorf("envelope = %+v", env)
+	}
+	if env.IssueID != "bd-1" {
+		t.Errorf("IssueID = %q, want the old issue's ID", env.IssueID)
+	}
+	if env.Timestamp.IsZero() {
+		t.Error("Timestamp not set")
+	}
+}
+
+// writeCaptureHook creates a hook that appends its arguments and stdin to out.
+func writeCaptureHook(t *testing.T, dir, name, out string) {
+	t.Helper()
+	script := "#!/bin/sh\n" +
+		"echo \"$1 $2\" >> \"" + out + "\"\n" +
+		"cat >> \"" + out + "\"\n" +
+		"echo >> \"" + out + "\"\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to create hook file: %v", err)
+	}
+}
+
+func TestEmitSync_ReceivesEnvelope(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "output.txt")
+	writeCaptureHook(t, tmpDir, HookOnStatusChanged, outputFile)
+
+	runner := NewRunner(tmpDir)
+	old := &types.Issue{ID: "bd-1", Status: types.StatusOpen}
+	updated := &types.Issue{ID: "bd-1", Status: types.StatusInProgress}
+	if err := runner.EmitSync(NewEnvelope(EventStatusChanged, "alice", old, updated)); err != nil {
+		t.Fatalf("EmitSync returned error: %v", err)
+	}
+
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	args, payload, _ := strings.Cut(string(output), "\n")
+	if args != "bd-1 status_changed" {
+		t.Errorf("hook args = %q, want %q", args, "bd-1 status_changed")
+	}
+	var env Envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil {
+		t.Fatalf("stdin is not an envelope: %v\n%s", err, payload)
+	}
+	if env.Version != EnvelopeVersion || env.Actor != "alice" || env.Old.Status != types.StatusOpen ||
+		env.New.Status != types.StatusInProgress || !reflect.DeepEqual(env.Changed, []string{"status"}) {
+		t.Errorf("envelope = %+v", env)
+	}
+}
+
+func TestEmitSync_LegacyPayload(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "output.txt")
+	writeCaptureHook(t, tmpDir, HookOnDelete, outputFile)
+
+	runner := NewRunner(tmpDir)
+	runner.SetLegacyPayload(true)
+	if err := runner.EmitSync(NewEnvelope(EventDelete, "alice", &types.Issue{ID: "bd-1", Title: "Gone"}, nil)); err != nil {
+		t.Fatalf("EmitSync returned error: %v", err)
+	}
+
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	_, payload, _ := strings.Cut(string(output), "\n")
+	var issue types.Issue
+	if err := json.Unmarshal([]byte(payload), &issue); err != nil || issue.ID != "bd-1" || issue.Title != "Gone" {
+		t.Errorf("stdin = %s, want the deleted issue (err %v)", payload, err)
+	}
+}
+
+func TestEmit_CatchAll(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "output.txt")
+	writeCaptureHook(t, tmpDir, HookOnLabelAdded, outputFile)
+	writeCaptureHook(t, tmpDir, HookOnAny, outputFile)
+
+	runner := NewRunner(tmpDir)
+	if !runner.HookExists(EventCommentAdded) {
+		t.Error("HookExists should report the catch-all hook")
+	}
+
+	env := NewEnvelope(EventLabelAdded, "alice", nil, &types.Issue{ID: "bd-1"})
+	env.Label = "urgent"
+	runner.Emit(env)
+	runner.Wait()
+	runner.Emit(NewEnvelope(EventCommentAdded, "bob", nil, &types.Issue{ID: "bd-2"}))
+	runner.Wait()
+
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	var calls []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "bd-") {
+			calls = append(calls, line)
+		}
+	}
+	want := []string{"bd-1 label_added", "bd-1 label_added", "bd-2 comment_added"}
+	if !reflect.DeepEqual(calls, want) || strings.Count(string(output), `"label":"urgent"`) != 2 {
+		t.Errorf("hook calls = %q, want %q\n%s", calls, want, output)
+	}
+}
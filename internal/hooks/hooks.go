@@ -3,8 +3,10 @@
 package hooks
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/steveyegge/beads/internal/types"
@@ -15,6 +17,27 @@ const (
 	EventCreate = "create"
 	EventUpdate = "update"
 	EventClose  = "close"
+	EventReopen = "reopen"
+	EventDelete = "delete"
+
+	// Finer-grained events, fired alongside update (or create, close and
+	// so on) when the change they describe happens.
+	EventStatusChanged     = "status_changed"
+	EventLabelAdded        = "label_added"
+	EventLabelRemoved      = "label_removed"
+	EventDependencyAdded   = "dependency_added"
+	EventDependencyRemoved = "dependency_removed"
+	EventCommentAdded      = "comment_added"
+	EventClaimed           = "claimed"
+
+	// Gate escalation stages, run by 'bd gate check --escalate' for gates
+	// whose escalation policy includes the notify action.
+	EventGateWarn     = "gate_warn"
+	EventGateEscalate = "gate_escalate"
+
+	// EventUnblocked fires for each issue unblocked by closing another,
+	// whether by 'bd close' or by 'bd gate watch' closing a gate.
+	EventUnblocked = "unblocked"
 )
 
 // Hook file names
@@ -22,12 +45,37 @@ const (
 	HookOnCreate = "on_create"
 	HookOnUpdate = "on_update"
 	HookOnClose  = "on_close"
+	HookOnReopen = "on_reopen"
+	HookOnDelete = "on_delete"
+
+	HookOnStatusChanged     = "on_status_changed"
+	HookOnLabelAdded        = "on_label_added"
+	HookOnLabelRemoved      = "on_label_removed"
+	HookOnDependencyAdded   = "on_dependency_added"
+	HookOnDependencyRemoved = "on_dependency_removed"
+	HookOnCommentAdded      = "on_comment_added"
+	HookOnClaimed           = "on_claimed"
+
+	HookOnGateWarn     = "on_gate_warn"
+	HookOnGateEscalate = "on_gate_escalate"
+	HookOnUnblocked    = "on_unblocked"
+
+	// HookOnAny is the catch-all hook, run for every event after the
+	// event's own hook.
+	HookOnAny = "on_any"
 )
 
 // Runner handles hook execution
 type Runner struct {
-	hooksDir string
-	timeout  time.Duration
+	hooksDir      string
+	timeout       time.Duration
+	legacyPayload bool                  // Pass the bare issue on stdin instead of an Envelope
+	configs       map[string]HookConfig // Per-hook configuration, keyed by hook name
+	logPath       string                // Run log; empty for none
+
+	wg       sync.WaitGroup // Tracks async hooks started by Run and Emit
+	mu       sync.Mutex     // Guards failures and the run log
+	failures []RunRecord    // Post-event hooks that failed
 }
 
 // NewRunner creates a new hook runner.
@@ -44,70 +92,125 @@ func NewRunnerFromWorkspace(workspaceRoot string) *Runner {
 	return NewRunner(filepath.Join(workspaceRoot, ".beads", "hooks"))
 }
 
-// Run executes a hook if it exists.
-// Runs asynchronously - returns immediately, hook runs in background.
+// SetLegacyPayload makes hooks receive the bare issue JSON on stdin, as they
+// did before the Envelope was introduced (hooks.payload: issue).
+func (r *Runner) SetLegacyPayload(legacy bool) {
+	r.legacyPayload = legacy
+}
+
+// Run executes the hooks for an event on an issue, if they exist, as Emit
+// does.
 func (r *Runner) Run(event string, issue *types.Issue) {
-	hookName := eventToHook(event)
-	if hookName == "" {
-		return
-	}
+	r.Emit(NewEnvelope(event, "", nil, issue))
+}
 
-	hookPath := filepath.Join(r.hooksDir, hookName)
+// RunSync executes the hooks for an event on an issue synchronously and
+// returns the first error. Useful for testing or when you need to wait for
+// the hook.
+func (r *Runner) RunSync(event string, issue *types.Issue) error {
+	return r.EmitSync(NewEnvelope(event, "", nil, issue))
+}
 
-	// Check if hook exists and is executable
-	info, err := os.Stat(hookPath)
-	if err != nil || info.IsDir() {
-		return // Hook doesn't exist, skip silently
+// Emit executes the hooks for an event, if they exist. Hooks run
+// synchronously unless configured with async: true; those run in the
+// background after the others, so use Wait to let them finish before the
+// process exits. Failures are recorded (see Failures) but not returned.
+func (r *Runner) Emit(env *Envelope) {
+	var background []string
+	for _, hookPath := range r.hookPaths(env.Event) {
+		if r.async(hookName(hookPath)) {
+			background = append(background, hookPath)
+			continue
+		}
+		_ = r.runHook(hookPath, env) // Best effort: hook failures should not block the triggering operation
 	}
-
-	// Check if executable (Unix)
-	if info.Mode()&0111 == 0 {
-		return // Not executable, skip
+	if len(background) == 0 {
+		return
 	}
 
-	// Run asynchronously (ignore error as this is fire-and-forget)
+	r.wg.Add(1)
 	go func() {
-		_ = r.runHook(hookPath, event, issue) // Best effort: hook failures should not block the triggering operation
+		defer r.wg.Done()
+		for _, hookPath := range background {
+			_ = r.runHook(hookPath, env) // Best effort: hook failures should not block the triggering operation
+		}
 	}()
 }
 
-// RunSync executes a hook synchronously and returns any error.
-// Useful for testing or when you need to wait for the hook.
-func (r *Runner) RunSync(event string, issue *types.Issue) error {
-	hookName := eventToHook(event)
-	if hookName == "" {
-		return nil
-	}
-
-	hookPath := filepath.Join(r.hooksDir, hookName)
-
-	// Check if hook exists and is executable
-	info, err := os.Stat(hookPath)
-	if err != nil || info.IsDir() {
-		return nil // Hook doesn't exist, skip silently
-	}
-
-	if info.Mode()&0111 == 0 {
-		return nil // Not executable, skip
+// EmitSync executes the hooks for an event synchronously: the event's own
+// hook, then the catch-all. Both run even if the first fails; the first
+// error is returned.
+func (r *Runner) EmitSync(env *Envelope) error {
+	var firstErr error
+	for _, hookPath := range r.hookPaths(env.Event) {
+		if err := r.runHook(hookPath, env); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
+	return firstErr
+}
 
-	return r.runHook(hookPath, event, issue)
+// Wait blocks until async hooks started by Run and Emit have finished. Each
+// hook is bounded by its timeout.
+func (r *Runner) Wait() {
+	r.wg.Wait()
 }
 
-// HookExists checks if a hook exists for an event
+// HookExists checks if a hook, or the catch-all hook, exists for an event
 func (r *Runner) HookExists(event string) bool {
+	return len(r.hookPaths(event)) > 0
+}
+
+// hookPaths returns the enabled, executable hooks to run for an event.
+func (r *Runner) hookPaths(event string) []string {
 	hookName := eventToHook(event)
 	if hookName == "" {
-		return false
+		return nil
 	}
 
-	hookPath := filepath.Join(r.hooksDir, hookName)
-	info, err := os.Stat(hookPath)
-	if err != nil || info.IsDir() {
-		return false
+	var paths []string
+	for _, name := range []string{hookName, HookOnAny} {
+		hookPath := filepath.Join(r.hooksDir, name)
+
+		// Check if hook exists and is executable
+		info, err := os.Stat(hookPath)
+		if err != nil || info.IsDir() {
+			continue // Hook doesn't exist, skip silently
+		}
+		if info.Mode()&0111 == 0 {
+			continue // Not executable, skip
+		}
+		if !r.enabled(name) {
+			continue
+		}
+		paths = append(paths, hookPath)
 	}
+	return paths
+}
 
-	return info.Mode()&0111 != 0
+// runHook executes a post-event hook, discarding its output but recording
+// the run.
+func (r *Runner) runHook(hookPath string, env *Envelope) error {
+	payload, err := r.payload(env)
+	if err != nil {
+		return err
+	}
+	rec, _, _, err := r.exec(hookPath, env, payload)
+	r.appendLog(rec)
+	if err != nil {
+		r.mu.Lock()
+		r.failures = append(r.failures, rec)
+		r.mu.Unlock()
+	}
+	return err
+}
+
+// payload returns the JSON passed to a hook on stdin.
+func (r *Runner) payload(env *Envelope) ([]byte, error) {
+	if r.legacyPayload {
+		return json.Marshal(env.issue())
+	}
+	return json.Marshal(env)
 }
 
 func eventToHook(event string) string {
@@ -118,6 +221,30 @@ func eventToHook(event string) string {
 		return HookOnUpdate
 	case EventClose:
 		return HookOnClose
+	case EventReopen:
+		return HookOnReopen
+	case EventDelete:
+		return HookOnDelete
+	case EventStatusChanged:
+		return HookOnStatusChanged
+	case EventLabelAdded:
+		return HookOnLabelAdded
+	case EventLabelRemoved:
+		return HookOnLabelRemoved
+	case EventDependencyAdded:
+		return HookOnDependencyAdded
+	case EventDependencyRemoved:
+		return HookOnDependencyRemoved
+	case EventCommentAdded:
+		return HookOnCommentAdded
+	case EventClaimed:
+		return HookOnClaimed
+	case EventGateWarn:
+		return HookOnGateWarn
+	case EventGateEscalate:
+		return HookOnGateEscalate
+	case EventUnblocked:
+		return HookOnUnblocked
 	default:
 		return ""
 	}
@@ -44,6 +44,18 @@ func TestEventToHook(t *testing.T) {
 		{EventCreate, HookOnCreate},
 		{EventUpdate, HookOnUpdate},
 		{EventClose, HookOnClose},
+		{EventReopen, HookOnReopen},
+		{EventDelete, HookOnDelete},
+		{EventStatusChanged, HookOnStatusChanged},
+		{EventLabelAdded, HookOnLabelAdded},
+		{EventLabelRemoved, HookOnLabelRemoved},
+		{EventDependencyAdded, HookOnDependencyAdded},
+		{EventDependencyRemoved, HookOnDependencyRemoved},
+		{EventCommentAdded, HookOnCommentAdded},
+		{EventClaimed, HookOnClaimed},
+		{EventGateWarn, HookOnGateWarn},
+		{EventGateEscalate, HookOnGateEscalate},
+		{EventUnblocked, HookOnUnblocked},
 		{"unknown", ""},
 		{"", ""},
 	}
@@ -343,6 +355,7 @@ func TestRun_Async(t *testing.T) {
 	}
 
 	runner := NewRunner(tmpDir)
+	runner.SetHookConfig(map[string]HookConfig{HookOnClose: {Async: boolPtr(true)}})
 	issue := &types.Issue{ID: "bd-test", Title: "Test"}
 
 	// Run should return immediately
@@ -380,6 +393,18 @@ func TestAllHookEvents(t *testing.T) {
 		{EventCreate, HookOnCreate},
 		{EventUpdate, HookOnUpdate},
 		{EventClose, HookOnClose},
+		{EventReopen, HookOnReopen},
+		{EventDelete, HookOnDelete},
+		{EventStatusChanged, HookOnStatusChanged},
+		{EventLabelAdded, HookOnLabelAdded},
+		{EventLabelRemoved, HookOnLabelRemoved},
+		{EventDependencyAdded, HookOnDependencyAdded},
+		{EventDependencyRemoved, HookOnDependencyRemoved},
+		{EventCommentAdded, HookOnCommentAdded},
+		{EventClaimed, HookOnClaimed},
+		{EventGateWarn, HookOnGateWarn},
+		{EventGateEscalate, HookOnGateEscalate},
+		{EventUnblocked, HookOnUnblocked},
 	}
 
 	for _, e := range events {
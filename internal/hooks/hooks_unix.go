@@ -5,36 +5,33 @@ package hooks
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"syscall"
-
-	"github.com/steveyegge/beads/internal/types"
+	"time"
 )
 
-// runHook executes the hook and enforces a timeout, killing the process group
-// on expiration to ensure descendant processes are terminated.
-func (r *Runner) runHook(hookPath, event string, issue *types.Issue) error {
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+// execHook executes the hook with payload on stdin and env added to its
+// environment, and returns its output. It enforces a timeout, killing the
+// process group on expiration to ensure descendant processes are terminated.
+func execHook(hookPath, issueID, event string, payload []byte, timeout time.Duration, env []string) (stdout, stderr []byte, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	// Prepare JSON data for stdin
-	issueJSON, err := json.Marshal(issue)
-	if err != nil {
-		return err
-	}
-
 	// Create command: hook_script <issue_id> <event_type>
 	// #nosec G204 -- hookPath is from controlled .beads/hooks directory
-	cmd := exec.CommandContext(ctx, hookPath, issue.ID, event)
-	cmd.Stdin = bytes.NewReader(issueJSON)
+	cmd := exec.CommandContext(ctx, hookPath, issueID, event)
+	cmd.Stdin = bytes.NewReader(payload)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
-	// Capture output for debugging (but don't block on it)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// Capture output for pre-operation hooks and debugging
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
 
 	// Start the hook so we can manage its process group and kill children on timeout.
 	//
@@ -47,7 +44,7 @@ func (r *Runner) runHook(hookPath, event string, issue *types.Issue) error {
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
 	if err := cmd.Start(); err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	done := make(chan error, 1)
@@ -59,16 +56,13 @@ func (r *Runner) runHook(hookPath, event string, issue *types.Issue) error {
 	case <-ctx.Done():
 		if cmd.Process != nil {
 			if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
-				return fmt.Errorf("kill process group: %w", err)
+				return nil, nil, fmt.Errorf("kill process group: %w", err)
 			}
 		}
 		// Wait for process to exit after the kill attempt
 		<-done
-		return ctx.Err()
+		return outBuf.Bytes(), errBuf.Bytes(), ctx.Err()
 	case err := <-done:
-		if err != nil {
-			return err
-		}
-		return nil
+		return outBuf.Bytes(), errBuf.Bytes(), err
 	}
 }
@@ -5,34 +5,32 @@ package hooks
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"os"
 	"os/exec"
-
-	"github.com/steveyegge/beads/internal/types"
+	"time"
 )
 
-// runHook executes the hook and enforces a timeout on Windows.
+// execHook executes the hook with payload on stdin and env added to its
+// environment, returns its output and enforces a timeout on Windows.
 // Windows lacks Unix-style process groups; on timeout we best-effort kill
 // the started process. Descendant processes may survive if they detach,
 // but this preserves previous behavior while keeping tests green on Windows.
-func (r *Runner) runHook(hookPath, event string, issue *types.Issue) error {
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+func execHook(hookPath, issueID, event string, payload []byte, timeout time.Duration, env []string) (stdout, stderr []byte, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	issueJSON, err := json.Marshal(issue)
-	if err != nil {
-		return err
+	cmd := exec.CommandContext(ctx, hookPath, issueID, event)
+	cmd.Stdin = bytes.NewReader(payload)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
 	}
 
-	cmd := exec.CommandContext(ctx, hookPath, issue.ID, event)
-	cmd.Stdin = bytes.NewReader(issueJSON)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
 
 	if err := cmd.Start(); err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	done := make(chan error, 1)
@@ -46,8 +44,8 @@ func (r *Runner) runHook(hookPath, event string, issue *types.Issue) error {
 			_ = cmd.Process.Kill()
 		}
 		<-done
-		return ctx.Err()
+		return outBuf.Bytes(), errBuf.Bytes(), ctx.Err()
 	case err := <-done:
-		return err
+		return outBuf.Bytes(), errBuf.Bytes(), err
 	}
 }
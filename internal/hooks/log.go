@@ -0,0 +1,154 @@
+package hooks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// LogFileName is the hook run log in the .beads directory.
+const LogFileName = "hook-log.jsonl"
+
+const (
+	// maxLogSize is the size at which the run log is rotated to
+	// LogFileName.1, replacing the previous rotation.
+	maxLogSize = 1 << 20
+
+	// maxLogOutput is how much of a hook's stdout and stderr is kept in a
+	// run record.
+	maxLogOutput = 2048
+)
+
+// RunRecord records one run of a hook.
+type RunRecord struct {
+	Time       time.Time `json:"time"`
+	Hook       string    `json:"hook"`
+	Event      string    `json:"event"`
+	IssueID    string    `json:"issue_id,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	ExitCode   int       `json:"exit_code"`       // -1 if the hook did not exit on its own
+	Error      string    `json:"error,omitempty"` // Why the run failed
+	Stdout     string    `json:"stdout,omitempty"`
+	Stderr     string    `json:"stderr,omitempty"`
+}
+
+// Failed reports whether the run failed.
+func (rec *RunRecord) Failed() bool {
+	return rec.Error != ""
+}
+
+// SetLogPath makes the runner append a RunRecord for each hook it runs to
+// the file at path. An empty path disables the log.
+func (r *Runner) SetLogPath(path string) {
+	r.logPath = path
+}
+
+// Failures returns the post-event hooks that failed so far, in the order
+// they finished.
+func (r *Runner) Failures() []RunRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RunRecord(nil), r.failures...)
+}
+
+// exec runs a hook with its configured timeout and environment, plus
+// extraEnv, and describes the run.
+func (r *Runner) exec(hookPath string, env *Envelope, payload []byte, extraEnv ...string) (RunRecord, []byte, []byte, error) {
+	name := hookName(hookPath)
+	timeout := r.timeoutFor(name)
+	start := time.Now()
+	hookEnv := append(append([]string(nil), r.configs[name].Env...), extraEnv...)
+	stdout, stderr, err := execHook(hookPath, env.IssueID, env.Event, payload, timeout, hookEnv)
+
+	rec := RunRecord{
+		Time:       start.UTC(),
+		Hook:       name,
+		Event:      env.Event,
+		IssueID:    env.IssueID,
+		DurationMs: time.Since(start).Milliseconds(),
+		Stdout:     truncateOutput(stdout),
+		Stderr:     truncateOutput(stderr),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.Is(err, context.DeadlineExceeded):
+		rec.ExitCode = -1
+		rec.Error = fmt.Sprintf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		rec.ExitCode = exitErr.ExitCode()
+		rec.Error = err.Error()
+	default:
+		rec.ExitCode = -1
+		rec.Error = err.Error()
+	}
+	return rec, stdout, stderr, err
+}
+
+// appendLog appends a record to the run log, rotating it when it is full.
+// Best effort: a hook run is never failed by its log.
+func (r *Runner) appendLog(rec RunRecord) {
+	if r.logPath == "" {
+		return
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if info, err := os.Stat(r.logPath); err == nil && info.Size() > maxLogSize {
+		_ = os.Rename(r.logPath, r.logPath+".1") // Best effort: keep appending to the full log otherwise
+	}
+	// #nosec G304 -- log path is .beads/hook-log.jsonl
+	f, err := os.OpenFile(r.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(data, '\n'))
+}
+
+func truncateOutput(out []byte) string {
+	if len(out) <= maxLogOutput {
+		return string(out)
+	}
+	return string(out[:maxLogOutput]) + "\n... (truncated)"
+}
+
+// ReadLog returns the records in the run log at path, including its
+// rotation, oldest first. A missing log has no records.
+func ReadLog(path string) ([]RunRecord, error) {
+	var records []RunRecord
+	for _, p := range []string{path + ".1", path} {
+		// #nosec G304 -- log path is .beads/hook-log.jsonl
+		f, err := os.Open(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			var rec RunRecord
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				continue // Skip a line cut short by a concurrent writer
+			}
+			records = append(records, rec)
+		}
+		err = scanner.Err()
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
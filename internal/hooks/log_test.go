@@ -0,0 +1,233 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestRunLog_RecordsRuns(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	writeHook(t, tmpDir, HookOnClose, "echo closing\n")
+	writeHook(t, tmpDir, HookOnAny, "echo 'no webhook configured' >&2\nexit 3\n")
+
+	runner := NewRunner(tmpDir)
+	logPath := filepath.Join(tmpDir, LogFileName)
+	runner.SetLogPath(logPath)
+	if err := runner.EmitSync(NewEnvelope(EventClose, "alice", nil, &types.Issue{ID: "bd-1"})); err == nil {
+		t.Fatal("EmitSync returned nil, want the on_any failure")
+	}
+
+	records, err := ReadLog(logPath)
+	if err != nil {
+		t.Fatalf("ReadLog: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2: %+v", len(records), records)
+	}
+	ok, failed := records[0], records[1]
+	if ok.Hook != HookOnClose || ok.Event != EventClose || ok.IssueID != "bd-1" || ok.Failed() || ok.Stdout != "closing\n" {
+		t.Errorf("on_close record = %+v", ok)
+	}
+	if failed.Hook != HookOnAny || failed.ExitCode != 3 || !failed.Failed() || failed.Stderr != "no webhook configured\n" {
+		t.Errorf("on_any record = %+v", failed)
+	}
+}
+
+func TestRunLog_Failures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	writeHook(t, tmpDir, HookOnUpdate, "exit 1\n")
+
+	runner := NewRunner(tmpDir)
+	runner.Emit(NewEnvelope(EventUpdate, "alice", nil, &types.Issue{ID: "bd-1"}))
+	runner.Wait()
+
+	failures := runner.Failures()
+	if len(failures) != 1 || failures[0].Hook != HookOnUpdate || failures[0].ExitCode != 1 {
+		t.Errorf("Failures() = %+v, want the on_update run", failures)
+	}
+}
+
+func TestRunLog_Rotation(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), LogFileName)
+	runner := NewRunner(t.TempDir())
+	runner.SetLogPath(logPath)
+
+	big := strings.Repeat("x", maxLogOutput)
+	var written int
+	for written <= maxLogSize {
+		runner.appendLog(RunRecord{Hook: HookOnCreate, Event: EventCreate, Stdout: big})
+		written += len(big)
+	}
+	runner.appendLog(RunRecord{Hook: HookOnClose, Event: EventClose})
+
+	if _, err := os.Stat(logPath + ".1"); err != nil {
+		t.Fatalf("log was not rotated: %v", err)
+	}
+	records, err := ReadLog(logPath)
+	if err != nil {
+		t.Fatalf("ReadLog: %v", err)
+	}
+	if len(records) < 2 || records[len(records)-1].Hook != HookOnClose {
+		t.Errorf("ReadLog did not end with the latest record (%d records)", len(records))
+	}
+}
+
+func TestReadLog_Missing(t *testing.T) {
+	records, err := ReadLog(filepath.Join(t.TempDir(), LogFileName))
+	if err != nil || records != nil {
+		t.Errorf("ReadLog = (%v, %v), want no records", records, err)
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	if got := truncateOutput([]byte("short")); got != "short" {
+		t.Errorf("truncateOutput(short) = %q", got)
+	}
+	got := truncateOutput([]byte(strings.Repeat("x", maxLogOutput+10)))
+	if !strings.HasSuffix(got, "(truncated)") || len(got) > maxLogOutput+20 {
+		t.Errorf("truncateOutput(long) = %d bytes ending %q", len(got), got[len(got)-12:])
+	}
+}
+
+func TestHookConfig_Disabled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	writeHook(t, tmpDir, HookOnCreate, "exit 1\n")
+	writeHook(t, tmpDir, HookBeforeCreate, "exit 1\n")
+
+	runner := NewRunner(tmpDir)
+	runner.SetHookConfig(map[string]HookConfig{
+		HookOnCreate:     {Enabled: boolPtr(false)},
+		HookBeforeCreate: {Enabled: boolPtr(false)},
+	})
+	if runner.HookExists(EventCreate) {
+		t.Error("HookExists returned true for a disabled hook")
+	}
+	if err := runner.EmitSync(NewEnvelope(EventCreate, "alice", nil, &types.Issue{ID: "bd-1"})); err != nil {
+		t.Errorf("EmitSync ran a disabled hook: %v", err)
+	}
+	if _, err := runner.RunBefore(NewEnvelope(EventBeforeCreate, "alice", nil, &types.Issue{ID: "bd-1"})); err != nil {
+		t.Errorf("RunBefore ran a disabled hook: %v", err)
+	}
+}
+
+func TestHookConfig_SyncAndEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "output.txt")
+	writeHook(t, tmpDir, HookOnClose, "echo \"$CHANNEL\" > \""+outputFile+"\"\n")
+
+	// Hooks are synchronous by default
+	runner := NewRunner(tmpDir)
+	runner.SetHookConfig(map[string]HookConfig{
+		HookOnClose: {Env: []string{"CHANNEL=#builds"}},
+	})
+	runner.Emit(NewEnvelope(EventClose, "alice", nil, &types.Issue{ID: "bd-1"}))
+
+	// No Wait: a synchronous hook has finished when Emit returns.
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("synchronous hook had not run when Emit returned: %v", err)
+	}
+	if string(output) != "#builds\n" {
+		t.Errorf("hook saw CHANNEL=%q, want %q", output, "#builds\n")
+	}
+}
+
+func TestHookConfig_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	writeHook(t, tmpDir, HookOnUpdate, "sleep 5\n")
+
+	runner := NewRunner(tmpDir)
+	runner.SetTimeout(time.Minute)
+	runner.SetHookConfig(map[string]HookConfig{HookOnUpdate: {Timeout: 200 * time.Millisecond}})
+	err := runner.EmitSync(NewEnvelope(EventUpdate, "alice", nil, &types.Issue{ID: "bd-1"}))
+	if err == nil {
+		t.Fatal("EmitSync returned nil, want a timeout")
+	}
+	failures := runner.Failures()
+	if len(failures) != 1 || failures[0].Error != "timed out after 200ms" || failures[0].ExitCode != -1 {
+		t.Errorf("Failures() = %+v, want a 200ms timeout", failures)
+	}
+}
+
+func TestHookNames(t *testing.T) {
+	names := HookNames()
+	for _, name := range []string{HookOnCreate, HookOnUnblocked, HookOnAny, HookBeforeClose} {
+		found := false
+		for _, n := range names {
+			found = found || n == name
+		}
+		if !found {
+			t.Errorf("HookNames() is missing %s", name)
+		}
+	}
+
+	tests := []struct {
+		hook   string
+		event  string
+		before bool
+	}{
+		{HookOnClose, EventClose, false},
+		{HookBeforeUpdate, EventBeforeUpdate, true},
+		{HookOnAny, "", false},
+		{"on_nothing", "", false},
+	}
+	for _, tt := range tests {
+		if got := EventForHook(tt.hook); got != tt.event {
+			t.Errorf("EventForHook(%q) = %q, want %q", tt.hook, got, tt.event)
+		}
+		if got := IsBeforeHook(tt.hook); got != tt.before {
+			t.Errorf("IsBeforeHook(%q) = %v, want %v", tt.hook, got, tt.before)
+		}
+	}
+}
+
+func TestRunnerTest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows")
+	}
+	tmpDir := t.TempDir()
+	writeHook(t, tmpDir, HookOnClose, "echo \"dry=$"+DryRunEnv+" $1 $2\"\n")
+
+	runner := NewRunner(tmpDir)
+	logPath := filepath.Join(tmpDir, LogFileName)
+	runner.SetLogPath(logPath)
+	runner.SetHookConfig(map[string]HookConfig{HookOnClose: {Enabled: boolPtr(false)}})
+
+	rec, err := runner.Test(HookOnClose, NewEnvelope(EventClose, "alice", nil, &types.Issue{ID: "bd-1"}))
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if rec.Failed() || rec.Stdout != "dry=1 bd-1 close\n" {
+		t.Errorf("Test record = %+v", rec)
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Error("Test logged the dry run")
+	}
+
+	if _, err := runner.Test(HookOnCreate, NewEnvelope(EventCreate, "alice", nil, &types.Issue{ID: "bd-1"})); err == nil {
+		t.Error("Test returned nil for a missing hook")
+	}
+}
@@ -0,0 +1,253 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// noneGroup is the group key for issues with no value for a dimension.
+const noneGroup = "(none)"
+
+// groupDimensions maps fields usable in GROUP BY to their issue columns
+// (see types.IsGroupableIssueColumn); label dimensions have none. Quoted
+// "<dimension>:*" terms group by the value of state labels (see bd set-state).
+var groupDimensions = map[string]string{
+	"status":        "status",
+	"priority":      "priority",
+	"type":          "issue_type",
+	"assignee":      "assignee",
+	"owner":         "owner",
+	"label":         "",
+	"labels":        "", // alias
+	"mol_type":      "mol_type",
+	"wisp_type":     "wisp_type",
+	"source_system": "source_system",
+}
+
+// aggregateFields maps fields usable in aggregates to their issue columns
+// (see types.IsAggregatableIssueColumn). Timestamps support min/max only.
+var aggregateFields = map[string]string{
+	"estimate": "estimated_minutes", // minutes
+	"priority": "priority",
+	"age":      "age", // days since creation
+	"created":  "created_at",
+	"updated":  "updated_at",
+	"closed":   "closed_at",
+	"due":      "due_at",
+	"defer":    "defer_until",
+}
+
+// isStateDimension reports whether a GROUP BY term is a "<dimension>:*" state label group.
+func isStateDimension(term string) bool {
+	return strings.HasSuffix(term, ":*") && len(term) > 2
+}
+
+// Aggregate is an aggregate function in a GROUP BY clause (e.g., sum(estimate)).
+type Aggregate struct {
+	Func  string // count, sum, avg, min, max
+	Field string // empty for count()
+}
+
+// String returns the aggregate as written in a query.
+func (a Aggregate) String() string {
+	return fmt.Sprintf("%s(%s)", a.Func, a.Field)
+}
+
+// Name returns the aggregate's column name in results (count, sum_estimate).
+func (a Aggregate) Name() string {
+	if a.Field == "" {
+		return a.Func
+	}
+	return a.Func + "_" + a.Field
+}
+
+func (a Aggregate) validate() error {
+	switch a.Func {
+	case "count":
+		if a.Field != "" {
+			return fmt.Errorf("count() takes no argument")
+		}
+		return nil
+	case "sum", "avg", "min", "max":
+		column, ok := aggregateFields[a.Field]
+		if !ok {
+			return fmt.Errorf("cannot aggregate %s(%s)", a.Func, a.Field)
+		}
+		_, isTime := types.IsAggregatableIssueColumn(column)
+		if isTime && (a.Func == "sum" || a.Func == "avg") {
+			return fmt.Errorf("%s() is not supported for timestamp field %s (use min or max)", a.Func, a.Field)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown aggregate %s()", a.Func)
+	}
+}
+
+// groupColumn returns the result column name for a GROUP BY dimension.
+func groupColumn(dim string) string {
+	if isStateDimension(dim) {
+		return strings.TrimSuffix(dim, ":*")
+	}
+	if dim == "labels" {
+		return "label"
+	}
+	return dim
+}
+
+// validateGroupOrder checks that ORDER BY terms on a grouped query name a
+// group column or an aggregate.
+func (q *Query) validateGroupOrder() error {
+	if len(q.GroupBy) == 0 {
+		return nil
+	}
+	for _, t := range q.OrderBy {
+		if q.groupColumnIndex(t.Field) < 0 {
+			return fmt.Errorf("cannot ORDER BY %s in a grouped query (use a GROUP BY field or aggregate name)", t.Field)
+		}
+	}
+	return nil
+}
+
+// groupColumnIndex returns the index of a result column (group dimensions
+// first, then aggregates), or -1 if the query has no such column.
+func (q *Query) groupColumnIndex(name string) int {
+	for i, dim := range q.GroupBy {
+		if groupColumn(dim) == name || dim == name {
+			return i
+		}
+	}
+	for i, a := range q.Aggregates {
+		if a.Name() == name {
+			return len(q.GroupBy) + i
+		}
+	}
+	return -1
+}
+
+// GroupRow is one group of an aggregation.
+type GroupRow struct {
+	Keys   []string // One value per GROUP BY dimension
+	Values []any    // One value per aggregate: int, float64, time.Time or nil
+}
+
+// Aggregation is the result of a GROUP BY query.
+type Aggregation struct {
+	Columns    []string // Group dimension column names
+	Aggregates []Aggregate
+	Rows       []GroupRow
+	Total      int // Number of issues aggregated
+}
+
+// Headers returns the result column names (dimensions, then aggregates).
+func (a *Aggregation) Headers() []string {
+	headers := slices.Clone(a.Columns)
+	for _, agg := range a.Aggregates {
+		headers = append(headers, agg.String())
+	}
+	return headers
+}
+
+// MarshalJSON renders each group as an object keyed by column name.
+func (a *Aggregation) MarshalJSON() ([]byte, error) {
+	names := make([]string, len(a.Aggregates))
+	for i, agg := range a.Aggregates {
+		names[i] = agg.Name()
+	}
+	groups := make([]map[string]any, len(a.Rows))
+	for i, row := range a.Rows {
+		g := make(map[string]any, len(row.Keys)+len(row.Values))
+		for j, key := range row.Keys {
+			g[a.Columns[j]] = key
+		}
+		for j, v := range row.Values {
+			g[names[j]] = v
+		}
+		groups[i] = g
+	}
+	return json.Marshal(struct {
+		GroupBy    []string         `json:"group_by"`
+		Aggregates []string         `json:"aggregates"`
+		Total      int              `json:"total"`
+		Groups     []map[string]any `json:"groups"`
+	}{a.Columns, names, a.Total, groups})
+}
+
+// Grouping resolves the query's GROUP BY, with its ORDER BY and LIMIT
+// (which apply to the groups), into the grouping storage runs. now is the
+// reference time for age.
+func (q *Query) Grouping(now time.Time) (types.IssueGrouping, error) {
+	if len(q.GroupBy) == 0 {
+		return types.IssueGrouping{}, fmt.Errorf("query has no GROUP BY clause")
+	}
+	grouping := types.IssueGrouping{Limit: q.Limit, Now: now}
+	for _, dim := range q.GroupBy {
+		switch {
+		case dim == "label" || dim == "labels":
+			grouping.Keys = append(grouping.Keys, types.GroupKey{Labels: true})
+		case isStateDimension(dim):
+			grouping.Keys = append(grouping.Keys, types.GroupKey{Labels: true, LabelPrefix: strings.TrimSuffix(dim, "*")})
+		default:
+			grouping.Keys = append(grouping.Keys, types.GroupKey{Column: groupDimensions[dim]})
+		}
+	}
+	for _, a := range q.Aggregates {
+		grouping.Aggregates = append(grouping.Aggregates, types.GroupAggregate{Func: a.Func, Column: aggregateFields[a.Field]})
+	}
+	for _, t := range q.OrderBy {
+		idx := q.groupColumnIndex(t.Field)
+		if idx < 0 {
+			return types.IssueGrouping{}, fmt.Errorf("cannot ORDER BY %s in a grouped query (use a GROUP BY field or aggregate name)", t.Field)
+		}
+		grouping.OrderBy = append(grouping.OrderBy, types.GroupOrder{Index: idx, Desc: t.Desc})
+	}
+	return grouping, nil
+}
+
+// NewAggregation builds the result of a GROUP BY query from the groups
+// storage returned and the number of issues they cover.
+func NewAggregation(q *Query, groups []types.IssueGroup, total int) *Aggregation {
+	agg := &Aggregation{Aggregates: q.Aggregates, Total: total}
+	for _, dim := range q.GroupBy {
+		agg.Columns = append(agg.Columns, groupColumn(dim))
+	}
+	for _, g := range groups {
+		row := GroupRow{Keys: make([]string, len(g.Keys)), Values: g.Values}
+		for i, key := range g.Keys {
+			switch {
+			case key == "":
+				row.Keys[i] = noneGroup
+			case q.GroupBy[i] == "priority":
+				row.Keys[i] = "P" + key
+			default:
+				row.Keys[i] = key
+			}
+		}
+		agg.Rows = append(agg.Rows, row)
+	}
+	return agg
+}
+
+// FormatAggregateValue renders an aggregate value for table output.
+func FormatAggregateValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "-"
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		if val == float64(int64(val)) {
+			return strconv.FormatInt(int64(val), 10)
+		}
+		return strconv.FormatFloat(val, 'f', 1, 64)
+	case time.Time:
+		return val.Local().Format("2006-01-02 15:04")
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -11,25 +11,21 @@ import (
 )
 
 // QueryResult contains the result of evaluating a query.
-// For simple queries, Filter will be populated and Predicate will be nil.
-// For complex queries with OR, Predicate will be set and Filter will contain
-// base filters that can pre-filter issues.
+// Filter always expresses the whole query: simple AND chains map onto its
+// fields, and compound queries (OR, NOT over expressions, != comparisons)
+// add a FilterExpr in Filter.Where that storage renders as SQL.
 type QueryResult struct {
-	// Filter contains filters that can be passed to SearchIssues.
-	// This is always populated with at least base filters.
+	// Filter contains the filters to pass to SearchIssues, including the
+	// query's ORDER BY and LIMIT.
 	Filter types.IssueFilter
 
-	// Predicate is a function that evaluates whether an issue matches the query.
-	// If nil, the Filter alone is sufficient.
-	// If non-nil, issues matching Filter should be further filtered by Predicate.
-	Predicate func(*types.Issue) bool
-
-	// RequiresPredicate indicates if in-memory filtering is needed.
-	// True when the query contains OR or complex NOT expressions.
-	RequiresPredicate bool
+	// OrderBy and Limit come from the query's ORDER BY and LIMIT clauses
+	// (also set on Filter).
+	OrderBy []types.IssueOrder
+	Limit   int
 }
 
-// Evaluator converts a query AST to an IssueFilter and/or predicate function.
+// Evaluator converts a query AST to an IssueFilter.
 type Evaluator struct {
 	now time.Time
 }
@@ -53,17 +49,146 @@ func (e *Evaluator) Evaluate(node Node) (*QueryResult, error) {
 		return result, nil
 	}
 
-	// Complex query: build predicate and extract base filters
-	pred, err := e.buildPredicate(node)
-	if err != nil {
-		return nil, err
+	// Compound query: filter-compatible conjuncts go into the filter, the
+	// rest into a FilterExpr that storage renders as SQL
+	var rest []*types.FilterExpr
+	for _, conjunct := range conjuncts(node) {
+		if e.canUseFilterOnly(conjunct) {
+			if err := e.buildFilter(conjunct, &result.Filter); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		expr, err := e.buildExpr(conjunct)
+		if err != nil {
+			return nil, err
+		}
+		rest = append(rest, expr)
+	}
+	if len(rest) == 1 {
+		result.Filter.Where = rest[0]
+	} else {
+		result.Filter.Where = &types.FilterExpr{And: rest}
+	}
+
+	return result, nil
+}
+
+// conjuncts flattens a top-level AND chain.
+func conjuncts(node Node) []Node {
+	if and, ok := node.(*AndNode); ok {
+		return append(conjuncts(and.Left), conjuncts(and.Right)...)
+	}
+	return []Node{node}
+}
+
+// buildExpr compiles an arbitrary expression into a FilterExpr tree whose
+// leaves are IssueFilters built by applyComparison and applyFunction.
+func (e *Evaluator) buildExpr(node Node) (*types.FilterExpr, error) {
+	switch n := node.(type) {
+	case *ComparisonNode:
+		return e.comparisonExpr(n)
+	case *FunctionNode:
+		var filter types.IssueFilter
+		if err := e.applyFunction(n, &filter, false); err != nil {
+			return nil, err
+		}
+		return &types.FilterExpr{Match: &filter}, nil
+	case *AndNode:
+		var children []*types.FilterExpr
+		for _, c := range conjuncts(n) {
+			child, err := e.buildExpr(c)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		}
+		return &types.FilterExpr{And: children}, nil
+	case *OrNode:
+		left, err := e.buildExpr(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.buildExpr(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		// Flatten nested ORs: a OR b OR c is one list
+		var children []*types.FilterExpr
+		for _, child := range []*types.FilterExpr{left, right} {
+			if len(child.Or) > 0 {
+				children = append(children, child.Or...)
+			} else {
+				children = append(children, child)
+			}
+		}
+		return &types.FilterExpr{Or: children}, nil
+	case *NotNode:
+		operand, err := e.buildExpr(n.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return &types.FilterExpr{Not: operand}, nil
+	default:
+		return nil, fmt.Errorf("unexpected node type: %T", node)
 	}
-	result.Predicate = pred
-	result.RequiresPredicate = true
+}
 
-	// Extract base filters for pre-filtering (optional optimization)
-	e.extractBaseFilters(node, &result.Filter)
+// comparisonExpr compiles one comparison. Filter fields only express
+// positive conditions, so field!=value becomes NOT (field=value); unset
+// values then match, as in the predicates (assignee!=alice includes
+// unassigned issues). closed!=day is the exception: issues that are not
+// closed never match a closed comparison.
+func (e *Evaluator) comparisonExpr(comp *ComparisonNode) (*types.FilterExpr, error) {
+	if comp.Op == OpNotEquals {
+		eq := *comp
+		eq.Op = OpEquals
+		if comp.Field == "closed" || comp.Field == "closed_at" {
+			var day types.IssueFilter
+			if err := e.applyClosedFilter(&eq, &day); err != nil {
+				return nil, err
+			}
+			return &types.FilterExpr{Or: []*types.FilterExpr{
+				{Match: &types.IssueFilter{ClosedBefore: day.ClosedAfter}},
+				{Match: &types.IssueFilter{ClosedAfter: day.ClosedBefore}},
+			}}, nil
+		}
+		match, err := e.comparisonExpr(&eq)
+		if err != nil {
+			return nil, err
+		}
+		return &types.FilterExpr{Not: match}, nil
+	}
+	var filter types.IssueFilter
+	if err := e.applyComparison(comp, &filter); err != nil {
+		return nil, err
+	}
+	return &types.FilterExpr{Match: &filter}, nil
+}
 
+// EvaluateQuery evaluates a full query including ORDER BY and LIMIT clauses.
+// For GROUP BY queries the clauses apply to groups (see Query.Grouping), so
+// they are not resolved here and nothing is pushed down into the filter.
+func (e *Evaluator) EvaluateQuery(q *Query) (*QueryResult, error) {
+	if q.Where == nil {
+		// GROUP BY over all issues
+		return &QueryResult{}, nil
+	}
+	result, err := e.Evaluate(q.Where)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.GroupBy) > 0 {
+		return result, nil
+	}
+	order, err := resolveOrder(q.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	result.OrderBy = order
+	result.Limit = q.Limit
+	result.Filter.OrderBy = order
+	result.Filter.Limit = q.Limit
 	return result, nil
 }
 
@@ -75,6 +200,8 @@ func (e *Evaluator) Evaluate(node Node) (*QueryResult, error) {
 func (e *Evaluator) canUseFilterOnly(node Node) bool {
 	switch n := node.(type) {
 	case *ComparisonNode:
+		return !needsExpr(n)
+	case *FunctionNode:
 		return true
 	case *AndNode:
 		return e.canUseFilterOnly(n.Left) && e.canUseFilterOnly(n.Right)
@@ -86,11 +213,14 @@ func (e *Evaluator) canUseFilterOnly(node Node) bool {
 				return comp.Op == OpEquals
 			case "type":
 				return comp.Op == OpEquals
+			case "blocked", "ready", "has_deps":
+				return comp.Op == OpEquals
 			default:
 				return false
 			}
 		}
-		return false
+		_, isFunc := n.Operand.(*FunctionNode)
+		return isFunc
 	case *OrNode:
 		// OR can be filter-compatible for labels
 		return e.canUseLabelsAnyOptimization(n)
@@ -131,6 +261,8 @@ func (e *Evaluator) buildFilter(node Node, filter *types.IssueFilter) error {
 	switch n := node.(type) {
 	case *ComparisonNode:
 		return e.applyComparison(n, filter)
+	case *FunctionNode:
+		return e.applyFunction(n, filter, false)
 	case *AndNode:
 		if err := e.buildFilter(n.Left, filter); err != nil {
 			return err
@@ -192,11 +324,43 @@ func (e *Evaluator) applyComparison(comp *ComparisonNode, filter *types.IssueFil
 		return e.applyBoolFilter(comp, filter, "template")
 	case "mol_type":
 		return e.applyMolTypeFilter(comp, filter)
+	case "due", "due_at":
+		return e.applyScheduleFilter(comp, &filter.DueAfter, &filter.DueBefore, &filter.NoDue)
+	case "defer", "defer_until":
+		return e.applyScheduleFilter(comp, &filter.DeferAfter, &filter.DeferBefore, &filter.NoDefer)
+	case "estimate", "estimated_minutes":
+		return e.applyEstimateFilter(comp, filter)
+	case "external_ref":
+		return e.applyExternalRefFilter(comp, filter)
+	case "source_system":
+		return e.applySourceSystemFilter(comp, filter)
+	case "comments":
+		return e.applyCommentsFilter(comp, filter)
+	case "blocked":
+		return e.applyBoolFilter(comp, filter, "blocked")
+	case "ready":
+		return e.applyBoolFilter(comp, filter, "ready")
+	case "has_deps":
+		return e.applyBoolFilter(comp, filter, "has_deps")
+	case "wisp_type":
+		return e.applyWispTypeFilter(comp, filter)
 	default:
 		return fmt.Errorf("unknown field: %s", comp.Field)
 	}
 }
 
+// needsExpr reports whether a comparison cannot be expressed by IssueFilter
+// fields alone. Filters only hold positive conditions, so != (other than on
+// status and type, which have exclusion lists) compiles to a FilterExpr NOT.
+func needsExpr(comp *ComparisonNode) bool {
+	switch comp.Field {
+	case "status", "type":
+		return false
+	default:
+		return comp.Op == OpNotEquals
+	}
+}
+
 func (e *Evaluator) applyStatusFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
 	if comp.Op != OpEquals && comp.Op != OpNotEquals {
 		return fmt.Errorf("status only supports = and != operators")
@@ -276,8 +440,11 @@ func (e *Evaluator) applyAssigneeFilter(comp *ComparisonNode, filter *types.Issu
 }
 
 func (e *Evaluator) applyOwnerFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
-	// Owner filtering requires predicate
-	return fmt.Errorf("owner filtering requires predicate mode")
+	if comp.Op != OpEquals {
+		return fmt.Errorf("owner only supports = operator")
+	}
+	filter.Owner = &comp.Value
+	return nil
 }
 
 func (e *Evaluator) applyLabelFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
@@ -379,6 +546,11 @@ func (e *Evaluator) applyClosedFilter(comp *ComparisonNode, filter *types.IssueF
 		return fmt.Errorf("invalid closed time: %w", err)
 	}
 	switch comp.Op {
+	case OpEquals:
+		dayStart := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		dayEnd := dayStart.Add(24 * time.Hour)
+		filter.ClosedAfter = &dayStart
+		filter.ClosedBefore = &dayEnd
 	case OpGreater:
 		filter.ClosedAfter = &t
 	case OpGreaterEq:
@@ -450,464 +622,294 @@ func (e *Evaluator) applyBoolFilter(comp *ComparisonNode, filter *types.IssueFil
 		filter.Ephemeral = &boolVal
 	case "template":
 		filter.IsTemplate = &boolVal
+	case "blocked":
+		filter.Blocked = &boolVal
+	case "ready":
+		filter.Ready = &boolVal
+	case "has_deps":
+		filter.HasDependencies = &boolVal
 	}
 	return nil
 }
 
-func (e *Evaluator) applyMolTypeFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
-	if comp.Op != OpEquals {
-		return fmt.Errorf("mol_type only supports = operator")
-	}
-	mt := types.MolType(strings.ToLower(comp.Value))
-	if !mt.IsValid() {
-		return fmt.Errorf("invalid mol_type: %s", comp.Value)
-	}
-	filter.MolType = &mt
-	return nil
-}
-
-// applyNot applies a NOT expression to the filter.
-func (e *Evaluator) applyNot(not *NotNode, filter *types.IssueFilter) error {
-	comp, ok := not.Operand.(*ComparisonNode)
-	if !ok {
-		return fmt.Errorf("NOT only supports simple comparisons in filter mode")
-	}
-
-	switch comp.Field {
-	case "status":
-		if comp.Op != OpEquals {
-			return fmt.Errorf("NOT status only supports = operator")
-		}
-		status := types.Status(strings.ToLower(comp.Value))
-		filter.ExcludeStatus = append(filter.ExcludeStatus, status)
-		return nil
-	case "type":
+// applyScheduleFilter applies a due/defer comparison using the given filter slots.
+func (e *Evaluator) applyScheduleFilter(comp *ComparisonNode, after, before **time.Time, none *bool) error {
+	if isNoneValue(comp.Value) {
 		if comp.Op != OpEquals {
-			return fmt.Errorf("NOT type only supports = operator")
+			return fmt.Errorf("%s=none only supports = operator", comp.Field)
 		}
-		issueType := types.IssueType(strings.ToLower(comp.Value))
-		filter.ExcludeTypes = append(filter.ExcludeTypes, issueType)
+		*none = true
 		return nil
-	default:
-		return fmt.Errorf("NOT not supported for field %s in filter mode", comp.Field)
 	}
-}
-
-// parseTimeValue parses a time value from a comparison node.
-// Supports duration values (7d, 24h) which are interpreted as "now - duration".
-func (e *Evaluator) parseTimeValue(comp *ComparisonNode) (time.Time, error) {
-	if comp.ValueType == TokenDuration {
-		// Duration values like 7d mean "7 days ago" for < comparisons
-		// and "within the last 7 days" for > comparisons
-		// We parse as relative to now, going backwards
-		return e.parseDurationAgo(comp.Value)
+	t, err := e.parseScheduleTime(comp)
+	if err != nil {
+		return fmt.Errorf("invalid %s time: %w", comp.Field, err)
 	}
-	// Otherwise use the standard time parser
-	return timeparsing.ParseRelativeTime(comp.Value, e.now)
-}
-
-// parseDurationAgo parses a duration and returns now - duration.
-func (e *Evaluator) parseDurationAgo(s string) (time.Time, error) {
-	// Negate the duration to get time in the past
-	negated := "-" + strings.TrimPrefix(s, "+")
-	return timeparsing.ParseCompactDuration(negated, e.now)
-}
-
-// extractBaseFilters extracts filter-compatible portions from a complex query.
-// This is used to pre-filter before applying the predicate.
-func (e *Evaluator) extractBaseFilters(node Node, filter *types.IssueFilter) {
-	switch n := node.(type) {
-	case *ComparisonNode:
-		// Try to apply, ignore errors (best-effort optimization: incompatible filters are safely skipped)
-		_ = e.applyComparison(n, filter)
-	case *AndNode:
-		e.extractBaseFilters(n.Left, filter)
-		e.extractBaseFilters(n.Right, filter)
-	case *NotNode:
-		_ = e.applyNot(n, filter) // Best-effort optimization: incompatible NOT filters are safely skipped
-	case *OrNode:
-		// For OR, we can't safely extract base filters
-		// (extracting from either side would over-filter)
+	// Storage compares the bounds strictly and at second precision, so the
+	// day's bounds are the last second before it and the next midnight
+	dayStart := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	beforeDay := dayStart.Add(-time.Second)
+	nextDay := dayStart.AddDate(0, 0, 1)
+	switch comp.Op {
+	case OpEquals:
+		*after = &beforeDay
+		*before = &nextDay
+	case OpGreater:
+		*after = &t
+	case OpGreaterEq:
+		*after = &beforeDay
+	case OpLess:
+		*before = &t
+	case OpLessEq:
+		*before = &nextDay
+	default:
+		return fmt.Errorf("%s does not support %s operator", comp.Field, comp.Op.String())
 	}
+	return nil
 }
 
-// buildPredicate builds a predicate function for complex queries.
-func (e *Evaluator) buildPredicate(node Node) (func(*types.Issue) bool, error) {
-	switch n := node.(type) {
-	case *ComparisonNode:
-		return e.buildComparisonPredicate(n)
-	case *AndNode:
-		left, err := e.buildPredicate(n.Left)
-		if err != nil {
-			return nil, err
-		}
-		right, err := e.buildPredicate(n.Right)
-		if err != nil {
-			return nil, err
-		}
-		return func(issue *types.Issue) bool {
-			return left(issue) && right(issue)
-		}, nil
-	case *OrNode:
-		left, err := e.buildPredicate(n.Left)
-		if err != nil {
-			return nil, err
-		}
-		right, err := e.buildPredicate(n.Right)
-		if err != nil {
-			return nil, err
-		}
-		return func(issue *types.Issue) bool {
-			return left(issue) || right(issue)
-		}, nil
-	case *NotNode:
-		operand, err := e.buildPredicate(n.Operand)
-		if err != nil {
-			return nil, err
+func (e *Evaluator) applyEstimateFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
+	if isNoneValue(comp.Value) {
+		if comp.Op != OpEquals {
+			return fmt.Errorf("estimate=none only supports = operator")
 		}
-		return func(issue *types.Issue) bool {
-			return !operand(issue)
-		}, nil
-	default:
-		return nil, fmt.Errorf("unexpected node type: %T", node)
+		filter.NoEstimate = true
+		return nil
 	}
-}
-
-// buildComparisonPredicate builds a predicate for a single comparison.
-func (e *Evaluator) buildComparisonPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	switch comp.Field {
-	case "status":
-		return e.buildStatusPredicate(comp)
-	case "priority":
-		return e.buildPriorityPredicate(comp)
-	case "type":
-		return e.buildTypePredicate(comp)
-	case "assignee":
-		return e.buildAssigneePredicate(comp)
-	case "owner":
-		return e.buildOwnerPredicate(comp)
-	case "label", "labels":
-		return e.buildLabelPredicate(comp)
-	case "title":
-		return e.buildTitlePredicate(comp)
-	case "description", "desc":
-		return e.buildDescriptionPredicate(comp)
-	case "notes":
-		return e.buildNotesPredicate(comp)
-	case "created", "created_at":
-		return e.buildCreatedPredicate(comp)
-	case "updated", "updated_at":
-		return e.buildUpdatedPredicate(comp)
-	case "closed", "closed_at":
-		return e.buildClosedPredicate(comp)
-	case "id":
-		return e.buildIDPredicate(comp)
-	case "spec", "spec_id":
-		return e.buildSpecPredicate(comp)
-	case "pinned":
-		return e.buildBoolPredicate(comp, func(i *types.Issue) bool { return i.Pinned })
-	case "ephemeral":
-		return e.buildBoolPredicate(comp, func(i *types.Issue) bool { return i.Ephemeral })
-	case "template":
-		return e.buildBoolPredicate(comp, func(i *types.Issue) bool { return i.IsTemplate })
-	default:
-		return nil, fmt.Errorf("unknown field: %s", comp.Field)
+	minutes, err := parseEstimateMinutes(comp)
+	if err != nil {
+		return err
 	}
+	return applyIntRange(comp, minutes, &filter.EstimateMin, &filter.EstimateMax)
 }
 
-func (e *Evaluator) buildStatusPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	status := types.Status(strings.ToLower(comp.Value))
-	switch comp.Op {
-	case OpEquals:
-		return func(i *types.Issue) bool { return i.Status == status }, nil
-	case OpNotEquals:
-		return func(i *types.Issue) bool { return i.Status != status }, nil
-	default:
-		return nil, fmt.Errorf("status does not support %s operator", comp.Op.String())
+func (e *Evaluator) applyCommentsFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
+	n, err := strconv.Atoi(comp.Value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid comments count: %s", comp.Value)
 	}
+	return applyIntRange(comp, n, &filter.CommentsMin, &filter.CommentsMax)
 }
 
-func (e *Evaluator) buildPriorityPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	priority, err := strconv.Atoi(comp.Value)
-	if err != nil {
-		return nil, fmt.Errorf("invalid priority: %s", comp.Value)
-	}
+// applyIntRange maps a numeric comparison onto inclusive min/max filter bounds.
+func applyIntRange(comp *ComparisonNode, v int, minPtr, maxPtr **int) error {
 	switch comp.Op {
 	case OpEquals:
-		return func(i *types.Issue) bool { return i.Priority == priority }, nil
-	case OpNotEquals:
-		return func(i *types.Issue) bool { return i.Priority != priority }, nil
+		lo, hi := v, v
+		*minPtr, *maxPtr = &lo, &hi
 	case OpLess:
-		return func(i *types.Issue) bool { return i.Priority < priority }, nil
+		if v <= 0 {
+			return fmt.Errorf("%s < %d matches nothing", comp.Field, v)
+		}
+		hi := v - 1
+		*maxPtr = &hi
 	case OpLessEq:
-		return func(i *types.Issue) bool { return i.Priority <= priority }, nil
+		*maxPtr = &v
 	case OpGreater:
-		return func(i *types.Issue) bool { return i.Priority > priority }, nil
+		lo := v + 1
+		*minPtr = &lo
 	case OpGreaterEq:
-		return func(i *types.Issue) bool { return i.Priority >= priority }, nil
+		*minPtr = &v
 	default:
-		return nil, fmt.Errorf("unexpected operator: %s", comp.Op.String())
+		return fmt.Errorf("%s does not support %s operator in filter mode", comp.Field, comp.Op.String())
 	}
+	return nil
 }
 
-func (e *Evaluator) buildTypePredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	issueType := types.IssueType(strings.ToLower(comp.Value))
-	switch comp.Op {
-	case OpEquals:
-		return func(i *types.Issue) bool { return i.IssueType == issueType }, nil
-	case OpNotEquals:
-		return func(i *types.Issue) bool { return i.IssueType != issueType }, nil
-	default:
-		return nil, fmt.Errorf("type does not support %s operator", comp.Op.String())
+func (e *Evaluator) applyExternalRefFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
+	if comp.Op != OpEquals {
+		return fmt.Errorf("external_ref only supports = operator in filter mode")
 	}
-}
-
-func (e *Evaluator) buildAssigneePredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	value := comp.Value
-	isNone := value == "" || strings.ToLower(value) == "none" || strings.ToLower(value) == "null"
-	switch comp.Op {
-	case OpEquals:
-		if isNone {
-			return func(i *types.Issue) bool { return i.Assignee == "" }, nil
-		}
-		return func(i *types.Issue) bool { return strings.EqualFold(i.Assignee, value) }, nil
-	case OpNotEquals:
-		if isNone {
-			return func(i *types.Issue) bool { return i.Assignee != "" }, nil
-		}
-		return func(i *types.Issue) bool { return !strings.EqualFold(i.Assignee, value) }, nil
+	switch {
+	case isNoneValue(comp.Value):
+		filter.NoExternalRef = true
+	case strings.HasSuffix(comp.Value, "*"):
+		filter.ExternalRefPrefix = strings.TrimSuffix(comp.Value, "*")
 	default:
-		return nil, fmt.Errorf("assignee does not support %s operator", comp.Op.String())
+		filter.ExternalRef = &comp.Value
 	}
+	return nil
 }
 
-func (e *Evaluator) buildOwnerPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+func (e *Evaluator) applySourceSystemFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
+	if comp.Op != OpEquals {
+		return fmt.Errorf("source_system only supports = operator in filter mode")
+	}
 	value := comp.Value
-	switch comp.Op {
-	case OpEquals:
-		return func(i *types.Issue) bool { return strings.EqualFold(i.Owner, value) }, nil
-	case OpNotEquals:
-		return func(i *types.Issue) bool { return !strings.EqualFold(i.Owner, value) }, nil
-	default:
-		return nil, fmt.Errorf("owner does not support %s operator", comp.Op.String())
+	if isNoneValue(value) {
+		value = ""
 	}
+	filter.SourceSystem = &value
+	return nil
 }
 
-func (e *Evaluator) buildLabelPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	value := comp.Value
-	isNone := value == "" || strings.ToLower(value) == "none" || strings.ToLower(value) == "null"
-	switch comp.Op {
-	case OpEquals:
-		if isNone {
-			return func(i *types.Issue) bool { return len(i.Labels) == 0 }, nil
-		}
-		return func(i *types.Issue) bool {
-			for _, l := range i.Labels {
-				if strings.EqualFold(l, value) {
-					return true
-				}
-			}
-			return false
-		}, nil
-	case OpNotEquals:
-		if isNone {
-			return func(i *types.Issue) bool { return len(i.Labels) > 0 }, nil
-		}
-		return func(i *types.Issue) bool {
-			for _, l := range i.Labels {
-				if strings.EqualFold(l, value) {
-					return false
-				}
-			}
-			return true
-		}, nil
-	default:
-		return nil, fmt.Errorf("label does not support %s operator", comp.Op.String())
+func (e *Evaluator) applyWispTypeFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
+	if comp.Op != OpEquals {
+		return fmt.Errorf("wisp_type only supports = operator in filter mode")
+	}
+	wt := types.WispType(strings.ToLower(comp.Value))
+	if wt == "" || !wt.IsValid() {
+		return fmt.Errorf("invalid wisp_type: %s", comp.Value)
 	}
+	filter.WispType = &wt
+	return nil
 }
 
-func (e *Evaluator) buildTitlePredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	value := strings.ToLower(comp.Value)
-	switch comp.Op {
-	case OpEquals:
-		return func(i *types.Issue) bool {
-			return strings.Contains(strings.ToLower(i.Title), value)
-		}, nil
-	case OpNotEquals:
-		return func(i *types.Issue) bool {
-			return !strings.Contains(strings.ToLower(i.Title), value)
-		}, nil
-	default:
-		return nil, fmt.Errorf("title does not support %s operator", comp.Op.String())
-	}
+// isNoneValue reports whether a comparison value means "unset".
+func isNoneValue(v string) bool {
+	return v == "" || strings.EqualFold(v, "none") || strings.EqualFold(v, "null")
 }
 
-func (e *Evaluator) buildDescriptionPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	value := comp.Value
-	isNone := value == "" || strings.ToLower(value) == "none" || strings.ToLower(value) == "null"
-	switch comp.Op {
-	case OpEquals:
-		if isNone {
-			return func(i *types.Issue) bool { return i.Description == "" }, nil
+// parseEstimateMinutes parses an estimate value: a plain number of minutes,
+// or a duration in hours, days or weeks (90, 2h, 1d, 1w).
+func parseEstimateMinutes(comp *ComparisonNode) (int, error) {
+	value := strings.TrimPrefix(comp.Value, "+")
+	if comp.ValueType == TokenDuration {
+		unit := strings.ToLower(value[len(value)-1:])
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid estimate: %s", comp.Value)
 		}
-		return func(i *types.Issue) bool {
-			return strings.Contains(strings.ToLower(i.Description), strings.ToLower(value))
-		}, nil
-	case OpNotEquals:
-		if isNone {
-			return func(i *types.Issue) bool { return i.Description != "" }, nil
+		switch unit {
+		case "h":
+			return n * 60, nil
+		case "d":
+			return n * 24 * 60, nil
+		case "w":
+			return n * 7 * 24 * 60, nil
+		default:
+			return 0, fmt.Errorf("invalid estimate unit in %s (use minutes, h, d or w)", comp.Value)
 		}
-		return func(i *types.Issue) bool {
-			return !strings.Contains(strings.ToLower(i.Description), strings.ToLower(value))
-		}, nil
-	default:
-		return nil, fmt.Errorf("description does not support %s operator", comp.Op.String())
 	}
-}
-
-func (e *Evaluator) buildNotesPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	value := strings.ToLower(comp.Value)
-	switch comp.Op {
-	case OpEquals:
-		return func(i *types.Issue) bool {
-			return strings.Contains(strings.ToLower(i.Notes), value)
-		}, nil
-	case OpNotEquals:
-		return func(i *types.Issue) bool {
-			return !strings.Contains(strings.ToLower(i.Notes), value)
-		}, nil
-	default:
-		return nil, fmt.Errorf("notes does not support %s operator", comp.Op.String())
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid estimate: %s", comp.Value)
 	}
+	return n, nil
 }
 
-func (e *Evaluator) buildCreatedPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	t, err := e.parseTimeValue(comp)
-	if err != nil {
-		return nil, fmt.Errorf("invalid created time: %w", err)
+func (e *Evaluator) applyMolTypeFilter(comp *ComparisonNode, filter *types.IssueFilter) error {
+	if comp.Op != OpEquals {
+		return fmt.Errorf("mol_type only supports = operator")
 	}
-	return e.buildTimePredicate(comp.Op, t, func(i *types.Issue) time.Time { return i.CreatedAt })
-}
-
-func (e *Evaluator) buildUpdatedPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	t, err := e.parseTimeValue(comp)
-	if err != nil {
-		return nil, fmt.Errorf("invalid updated time: %w", err)
+	mt := types.MolType(strings.ToLower(comp.Value))
+	if !mt.IsValid() {
+		return fmt.Errorf("invalid mol_type: %s", comp.Value)
 	}
-	return e.buildTimePredicate(comp.Op, t, func(i *types.Issue) time.Time { return i.UpdatedAt })
+	filter.MolType = &mt
+	return nil
 }
 
-func (e *Evaluator) buildClosedPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	t, err := e.parseTimeValue(comp)
-	if err != nil {
-		return nil, fmt.Errorf("invalid closed time: %w", err)
+// graphFilter converts a graph predicate call to a storage-level GraphFilter.
+func graphFilter(fn *FunctionNode) (types.GraphFilter, error) {
+	g := types.GraphFilter{Relation: types.GraphRelation(fn.Name)}
+	if !g.Relation.IsValid() {
+		return g, fmt.Errorf("unknown function %s()", fn.Name)
+	}
+	if len(fn.Args) > 0 {
+		g.Target = fn.Args[0]
 	}
-	return func(i *types.Issue) bool {
-		if i.ClosedAt == nil {
-			return false
+	if g.Relation == types.GraphDependsOnType {
+		depType := types.DependencyType(strings.ToLower(g.Target))
+		if !depType.IsValid() {
+			return g, fmt.Errorf("invalid dependency type: %s", g.Target)
 		}
-		return e.compareTime(comp.Op, *i.ClosedAt, t)
-	}, nil
-}
-
-func (e *Evaluator) buildTimePredicate(op ComparisonOp, t time.Time, getter func(*types.Issue) time.Time) (func(*types.Issue) bool, error) {
-	return func(i *types.Issue) bool {
-		return e.compareTime(op, getter(i), t)
-	}, nil
+		g.Target = string(depType)
+	}
+	return g, nil
 }
 
-func (e *Evaluator) compareTime(op ComparisonOp, actual, target time.Time) bool {
-	switch op {
-	case OpEquals:
-		// Same day comparison
-		return actual.Year() == target.Year() &&
-			actual.Month() == target.Month() &&
-			actual.Day() == target.Day()
-	case OpNotEquals:
-		return !(actual.Year() == target.Year() &&
-			actual.Month() == target.Month() &&
-			actual.Day() == target.Day())
-	case OpLess:
-		return actual.Before(target)
-	case OpLessEq:
-		return actual.Before(target) || actual.Equal(target)
-	case OpGreater:
-		return actual.After(target)
-	case OpGreaterEq:
-		return actual.After(target) || actual.Equal(target)
-	default:
-		return false
+// applyFunction adds a graph predicate to the filter.
+func (e *Evaluator) applyFunction(fn *FunctionNode, filter *types.IssueFilter, negate bool) error {
+	g, err := graphFilter(fn)
+	if err != nil {
+		return err
 	}
+	g.Negate = negate
+	filter.Graph = append(filter.Graph, g)
+	return nil
 }
 
-func (e *Evaluator) buildIDPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	value := comp.Value
-	hasWildcard := strings.HasSuffix(value, "*")
-	if hasWildcard {
-		prefix := strings.TrimSuffix(value, "*")
-		switch comp.Op {
-		case OpEquals:
-			return func(i *types.Issue) bool { return strings.HasPrefix(i.ID, prefix) }, nil
-		case OpNotEquals:
-			return func(i *types.Issue) bool { return !strings.HasPrefix(i.ID, prefix) }, nil
-		default:
-			return nil, fmt.Errorf("id with wildcard only supports = and != operators")
-		}
+// applyNot applies a NOT expression to the filter.
+func (e *Evaluator) applyNot(not *NotNode, filter *types.IssueFilter) error {
+	if fn, ok := not.Operand.(*FunctionNode); ok {
+		return e.applyFunction(fn, filter, true)
 	}
-	switch comp.Op {
-	case OpEquals:
-		return func(i *types.Issue) bool { return i.ID == value }, nil
-	case OpNotEquals:
-		return func(i *types.Issue) bool { return i.ID != value }, nil
-	default:
-		return nil, fmt.Errorf("id does not support %s operator", comp.Op.String())
+	comp, ok := not.Operand.(*ComparisonNode)
+	if !ok {
+		return fmt.Errorf("NOT only supports simple comparisons in filter mode")
 	}
-}
 
-func (e *Evaluator) buildSpecPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
-	value := comp.Value
-	hasWildcard := strings.HasSuffix(value, "*")
-	if hasWildcard {
-		prefix := strings.TrimSuffix(value, "*")
-		switch comp.Op {
-		case OpEquals:
-			return func(i *types.Issue) bool { return strings.HasPrefix(i.SpecID, prefix) }, nil
-		case OpNotEquals:
-			return func(i *types.Issue) bool { return !strings.HasPrefix(i.SpecID, prefix) }, nil
-		default:
-			return nil, fmt.Errorf("spec with wildcard only supports = and != operators")
+	switch comp.Field {
+	case "status":
+		if comp.Op != OpEquals {
+			return fmt.Errorf("NOT status only supports = operator")
 		}
-	}
-	switch comp.Op {
-	case OpEquals:
-		return func(i *types.Issue) bool { return i.SpecID == value }, nil
-	case OpNotEquals:
-		return func(i *types.Issue) bool { return i.SpecID != value }, nil
+		status := types.Status(strings.ToLower(comp.Value))
+		filter.ExcludeStatus = append(filter.ExcludeStatus, status)
+		return nil
+	case "type":
+		if comp.Op != OpEquals {
+			return fmt.Errorf("NOT type only supports = operator")
+		}
+		issueType := types.IssueType(strings.ToLower(comp.Value))
+		filter.ExcludeTypes = append(filter.ExcludeTypes, issueType)
+		return nil
+	case "blocked", "ready", "has_deps":
+		if comp.Op != OpEquals {
+			return fmt.Errorf("NOT %s only supports = operator", comp.Field)
+		}
+		flipped, err := flipBool(comp)
+		if err != nil {
+			return err
+		}
+		return e.applyBoolFilter(flipped, filter, comp.Field)
 	default:
-		return nil, fmt.Errorf("spec does not support %s operator", comp.Op.String())
+		return fmt.Errorf("NOT not supported for field %s in filter mode", comp.Field)
 	}
 }
 
-func (e *Evaluator) buildBoolPredicate(comp *ComparisonNode, getter func(*types.Issue) bool) (func(*types.Issue) bool, error) {
-	val := strings.ToLower(comp.Value)
-	var boolVal bool
-	switch val {
+// flipBool returns the comparison "field=!v" for a boolean comparison "field=v".
+func flipBool(comp *ComparisonNode) (*ComparisonNode, error) {
+	switch strings.ToLower(comp.Value) {
 	case "true", "yes", "1":
-		boolVal = true
+		return &ComparisonNode{Field: comp.Field, Op: OpEquals, Value: "false", ValueType: TokenIdent}, nil
 	case "false", "no", "0":
-		boolVal = false
+		return &ComparisonNode{Field: comp.Field, Op: OpEquals, Value: "true", ValueType: TokenIdent}, nil
 	default:
-		return nil, fmt.Errorf("invalid boolean value: %s", comp.Value)
+		return nil, fmt.Errorf("invalid boolean value for %s: %s", comp.Field, comp.Value)
 	}
+}
 
-	switch comp.Op {
-	case OpEquals:
-		return func(i *types.Issue) bool { return getter(i) == boolVal }, nil
-	case OpNotEquals:
-		return func(i *types.Issue) bool { return getter(i) != boolVal }, nil
-	default:
-		return nil, fmt.Errorf("boolean field does not support %s operator", comp.Op.String())
+// parseTimeValue parses a time value from a comparison node.
+// Supports duration values (7d, 24h) which are interpreted as "now - duration".
+func (e *Evaluator) parseTimeValue(comp *ComparisonNode) (time.Time, error) {
+	if comp.ValueType == TokenDuration {
+		// Duration values like 7d mean "7 days ago" for < comparisons
+		// and "within the last 7 days" for > comparisons
+		// We parse as relative to now, going backwards
+		return e.parseDurationAgo(comp.Value)
 	}
+	// Otherwise use the standard time parser
+	return timeparsing.ParseRelativeTime(comp.Value, e.now)
+}
+
+// parseScheduleTime parses a due/defer time value. Unlike created/updated,
+// scheduling fields usually look ahead, so an explicit "+" makes a duration
+// relative to the future (due<+3d = due within the next 3 days). Unsigned
+// and "-" durations keep the "ago" meaning used by other time fields.
+func (e *Evaluator) parseScheduleTime(comp *ComparisonNode) (time.Time, error) {
+	if comp.ValueType == TokenDuration && strings.HasPrefix(comp.Value, "+") {
+		return timeparsing.ParseCompactDuration(comp.Value, e.now)
+	}
+	return e.parseTimeValue(comp)
+}
+
+// parseDurationAgo parses a duration and returns now - duration.
+func (e *Evaluator) parseDurationAgo(s string) (time.Time, error) {
+	// Negate the duration to get time in the past
+	negated := "-" + strings.TrimPrefix(s, "+")
+	return timeparsing.ParseCompactDuration(negated, e.now)
 }
 
 // Evaluate is a convenience function that parses and evaluates a query string.
@@ -916,11 +918,12 @@ func Evaluate(query string) (*QueryResult, error) {
 }
 
 // EvaluateAt parses and evaluates a query string with a specific reference time.
+// The query may include ORDER BY and LIMIT clauses.
 func EvaluateAt(query string, now time.Time) (*QueryResult, error) {
-	node, err := Parse(query)
+	q, err := ParseQuery(query)
 	if err != nil {
 		return nil, err
 	}
 	eval := NewEvaluator(now)
-	return eval.Evaluate(node)
+	return eval.EvaluateQuery(q)
 }
@@ -5,12 +5,18 @@
 //   - Boolean operators: AND, OR, NOT
 //   - Parentheses for grouping: (status=open OR status=blocked) AND priority<2
 //   - Date-relative expressions: updated>7d, created<30d
+//   - Ordering and limits: ORDER BY created ASC, priority DESC LIMIT 10
+//   - Graph predicates: descendant_of(bd-epic1), blocked_by(bd-42), in_cycle()
+//   - Aggregation: GROUP BY assignee, count(), sum(estimate)
 //
 // Example queries:
 //   - status=open AND priority>1
 //   - (status=open OR status=blocked) AND updated>7d
 //   - NOT status=closed
 //   - type=bug AND priority=0
+//   - priority=1 AND type=bug ORDER BY created ASC LIMIT 3
+//   - descendant_of(bd-epic1) AND status=open AND NOT blocked
+//   - status=open GROUP BY "patrol:*", avg(age) ORDER BY avg_age DESC
 package query
 
 import (
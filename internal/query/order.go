@@ -0,0 +1,136 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// orderColumns maps sortable query fields (and their aliases) to issue columns.
+var orderColumns = map[string]string{
+	"id":         "id",
+	"title":      "title",
+	"status":     "status",
+	"priority":   "priority",
+	"type":       "issue_type",
+	"assignee":   "assignee",
+	"owner":      "owner",
+	"created":    "created_at",
+	"created_at": "created_at",
+	"updated":    "updated_at",
+	"updated_at": "updated_at",
+	"closed":     "closed_at",
+	"closed_at":  "closed_at",
+
+	"due":               "due_at",
+	"due_at":            "due_at",
+	"defer":             "defer_until",
+	"defer_until":       "defer_until",
+	"estimate":          "estimated_minutes",
+	"estimated_minutes": "estimated_minutes",
+}
+
+// resolveOrder converts ORDER BY terms to storage-level sort columns.
+func resolveOrder(terms []OrderTerm) ([]types.IssueOrder, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	order := make([]types.IssueOrder, len(terms))
+	for i, t := range terms {
+		col, ok := orderColumns[t.Field]
+		if !ok {
+			return nil, fmt.Errorf("cannot ORDER BY %s", t.Field)
+		}
+		order[i] = types.IssueOrder{Column: col, Desc: t.Desc}
+	}
+	return order, nil
+}
+
+// SortIssues sorts issues in memory by the given order, matching the SQL
+// semantics used by storage: titles compare case-insensitively, NULL (unset)
+// values sort first in ascending order and last in descending order, and ID
+// is the final tie-breaker.
+func SortIssues(issues []*types.Issue, order []types.IssueOrder) {
+	if len(order) == 0 {
+		return
+	}
+	slices.SortStableFunc(issues, func(a, b *types.Issue) int {
+		for _, o := range order {
+			c := compareIssueColumn(a, b, o.Column)
+			if c == 0 {
+				continue
+			}
+			if o.Desc {
+				return -c
+			}
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+}
+
+// compareIssueColumn compares two issues on a single sortable column.
+func compareIssueColumn(a, b *types.Issue, column string) int {
+	switch column {
+	case "id":
+		return cmp.Compare(a.ID, b.ID)
+	case "title":
+		return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case "status":
+		return cmp.Compare(a.Status, b.Status)
+	case "priority":
+		return cmp.Compare(a.Priority, b.Priority)
+	case "issue_type":
+		return cmp.Compare(a.IssueType, b.IssueType)
+	case "assignee":
+		return cmp.Compare(a.Assignee, b.Assignee)
+	case "owner":
+		return cmp.Compare(a.Owner, b.Owner)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case "closed_at":
+		return compareOptionalTime(a.ClosedAt, b.ClosedAt)
+	case "due_at":
+		return compareOptionalTime(a.DueAt, b.DueAt)
+	case "defer_until":
+		return compareOptionalTime(a.DeferUntil, b.DeferUntil)
+	case "estimated_minutes":
+		return compareOptionalInt(a.EstimatedMinutes, b.EstimatedMinutes)
+	default:
+		return 0
+	}
+}
+
+// compareOptionalTime orders nil before any time (SQL NULLs sort first ASC).
+func compareOptionalTime(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	default:
+		return a.Compare(*b)
+	}
+}
+
+// compareOptionalInt orders nil before any value (SQL NULLs sort first ASC).
+func compareOptionalInt(a, b *int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	default:
+		return cmp.Compare(*a, *b)
+	}
+}
@@ -2,6 +2,7 @@ package query
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -88,6 +89,88 @@ func (n *NotNode) String() string {
 	return fmt.Sprintf("NOT %s", n.Operand.String())
 }
 
+// FunctionNode represents a graph predicate call (e.g., descendant_of(bd-epic1)).
+type FunctionNode struct {
+	Name string
+	Args []string
+}
+
+func (n *FunctionNode) node() {}
+func (n *FunctionNode) String() string {
+	return fmt.Sprintf("%s(%s)", n.Name, strings.Join(n.Args, ", "))
+}
+
+// OrderTerm is a single ORDER BY term (e.g., "created ASC").
+type OrderTerm struct {
+	Field string
+	Desc  bool
+}
+
+// String returns the string representation of an OrderTerm.
+func (t OrderTerm) String() string {
+	if t.Desc {
+		return t.Field + " DESC"
+	}
+	return t.Field + " ASC"
+}
+
+// Query is a fully parsed query: a filter expression plus optional
+// GROUP BY, ORDER BY and LIMIT clauses.
+//
+//	priority=1 AND type=bug ORDER BY created ASC LIMIT 3
+//	status=open GROUP BY assignee, count(), sum(estimate)
+//
+// The filter expression may be omitted when the query starts with GROUP BY,
+// in which case Where is nil and every issue is aggregated.
+type Query struct {
+	Where   Node
+	OrderBy []OrderTerm
+	Limit   int // 0 = no LIMIT clause
+
+	// GroupBy lists grouping dimensions (fields, or "<dimension>:*" state
+	// labels). When set, the query yields one row per group with the
+	// requested Aggregates, and ORDER BY/LIMIT apply to the groups.
+	GroupBy    []string
+	Aggregates []Aggregate
+}
+
+// String returns the string representation of a Query.
+func (q *Query) String() string {
+	var sb strings.Builder
+	if q.Where != nil {
+		sb.WriteString(q.Where.String())
+	}
+	if len(q.GroupBy) > 0 {
+		terms := make([]string, 0, len(q.GroupBy)+len(q.Aggregates))
+		for _, g := range q.GroupBy {
+			if strings.HasSuffix(g, ":*") {
+				g = strconv.Quote(g)
+			}
+			terms = append(terms, g)
+		}
+		for _, a := range q.Aggregates {
+			terms = append(terms, a.String())
+		}
+		if sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("GROUP BY ")
+		sb.WriteString(strings.Join(terms, ", "))
+	}
+	if len(q.OrderBy) > 0 {
+		terms := make([]string, len(q.OrderBy))
+		for i, t := range q.OrderBy {
+			terms[i] = t.String()
+		}
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(terms, ", "))
+	}
+	if q.Limit > 0 {
+		fmt.Fprintf(&sb, " LIMIT %d", q.Limit)
+	}
+	return sb.String()
+}
+
 // Parser parses a query string into an AST.
 type Parser struct {
 	lexer   *Lexer
@@ -101,7 +184,21 @@ func NewParser(input string) *Parser {
 }
 
 // Parse parses the query string and returns the root AST node.
+// ORDER BY and LIMIT clauses are rejected; use ParseQuery to accept them.
 func (p *Parser) Parse() (Node, error) {
+	q, err := p.ParseQuery()
+	if err != nil {
+		return nil, err
+	}
+	if len(q.OrderBy) > 0 || q.Limit > 0 || len(q.GroupBy) > 0 {
+		return nil, fmt.Errorf("GROUP BY, ORDER BY and LIMIT are not supported here")
+	}
+	return q.Where, nil
+}
+
+// ParseQuery parses the query string including optional trailing
+// GROUP BY, ORDER BY and LIMIT clauses.
+func (p *Parser) ParseQuery() (*Query, error) {
 	if err := p.advance(); err != nil {
 		return nil, err
 	}
@@ -109,17 +206,203 @@ func (p *Parser) Parse() (Node, error) {
 	if p.current.Type == TokenEOF {
 		return nil, fmt.Errorf("empty query")
 	}
+	if p.isKeyword("ORDER") || p.isKeyword("LIMIT") {
+		return nil, fmt.Errorf("expected filter expression before %s at position %d", strings.ToUpper(p.current.Value), p.current.Pos)
+	}
 
-	node, err := p.parseOr()
-	if err != nil {
-		return nil, err
+	q := &Query{}
+	if !p.isKeyword("GROUP") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		q.Where = node
+	}
+
+	if p.isKeyword("GROUP") {
+		if err := p.parseGroupBy(q); err != nil {
+			return nil, err
+		}
+	}
+	if p.isKeyword("ORDER") {
+		if err := p.parseOrderBy(q); err != nil {
+			return nil, err
+		}
+	}
+	if p.isKeyword("LIMIT") {
+		if err := p.parseLimit(q); err != nil {
+			return nil, err
+		}
 	}
 
 	if p.current.Type != TokenEOF {
 		return nil, fmt.Errorf("unexpected token %q at position %d (expected end of query)", p.current.Value, p.current.Pos)
 	}
+	if err := q.validateGroupOrder(); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// isKeyword reports whether the current token is the given clause keyword.
+// Clause keywords (GROUP, ORDER, BY, LIMIT, ASC, DESC) are lexed as
+// identifiers so they remain usable as comparison values (e.g., title=order).
+func (p *Parser) isKeyword(kw string) bool {
+	return p.current.Type == TokenIdent && strings.EqualFold(p.current.Value, kw)
+}
+
+// atExpressionEnd reports whether the current token ends a comparison
+// (boolean operator, closing paren, clause keyword, or end of input).
+func (p *Parser) atExpressionEnd() bool {
+	switch p.current.Type {
+	case TokenEOF, TokenAnd, TokenOr, TokenRParen:
+		return true
+	}
+	return p.isKeyword("GROUP") || p.isKeyword("ORDER") || p.isKeyword("LIMIT")
+}
+
+// parseOrderBy parses "ORDER BY field [ASC|DESC], ...".
+func (p *Parser) parseOrderBy(q *Query) error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if !p.isKeyword("BY") {
+		return fmt.Errorf("expected BY after ORDER at position %d", p.current.Pos)
+	}
+	if err := p.advance(); err != nil {
+		return err
+	}
+
+	for {
+		if p.current.Type != TokenIdent || p.isKeyword("LIMIT") {
+			return fmt.Errorf("expected sort field at position %d, got %s", p.current.Pos, p.current.Type.String())
+		}
+		term := OrderTerm{Field: strings.ToLower(p.current.Value)}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if p.isKeyword("ASC") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+		} else if p.isKeyword("DESC") {
+			term.Desc = true
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+		q.OrderBy = append(q.OrderBy, term)
+
+		if p.current.Type != TokenComma {
+			return nil
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseGroupBy parses "GROUP BY dim, ..., agg(field), ...". Terms written as
+// calls are aggregates; the rest are grouping dimensions. count() is implied
+// when no aggregate is given.
+func (p *Parser) parseGroupBy(q *Query) error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if !p.isKeyword("BY") {
+		return fmt.Errorf("expected BY after GROUP at position %d", p.current.Pos)
+	}
+	if err := p.advance(); err != nil {
+		return err
+	}
+
+	for {
+		pos := p.current.Pos
+		switch {
+		case p.current.Type == TokenString:
+			dim := strings.ToLower(p.current.Value)
+			if !isStateDimension(dim) {
+				return fmt.Errorf("invalid GROUP BY term %q at position %d (expected a field or \"<dimension>:*\")", p.current.Value, pos)
+			}
+			q.GroupBy = append(q.GroupBy, dim)
+			if err := p.advance(); err != nil {
+				return err
+			}
+		case p.current.Type == TokenIdent && !p.isKeyword("ORDER") && !p.isKeyword("LIMIT"):
+			name := strings.ToLower(p.current.Value)
+			if err := p.advance(); err != nil {
+				return err
+			}
+			if p.current.Type == TokenLParen {
+				agg, err := p.parseAggregate(name)
+				if err != nil {
+					return err
+				}
+				q.Aggregates = append(q.Aggregates, agg)
+				break
+			}
+			if _, ok := groupDimensions[name]; !ok {
+				return fmt.Errorf("cannot GROUP BY %s", name)
+			}
+			q.GroupBy = append(q.GroupBy, name)
+		default:
+			return fmt.Errorf("expected group field or aggregate at position %d, got %s", pos, p.current.Type.String())
+		}
+
+		if p.current.Type != TokenComma {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
 
-	return node, nil
+	if len(q.GroupBy) == 0 {
+		return fmt.Errorf("GROUP BY requires at least one field")
+	}
+	if len(q.Aggregates) == 0 {
+		q.Aggregates = []Aggregate{{Func: "count"}}
+	}
+	return nil
+}
+
+// parseAggregate parses the argument list of an aggregate call.
+// The current token is the opening parenthesis.
+func (p *Parser) parseAggregate(name string) (Aggregate, error) {
+	if err := p.advance(); err != nil {
+		return Aggregate{}, err
+	}
+	agg := Aggregate{Func: name}
+	if p.current.Type == TokenIdent {
+		agg.Field = strings.ToLower(p.current.Value)
+		if err := p.advance(); err != nil {
+			return Aggregate{}, err
+		}
+	}
+	if p.current.Type != TokenRParen {
+		return Aggregate{}, fmt.Errorf("expected ')' at position %d, got %s", p.current.Pos, p.current.Type.String())
+	}
+	if err := p.advance(); err != nil {
+		return Aggregate{}, err
+	}
+	return agg, agg.validate()
+}
+
+// parseLimit parses "LIMIT n".
+func (p *Parser) parseLimit(q *Query) error {
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if p.current.Type != TokenNumber {
+		return fmt.Errorf("expected number after LIMIT at position %d, got %s", p.current.Pos, p.current.Type.String())
+	}
+	n, err := strconv.Atoi(p.current.Value)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("LIMIT must be a positive integer, got %s", p.current.Value)
+	}
+	q.Limit = n
+	return p.advance()
 }
 
 // advance moves to the next token.
@@ -241,6 +524,15 @@ func (p *Parser) parseComparison() (Node, error) {
 		return nil, err
 	}
 
+	if p.current.Type == TokenLParen {
+		return p.parseFunction(field)
+	}
+
+	// Bare boolean field: "blocked" means "blocked=true"
+	if booleanFields[field] && p.atExpressionEnd() {
+		return &ComparisonNode{Field: field, Op: OpEquals, Value: "true", ValueType: TokenIdent}, nil
+	}
+
 	var op ComparisonOp
 	switch p.current.Type {
 	case TokenEquals:
@@ -295,12 +587,82 @@ func (p *Parser) parseComparison() (Node, error) {
 	}, nil
 }
 
+// parseFunction parses the argument list of a graph predicate call.
+// The current token is the opening parenthesis.
+func (p *Parser) parseFunction(name string) (Node, error) {
+	arity, ok := KnownFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s()", name)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	fn := &FunctionNode{Name: name}
+	for p.current.Type != TokenRParen {
+		if len(fn.Args) > 0 {
+			if p.current.Type != TokenComma {
+				return nil, fmt.Errorf("expected ',' or ')' at position %d, got %s", p.current.Pos, p.current.Type.String())
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		switch p.current.Type {
+		case TokenIdent, TokenString, TokenNumber:
+			fn.Args = append(fn.Args, p.current.Value)
+		default:
+			return nil, fmt.Errorf("expected argument at position %d, got %s", p.current.Pos, p.current.Type.String())
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if len(fn.Args) != arity {
+		return nil, fmt.Errorf("%s() takes %d argument(s), got %d", name, arity, len(fn.Args))
+	}
+	return fn, nil
+}
+
 // Parse is a convenience function that parses a query string.
 func Parse(input string) (Node, error) {
 	p := NewParser(input)
 	return p.Parse()
 }
 
+// ParseQuery is a convenience function that parses a query string
+// with optional GROUP BY, ORDER BY and LIMIT clauses.
+func ParseQuery(input string) (*Query, error) {
+	p := NewParser(input)
+	return p.ParseQuery()
+}
+
+// SetOrderBy parses sort terms as written after ORDER BY (e.g.
+// "priority, updated desc") and makes them the query's order.
+func (q *Query) SetOrderBy(terms string) error {
+	p := NewParser("ORDER BY " + terms)
+	if err := p.advance(); err != nil {
+		return err
+	}
+	sorted := *q
+	sorted.OrderBy = nil
+	if err := p.parseOrderBy(&sorted); err != nil {
+		return err
+	}
+	if p.current.Type != TokenEOF {
+		return fmt.Errorf("unexpected token %q at position %d (expected end of sort)", p.current.Value, p.current.Pos)
+	}
+	if err := sorted.validateGroupOrder(); err != nil {
+		return err
+	}
+	q.OrderBy = sorted.OrderBy
+	return nil
+}
+
 // KnownFields lists fields that can be queried.
 var KnownFields = map[string]bool{
 	// Core fields
@@ -331,10 +693,50 @@ var KnownFields = map[string]bool{
 	"ephemeral": true,
 	"template":  true,
 
+	// Scheduling and estimates
+	"due":               true,
+	"due_at":            true, // alias
+	"defer":             true,
+	"defer_until":       true, // alias
+	"estimate":          true,
+	"estimated_minutes": true, // alias
+
+	// External integration
+	"external_ref":  true,
+	"source_system": true,
+
+	// Relationships and derived state
+	"comments": true,
+	"blocked":  true,
+	"ready":    true,
+	"has_deps": true,
+
 	// Other
-	"spec":     true,
-	"spec_id":  true, // alias
-	"parent":   true,
-	"mol_type": true,
-	"notes":    true,
+	"spec":      true,
+	"spec_id":   true, // alias
+	"parent":    true,
+	"mol_type":  true,
+	"wisp_type": true,
+	"notes":     true,
+}
+
+// KnownFunctions lists graph predicates and their argument counts.
+var KnownFunctions = map[string]int{
+	"blocked_by":      1, // Issues with a 'blocks' dependency on the argument
+	"blocks":          1, // Issues the argument has a 'blocks' dependency on
+	"descendant_of":   1, // Transitive parent-child descendants
+	"related_to":      1, // 'related'/'relates-to' links in either direction
+	"depends_on_type": 1, // Issues with an outgoing dependency of the given type
+	"in_cycle":        0, // Issues on a 'blocks' dependency cycle
+}
+
+// booleanFields may be written bare as a shorthand for field=true
+// (e.g., "NOT blocked" is "NOT blocked=true").
+var booleanFields = map[string]bool{
+	"pinned":    true,
+	"ephemeral": true,
+	"template":  true,
+	"blocked":   true,
+	"ready":     true,
+	"has_deps":  true,
 }
@@ -0,0 +1,583 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// predicateBuilder is an in-memory reference implementation of the query
+// language, kept as an oracle for the filters and FilterExprs the Evaluator
+// compiles. It does not model parent or graph functions.
+type predicateBuilder struct {
+	*Evaluator
+	facts issueFacts
+}
+
+// issueFacts holds derived per-issue data that is not stored on types.Issue.
+// Maps are keyed by issue ID; missing entries read as zero/false.
+type issueFacts struct {
+	CommentCounts     map[string]int  // Number of comments
+	BlockedIDs        map[string]bool // Issues with an active blocking dependency
+	DeferredParentIDs map[string]bool // Issues whose parent is deferred into the future
+	DependencyCounts  map[string]int  // Number of dependencies whose type AffectsReadyWork
+}
+
+func newPredicateBuilder(now time.Time) *predicateBuilder {
+	return &predicateBuilder{Evaluator: NewEvaluator(now)}
+}
+
+// predicateFor parses query and builds its reference predicate.
+func (e *predicateBuilder) predicateFor(query string) (func(*types.Issue) bool, error) {
+	node, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return e.buildPredicate(node)
+}
+
+// buildPredicate builds a predicate function for complex queries.
+func (e *predicateBuilder) buildPredicate(node Node) (func(*types.Issue) bool, error) {
+	switch n := node.(type) {
+	case *ComparisonNode:
+		return e.buildComparisonPredicate(n)
+	case *FunctionNode:
+		return nil, fmt.Errorf("%s() has no in-memory predicate", n.Name)
+	case *AndNode:
+		left, err := e.buildPredicate(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.buildPredicate(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return func(issue *types.Issue) bool {
+			return left(issue) && right(issue)
+		}, nil
+	case *OrNode:
+		left, err := e.buildPredicate(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.buildPredicate(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return func(issue *types.Issue) bool {
+			return left(issue) || right(issue)
+		}, nil
+	case *NotNode:
+		operand, err := e.buildPredicate(n.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return func(issue *types.Issue) bool {
+			return !operand(issue)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unexpected node type: %T", node)
+	}
+}
+
+// buildComparisonPredicate builds a predicate for a single comparison.
+func (e *predicateBuilder) buildComparisonPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	switch comp.Field {
+	case "status":
+		return e.buildStatusPredicate(comp)
+	case "priority":
+		return e.buildPriorityPredicate(comp)
+	case "type":
+		return e.buildTypePredicate(comp)
+	case "assignee":
+		return e.buildAssigneePredicate(comp)
+	case "owner":
+		return e.buildOwnerPredicate(comp)
+	case "label", "labels":
+		return e.buildLabelPredicate(comp)
+	case "title":
+		return e.buildTitlePredicate(comp)
+	case "description", "desc":
+		return e.buildDescriptionPredicate(comp)
+	case "notes":
+		return e.buildNotesPredicate(comp)
+	case "created", "created_at":
+		return e.buildCreatedPredicate(comp)
+	case "updated", "updated_at":
+		return e.buildUpdatedPredicate(comp)
+	case "closed", "closed_at":
+		return e.buildClosedPredicate(comp)
+	case "id":
+		return e.buildIDPredicate(comp)
+	case "spec", "spec_id":
+		return e.buildSpecPredicate(comp)
+	case "pinned":
+		return e.buildBoolPredicate(comp, func(i *types.Issue) bool { return i.Pinned })
+	case "ephemeral":
+		return e.buildBoolPredicate(comp, func(i *types.Issue) bool { return i.Ephemeral })
+	case "template":
+		return e.buildBoolPredicate(comp, func(i *types.Issue) bool { return i.IsTemplate })
+	case "due", "due_at":
+		return e.buildScheduleTimePredicate(comp, func(i *types.Issue) *time.Time { return i.DueAt })
+	case "defer", "defer_until":
+		return e.buildScheduleTimePredicate(comp, func(i *types.Issue) *time.Time { return i.DeferUntil })
+	case "estimate", "estimated_minutes":
+		return e.buildEstimatePredicate(comp)
+	case "external_ref":
+		return e.buildExternalRefPredicate(comp)
+	case "source_system":
+		return e.buildStringPredicate(comp, func(i *types.Issue) string { return i.SourceSystem })
+	case "wisp_type":
+		return e.buildStringPredicate(comp, func(i *types.Issue) string { return string(i.WispType) })
+	case "mol_type":
+		return e.buildStringPredicate(comp, func(i *types.Issue) string { return string(i.MolType) })
+	case "comments":
+		return e.buildCommentsPredicate(comp)
+	case "blocked":
+		return e.buildBoolPredicate(comp, func(i *types.Issue) bool { return e.facts.BlockedIDs[i.ID] })
+	case "ready":
+		return e.buildBoolPredicate(comp, e.isReady)
+	case "has_deps":
+		return e.buildBoolPredicate(comp, func(i *types.Issue) bool { return e.facts.DependencyCounts[i.ID] > 0 })
+	default:
+		return nil, fmt.Errorf("unknown field: %s", comp.Field)
+	}
+}
+
+// isReady mirrors GetReadyWork with an empty WorkFilter: open or in
+// progress, not pinned, ephemeral or a workflow type, not blocked, and
+// neither the issue nor its parent deferred into the future.
+func (e *predicateBuilder) isReady(i *types.Issue) bool {
+	if i.Status != types.StatusOpen && i.Status != types.StatusInProgress {
+		return false
+	}
+	if i.Pinned || i.Ephemeral || slices.Contains(types.ReadyWorkExcludedTypes(), i.IssueType) {
+		return false
+	}
+	if e.facts.BlockedIDs[i.ID] || e.facts.DeferredParentIDs[i.ID] {
+		return false
+	}
+	return i.DeferUntil == nil || !i.DeferUntil.After(e.now)
+}
+
+func (e *predicateBuilder) buildScheduleTimePredicate(comp *ComparisonNode, getter func(*types.Issue) *time.Time) (func(*types.Issue) bool, error) {
+	if isNoneValue(comp.Value) {
+		switch comp.Op {
+		case OpEquals:
+			return func(i *types.Issue) bool { return getter(i) == nil }, nil
+		case OpNotEquals:
+			return func(i *types.Issue) bool { return getter(i) != nil }, nil
+		default:
+			return nil, fmt.Errorf("%s=none only supports = and != operators", comp.Field)
+		}
+	}
+	t, err := e.parseScheduleTime(comp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s time: %w", comp.Field, err)
+	}
+	return func(i *types.Issue) bool {
+		v := getter(i)
+		if v == nil {
+			// Unset never matches a comparison, except "is not this day"
+			return comp.Op == OpNotEquals
+		}
+		return e.compareTime(comp.Op, *v, t)
+	}, nil
+}
+
+func (e *predicateBuilder) buildEstimatePredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	if isNoneValue(comp.Value) {
+		switch comp.Op {
+		case OpEquals:
+			return func(i *types.Issue) bool { return i.EstimatedMinutes == nil }, nil
+		case OpNotEquals:
+			return func(i *types.Issue) bool { return i.EstimatedMinutes != nil }, nil
+		default:
+			return nil, fmt.Errorf("estimate=none only supports = and != operators")
+		}
+	}
+	minutes, err := parseEstimateMinutes(comp)
+	if err != nil {
+		return nil, err
+	}
+	return func(i *types.Issue) bool {
+		if i.EstimatedMinutes == nil {
+			return comp.Op == OpNotEquals
+		}
+		return compareInt(comp.Op, *i.EstimatedMinutes, minutes)
+	}, nil
+}
+
+func (e *predicateBuilder) buildCommentsPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	n, err := strconv.Atoi(comp.Value)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid comments count: %s", comp.Value)
+	}
+	return func(i *types.Issue) bool {
+		return compareInt(comp.Op, e.facts.CommentCounts[i.ID], n)
+	}, nil
+}
+
+func (e *predicateBuilder) buildExternalRefPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	value := comp.Value
+	ref := func(i *types.Issue) string {
+		if i.ExternalRef == nil {
+			return ""
+		}
+		return *i.ExternalRef
+	}
+	var match func(*types.Issue) bool
+	switch {
+	case isNoneValue(value):
+		match = func(i *types.Issue) bool { return ref(i) == "" }
+	case strings.HasSuffix(value, "*"):
+		prefix := strings.TrimSuffix(value, "*")
+		match = func(i *types.Issue) bool { return strings.HasPrefix(ref(i), prefix) }
+	default:
+		match = func(i *types.Issue) bool { return ref(i) == value }
+	}
+	switch comp.Op {
+	case OpEquals:
+		return match, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool { return !match(i) }, nil
+	default:
+		return nil, fmt.Errorf("external_ref does not support %s operator", comp.Op.String())
+	}
+}
+
+// buildStringPredicate builds a case-insensitive equality predicate for a
+// plain string field ("none" matches the empty string).
+func (e *predicateBuilder) buildStringPredicate(comp *ComparisonNode, getter func(*types.Issue) string) (func(*types.Issue) bool, error) {
+	value := comp.Value
+	if isNoneValue(value) {
+		value = ""
+	}
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool { return strings.EqualFold(getter(i), value) }, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool { return !strings.EqualFold(getter(i), value) }, nil
+	default:
+		return nil, fmt.Errorf("%s does not support %s operator", comp.Field, comp.Op.String())
+	}
+}
+
+// compareInt applies a comparison operator to two integers.
+func compareInt(op ComparisonOp, actual, target int) bool {
+	switch op {
+	case OpEquals:
+		return actual == target
+	case OpNotEquals:
+		return actual != target
+	case OpLess:
+		return actual < target
+	case OpLessEq:
+		return actual <= target
+	case OpGreater:
+		return actual > target
+	case OpGreaterEq:
+		return actual >= target
+	default:
+		return false
+	}
+}
+
+func (e *predicateBuilder) buildStatusPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	status := types.Status(strings.ToLower(comp.Value))
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool { return i.Status == status }, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool { return i.Status != status }, nil
+	default:
+		return nil, fmt.Errorf("status does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildPriorityPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	priority, err := strconv.Atoi(comp.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid priority: %s", comp.Value)
+	}
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool { return i.Priority == priority }, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool { return i.Priority != priority }, nil
+	case OpLess:
+		return func(i *types.Issue) bool { return i.Priority < priority }, nil
+	case OpLessEq:
+		return func(i *types.Issue) bool { return i.Priority <= priority }, nil
+	case OpGreater:
+		return func(i *types.Issue) bool { return i.Priority > priority }, nil
+	case OpGreaterEq:
+		return func(i *types.Issue) bool { return i.Priority >= priority }, nil
+	default:
+		return nil, fmt.Errorf("unexpected operator: %s", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildTypePredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	issueType := types.IssueType(strings.ToLower(comp.Value))
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool { return i.IssueType == issueType }, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool { return i.IssueType != issueType }, nil
+	default:
+		return nil, fmt.Errorf("type does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildAssigneePredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	value := comp.Value
+	isNone := value == "" || strings.ToLower(value) == "none" || strings.ToLower(value) == "null"
+	switch comp.Op {
+	case OpEquals:
+		if isNone {
+			return func(i *types.Issue) bool { return i.Assignee == "" }, nil
+		}
+		return func(i *types.Issue) bool { return strings.EqualFold(i.Assignee, value) }, nil
+	case OpNotEquals:
+		if isNone {
+			return func(i *types.Issue) bool { return i.Assignee != "" }, nil
+		}
+		return func(i *types.Issue) bool { return !strings.EqualFold(i.Assignee, value) }, nil
+	default:
+		return nil, fmt.Errorf("assignee does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildOwnerPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	value := comp.Value
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool { return strings.EqualFold(i.Owner, value) }, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool { return !strings.EqualFold(i.Owner, value) }, nil
+	default:
+		return nil, fmt.Errorf("owner does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildLabelPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	value := comp.Value
+	isNone := value == "" || strings.ToLower(value) == "none" || strings.ToLower(value) == "null"
+	switch comp.Op {
+	case OpEquals:
+		if isNone {
+			return func(i *types.Issue) bool { return len(i.Labels) == 0 }, nil
+		}
+		return func(i *types.Issue) bool {
+			for _, l := range i.Labels {
+				if strings.EqualFold(l, value) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case OpNotEquals:
+		if isNone {
+			return func(i *types.Issue) bool { return len(i.Labels) > 0 }, nil
+		}
+		return func(i *types.Issue) bool {
+			for _, l := range i.Labels {
+				if strings.EqualFold(l, value) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	default:
+		return nil, fmt.Errorf("label does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildTitlePredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	value := strings.ToLower(comp.Value)
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool {
+			return strings.Contains(strings.ToLower(i.Title), value)
+		}, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool {
+			return !strings.Contains(strings.ToLower(i.Title), value)
+		}, nil
+	default:
+		return nil, fmt.Errorf("title does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildDescriptionPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	value := comp.Value
+	isNone := value == "" || strings.ToLower(value) == "none" || strings.ToLower(value) == "null"
+	switch comp.Op {
+	case OpEquals:
+		if isNone {
+			return func(i *types.Issue) bool { return i.Description == "" }, nil
+		}
+		return func(i *types.Issue) bool {
+			return strings.Contains(strings.ToLower(i.Description), strings.ToLower(value))
+		}, nil
+	case OpNotEquals:
+		if isNone {
+			return func(i *types.Issue) bool { return i.Description != "" }, nil
+		}
+		return func(i *types.Issue) bool {
+			return !strings.Contains(strings.ToLower(i.Description), strings.ToLower(value))
+		}, nil
+	default:
+		return nil, fmt.Errorf("description does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildNotesPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	value := strings.ToLower(comp.Value)
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool {
+			return strings.Contains(strings.ToLower(i.Notes), value)
+		}, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool {
+			return !strings.Contains(strings.ToLower(i.Notes), value)
+		}, nil
+	default:
+		return nil, fmt.Errorf("notes does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildCreatedPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	t, err := e.parseTimeValue(comp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid created time: %w", err)
+	}
+	return e.buildTimePredicate(comp.Op, t, func(i *types.Issue) time.Time { return i.CreatedAt })
+}
+
+func (e *predicateBuilder) buildUpdatedPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	t, err := e.parseTimeValue(comp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid updated time: %w", err)
+	}
+	return e.buildTimePredicate(comp.Op, t, func(i *types.Issue) time.Time { return i.UpdatedAt })
+}
+
+func (e *predicateBuilder) buildClosedPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	t, err := e.parseTimeValue(comp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid closed time: %w", err)
+	}
+	return func(i *types.Issue) bool {
+		if i.ClosedAt == nil {
+			return false
+		}
+		return e.compareTime(comp.Op, *i.ClosedAt, t)
+	}, nil
+}
+
+func (e *predicateBuilder) buildTimePredicate(op ComparisonOp, t time.Time, getter func(*types.Issue) time.Time) (func(*types.Issue) bool, error) {
+	return func(i *types.Issue) bool {
+		return e.compareTime(op, getter(i), t)
+	}, nil
+}
+
+func (e *predicateBuilder) compareTime(op ComparisonOp, actual, target time.Time) bool {
+	switch op {
+	case OpEquals:
+		// Same day comparison
+		return actual.Year() == target.Year() &&
+			actual.Month() == target.Month() &&
+			actual.Day() == target.Day()
+	case OpNotEquals:
+		return !(actual.Year() == target.Year() &&
+			actual.Month() == target.Month() &&
+			actual.Day() == target.Day())
+	case OpLess:
+		return actual.Before(target)
+	case OpLessEq:
+		return actual.Before(target) || actual.Equal(target)
+	case OpGreater:
+		return actual.After(target)
+	case OpGreaterEq:
+		return actual.After(target) || actual.Equal(target)
+	default:
+		return false
+	}
+}
+
+func (e *predicateBuilder) buildIDPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	value := comp.Value
+	hasWildcard := strings.HasSuffix(value, "*")
+	if hasWildcard {
+		prefix := strings.TrimSuffix(value, "*")
+		switch comp.Op {
+		case OpEquals:
+			return func(i *types.Issue) bool { return strings.HasPrefix(i.ID, prefix) }, nil
+		case OpNotEquals:
+			return func(i *types.Issue) bool { return !strings.HasPrefix(i.ID, prefix) }, nil
+		default:
+			return nil, fmt.Errorf("id with wildcard only supports = and != operators")
+		}
+	}
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool { return i.ID == value }, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool { return i.ID != value }, nil
+	default:
+		return nil, fmt.Errorf("id does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildSpecPredicate(comp *ComparisonNode) (func(*types.Issue) bool, error) {
+	value := comp.Value
+	hasWildcard := strings.HasSuffix(value, "*")
+	if hasWildcard {
+		prefix := strings.TrimSuffix(value, "*")
+		switch comp.Op {
+		case OpEquals:
+			return func(i *types.Issue) bool { return strings.HasPrefix(i.SpecID, prefix) }, nil
+		case OpNotEquals:
+			return func(i *types.Issue) bool { return !strings.HasPrefix(i.SpecID, prefix) }, nil
+		default:
+			return nil, fmt.Errorf("spec with wildcard only supports = and != operators")
+		}
+	}
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool { return i.SpecID == value }, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool { return i.SpecID != value }, nil
+	default:
+		return nil, fmt.Errorf("spec does not support %s operator", comp.Op.String())
+	}
+}
+
+func (e *predicateBuilder) buildBoolPredicate(comp *ComparisonNode, getter func(*types.Issue) bool) (func(*types.Issue) bool, error) {
+	val := strings.ToLower(comp.Value)
+	var boolVal bool
+	switch val {
+	case "true", "yes", "1":
+		boolVal = true
+	case "false", "no", "0":
+		boolVal = false
+	default:
+		return nil, fmt.Errorf("invalid boolean value: %s", comp.Value)
+	}
+
+	switch comp.Op {
+	case OpEquals:
+		return func(i *types.Issue) bool { return getter(i) == boolVal }, nil
+	case OpNotEquals:
+		return func(i *types.Issue) bool { return getter(i) != boolVal }, nil
+	default:
+		return nil, fmt.Errorf("boolean field does not support %s operator", comp.Op.String())
+	}
+}
@@ -1,6 +1,10 @@
 package query
 
 import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -234,10 +238,10 @@ func TestEvaluatorSimpleQueries(t *testing.T) {
 	now := time.Date(2025, 2, 4, 12, 0, 0, 0, time.UTC)
 
 	tests := []struct {
-		name              string
-		query             string
-		expectFilter      func(*types.IssueFilter) bool
-		requiresPredicate bool
+		name         string
+		query        string
+		expectFilter func(*types.IssueFilter) bool
+		compound     bool
 	}{
 		{
 			name:  "status equals",
@@ -400,8 +404,8 @@ func TestEvaluatorSimpleQueries(t *testing.T) {
 				t.Errorf("filter check failed for %q", tt.query)
 			}
 
-			if result.RequiresPredicate != tt.requiresPredicate {
-				t.Errorf("RequiresPredicate = %v, want %v", result.RequiresPredicate, tt.requiresPredicate)
+			if compound := result.Filter.Where != nil; compound != tt.compound {
+				t.Errorf("compound (Filter.Where set) = %v, want %v", compound, tt.compound)
 			}
 		})
 	}
@@ -411,24 +415,24 @@ func TestEvaluatorComplexQueries(t *testing.T) {
 	now := time.Date(2025, 2, 4, 12, 0, 0, 0, time.UTC)
 
 	tests := []struct {
-		name              string
-		query             string
-		requiresPredicate bool
+		name     string
+		query    string
+		compound bool
 	}{
 		{
-			name:              "OR with different fields requires predicate",
-			query:             "status=open OR priority>1",
-			requiresPredicate: true,
+			name:     "OR with different fields compiles to an expression",
+			query:    "status=open OR priority>1",
+			compound: true,
 		},
 		{
-			name:              "nested OR requires predicate",
-			query:             "(status=open OR status=blocked) AND priority<2",
-			requiresPredicate: true,
+			name:     "nested OR compiles to an expression",
+			query:    "(status=open OR status=blocked) AND priority<2",
+			compound: true,
 		},
 		{
-			name:              "NOT with complex expression requires predicate",
-			query:             "NOT (status=closed AND type=bug)",
-			requiresPredicate: true,
+			name:     "NOT with complex expression compiles to an expression",
+			query:    "NOT (status=closed AND type=bug)",
+			compound: true,
 		},
 	}
 
@@ -439,12 +443,8 @@ func TestEvaluatorComplexQueries(t *testing.T) {
 				t.Fatalf("EvaluateAt() error = %v", err)
 			}
 
-			if result.RequiresPredicate != tt.requiresPredicate {
-				t.Errorf("RequiresPredicate = %v, want %v", result.RequiresPredicate, tt.requiresPredicate)
-			}
-
-			if tt.requiresPredicate && result.Predicate == nil {
-				t.Error("expected Predicate to be set")
+			if compound := result.Filter.Where != nil; compound != tt.compound {
+				t.Errorf("compound (Filter.Where set) = %v, want %v", compound, tt.compound)
 			}
 		})
 	}
@@ -537,30 +537,12 @@ func TestPredicateEvaluation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := EvaluateAt(tt.query, now)
+			pred, err := newPredicateBuilder(now).predicateFor(tt.query)
 			if err != nil {
-				t.Fatalf("EvaluateAt() error = %v", err)
+				t.Fatalf("predicateFor() error = %v", err)
 			}
-
-			// For simple queries, check filter
-			if !result.RequiresPredicate {
-				// Build predicate anyway for testing
-				eval := NewEvaluator(now)
-				node, _ := Parse(tt.query)
-				pred, err := eval.buildPredicate(node)
-				if err != nil {
-					t.Fatalf("buildPredicate() error = %v", err)
-				}
-				got := pred(tt.issue)
-				if got != tt.matches {
-					t.Errorf("predicate(%s) = %v, want %v", tt.issue.ID, got, tt.matches)
-				}
-			} else {
-				// Use the predicate from result
-				got := result.Predicate(tt.issue)
-				if got != tt.matches {
-					t.Errorf("predicate(%s) = %v, want %v", tt.issue.ID, got, tt.matches)
-				}
+			if got := pred(tt.issue); got != tt.matches {
+				t.Errorf("predicate(%s) = %v, want %v", tt.issue.ID, got, tt.matches)
 			}
 		})
 	}
@@ -617,3 +599,571 @@ func TestDurationParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestParseQueryClauses(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "no clauses",
+			input:    "status=open",
+			expected: "status=open",
+		},
+		{
+			name:     "order by default direction",
+			input:    "type=bug ORDER BY created",
+			expected: "type=bug ORDER BY created ASC",
+		},
+		{
+			name:     "order by multiple terms",
+			input:    "status=open order by priority asc, updated DESC",
+			expected: "status=open ORDER BY priority ASC, updated DESC",
+		},
+		{
+			name:     "limit only",
+			input:    "status=open LIMIT 5",
+			expected: "status=open LIMIT 5",
+		},
+		{
+			name:     "order by and limit",
+			input:    "priority=1 AND type=bug ORDER BY created ASC LIMIT 3",
+			expected: "(priority=1 AND type=bug) ORDER BY created ASC LIMIT 3",
+		},
+		{
+			name:     "keywords remain usable as values",
+			input:    "title=order AND label=limit",
+			expected: "(title=order AND label=limit)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+			if got := q.String(); got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseQueryClauseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"order without by", "status=open ORDER created"},
+		{"order by without field", "status=open ORDER BY"},
+		{"trailing comma", "status=open ORDER BY created,"},
+		{"limit without number", "status=open LIMIT"},
+		{"limit zero", "status=open LIMIT 0"},
+		{"limit negative", "status=open LIMIT -1"},
+		{"limit before order", "status=open LIMIT 3 ORDER BY created"},
+		{"clauses without filter", "ORDER BY created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseQuery(tt.input); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+
+	// Parse (expression only) rejects clauses
+	if _, err := Parse("status=open LIMIT 3"); err == nil {
+		t.Error("Parse() should reject LIMIT clause")
+	}
+}
+
+func TestEvaluateQueryOrderAndLimit(t *testing.T) {
+	now := time.Date(2025, 2, 4, 12, 0, 0, 0, time.UTC)
+
+	// Filter-only query pushes ORDER BY and LIMIT into the filter
+	result, err := EvaluateAt("priority=1 AND type=bug ORDER BY created ASC LIMIT 3", now)
+	if err != nil {
+		t.Fatalf("EvaluateAt() error = %v", err)
+	}
+	if result.Filter.Where != nil {
+		t.Fatal("expected filter-only query")
+	}
+	want := []types.IssueOrder{{Column: "created_at"}}
+	if len(result.Filter.OrderBy) != 1 || result.Filter.OrderBy[0] != want[0] {
+		t.Errorf("Filter.OrderBy = %v, want %v", result.Filter.OrderBy, want)
+	}
+	if result.Filter.Limit != 3 || result.Limit != 3 {
+		t.Errorf("Limit = %d/%d, want 3", result.Filter.Limit, result.Limit)
+	}
+
+	// Compound queries run in SQL too, so ORDER BY and LIMIT are pushed down
+	result, err = EvaluateAt("(status=open OR priority=0) ORDER BY priority DESC LIMIT 2", now)
+	if err != nil {
+		t.Fatalf("EvaluateAt() error = %v", err)
+	}
+	if result.Filter.Where == nil {
+		t.Fatal("expected compound query")
+	}
+	if len(result.Filter.OrderBy) != 1 || result.Filter.Limit != 2 {
+		t.Errorf("compound query should push down ORDER BY/LIMIT, got %v/%d", result.Filter.OrderBy, result.Filter.Limit)
+	}
+	if len(result.OrderBy) != 1 || result.OrderBy[0].Column != "priority" || !result.OrderBy[0].Desc {
+		t.Errorf("OrderBy = %v, want priority DESC", result.OrderBy)
+	}
+
+	// Unknown sort field
+	if _, err := EvaluateAt("status=open ORDER BY bogus", now); err == nil {
+		t.Error("expected error for unknown ORDER BY field")
+	}
+}
+
+func TestSortIssues(t *testing.T) {
+	base := time.Date(2025, 2, 4, 12, 0, 0, 0, time.UTC)
+	closed := base.Add(time.Hour)
+	issues := []*types.Issue{
+		{ID: "c", Priority: 1, CreatedAt: base.Add(2 * time.Hour)},
+		{ID: "a", Priority: 2, CreatedAt: base, ClosedAt: &closed},
+		{ID: "b", Priority: 1, CreatedAt: base.Add(time.Hour)},
+	}
+
+	SortIssues(issues, []types.IssueOrder{{Column: "priority"}, {Column: "created_at", Desc: true}})
+	if got := issues[0].ID + issues[1].ID + issues[2].ID; got != "cba" {
+		t.Errorf("priority ASC, created DESC order = %s, want cba", got)
+	}
+
+	// NULL closed_at sorts first ascending, last descending
+	SortIssues(issues, []types.IssueOrder{{Column: "closed_at"}})
+	if issues[2].ID != "a" {
+		t.Errorf("closed_at ASC should put closed issue last, got %s", issues[2].ID)
+	}
+	SortIssues(issues, []types.IssueOrder{{Column: "closed_at", Desc: true}})
+	if issues[0].ID != "a" {
+		t.Errorf("closed_at DESC should put closed issue first, got %s", issues[0].ID)
+	}
+}
+
+func TestSchedulingAndStateFilters(t *testing.T) {
+	now := time.Date(2025, 2, 4, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		query        string
+		expectFilter func(*types.IssueFilter) bool
+	}{
+		{
+			name:  "due within next 3 days",
+			query: "due<+3d",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.DueBefore != nil && f.DueBefore.Equal(now.AddDate(0, 0, 3))
+			},
+		},
+		{
+			name:  "due after date is exclusive",
+			query: `due>"2025-03-01"`,
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.DueAfter != nil && f.DueAfter.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local)) && f.DueBefore == nil
+			},
+		},
+		{
+			name:  "due on or after date includes midnight",
+			query: `due>="2025-03-01"`,
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.DueAfter != nil && f.DueAfter.Equal(time.Date(2025, 2, 28, 23, 59, 59, 0, time.Local)) && f.DueBefore == nil
+			},
+		},
+		{
+			name:  "due on or before date includes the whole day",
+			query: `due<="2025-03-01"`,
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.DueBefore != nil && f.DueBefore.Equal(time.Date(2025, 3, 2, 0, 0, 0, 0, time.Local)) && f.DueAfter == nil
+			},
+		},
+		{
+			name:  "due none",
+			query: "due=none",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.NoDue
+			},
+		},
+		{
+			name:  "defer after",
+			query: "defer>+1w",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.DeferAfter != nil && f.DeferAfter.Equal(now.AddDate(0, 0, 7))
+			},
+		},
+		{
+			name:  "estimate in hours",
+			query: "estimate<=2h",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.EstimateMax != nil && *f.EstimateMax == 120 && f.EstimateMin == nil
+			},
+		},
+		{
+			name:  "estimate greater than minutes",
+			query: "estimate>30",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.EstimateMin != nil && *f.EstimateMin == 31
+			},
+		},
+		{
+			name:  "external ref prefix",
+			query: `external_ref="gh-*"`,
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.ExternalRefPrefix == "gh-"
+			},
+		},
+		{
+			name:  "source system",
+			query: "source_system=jira",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.SourceSystem != nil && *f.SourceSystem == "jira"
+			},
+		},
+		{
+			name:  "comment count",
+			query: "comments>=3",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.CommentsMin != nil && *f.CommentsMin == 3 && f.CommentsMax == nil
+			},
+		},
+		{
+			name:  "bare ready",
+			query: "ready AND priority<2",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.Ready != nil && *f.Ready && f.PriorityMax != nil && *f.PriorityMax == 1
+			},
+		},
+		{
+			name:  "NOT blocked",
+			query: "status=open AND NOT blocked",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.Blocked != nil && !*f.Blocked
+			},
+		},
+		{
+			name:  "has_deps false",
+			query: "has_deps=false",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.HasDependencies != nil && !*f.HasDependencies
+			},
+		},
+		{
+			name:  "wisp type",
+			query: "wisp_type=heartbeat",
+			expectFilter: func(f *types.IssueFilter) bool {
+				return f.WispType != nil && *f.WispType == types.WispTypeHeartbeat
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := EvaluateAt(tt.query, now)
+			if err != nil {
+				t.Fatalf("EvaluateAt() error = %v", err)
+			}
+			if result.Filter.Where != nil {
+				t.Fatal("expected filter-only query")
+			}
+			if !tt.expectFilter(&result.Filter) {
+				t.Errorf("filter check failed for %q: %+v", tt.query, result.Filter)
+			}
+		})
+	}
+}
+
+func TestSchedulingAndStatePredicates(t *testing.T) {
+	now := time.Date(2025, 2, 4, 12, 0, 0, 0, time.UTC)
+	soon := now.Add(24 * time.Hour)
+	later := now.AddDate(0, 0, 10)
+	est := 45
+	ref := "gh-12"
+
+	dueSoon := &types.Issue{ID: "bd-1", Priority: 2, Status: types.StatusOpen, DueAt: &soon, EstimatedMinutes: &est, ExternalRef: &ref}
+	dueLater := &types.Issue{ID: "bd-2", Priority: 2, Status: types.StatusOpen, DueAt: &later, SourceSystem: "jira"}
+	blocked := &types.Issue{ID: "bd-3", Priority: 2, Status: types.StatusOpen}
+	deferred := &types.Issue{ID: "bd-4", Priority: 2, Status: types.StatusOpen, DeferUntil: &later}
+	inProgress := &types.Issue{ID: "bd-5", Priority: 2, Status: types.StatusInProgress}
+	pinned := &types.Issue{ID: "bd-6", Priority: 2, Status: types.StatusOpen, Pinned: true}
+	gate := &types.Issue{ID: "bd-7", Priority: 2, Status: types.StatusOpen, IssueType: "gate"}
+	deferredChild := &types.Issue{ID: "bd-8", Priority: 2, Status: types.StatusOpen}
+
+	tests := []struct {
+		query string
+		issue *types.Issue
+		want  bool
+	}{
+		{"due<+3d OR priority=0", dueSoon, true},
+		{"due<+3d OR priority=0", dueLater, false},
+		{"due<+3d OR priority=0", blocked, false},
+		{"due!=none", dueLater, true},
+		{"due!=none", blocked, false},
+		{"estimate<1h OR due=none", dueSoon, true},
+		{`external_ref!="gh-*"`, dueSoon, false},
+		{"source_system!=jira", dueLater, false},
+		{"comments>1 OR priority=4", dueSoon, true},
+		{"comments>1 OR priority=4", dueLater, false},
+		{"blocked OR priority=4", blocked, true},
+		{"blocked OR priority=4", dueLater, false},
+		{"ready OR priority=4", dueLater, true},
+		{"ready OR priority=4", blocked, false},
+		{"ready OR priority=4", deferred, false},
+		{"ready OR priority=4", inProgress, true},
+		{"ready OR priority=4", pinned, false},
+		{"ready OR priority=4", gate, false},
+		{"ready OR priority=4", deferredChild, false},
+		{"has_deps OR priority=4", blocked, true},
+		{"has_deps OR priority=4", dueSoon, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.issue.ID, func(t *testing.T) {
+			result, err := EvaluateAt(tt.query, now)
+			if err != nil {
+				t.Fatalf("EvaluateAt() error = %v", err)
+			}
+			if result.Filter.Where == nil {
+				t.Fatal("expected compound query")
+			}
+			b := newPredicateBuilder(now)
+			b.facts.CommentCounts = map[string]int{"bd-1": 2}
+			b.facts.BlockedIDs = map[string]bool{"bd-3": true}
+			b.facts.DeferredParentIDs = map[string]bool{"bd-8": true}
+			b.facts.DependencyCounts = map[string]int{"bd-3": 1}
+			pred, err := b.predicateFor(tt.query)
+			if err != nil {
+				t.Fatalf("predicateFor() error = %v", err)
+			}
+			if got := pred(tt.issue); got != tt.want {
+				t.Errorf("predicate(%s) = %v, want %v", tt.issue.ID, got, tt.want)
+			}
+		})
+	}
+
+}
+
+func TestGraphPredicates(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		tests := []struct {
+			input string
+			want  string
+		}{
+			{"descendant_of(bd-epic1)", "descendant_of(bd-epic1)"},
+			{"in_cycle()", "in_cycle()"},
+			{"NOT blocked_by(bd-1) AND status=open", "(NOT blocked_by(bd-1) AND status=open)"},
+			{`related_to("bd-2")`, "related_to(bd-2)"},
+		}
+		for _, tt := range tests {
+			node, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if got := node.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("parse errors", func(t *testing.T) {
+		for _, input := range []string{
+			"unknown_fn(bd-1)",
+			"descendant_of()",
+			"in_cycle(bd-1)",
+			"blocks(bd-1, bd-2)",
+			"blocks(bd-1",
+		} {
+			if _, err := Parse(input); err == nil {
+				t.Errorf("Parse(%q) expected error", input)
+			}
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		result, err := Evaluate("descendant_of(bd-epic1) AND status=open AND NOT in_cycle()")
+		if err != nil {
+			t.Fatalf("Evaluate() error = %v", err)
+		}
+		if result.Filter.Where != nil {
+			t.Fatal("graph predicates in AND chains should use filter mode")
+		}
+		want := []types.GraphFilter{
+			{Relation: types.GraphDescendantOf, Target: "bd-epic1"},
+			{Relation: types.GraphInCycle, Negate: true},
+		}
+		if !slices.Equal(result.Filter.Graph, want) {
+			t.Errorf("Filter.Graph = %+v, want %+v", result.Filter.Graph, want)
+		}
+
+		result, err = Evaluate("depends_on_type(Discovered-From)")
+		if err != nil {
+			t.Fatalf("Evaluate() error = %v", err)
+		}
+		if got := result.Filter.Graph[0].Target; got != "discovered-from" {
+			t.Errorf("depends_on_type target = %q, want discovered-from", got)
+		}
+	})
+
+	t.Run("compound", func(t *testing.T) {
+		result, err := Evaluate("blocked_by(bd-1) OR priority=0")
+		if err != nil {
+			t.Fatalf("Evaluate() error = %v", err)
+		}
+		if result.Filter.Where == nil {
+			t.Fatal("expected compound query")
+		}
+		g := types.GraphFilter{Relation: types.GraphBlockedBy, Target: "bd-1"}
+		if or := result.Filter.Where.Or; len(or) != 2 || or[0].Match == nil || !slices.Equal(or[0].Match.Graph, []types.GraphFilter{g}) {
+			t.Errorf("Filter.Where = %+v, want blocked_by(bd-1) first", result.Filter.Where)
+		}
+	})
+}
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"GROUP BY status", "GROUP BY status, count()"},
+		{"type=bug GROUP BY assignee, count(), sum(estimate)", "type=bug GROUP BY assignee, count(), sum(estimate)"},
+		{`GROUP BY "Patrol:*", avg(age) ORDER BY avg_age DESC LIMIT 3`, `GROUP BY "patrol:*", avg(age) ORDER BY avg_age DESC LIMIT 3`},
+		{"status=open GROUP BY label, type, max(updated) ORDER BY label", "status=open GROUP BY label, type, max(updated) ORDER BY label ASC"},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.input)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) error = %v", tt.input, err)
+		}
+		if got := q.String(); got != tt.want {
+			t.Errorf("ParseQuery(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{
+		"GROUP status",
+		"GROUP BY",
+		"GROUP BY count()",
+		"GROUP BY title",
+		`GROUP BY "patrol"`,
+		"GROUP BY status, median(age)",
+		"GROUP BY status, sum(created)",
+		"GROUP BY status, count(estimate)",
+		"GROUP BY status ORDER BY priority",
+	} {
+		if _, err := ParseQuery(input); err == nil {
+			t.Errorf("ParseQuery(%q) expected error", input)
+		}
+	}
+
+	if _, err := Parse("status=open GROUP BY type"); err == nil {
+		t.Error("Parse() should reject GROUP BY")
+	}
+}
+
+func TestGrouping(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	q, err := ParseQuery(`GROUP BY "patrol:*", labels, type, count(), avg(age), max(created) ORDER BY count DESC, patrol LIMIT 2`)
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	got, err := q.Grouping(now)
+	if err != nil {
+		t.Fatalf("Grouping: %v", err)
+	}
+	want := types.IssueGrouping{
+		Keys: []types.GroupKey{
+			{Labels: true, LabelPrefix: "patrol:"},
+			{Labels: true},
+			{Column: "issue_type"},
+		},
+		Aggregates: []types.GroupAggregate{
+			{Func: "count"},
+			{Func: "avg", Column: "age"},
+			{Func: "max", Column: "created_at"},
+		},
+		OrderBy: []types.GroupOrder{{Index: 3, Desc: true}, {Index: 0}},
+		Limit:   2,
+		Now:     now,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Grouping = %+v, want %+v", got, want)
+	}
+
+	if _, err := (&Query{Where: q.Where}).Grouping(now); err == nil {
+		t.Error("Grouping should fail without GROUP BY")
+	}
+}
+
+func TestNewAggregation(t *testing.T) {
+	q, err := ParseQuery("GROUP BY priority, assignee, count(), sum(estimate)")
+	if err != nil {
+		t.Fatalf("ParseQuery: %v", err)
+	}
+	agg := NewAggregation(q, []types.IssueGroup{
+		{Keys: []string{"1", ""}, Values: []any{1, nil}},
+		{Keys: []string{"2", "alice"}, Values: []any{2, 120.0}},
+	}, 3)
+
+	want := []GroupRow{
+		{Keys: []string{"P1", "(none)"}, Values: []any{1, nil}},
+		{Keys: []string{"P2", "alice"}, Values: []any{2, 120.0}},
+	}
+	if !reflect.DeepEqual(agg.Rows, want) {
+		t.Errorf("rows = %+v, want %+v", agg.Rows, want)
+	}
+	if got := strings.Join(agg.Headers(), ","); got != "priority,assignee,count(),sum(estimate)" {
+		t.Errorf("headers = %s", got)
+	}
+
+	data, err := json.Marshal(agg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `{"group_by":["priority","assignee"],"aggregates":["count","sum_estimate"],"total":3,"groups":[{"assignee":"(none)","count":1,"priority":"P1","sum_estimate":null},{"assignee":"alice","count":2,"priority":"P2","sum_estimate":120}]}`
+	if string(data) != wantJSON {
+		t.Errorf("JSON = %s, want %s", data, wantJSON)
+	}
+}
+
+func TestCompoundFilterExpr(t *testing.T) {
+	result, err := Evaluate("status=open AND (assignee!=alice OR label=ui)")
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if result.Filter.Status == nil || *result.Filter.Status != types.StatusOpen {
+		t.Errorf("top-level conjunct should stay in Filter.Status, got %v", result.Filter.Status)
+	}
+	where := result.Filter.Where
+	if where == nil || len(where.Or) != 2 {
+		t.Fatalf("Where = %+v, want an OR of two branches", where)
+	}
+	if not := where.Or[0].Not; not == nil || not.Match == nil || not.Match.Assignee == nil || *not.Match.Assignee != "alice" {
+		t.Errorf("first branch = %+v, want NOT assignee=alice", where.Or[0])
+	}
+	if m := where.Or[1].Match; m == nil || !slices.Equal(m.Labels, []string{"ui"}) {
+		t.Errorf("second branch = %+v, want label=ui", where.Or[1])
+	}
+
+	// != on closed is "closed before or after that day"; unset never matches
+	result, err = EvaluateAt(`closed!="2026-03-01"`, time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if where := result.Filter.Where; where == nil || len(where.Or) != 2 ||
+		where.Or[0].Match == nil || where.Or[0].Match.ClosedBefore == nil ||
+		where.Or[1].Match == nil || where.Or[1].Match.ClosedAfter == nil {
+		t.Errorf("closed!= Where = %+v, want closed before OR after the day", where)
+	}
+
+	// Owner equality is a plain filter
+	result, err = Evaluate("owner=ops")
+	if err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if result.Filter.Owner == nil || *result.Filter.Owner != "ops" || result.Filter.Where != nil {
+		t.Errorf("owner=ops filter = %+v", result.Filter)
+	}
+}
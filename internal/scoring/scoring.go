@@ -0,0 +1,187 @@
+// Package scoring implements weighted (WSJF-style) ranking for ready work.
+//
+// Each factor is normalized to [0, 1] and multiplied by a configurable weight.
+// The total score is the sum of the weighted factors; higher scores sort first.
+package scoring
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// Weights controls how much each factor contributes to an issue's score.
+// A weight of zero disables the factor.
+type Weights struct {
+	Priority float64 `json:"priority"` // P0 scores highest
+	Age      float64 `json:"age"`      // Older issues score higher (saturates at AgeHorizon)
+	Due      float64 `json:"due"`      // Issues closer to (or past) DueAt score higher
+	Unblocks float64 `json:"unblocks"` // Issues that transitively unblock more work score higher
+	Estimate float64 `json:"estimate"` // Shorter EstimatedMinutes score higher
+}
+
+// DefaultWeights returns the weights used when config.yaml does not override them.
+// Unblocking and priority dominate so agents pick the work that frees the most.
+func DefaultWeights() Weights {
+	return Weights{
+		Priority: 3,
+		Age:      1,
+		Due:      2,
+		Unblocks: 3,
+		Estimate: 1,
+	}
+}
+
+const (
+	// AgeHorizon is the age at which the age factor saturates at 1.0.
+	AgeHorizon = 30 * 24 * time.Hour
+
+	// DueHorizon is how far ahead of DueAt the due factor starts rising above 0.
+	DueHorizon = 14 * 24 * time.Hour
+
+	// EstimateHalfLife is the estimate (in minutes) that scores 0.5 on the estimate factor.
+	EstimateHalfLife = 60.0
+
+	// neutralEstimate is used for issues without an estimate so they are
+	// neither favored nor penalized relative to a one-hour job.
+	neutralEstimate = 0.5
+)
+
+// Breakdown is the per-factor contribution to an issue's score.
+// Each field is already multiplied by its weight; Total is their sum.
+type Breakdown struct {
+	Priority float64 `json:"priority"`
+	Age      float64 `json:"age"`
+	Due      float64 `json:"due"`
+	Unblocks float64 `json:"unblocks"`
+	Estimate float64 `json:"estimate"`
+	Total    float64 `json:"total"`
+
+	// UnblockCount is the raw number of open issues transitively unblocked.
+	UnblockCount int `json:"unblock_count"`
+}
+
+// Score computes the weighted score for a single issue.
+// unblocks is the number of open issues transitively waiting on this one.
+func Score(issue *types.Issue, unblocks int, w Weights, now time.Time) Breakdown {
+	b := Breakdown{UnblockCount: unblocks}
+	b.Priority = w.Priority * priorityFactor(issue.Priority)
+	b.Age = w.Age * ageFactor(issue.CreatedAt, now)
+	b.Due = w.Due * dueFactor(issue.DueAt, now)
+	b.Unblocks = w.Unblocks * unblocksFactor(unblocks)
+	b.Estimate = w.Estimate * estimateFactor(issue.EstimatedMinutes)
+	b.Total = b.Priority + b.Age + b.Due + b.Unblocks + b.Estimate
+	return b
+}
+
+// priorityFactor maps P0..P4 to 1.0..0.0.
+func priorityFactor(p int) float64 {
+	return clamp01(float64(4-p) / 4)
+}
+
+func ageFactor(created, now time.Time) float64 {
+	if created.IsZero() {
+		return 0
+	}
+	return clamp01(float64(now.Sub(created)) / float64(AgeHorizon))
+}
+
+// dueFactor is 0 outside DueHorizon, rises linearly to 1 at DueAt, and stays 1 when overdue.
+func dueFactor(due *time.Time, now time.Time) float64 {
+	if due == nil {
+		return 0
+	}
+	remaining := due.Sub(now)
+	if remaining <= 0 {
+		return 1
+	}
+	return clamp01(1 - float64(remaining)/float64(DueHorizon))
+}
+
+// unblocksFactor saturates: 0 → 0, 1 → 0.5, 3 → 0.75, 9 → 0.9.
+func unblocksFactor(n int) float64 {
+	if n <= 0 {
+		return 0
+	}
+	return 1 - 1/float64(n+1)
+}
+
+// estimateFactor favors short jobs: 0 min → 1.0, 60 min → 0.5, 180 min → 0.25.
+func estimateFactor(minutes *int) float64 {
+	if minutes == nil {
+		return neutralEstimate
+	}
+	return 1 / (1 + math.Max(0, float64(*minutes))/EstimateHalfLife)
+}
+
+func clamp01(f float64) float64 {
+	return math.Max(0, math.Min(1, f))
+}
+
+// CountUnblocked returns, for each ID in ids, the number of open issues that
+// transitively depend on it through blocking edges (see DependencyType.AffectsReadyWork).
+//
+// deps maps issue ID to its outgoing dependency records (as returned by
+// GetAllDependencyRecords). open reports whether an issue is still unfinished;
+// closed dependents are not counted and are not traversed.
+func CountUnblocked(ids []string, deps map[string][]*types.Dependency, open func(id string) bool) map[string]int {
+	// Invert edges: blocker -> issues waiting on it
+	dependents := make(map[string][]string)
+	for issueID, records := range deps {
+		for _, dep := range records {
+			if dep.Type.AffectsReadyWork() && dep.Type != types.DepParentChild {
+				dependents[dep.DependsOnID] = append(dependents[dep.DependsOnID], issueID)
+			}
+		}
+	}
+
+	counts := make(map[string]int, len(ids))
+	for _, id := range ids {
+		visited := map[string]bool{id: true}
+		queue := []string{id}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			for _, next := range dependents[cur] {
+				if visited[next] || !open(next) {
+					continue
+				}
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+		counts[id] = len(visited) - 1
+	}
+	return counts
+}
+
+// Scored pairs an issue with its score breakdown.
+type Scored struct {
+	Issue *types.Issue
+	Score Breakdown
+}
+
+// Rank scores and sorts issues by descending total score.
+// Ties fall back to priority, then oldest first, then ID for stability.
+func Rank(issues []*types.Issue, unblocks map[string]int, w Weights, now time.Time) []Scored {
+	ranked := make([]Scored, len(issues))
+	for i, issue := range issues {
+		ranked[i] = Scored{Issue: issue, Score: Score(issue, unblocks[issue.ID], w, now)}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Score.Total != b.Score.Total {
+			return a.Score.Total > b.Score.Total
+		}
+		if a.Issue.Priority != b.Issue.Priority {
+			return a.Issue.Priority < b.Issue.Priority
+		}
+		if !a.Issue.CreatedAt.Equal(b.Issue.CreatedAt) {
+			return a.Issue.CreatedAt.Before(b.Issue.CreatedAt)
+		}
+		return a.Issue.ID < b.Issue.ID
+	})
+	return ranked
+}
@@ -0,0 +1,153 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/config"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func intPtr(i int) *int { return &i }
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestScoreFactors(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	w := Weights{Priority: 1, Age: 1, Due: 1, Unblocks: 1, Estimate: 1}
+
+	tests := []struct {
+		name     string
+		issue    *types.Issue
+		unblocks int
+		want     Breakdown
+	}{
+		{
+			name:  "P0 brand new without due or estimate",
+			issue: &types.Issue{Priority: 0, CreatedAt: now},
+			want:  Breakdown{Priority: 1, Estimate: 0.5, Total: 1.5},
+		},
+		{
+			name:  "P4 at age horizon",
+			issue: &types.Issue{Priority: 4, CreatedAt: now.Add(-AgeHorizon), EstimatedMinutes: intPtr(60)},
+			want:  Breakdown{Age: 1, Estimate: 0.5, Total: 1.5},
+		},
+		{
+			name: "overdue",
+			issue: func() *types.Issue {
+				due := now.Add(-time.Hour)
+				return &types.Issue{Priority: 4, CreatedAt: now, DueAt: &due, EstimatedMinutes: intPtr(0)}
+			}(),
+			want: Breakdown{Due: 1, Estimate: 1, Total: 2},
+		},
+		{
+			name: "due halfway through horizon",
+			issue: func() *types.Issue {
+				due := now.Add(DueHorizon / 2)
+				return &types.Issue{Priority: 4, CreatedAt: now, DueAt: &due, EstimatedMinutes: intPtr(180)}
+			}(),
+			want: Breakdown{Due: 0.5, Estimate: 0.25, Total: 0.75},
+		},
+		{
+			name:     "unblocks three",
+			issue:    &types.Issue{Priority: 4, CreatedAt: now, EstimatedMinutes: intPtr(60)},
+			unblocks: 3,
+			want:     Breakdown{Unblocks: 0.75, Estimate: 0.5, Total: 1.25, UnblockCount: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.issue, tt.unblocks, w, now)
+			if !approx(got.Priority, tt.want.Priority) || !approx(got.Age, tt.want.Age) ||
+				!approx(got.Due, tt.want.Due) || !approx(got.Unblocks, tt.want.Unblocks) ||
+				!approx(got.Estimate, tt.want.Estimate) || !approx(got.Total, tt.want.Total) ||
+				got.UnblockCount != tt.want.UnblockCount {
+				t.Errorf("Score() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreZeroWeightDisablesFactor(t *testing.T) {
+	now := time.Now()
+	got := Score(&types.Issue{Priority: 0, CreatedAt: now}, 5, Weights{}, now)
+	if got.Total != 0 {
+		t.Errorf("Total = %v, want 0 with zero weights", got.Total)
+	}
+	if got.UnblockCount != 5 {
+		t.Errorf("UnblockCount = %d, want 5", got.UnblockCount)
+	}
+}
+
+func TestCountUnblocked(t *testing.T) {
+	dep := func(from, to string, typ types.DependencyType) *types.Dependency {
+		return &types.Dependency{IssueID: from, DependsOnID: to, Type: typ}
+	}
+	// a <- b <- c, a <- d (closed) <- e, a <- f (related, ignored), child -> a (parent-child, ignored)
+	deps := map[string][]*types.Dependency{
+		"b":     {dep("b", "a", types.DepBlocks)},
+		"c":     {dep("c", "b", types.DepBlocks)},
+		"d":     {dep("d", "a", types.DepBlocks)},
+		"e":     {dep("e", "d", types.DepBlocks)},
+		"f":     {dep("f", "a", types.DepRelated)},
+		"child": {dep("child", "a", types.DepParentChild)},
+	}
+	closed := map[string]bool{"d": true}
+	open := func(id string) bool { return !closed[id] }
+
+	got := CountUnblocked([]string{"a", "b", "c"}, deps, open)
+	want := map[string]int{"a": 2, "b": 1, "c": 0}
+	for id, n := range want {
+		if got[id] != n {
+			t.Errorf("CountUnblocked[%s] = %d, want %d", id, got[id], n)
+		}
+	}
+}
+
+func TestCountUnblockedCycle(t *testing.T) {
+	deps := map[string][]*types.Dependency{
+		"a": {{IssueID: "a", DependsOnID: "b", Type: types.DepBlocks}},
+		"b": {{IssueID: "b", DependsOnID: "a", Type: types.DepBlocks}},
+	}
+	got := CountUnblocked([]string{"a"}, deps, func(string) bool { return true })
+	if got["a"] != 1 {
+		t.Errorf("CountUnblocked[a] = %d, want 1", got["a"])
+	}
+}
+
+func TestRank(t *testing.T) {
+	now := time.Now()
+	issues := []*types.Issue{
+		{ID: "low", Priority: 3, CreatedAt: now},
+		{ID: "unblocker", Priority: 2, CreatedAt: now},
+		{ID: "urgent", Priority: 1, CreatedAt: now},
+	}
+	ranked := Rank(issues, map[string]int{"unblocker": 9}, DefaultWeights(), now)
+	gotOrder := []string{ranked[0].Issue.ID, ranked[1].Issue.ID, ranked[2].Issue.ID}
+	wantOrder := []string{"unblocker", "urgent", "low"}
+	for i := range wantOrder {
+		if gotOrder[i] != wantOrder[i] {
+			t.Fatalf("Rank order = %v, want %v", gotOrder, wantOrder)
+		}
+	}
+}
+
+// TestDefaultWeightsMatchConfig guards the ready.score.* defaults in config,
+// which repeat DefaultWeights.
+func TestDefaultWeightsMatchConfig(t *testing.T) {
+	if err := config.Initialize(); err != nil {
+		t.Fatalf("config.Initialize: %v", err)
+	}
+	got := Weights{
+		Priority: config.GetFloat64("ready.score.priority"),
+		Age:      config.GetFloat64("ready.score.age"),
+		Due:      config.GetFloat64("ready.score.due"),
+		Unblocks: config.GetFloat64("ready.score.unblocks"),
+		Estimate: config.GetFloat64("ready.score.estimate"),
+	}
+	if want := DefaultWeights(); got != want {
+		t.Errorf("config defaults = %+v, want %+v", got, want)
+	}
+}
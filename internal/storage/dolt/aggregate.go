@@ -0,0 +1,195 @@
+//go:build cgo
+
+package dolt
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// GroupIssues runs a GROUP BY over the issues matching filter and returns
+// the groups, with the grouping's ORDER BY and LIMIT applied, and the number
+// of issues matched. filter's Limit and OrderBy are ignored.
+func (s *DoltStore) GroupIssues(ctx context.Context, filter types.IssueFilter, grouping types.IssueGrouping) ([]types.IssueGroup, int, error) {
+	if len(grouping.Keys) == 0 {
+		return nil, 0, fmt.Errorf("grouping has no keys")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filterClauses, filterArgs, err := newIssueFilterSQL(ctx, s).clauses(&filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	whereSQL := ""
+	if len(filterClauses) > 0 {
+		whereSQL = "WHERE " + strings.Join(filterClauses, " AND ")
+	}
+
+	var total int
+	// nolint:gosec // G201: whereSQL contains column comparisons with ?
+	if err := s.queryRowContext(ctx, func(row *sql.Row) error { return row.Scan(&total) },
+		fmt.Sprintf("SELECT COUNT(*) FROM issues %s", whereSQL), filterArgs...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count issues: %w", err)
+	}
+
+	// Keys and aggregates read from the matching issues; label keys join one
+	// row per label, so an issue counts in the group of each of its labels
+	var selects, joins, keyAliases []string
+	var selectArgs, joinArgs []interface{}
+	for i, k := range grouping.Keys {
+		alias := fmt.Sprintf("k%d", i)
+		keyAliases = append(keyAliases, alias)
+		if !k.Labels {
+			if !types.IsGroupableIssueColumn(k.Column) {
+				return nil, 0, fmt.Errorf("cannot group by column %q", k.Column)
+			}
+			selects = append(selects, fmt.Sprintf("i.%s AS %s", k.Column, alias))
+			continue
+		}
+		table := fmt.Sprintf("l%d", i)
+		on := table + ".issue_id = i.id"
+		key := table + ".label"
+		if k.LabelPrefix != "" {
+			n := utf8.RuneCountInString(k.LabelPrefix)
+			on += fmt.Sprintf(" AND LEFT(%s.label, %d) = ? AND CHAR_LENGTH(%s.label) > %d", table, n, table, n)
+			joinArgs = append(joinArgs, k.LabelPrefix)
+			key = fmt.Sprintf("SUBSTRING(%s.label, %d)", table, n+1)
+		}
+		joins = append(joins, fmt.Sprintf("LEFT JOIN labels %s ON %s", table, on))
+		selects = append(selects, fmt.Sprintf("%s AS %s", key, alias))
+	}
+
+	aggAliases := make([]string, len(grouping.Aggregates))
+	isTime := make([]bool, len(grouping.Aggregates))
+	for i, a := range grouping.Aggregates {
+		aggAliases[i] = fmt.Sprintf("a%d", i)
+		expr, timestamp, args, err := aggregateSQL(a, grouping)
+		if err != nil {
+			return nil, 0, err
+		}
+		isTime[i] = timestamp
+		selects = append(selects, fmt.Sprintf("%s AS %s", expr, aggAliases[i]))
+		selectArgs = append(selectArgs, args...)
+	}
+
+	resultColumns := append(append([]string{}, keyAliases...), aggAliases...)
+	orderTerms := make([]string, 0, len(grouping.OrderBy)+len(keyAliases))
+	for _, o := range grouping.OrderBy {
+		if o.Index < 0 || o.Index >= len(resultColumns) {
+			return nil, 0, fmt.Errorf("group order index %d out of range", o.Index)
+		}
+		dir := "ASC"
+		if o.Desc {
+			dir = "DESC"
+		}
+		orderTerms = append(orderTerms, resultColumns[o.Index]+" "+dir)
+	}
+	for _, alias := range keyAliases {
+		orderTerms = append(orderTerms, alias+" ASC")
+	}
+
+	limitSQL := ""
+	if grouping.Limit > 0 {
+		limitSQL = fmt.Sprintf(" LIMIT %d", grouping.Limit)
+	}
+
+	// nolint:gosec // G201: columns are whitelisted, aliases generated, values passed via args, limitSQL is a safe integer
+	querySQL := fmt.Sprintf(`
+		SELECT %s
+		FROM (SELECT * FROM issues %s) AS i
+		%s
+		GROUP BY %s
+		ORDER BY %s
+		%s
+	`, strings.Join(selects, ", "), whereSQL, strings.Join(joins, " "),
+		strings.Join(keyAliases, ", "), strings.Join(orderTerms, ", "), limitSQL)
+
+	args := append(append(selectArgs, filterArgs...), joinArgs...)
+	rows, err := s.queryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to group issues: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []types.IssueGroup
+	for rows.Next() {
+		keys := make([]sql.NullString, len(keyAliases))
+		counts := make([]sql.NullInt64, len(aggAliases))
+		numbers := make([]sql.NullFloat64, len(aggAliases))
+		times := make([]sql.NullTime, len(aggAliases))
+		dest := make([]interface{}, 0, len(resultColumns))
+		for i := range keys {
+			dest = append(dest, &keys[i])
+		}
+		for i, a := range grouping.Aggregates {
+			switch {
+			case a.Func == "count":
+				dest = append(dest, &counts[i])
+			case isTime[i]:
+				dest = append(dest, &times[i])
+			default:
+				dest = append(dest, &numbers[i])
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan group: %w", err)
+		}
+
+		group := types.IssueGroup{Keys: make([]string, len(keys)), Values: make([]any, len(aggAliases))}
+		for i, k := range keys {
+			group.Keys[i] = k.String
+		}
+		for i, a := range grouping.Aggregates {
+			switch {
+			case a.Func == "count":
+				group.Values[i] = int(counts[i].Int64)
+			case isTime[i] && times[i].Valid:
+				group.Values[i] = times[i].Time
+			case !isTime[i] && numbers[i].Valid:
+				group.Values[i] = numbers[i].Float64
+			}
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return groups, total, nil
+}
+
+// aggregateSQL renders an aggregate over the grouped issues (aliased i).
+// It reports whether the aggregate is a timestamp.
+func aggregateSQL(a types.GroupAggregate, grouping types.IssueGrouping) (string, bool, []interface{}, error) {
+	if a.Func == "count" {
+		if a.Column != "" {
+			return "", false, nil, fmt.Errorf("count takes no column")
+		}
+		return "COUNT(*)", false, nil, nil
+	}
+	ok, isTime := types.IsAggregatableIssueColumn(a.Column)
+	if !ok {
+		return "", false, nil, fmt.Errorf("cannot aggregate column %q", a.Column)
+	}
+	switch a.Func {
+	case "min", "max":
+	case "sum", "avg":
+		if isTime {
+			return "", false, nil, fmt.Errorf("cannot %s timestamp column %q", a.Func, a.Column)
+		}
+	default:
+		return "", false, nil, fmt.Errorf("unknown aggregate %q", a.Func)
+	}
+
+	fn := strings.ToUpper(a.Func)
+	if a.Column == "age" {
+		return fn + "(TIMESTAMPDIFF(SECOND, i.created_at, ?) / 86400.0)", false, []interface{}{grouping.Now.UTC()}, nil
+	}
+	return fmt.Sprintf("%s(i.%s)", fn, a.Column), isTime, nil, nil
+}
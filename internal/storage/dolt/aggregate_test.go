@@ -0,0 +1,115 @@
+//go:build cgo
+
+package dolt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/query"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestGroupIssues(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := testContext(t)
+	defer cancel()
+
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	est := func(m int) *int { return &m }
+	issues := []struct {
+		issue  types.Issue
+		labels []string
+	}{
+		{types.Issue{ID: "ag-1", Title: "one", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeTask, Assignee: "alice", EstimatedMinutes: est(30), CreatedAt: now.AddDate(0, 0, -2)}, []string{"patrol:active", "ui"}},
+		{types.Issue{ID: "ag-2", Title: "two", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask, Assignee: "alice", EstimatedMinutes: est(90), CreatedAt: now.AddDate(0, 0, -4)}, []string{"patrol:muted"}},
+		{types.Issue{ID: "ag-3", Title: "three", Status: types.StatusClosed, Priority: 2, IssueType: types.TypeBug, Assignee: "bob", CreatedAt: now.AddDate(0, 0, -6), ClosedAt: &now}, []string{"ui"}},
+		{types.Issue{ID: "ag-4", Title: "four", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask, CreatedAt: now.AddDate(0, 0, -8)}, nil},
+	}
+	for _, tc := range issues {
+		issue := tc.issue
+		if err := store.CreateIssue(ctx, &issue, "tester"); err != nil {
+			t.Fatalf("failed to create issue %s: %v", issue.ID, err)
+		}
+		for _, label := range tc.labels {
+			if err := store.AddLabel(ctx, issue.ID, label, "tester"); err != nil {
+				t.Fatalf("failed to add label: %v", err)
+			}
+		}
+	}
+
+	group := func(input string) *query.Aggregation {
+		t.Helper()
+		q, err := query.ParseQuery(input)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", input, err)
+		}
+		result, err := query.NewEvaluator(now).EvaluateQuery(q)
+		if err != nil {
+			t.Fatalf("EvaluateQuery(%q): %v", input, err)
+		}
+		grouping, err := q.Grouping(now)
+		if err != nil {
+			t.Fatalf("Grouping(%q): %v", input, err)
+		}
+		groups, total, err := store.GroupIssues(ctx, result.Filter, grouping)
+		if err != nil {
+			t.Fatalf("GroupIssues(%q): %v", input, err)
+		}
+		return query.NewAggregation(q, groups, total)
+	}
+	summary := func(agg *query.Aggregation) string {
+		var rows []string
+		for _, row := range agg.Rows {
+			values := make([]string, len(row.Values))
+			for i, v := range row.Values {
+				values[i] = query.FormatAggregateValue(v)
+			}
+			rows = append(rows, strings.Join(row.Keys, "/")+"="+strings.Join(values, ","))
+		}
+		return strings.Join(rows, " ")
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"GROUP BY assignee, count(), sum(estimate), avg(age)", "(none)=1,-,8 alice=2,120,3 bob=1,-,6"},
+		{"GROUP BY priority", "P1=1 P2=3"},
+		// Multi-valued label dimensions count an issue in each group
+		{"GROUP BY label", "(none)=1 patrol:active=1 patrol:muted=1 ui=2"},
+		// State dimensions group by the label value
+		{`GROUP BY "patrol:*", status ORDER BY count DESC, patrol LIMIT 2`, "(none)/closed=1 (none)/open=1"},
+		{`GROUP BY "patrol:*", count() ORDER BY count DESC LIMIT 1`, "(none)=2"},
+		{"status=open GROUP BY type, min(estimate), max(estimate)", "task=30,90"},
+	}
+	for _, tt := range tests {
+		if got := summary(group(tt.query)); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	// Timestamps aggregate with min/max
+	agg := group("GROUP BY status, max(created), min(closed)")
+	if got := agg.Rows[1].Values[0]; !reflect.DeepEqual(got, now.AddDate(0, 0, -2)) {
+		t.Errorf("max(created) for open = %v", got)
+	}
+	if got := agg.Rows[1].Values[1]; got != nil {
+		t.Errorf("min(closed) for open = %v, want nil", got)
+	}
+	if agg.Total != 4 {
+		t.Errorf("Total = %d, want 4", agg.Total)
+	}
+
+	if got := group("status=closed GROUP BY label").Total; got != 1 {
+		t.Errorf("Total for closed = %d, want 1", got)
+	}
+
+	if _, _, err := store.GroupIssues(ctx, types.IssueFilter{}, types.IssueGrouping{Keys: []types.GroupKey{{Column: "title; DROP TABLE issues"}}}); err == nil {
+		t.Error("expected an error for a column that is not groupable")
+	}
+}
@@ -620,7 +620,24 @@ func (s *DoltStore) scanIssueIDs(ctx context.Context, rows *sql.Rows) ([]*types.
 	}
 
 	// Fetch all issues in a single batch query
-	return s.GetIssuesByIDs(ctx, ids)
+	issues, err := s.GetIssuesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// The IN (...) batch query returns rows in storage order; restore the
+	// order of the original query so ORDER BY and LIMIT results are honored.
+	byID := make(map[string]*types.Issue, len(issues))
+	for _, issue := range issues {
+		byID[issue.ID] = issue
+	}
+	ordered := make([]*types.Issue, 0, len(issues))
+	for _, id := range ids {
+		if issue, ok := byID[id]; ok {
+			ordered = append(ordered, issue)
+		}
+	}
+	return ordered, nil
 }
 
 // GetIssuesByIDs retrieves multiple issues by ID in a single query to avoid N+1 performance issues
@@ -648,7 +665,7 @@ func (s *DoltStore) GetIssuesByIDs(ctx context.Context, ids []string) ([]*types.
 		       hook_bead, role_bead, agent_state, last_activity, role_type, rig, mol_type,
 		       event_kind, actor, target, payload,
 		       due_at, defer_until,
-		       quality_score, work_type, source_system
+		       quality_score, work_type, source_system, metadata, validations
 		FROM issues
 		WHERE id IN (%s)
 	`, strings.Join(placeholders, ","))
@@ -685,6 +702,7 @@ func scanIssueRow(rows *sql.Rows) (*types.Issue, error) {
 	var hookBead, roleBead, agentState, roleType, rig sql.NullString
 	var ephemeral, pinned, isTemplate, crystallizes sql.NullInt64
 	var qualityScore sql.NullFloat64
+	var metadata, validations sql.NullString
 
 	if err := rows.Scan(
 		&issue.ID, &contentHash, &issue.Title, &issue.Description, &issue.Design,
@@ -697,7 +715,7 @@ func scanIssueRow(rows *sql.Rows) (*types.Issue, error) {
 		&hookBead, &roleBead, &agentState, &lastActivity, &roleType, &rig, &molType,
 		&eventKind, &actor, &target, &payload,
 		&dueAt, &deferUntil,
-		&qualityScore, &workType, &sourceSystem,
+		&qualityScore, &workType, &sourceSystem, &metadata, &validations,
 	); err != nil {
 		return nil, fmt.Errorf("failed to scan issue row: %w", err)
 	}
@@ -824,6 +842,12 @@ func scanIssueRow(rows *sql.Rows) (*types.Issue, error) {
 	if sourceSystem.Valid {
 		issue.SourceSystem = sourceSystem.String
 	}
+	if metadata.Valid && metadata.String != "" && metadata.String != "{}" {
+		issue.Metadata = []byte(metadata.String)
+	}
+	if validations.Valid && validations.String != "" {
+		issue.Validations = parseValidations(validations.String)
+	}
 
 	return &issue, nil
 }
@@ -0,0 +1,342 @@
+//go:build cgo
+
+package dolt
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/query"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// TestSearchIssuesFilterExpr checks the SQL rendering of compound queries
+// (Filter.Where).
+func TestSearchIssuesFilterExpr(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := testContext(t)
+	defer cancel()
+
+	issues := []struct {
+		issue  types.Issue
+		labels []string
+	}{
+		{types.Issue{ID: "fx-1", Title: "login bug", Status: types.StatusOpen, Priority: 1, IssueType: types.TypeBug, Assignee: "alice", Owner: "ops"}, []string{"auth", "web"}},
+		{types.Issue{ID: "fx-2", Title: "cache layer", Status: types.StatusInProgress, Priority: 2, IssueType: types.TypeFeature, Assignee: "bob"}, []string{"web"}},
+		{types.Issue{ID: "fx-3", Title: "docs pass", Status: types.StatusOpen, Priority: 3, IssueType: types.TypeTask}, nil},
+		{types.Issue{ID: "fx-4", Title: "flaky test", Status: types.StatusBlocked, Priority: 0, IssueType: types.TypeBug, Assignee: "alice"}, []string{"ci"}},
+		{types.Issue{ID: "fx-5", Title: "old cleanup", Status: types.StatusClosed, Priority: 4, IssueType: types.TypeChore, Owner: "ops"}, []string{"ci", "auth"}},
+	}
+	for _, tc := range issues {
+		issue := tc.issue
+		if err := store.CreateIssue(ctx, &issue, "tester"); err != nil {
+			t.Fatalf("failed to create issue %s: %v", issue.ID, err)
+		}
+		for _, label := range tc.labels {
+			if err := store.AddLabel(ctx, issue.ID, label, "tester"); err != nil {
+				t.Fatalf("failed to add label: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"assignee!=alice", "fx-2,fx-3,fx-5"},
+		{"status=open AND (assignee!=alice OR label=ci)", "fx-3"},
+		{"NOT (label=web OR priority<=1)", "fx-3,fx-5"},
+		{"owner=ops OR priority=2", "fx-1,fx-2,fx-5"},
+		{"owner!=ops AND type!=bug", "fx-2,fx-3"},
+		{"NOT label=auth", "fx-2,fx-3,fx-4"},
+		{"(type=bug AND NOT status=blocked) OR (label=ci AND priority>3)", "fx-1,fx-5"},
+		{"title=cache OR NOT (status=open OR status=closed)", "fx-2,fx-4"},
+		{"label!=web AND NOT assignee=none", "fx-4"},
+		{`closed!="2020-01-01" OR priority=3`, "fx-3,fx-5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			result, err := query.Evaluate(tt.query)
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+			if result.Filter.Where == nil {
+				t.Fatalf("expected a compound query")
+			}
+
+			got, err := store.SearchIssues(ctx, "", result.Filter)
+			if err != nil {
+				t.Fatalf("SearchIssues: %v", err)
+			}
+			if gotIDs := strings.Join(issueIDs(got), ","); gotIDs != tt.want {
+				t.Errorf("SQL matched %s, want %s", gotIDs, tt.want)
+			}
+		})
+	}
+}
+
+// TestSearchIssuesReadyMatchesGetReadyWork checks that the query language's
+// ready field selects exactly the issues bd ready lists.
+func TestSearchIssuesReadyMatchesGetReadyWork(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := testContext(t)
+	defer cancel()
+
+	future := time.Now().Add(72 * time.Hour)
+	fixture := []types.Issue{
+		{ID: "rd-open", Title: "open", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask},
+		{ID: "rd-wip", Title: "in progress", Status: types.StatusInProgress, Priority: 2, IssueType: types.TypeTask},
+		{ID: "rd-closed", Title: "closed", Status: types.StatusClosed, Priority: 2, IssueType: types.TypeTask},
+		{ID: "rd-pinned", Title: "pinned", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask, Pinned: true},
+		{ID: "rd-wisp", Title: "ephemeral", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask, Ephemeral: true},
+		{ID: "rd-gate", Title: "gate", Status: types.StatusOpen, Priority: 2, IssueType: "gate"},
+		{ID: "rd-deferred", Title: "deferred", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeEpic, DeferUntil: &future},
+		{ID: "rd-child", Title: "child of deferred", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask},
+		{ID: "rd-blocked", Title: "blocked", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask},
+	}
+	for i := range fixture {
+		if err := store.CreateIssue(ctx, &fixture[i], "tester"); err != nil {
+			t.Fatalf("failed to create issue %s: %v", fixture[i].ID, err)
+		}
+	}
+	for _, dep := range []*types.Dependency{
+		{IssueID: "rd-child", DependsOnID: "rd-deferred", Type: types.DepParentChild},
+		{IssueID: "rd-blocked", DependsOnID: "rd-open", Type: types.DepBlocks},
+	} {
+		if err := store.AddDependency(ctx, dep, "tester"); err != nil {
+			t.Fatalf("failed to add dependency: %v", err)
+		}
+	}
+
+	ready, err := store.GetReadyWork(ctx, types.WorkFilter{})
+	if err != nil {
+		t.Fatalf("GetReadyWork: %v", err)
+	}
+	want := issueIDs(ready)
+	if strings.Join(want, ",") != "rd-open,rd-wip" {
+		t.Fatalf("GetReadyWork = %v, want the open and in-progress issues", want)
+	}
+
+	for _, q := range []string{"ready=true", "ready AND priority=2", "ready OR priority=0"} {
+		t.Run(q, func(t *testing.T) {
+			result, err := query.Evaluate(q)
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+			got, err := store.SearchIssues(ctx, "", result.Filter)
+			if err != nil {
+				t.Fatalf("SearchIssues: %v", err)
+			}
+			if gotIDs := issueIDs(got); strings.Join(gotIDs, ",") != strings.Join(want, ",") {
+				t.Errorf("SQL matched %v, bd ready lists %v", gotIDs, want)
+			}
+		})
+	}
+}
+
+// TestSearchIssuesHasDeps checks that has_deps counts every dependency type
+// that affects ready work, and only those.
+func TestSearchIssuesHasDeps(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := testContext(t)
+	defer cancel()
+
+	for _, id := range []string{"hd-target", "hd-blocks", "hd-cond", "hd-waits", "hd-related", "hd-none"} {
+		issue := &types.Issue{ID: id, Title: id, Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+		if err := store.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("failed to create issue %s: %v", id, err)
+		}
+	}
+	for id, depType := range map[string]types.DependencyType{
+		"hd-blocks":  types.DepBlocks,
+		"hd-cond":    types.DepConditionalBlocks,
+		"hd-waits":   types.DepWaitsFor,
+		"hd-related": types.DepRelated,
+	} {
+		dep := &types.Dependency{IssueID: id, DependsOnID: "hd-target", Type: depType}
+		if err := store.AddDependency(ctx, dep, "tester"); err != nil {
+			t.Fatalf("failed to add %s dependency: %v", depType, err)
+		}
+	}
+
+	for q, want := range map[string]string{
+		"has_deps=true":  "hd-blocks,hd-cond,hd-waits",
+		"has_deps=false": "hd-none,hd-related,hd-target",
+	} {
+		result, err := query.Evaluate(q)
+		if err != nil {
+			t.Fatalf("Evaluate(%q): %v", q, err)
+		}
+		got, err := store.SearchIssues(ctx, "", result.Filter)
+		if err != nil {
+			t.Fatalf("SearchIssues(%q): %v", q, err)
+		}
+		if gotIDs := strings.Join(issueIDs(got), ","); gotIDs != want {
+			t.Errorf("%s matched %s, want %s", q, gotIDs, want)
+		}
+	}
+}
+
+// TestSearchIssuesOrderByTitle checks that ORDER BY title sorts the same in
+// SQL as query.SortIssues does in memory: case-insensitively.
+func TestSearchIssuesOrderByTitle(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := testContext(t)
+	defer cancel()
+
+	for id, title := range map[string]string{"ot-1": "banana", "ot-2": "Apple", "ot-3": "cherry", "ot-4": "Banana split"} {
+		issue := &types.Issue{ID: id, Title: title, Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+		if err := store.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("failed to create issue %s: %v", id, err)
+		}
+	}
+
+	for _, q := range []string{"status=open ORDER BY title", "status=open ORDER BY title DESC"} {
+		result, err := query.Evaluate(q)
+		if err != nil {
+			t.Fatalf("Evaluate(%q): %v", q, err)
+		}
+		got, err := store.SearchIssues(ctx, "", result.Filter)
+		if err != nil {
+			t.Fatalf("SearchIssues(%q): %v", q, err)
+		}
+		want := slices.Clone(got)
+		query.SortIssues(want, result.OrderBy)
+
+		var gotTitles, wantTitles []string
+		for i := range got {
+			gotTitles = append(gotTitles, got[i].Title)
+			wantTitles = append(wantTitles, want[i].Title)
+		}
+		if !slices.Equal(gotTitles, wantTitles) {
+			t.Errorf("%s: SQL order %q, in-memory order %q", q, gotTitles, wantTitles)
+		}
+	}
+}
+
+// TestSearchIssuesScheduleBounds checks date comparisons on due times at and
+// around midnight: > excludes the boundary instant, >= and <= take in the
+// whole day.
+func TestSearchIssuesScheduleBounds(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := testContext(t)
+	defer cancel()
+
+	for id, due := range map[string]time.Time{
+		"sb-1": time.Date(2026, 2, 28, 23, 59, 59, 0, time.Local), // Last second before the day
+		"sb-2": time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),     // The boundary instant
+		"sb-3": time.Date(2026, 3, 1, 23, 59, 59, 0, time.Local),  // Last second of the day
+		"sb-4": time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local),     // Next midnight
+	} {
+		issue := &types.Issue{ID: id, Title: id, Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask, DueAt: &due}
+		if err := store.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("failed to create issue %s: %v", id, err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`due>"2026-03-01"`, []string{"sb-3", "sb-4"}},
+		{`due>="2026-03-01"`, []string{"sb-2", "sb-3", "sb-4"}},
+		{`due="2026-03-01"`, []string{"sb-2", "sb-3"}},
+		{`due<="2026-03-01"`, []string{"sb-1", "sb-2", "sb-3"}},
+		{`due<"2026-03-01"`, []string{"sb-1"}},
+	}
+	for _, tt := range tests {
+		result, err := query.Evaluate(tt.query)
+		if err != nil {
+			t.Fatalf("Evaluate(%q): %v", tt.query, err)
+		}
+		got, err := store.SearchIssues(ctx, "", result.Filter)
+		if err != nil {
+			t.Fatalf("SearchIssues(%q): %v", tt.query, err)
+		}
+		if ids := issueIDs(got); !slices.Equal(ids, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+}
+
+func issueIDs(issues []*types.Issue) []string {
+	ids := make([]string, len(issues))
+	for i, issue := range issues {
+		ids[i] = issue.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// TestSearchIssuesGraph runs each graph relation through the SQL rendering,
+// alone, negated, and under OR.
+func TestSearchIssuesGraph(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := testContext(t)
+	defer cancel()
+
+	for _, id := range []string{"gr-epic", "gr-child", "gr-grand", "gr-target", "gr-blocked", "gr-rel", "gr-c1", "gr-c2"} {
+		issue := &types.Issue{ID: id, Title: id, Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+		if err := store.CreateIssue(ctx, issue, "tester"); err != nil {
+			t.Fatalf("failed to create issue %s: %v", id, err)
+		}
+	}
+	for _, dep := range []*types.Dependency{
+		{IssueID: "gr-child", DependsOnID: "gr-epic", Type: types.DepParentChild},
+		{IssueID: "gr-grand", DependsOnID: "gr-child", Type: types.DepParentChild},
+		{IssueID: "gr-blocked", DependsOnID: "gr-target", Type: types.DepBlocks},
+		{IssueID: "gr-rel", DependsOnID: "gr-target", Type: types.DepRelated},
+		{IssueID: "gr-c1", DependsOnID: "gr-c2", Type: types.DepBlocks},
+	} {
+		if err := store.AddDependency(ctx, dep, "tester"); err != nil {
+			t.Fatalf("failed to add %s dependency: %v", dep.Type, err)
+		}
+	}
+	// AddDependency refuses to close a cycle, so insert the back edge directly
+	if _, err := store.db.ExecContext(ctx, `
+		INSERT INTO dependencies (issue_id, depends_on_id, type, created_at, created_by)
+		VALUES ('gr-c2', 'gr-c1', 'blocks', NOW(), 'tester')
+	`); err != nil {
+		t.Fatalf("failed to add cycle edge: %v", err)
+	}
+
+	for q, want := range map[string]string{
+		"blocked_by(gr-target)":                            "gr-blocked",
+		"blocks(gr-blocked)":                               "gr-target",
+		"descendant_of(gr-epic)":                           "gr-child,gr-grand",
+		"related_to(gr-target)":                            "gr-rel",
+		"related_to(gr-rel)":                               "gr-target",
+		"depends_on_type(parent-child)":                    "gr-child,gr-grand",
+		"in_cycle()":                                       "gr-c1,gr-c2",
+		"blocked_by(gr-nothing)":                           "",
+		"NOT descendant_of(gr-epic) AND NOT in_cycle()":    "gr-blocked,gr-epic,gr-rel,gr-target",
+		"blocked_by(gr-target) OR descendant_of(gr-child)": "gr-blocked,gr-grand",
+	} {
+		result, err := query.Evaluate(q)
+		if err != nil {
+			t.Fatalf("Evaluate(%q): %v", q, err)
+		}
+		got, err := store.SearchIssues(ctx, "", result.Filter)
+		if err != nil {
+			t.Fatalf("SearchIssues(%q): %v", q, err)
+		}
+		if gotIDs := strings.Join(issueIDs(got), ","); gotIDs != want {
+			t.Errorf("%s matched %s, want %s", q, gotIDs, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+//go:build cgo
+
+package dolt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// graphSubquery renders a graph filter as a subquery that selects the IDs of
+// the matching issues, for use as "id IN (...)". Every relation is answered
+// from the dependencies table alone (no joins against issues, see
+// computeBlockedIDs); descendant_of walks parent-child edges with a recursive
+// CTE. in_cycle has no SQL form and is resolved by computeCycleIDs instead.
+func graphSubquery(g types.GraphFilter) (string, []interface{}, error) {
+	switch g.Relation {
+	case types.GraphBlockedBy:
+		return `SELECT issue_id FROM dependencies
+			WHERE depends_on_id = ? AND type = 'blocks'`, []interface{}{g.Target}, nil
+	case types.GraphBlocks:
+		return `SELECT depends_on_id FROM dependencies
+			WHERE issue_id = ? AND type = 'blocks'`, []interface{}{g.Target}, nil
+	case types.GraphDescendantOf:
+		return `WITH RECURSIVE descendants AS (
+				SELECT issue_id AS id, 0 AS depth
+				FROM dependencies
+				WHERE depends_on_id = ? AND type = 'parent-child'
+				UNION ALL
+				SELECT d.issue_id, ds.depth + 1
+				FROM descendants ds
+				JOIN dependencies d ON d.depends_on_id = ds.id
+				WHERE d.type = 'parent-child'
+				  AND ds.depth < 50
+			)
+			SELECT id FROM descendants`, []interface{}{g.Target}, nil
+	case types.GraphRelatedTo:
+		return `SELECT depends_on_id FROM dependencies
+			WHERE issue_id = ? AND type IN ('related', 'relates-to')
+			UNION
+			SELECT issue_id FROM dependencies
+			WHERE depends_on_id = ? AND type IN ('related', 'relates-to')`, []interface{}{g.Target, g.Target}, nil
+	case types.GraphDependsOnType:
+		return `SELECT issue_id FROM dependencies WHERE type = ?`, []interface{}{g.Target}, nil
+	default:
+		return "", nil, fmt.Errorf("unknown graph relation: %s", g.Relation)
+	}
+}
+
+// computeCycleIDs returns the sorted IDs of issues that lie on a cycle of
+// 'blocks' dependencies. Finding strongly connected components needs the
+// whole edge set, so this loads it and runs Tarjan's algorithm in Go.
+// Caller must hold s.mu (at least RLock).
+func (s *DoltStore) computeCycleIDs(ctx context.Context) ([]string, error) {
+	edges, err := s.queryEdges(ctx, `
+		SELECT issue_id, depends_on_id FROM dependencies WHERE type = 'blocks'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return findCycleMembers(edges), nil
+}
+
+// queryEdges runs a two-column (from, to) query and returns an adjacency list.
+func (s *DoltStore) queryEdges(ctx context.Context, query string, args ...any) (map[string][]string, error) {
+	rows, err := s.queryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dependency graph: %w", err)
+	}
+	defer rows.Close()
+
+	edges := make(map[string][]string)
+	for rows.Next() {
+		var from, to string
+		if err := rows.Scan(&from, &to); err != nil {
+			return nil, err
+		}
+		edges[from] = append(edges[from], to)
+	}
+	return edges, rows.Err()
+}
+
+// findCycleMembers returns every node that lies on at least one cycle:
+// members of a strongly connected component with more than one node, or
+// nodes with a self-loop. Uses Tarjan's algorithm; the result is sorted.
+func findCycleMembers(edges map[string][]string) []string {
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var members []string
+	next := 0
+
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		index[v] = next
+		lowlink[v] = next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range edges[v] {
+			if _, visited := index[w]; !visited {
+				strongConnect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], index[w])
+			}
+		}
+
+		if lowlink[v] != index[v] {
+			return
+		}
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 || hasSelfLoop(edges, v) {
+			members = append(members, component...)
+		}
+	}
+
+	// Visit in sorted order so the traversal is deterministic
+	nodes := make([]string, 0, len(edges))
+	for v := range edges {
+		nodes = append(nodes, v)
+	}
+	sort.Strings(nodes)
+	for _, v := range nodes {
+		if _, visited := index[v]; !visited {
+			strongConnect(v)
+		}
+	}
+
+	sort.Strings(members)
+	return members
+}
+
+func hasSelfLoop(edges map[string][]string, v string) bool {
+	for _, w := range edges[v] {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+//go:build cgo
+
+package dolt
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindCycleMembers(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges map[string][]string
+		want  []string
+	}{
+		{
+			name:  "acyclic chain",
+			edges: map[string][]string{"a": {"b"}, "b": {"c"}},
+			want:  []string{},
+		},
+		{
+			name:  "two-node cycle with tail",
+			edges: map[string][]string{"a": {"b"}, "b": {"a"}, "c": {"a"}},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "self loop",
+			edges: map[string][]string{"a": {"a"}, "b": {"a"}},
+			want:  []string{"a"},
+		},
+		{
+			name: "two separate cycles",
+			edges: map[string][]string{
+				"a": {"b"}, "b": {"c"}, "c": {"a"},
+				"x": {"y"}, "y": {"x"},
+				"z": {"x"},
+			},
+			want: []string{"a", "b", "c", "x", "y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findCycleMembers(tt.edges)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findCycleMembers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
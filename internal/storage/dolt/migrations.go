@@ -23,6 +23,9 @@ type Migration struct {
 var migrationsList = []Migration{
 	{"wisp_type_column", migrations.MigrateWispTypeColumn},
 	{"spec_id_column", migrations.MigrateSpecIDColumn},
+	{"validations_column", migrations.MigrateValidationsColumn},
+	{"webhook_outbox_local", migrations.MigrateWebhookOutboxLocal},
+	{"webhook_outbox_lease", migrations.MigrateWebhookOutboxLease},
 }
 
 // RunMigrations executes all registered Dolt migrations in order.
@@ -0,0 +1,30 @@
+//go:build cgo
+
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MigrateValidationsColumn adds the validations column to the issues table.
+// It stores the issue's Validations (who approved or rejected the work) as
+// a JSON array; approval gates count them.
+// New databases already have this column from the schema definition;
+// this migration handles databases created before it was added.
+func MigrateValidationsColumn(db *sql.DB) error {
+	exists, err := columnExists(db, "issues", "validations")
+	if err != nil {
+		return fmt.Errorf("failed to check validations column: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = db.Exec(`ALTER TABLE issues ADD COLUMN validations JSON`)
+	if err != nil {
+		return fmt.Errorf("failed to add validations column: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+//go:build cgo
+
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MigrateWebhookOutboxLocal keeps the webhook_outbox table out of Dolt
+// commits. The outbox is per-clone delivery state: if it were versioned,
+// every clone would re-send the pending rows it pulled, and AUTO_INCREMENT
+// ids from different clones would collide on merge.
+// The table is added to dolt_ignore so that DOLT_COMMIT('-Am') skips it,
+// and databases that already committed it have it deleted in the next
+// commit while keeping the local rows.
+func MigrateWebhookOutboxLocal(db *sql.DB) error {
+	if _, err := db.Exec(`REPLACE INTO dolt_ignore (pattern, ignored) VALUES ('webhook_outbox', true)`); err != nil {
+		return fmt.Errorf("failed to ignore webhook_outbox: %w", err)
+	}
+
+	exists, err := tableExists(db, "webhook_outbox")
+	if err != nil {
+		return fmt.Errorf("failed to check webhook_outbox table: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	// dolt_ignore keeps DOLT_COMMIT('-Am') from staging the table, but a copy
+	// that an earlier version committed stays in HEAD. Stage its deletion and
+	// put the local rows back. The query fails when HEAD has no such table.
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM webhook_outbox AS OF 'HEAD'`).Scan(&count); err != nil {
+		return nil
+	}
+	for _, stmt := range []string{
+		`DROP TABLE IF EXISTS webhook_outbox_copy`,
+		`CREATE TABLE webhook_outbox_copy LIKE webhook_outbox`,
+		`INSERT INTO webhook_outbox_copy SELECT * FROM webhook_outbox`,
+		`DROP TABLE webhook_outbox`,
+		`CALL DOLT_ADD('-f', 'webhook_outbox')`,
+		// Recreate under the original name (a RENAME resets the
+		// AUTO_INCREMENT counter) so the copied ids are not reused
+		`CREATE TABLE webhook_outbox LIKE webhook_outbox_copy`,
+		`INSERT INTO webhook_outbox SELECT * FROM webhook_outbox_copy`,
+		`DROP TABLE webhook_outbox_copy`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to untrack webhook_outbox: %w", err)
+		}
+	}
+	return nil
+}
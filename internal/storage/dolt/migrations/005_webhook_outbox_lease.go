@@ -0,0 +1,37 @@
+//go:build cgo
+
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MigrateWebhookOutboxLease adds the lease_until column to webhook_outbox.
+// A delivery pass claims each delivery by setting it before sending, so two
+// bd processes never send the same delivery.
+// New databases already have this column from the schema definition;
+// this migration handles databases created before it was added.
+func MigrateWebhookOutboxLease(db *sql.DB) error {
+	exists, err := tableExists(db, "webhook_outbox")
+	if err != nil {
+		return fmt.Errorf("failed to check webhook_outbox table: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+	exists, err = columnExists(db, "webhook_outbox", "lease_until")
+	if err != nil {
+		return fmt.Errorf("failed to check lease_until column: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = db.Exec(`ALTER TABLE webhook_outbox ADD COLUMN lease_until DATETIME`)
+	if err != nil {
+		return fmt.Errorf("failed to add lease_until column: %w", err)
+	}
+
+	return nil
+}
@@ -113,6 +113,35 @@ func TestMigrateWispTypeColumn(t *testing.T) {
 	}
 }
 
+func TestMigrateValidationsColumn(t *testing.T) {
+	db := openTestDolt(t)
+
+	exists, err := columnExists(db, "issues", "validations")
+	if err != nil {
+		t.Fatalf("failed to check column: %v", err)
+	}
+	if exists {
+		t.Fatal("validations should not exist yet")
+	}
+
+	if err := MigrateValidationsColumn(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	exists, err = columnExists(db, "issues", "validations")
+	if err != nil {
+		t.Fatalf("failed to check column: %v", err)
+	}
+	if !exists {
+		t.Fatal("validations should exist after migration")
+	}
+
+	// Run migration again (idempotent)
+	if err := MigrateValidationsColumn(db); err != nil {
+		t.Fatalf("re-running migration should be idempotent: %v", err)
+	}
+}
+
 func TestColumnExists(t *testing.T) {
 	db := openTestDolt(t)
 
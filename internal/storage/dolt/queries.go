@@ -27,6 +27,90 @@ func (s *DoltStore) SearchIssues(ctx context.Context, query string, filter types
 		args = append(args, pattern, pattern, pattern)
 	}
 
+	filterClauses, filterArgs, err := newIssueFilterSQL(ctx, s).clauses(&filter)
+	if err != nil {
+		return nil, err
+	}
+	whereClauses = append(whereClauses, filterClauses...)
+	args = append(args, filterArgs...)
+
+	whereSQL := ""
+	if len(whereClauses) > 0 {
+		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	limitSQL := ""
+	if filter.Limit > 0 {
+		limitSQL = fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	orderSQL, err := buildIssueOrderSQL(filter.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec // G201: whereSQL contains column comparisons with ?, orderSQL uses whitelisted columns, limitSQL is a safe integer
+	querySQL := fmt.Sprintf(`
+		SELECT id FROM issues
+		%s
+		ORDER BY %s
+		%s
+	`, whereSQL, orderSQL, limitSQL)
+
+	rows, err := s.queryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+	defer rows.Close()
+
+	return s.scanIssueIDs(ctx, rows)
+}
+
+// issueFilterSQL renders IssueFilters as SQL WHERE conditions. Derived ID
+// sets (blocked issues, dependency cycles) are computed at most once per
+// search and shared by every branch of a FilterExpr.
+// The store's lock must be held (at least RLock) while it is used.
+type issueFilterSQL struct {
+	ctx     context.Context
+	s       *DoltStore
+	blocked []string
+	loaded  bool
+	cycles  []string
+	cycled  bool
+}
+
+func newIssueFilterSQL(ctx context.Context, s *DoltStore) *issueFilterSQL {
+	return &issueFilterSQL{ctx: ctx, s: s}
+}
+
+func (b *issueFilterSQL) blockedIDs() ([]string, error) {
+	if !b.loaded {
+		ids, err := b.s.computeBlockedIDs(b.ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.blocked, b.loaded = ids, true
+	}
+	return b.blocked, nil
+}
+
+func (b *issueFilterSQL) cycleIDs() ([]string, error) {
+	if !b.cycled {
+		ids, err := b.s.computeCycleIDs(b.ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.cycles, b.cycled = ids, true
+	}
+	return b.cycles, nil
+}
+
+// clauses returns the WHERE conditions (to be ANDed) and their arguments for
+// a filter. Limit and OrderBy are not part of the WHERE clause and are ignored.
+func (b *issueFilterSQL) clauses(filter *types.IssueFilter) ([]string, []interface{}, error) {
+	whereClauses := []string{}
+	args := []interface{}{}
+
 	if filter.TitleSearch != "" {
 		whereClauses = append(whereClauses, "title LIKE ?")
 		args = append(args, "%"+filter.TitleSearch+"%")
@@ -96,6 +180,10 @@ func (s *DoltStore) SearchIssues(ctx context.Context, query string, filter types
 		whereClauses = append(whereClauses, "assignee = ?")
 		args = append(args, *filter.Assignee)
 	}
+	if filter.Owner != nil {
+		whereClauses = append(whereClauses, "owner = ?")
+		args = append(args, *filter.Owner)
+	}
 
 	// Date ranges
 	if filter.CreatedAfter != nil {
@@ -254,44 +342,241 @@ func (s *DoltStore) SearchIssues(ctx context.Context, query string, filter types
 		args = append(args, filter.DueBefore.Format(time.RFC3339))
 	}
 
-	whereSQL := ""
-	if len(whereClauses) > 0 {
-		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
+	if filter.NoDue {
+		whereClauses = append(whereClauses, "due_at IS NULL")
+	}
+	if filter.NoDefer {
+		whereClauses = append(whereClauses, "defer_until IS NULL")
 	}
 
-	limitSQL := ""
-	if filter.Limit > 0 {
-		limitSQL = fmt.Sprintf(" LIMIT %d", filter.Limit)
+	// Estimate filtering
+	if filter.EstimateMin != nil {
+		whereClauses = append(whereClauses, "estimated_minutes >= ?")
+		args = append(args, *filter.EstimateMin)
+	}
+	if filter.EstimateMax != nil {
+		whereClauses = append(whereClauses, "estimated_minutes <= ?")
+		args = append(args, *filter.EstimateMax)
+	}
+	if filter.NoEstimate {
+		whereClauses = append(whereClauses, "estimated_minutes IS NULL")
 	}
 
-	// nolint:gosec // G201: whereSQL contains column comparisons with ?, limitSQL is a safe integer
-	querySQL := fmt.Sprintf(`
-		SELECT id FROM issues
-		%s
-		ORDER BY priority ASC, created_at DESC
-		%s
-	`, whereSQL, limitSQL)
+	// External integration filtering
+	if filter.ExternalRef != nil {
+		whereClauses = append(whereClauses, "external_ref = ?")
+		args = append(args, *filter.ExternalRef)
+	}
+	if filter.ExternalRefPrefix != "" {
+		whereClauses = append(whereClauses, "external_ref LIKE ?")
+		args = append(args, filter.ExternalRefPrefix+"%")
+	}
+	if filter.NoExternalRef {
+		whereClauses = append(whereClauses, "(external_ref IS NULL OR external_ref = '')")
+	}
+	if filter.SourceSystem != nil {
+		if *filter.SourceSystem == "" {
+			whereClauses = append(whereClauses, "(source_system IS NULL OR source_system = '')")
+		} else {
+			whereClauses = append(whereClauses, "source_system = ?")
+			args = append(args, *filter.SourceSystem)
+		}
+	}
 
-	rows, err := s.queryContext(ctx, querySQL, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search issues: %w", err)
+	// Comment count filtering. Uses uncorrelated GROUP BY subqueries (correlated
+	// subqueries trigger Dolt's joinIter panic, see computeBlockedIDs).
+	if filter.CommentsMin != nil && *filter.CommentsMin > 0 {
+		whereClauses = append(whereClauses, "id IN (SELECT issue_id FROM comments GROUP BY issue_id HAVING COUNT(*) >= ?)")
+		args = append(args, *filter.CommentsMin)
+	}
+	if filter.CommentsMax != nil {
+		whereClauses = append(whereClauses, "id NOT IN (SELECT issue_id FROM comments GROUP BY issue_id HAVING COUNT(*) > ?)")
+		args = append(args, *filter.CommentsMax)
 	}
-	defer rows.Close()
 
-	return s.scanIssueIDs(ctx, rows)
+	// Derived dependency state: dependencies that affect ready work
+	if filter.HasDependencies != nil {
+		depTypes := types.ReadyWorkDependencyTypes()
+		placeholders := make([]string, len(depTypes))
+		for i, t := range depTypes {
+			placeholders[i] = "?"
+			args = append(args, string(t))
+		}
+		depsSQL := fmt.Sprintf("id IN (SELECT issue_id FROM dependencies WHERE type IN (%s))", strings.Join(placeholders, ", "))
+		if *filter.HasDependencies {
+			whereClauses = append(whereClauses, depsSQL)
+		} else {
+			whereClauses = append(whereClauses, "NOT "+depsSQL)
+		}
+	}
+	if filter.Blocked != nil {
+		blockedIDs, err := b.blockedIDs()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compute blocked issues: %w", err)
+		}
+		blockedIn := "1 = 0" // no blocked issues
+		if len(blockedIDs) > 0 {
+			placeholders := make([]string, len(blockedIDs))
+			for i, id := range blockedIDs {
+				placeholders[i] = "?"
+				args = append(args, id)
+			}
+			blockedIn = fmt.Sprintf("id IN (%s)", strings.Join(placeholders, ", "))
+		}
+		if *filter.Blocked {
+			whereClauses = append(whereClauses, blockedIn)
+		} else {
+			whereClauses = append(whereClauses, "NOT ("+blockedIn+")")
+		}
+	}
+	if filter.Ready != nil {
+		blockedIDs, err := b.blockedIDs()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compute blocked issues: %w", err)
+		}
+		readyClauses, readyArgs := readyWorkClauses(types.WorkFilter{}, blockedIDs)
+		readySQL := "(" + strings.Join(readyClauses, " AND ") + ")"
+		if *filter.Ready {
+			whereClauses = append(whereClauses, readySQL)
+		} else {
+			whereClauses = append(whereClauses, "NOT "+readySQL)
+		}
+		args = append(args, readyArgs...)
+	}
+
+	// Dependency graph relationships: a subquery on dependencies, like
+	// HasDependencies above, except in_cycle which needs the whole edge set
+	// in Go and filters with an ID list like blocked/ready.
+	for _, g := range filter.Graph {
+		var graphSQL string
+		if g.Relation == types.GraphInCycle {
+			ids, err := b.cycleIDs()
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to resolve %s: %w", g.Relation, err)
+			}
+			graphSQL = "1 = 0" // no cycles
+			if len(ids) > 0 {
+				placeholders := make([]string, len(ids))
+				for i, id := range ids {
+					placeholders[i] = "?"
+					args = append(args, id)
+				}
+				graphSQL = fmt.Sprintf("id IN (%s)", strings.Join(placeholders, ", "))
+			}
+		} else {
+			subquery, subArgs, err := graphSubquery(g)
+			if err != nil {
+				return nil, nil, err
+			}
+			graphSQL = fmt.Sprintf("id IN (%s)", subquery)
+			args = append(args, subArgs...)
+		}
+		if g.Negate {
+			graphSQL = "NOT (" + graphSQL + ")"
+		}
+		whereClauses = append(whereClauses, graphSQL)
+	}
+
+	if filter.Where != nil {
+		exprSQL, exprArgs, err := b.expr(filter.Where)
+		if err != nil {
+			return nil, nil, err
+		}
+		whereClauses = append(whereClauses, exprSQL)
+		args = append(args, exprArgs...)
+	}
+
+	return whereClauses, args, nil
 }
 
-// GetReadyWork returns issues that are ready to work on (not blocked)
-func (s *DoltStore) GetReadyWork(ctx context.Context, filter types.WorkFilter) ([]*types.Issue, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// expr renders a FilterExpr as a single parenthesized condition. NOT uses
+// IS NOT TRUE so rows where the operand is NULL (e.g. assignee = ? on an
+// unassigned issue) count as not matching, as they do in the AND/OR cases.
+func (b *issueFilterSQL) expr(e *types.FilterExpr) (string, []interface{}, error) {
+	switch {
+	case e.Match != nil:
+		clauses, args, err := b.clauses(e.Match)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(clauses) == 0 {
+			return "(1 = 1)", nil, nil
+		}
+		return "(" + strings.Join(clauses, " AND ") + ")", args, nil
+	case e.Not != nil:
+		operand, args, err := b.expr(e.Not)
+		if err != nil {
+			return "", nil, err
+		}
+		return "(" + operand + " IS NOT TRUE)", args, nil
+	case len(e.And) > 0:
+		return b.join(e.And, " AND ")
+	case len(e.Or) > 0:
+		return b.join(e.Or, " OR ")
+	case e.Or != nil:
+		return "(1 = 0)", nil, nil // Empty OR matches nothing
+	default:
+		return "(1 = 1)", nil, nil // Empty AND matches everything
+	}
+}
+
+func (b *issueFilterSQL) join(exprs []*types.FilterExpr, op string) (string, []interface{}, error) {
+	parts := make([]string, len(exprs))
+	var args []interface{}
+	for i, sub := range exprs {
+		part, subArgs, err := b.expr(sub)
+		if err != nil {
+			return "", nil, err
+		}
+		parts[i] = part
+		args = append(args, subArgs...)
+	}
+	return "(" + strings.Join(parts, op) + ")", args, nil
+}
+
+// buildIssueOrderSQL renders an ORDER BY list from IssueFilter.OrderBy.
+// Columns are checked against types.IsSortableIssueColumn before interpolation.
+// Titles sort case-insensitively, as query.SortIssues sorts them in memory.
+// An id tie-breaker is appended so LIMIT results are deterministic.
+func buildIssueOrderSQL(order []types.IssueOrder) (string, error) {
+	if len(order) == 0 {
+		return "priority ASC, created_at DESC", nil
+	}
+	terms := make([]string, 0, len(order)+1)
+	hasID := false
+	for _, o := range order {
+		if !types.IsSortableIssueColumn(o.Column) {
+			return "", fmt.Errorf("cannot sort by column %q", o.Column)
+		}
+		dir := "ASC"
+		if o.Desc {
+			dir = "DESC"
+		}
+		column := o.Column
+		if column == "title" {
+			column = "LOWER(title)"
+		}
+		terms = append(terms, column+" "+dir)
+		hasID = hasID || o.Column == "id"
+	}
+	if !hasID {
+		terms = append(terms, "id ASC")
+	}
+	return strings.Join(terms, ", "), nil
+}
+
+// readyWorkClauses returns the WHERE conditions, and their arguments, that
+// make an issue ready work: the status, type, pin, ephemeral, deferral and
+// blocker rules of GetReadyWork, as adjusted by filter. The query language's
+// ready field uses them with an empty filter, so ready=true matches bd ready.
+func readyWorkClauses(filter types.WorkFilter, blockedIDs []string) ([]string, []interface{}) {
+	var args []interface{}
 
 	// Status filtering: default to open OR in_progress (matches memory storage)
-	var statusClause string
+	statusClause := "status IN ('open', 'in_progress')"
 	if filter.Status != "" {
 		statusClause = "status = ?"
-	} else {
-		statusClause = "status IN ('open', 'in_progress')"
+		args = append(args, string(filter.Status))
 	}
 	whereClauses := []string{
 		statusClause,
@@ -300,50 +585,26 @@ func (s *DoltStore) GetReadyWork(ctx context.Context, filter types.WorkFilter) (
 	if !filter.IncludeEphemeral {
 		whereClauses = append(whereClauses, "(ephemeral = 0 OR ephemeral IS NULL)")
 	}
-	args := []interface{}{}
-	if filter.Status != "" {
-		args = append(args, string(filter.Status))
-	}
 
-	if filter.Priority != nil {
-		whereClauses = append(whereClauses, "priority = ?")
-		args = append(args, *filter.Priority)
-	}
 	// Use subquery for type filter to prevent Dolt mergeJoinIter panic (see SearchIssues).
 	if filter.Type != "" {
 		whereClauses = append(whereClauses, "id IN (SELECT id FROM issues WHERE issue_type = ?)")
 		args = append(args, filter.Type)
 	} else {
-		// Exclude workflow/identity types from ready work by default.
-		// These are internal items, not actionable work for agents to claim:
-		// - merge-request: processed by Refinery
-		// - gate: async wait conditions
-		// - molecule: workflow containers
-		// - message: mail/communication items
-		// - agent: identity/state tracking beads
-		// - role: agent role definitions (reference metadata)
-		// - rig: rig identity beads (reference metadata)
-		excludeTypes := []string{"merge-request", "gate", "molecule", "message", "agent", "role", "rig"}
+		// Exclude workflow/identity types from ready work by default
+		excludeTypes := types.ReadyWorkExcludedTypes()
 		placeholders := make([]string, len(excludeTypes))
 		for i, t := range excludeTypes {
 			placeholders[i] = "?"
-			args = append(args, t)
+			args = append(args, string(t))
 		}
 		whereClauses = append(whereClauses, fmt.Sprintf("id IN (SELECT id FROM issues WHERE issue_type NOT IN (%s))", strings.Join(placeholders, ",")))
 	}
-	// Unassigned takes precedence over Assignee filter (matches memory storage)
-	if filter.Unassigned {
-		whereClauses = append(whereClauses, "(assignee IS NULL OR assignee = '')")
-	} else if filter.Assignee != nil {
-		whereClauses = append(whereClauses, "assignee = ?")
-		args = append(args, *filter.Assignee)
-	}
-	// Exclude future-deferred issues unless IncludeDeferred is set
+
+	// Exclude future-deferred issues, and children of future-deferred
+	// parents (GH#1190), unless IncludeDeferred is set
 	if !filter.IncludeDeferred {
 		whereClauses = append(whereClauses, "(defer_until IS NULL OR defer_until <= NOW())")
-	}
-	// Exclude children of future-deferred parents (GH#1190)
-	if !filter.IncludeDeferred {
 		whereClauses = append(whereClauses, `
 			NOT EXISTS (
 				SELECT 1 FROM dependencies d_parent
@@ -355,18 +616,11 @@ func (s *DoltStore) GetReadyWork(ctx context.Context, filter types.WorkFilter) (
 			)
 		`)
 	}
-	if len(filter.Labels) > 0 {
-		for _, label := range filter.Labels {
-			whereClauses = append(whereClauses, "id IN (SELECT issue_id FROM labels WHERE label = ?)")
-			args = append(args, label)
-		}
-	}
 
-	// Exclude blocked issues: pre-compute blocked set using separate single-table
-	// queries to avoid Dolt's joinIter panic (join_iters.go:192).
+	// Exclude blocked issues: the blocked set is pre-computed using separate
+	// single-table queries to avoid Dolt's joinIter panic (join_iters.go:192).
 	// Correlated EXISTS/NOT EXISTS subqueries across tables trigger the same panic.
-	blockedIDs, err := s.computeBlockedIDs(ctx)
-	if err == nil && len(blockedIDs) > 0 {
+	if len(blockedIDs) > 0 {
 		placeholders := make([]string, len(blockedIDs))
 		for i, id := range blockedIDs {
 			placeholders[i] = "?"
@@ -374,6 +628,46 @@ func (s *DoltStore) GetReadyWork(ctx context.Context, filter types.WorkFilter) (
 		}
 		whereClauses = append(whereClauses, fmt.Sprintf("id NOT IN (%s)", strings.Join(placeholders, ", ")))
 	}
+	return whereClauses, args
+}
+
+// GetReadyWork returns issues that are ready to work on (not blocked)
+func (s *DoltStore) GetReadyWork(ctx context.Context, filter types.WorkFilter) ([]*types.Issue, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blockedIDs, err := s.computeBlockedIDs(ctx)
+	if err != nil {
+		blockedIDs = nil // Best effort: ready work without blocker filtering
+	}
+	whereClauses, args := readyWorkClauses(filter, blockedIDs)
+
+	if filter.Priority != nil {
+		whereClauses = append(whereClauses, "priority = ?")
+		args = append(args, *filter.Priority)
+	}
+	// Unassigned takes precedence over Assignee filter (matches memory storage)
+	if filter.Unassigned {
+		whereClauses = append(whereClauses, "(assignee IS NULL OR assignee = '')")
+	} else if filter.Assignee != nil {
+		whereClauses = append(whereClauses, "assignee = ?")
+		args = append(args, *filter.Assignee)
+	}
+	if len(filter.Labels) > 0 {
+		for _, label := range filter.Labels {
+			whereClauses = append(whereClauses, "id IN (SELECT issue_id FROM labels WHERE label = ?)")
+			args = append(args, label)
+		}
+	}
+
+	if filter.Where != nil {
+		exprSQL, exprArgs, err := newIssueFilterSQL(ctx, s).expr(filter.Where)
+		if err != nil {
+			return nil, err
+		}
+		whereClauses = append(whereClauses, exprSQL)
+		args = append(args, exprArgs...)
+	}
 
 	whereSQL := "WHERE " + strings.Join(whereClauses, " AND ")
 
@@ -382,13 +676,18 @@ func (s *DoltStore) GetReadyWork(ctx context.Context, filter types.WorkFilter) (
 		limitSQL = fmt.Sprintf(" LIMIT %d", filter.Limit)
 	}
 
-	// nolint:gosec // G201: whereSQL contains column comparisons with ?, limitSQL is a safe integer
+	orderSQL, err := buildIssueOrderSQL(filter.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:gosec // G201: whereSQL contains column comparisons with ?, orderSQL uses whitelisted columns, limitSQL is a safe integer
 	query := fmt.Sprintf(`
 		SELECT id FROM issues
 		%s
-		ORDER BY priority ASC, created_at DESC
+		ORDER BY %s
 		%s
-	`, whereSQL, limitSQL)
+	`, whereSQL, orderSQL, limitSQL)
 
 	rows, err := s.queryContext(ctx, query, args...)
 	if err != nil {
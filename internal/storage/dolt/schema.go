@@ -5,7 +5,7 @@ package dolt
 // currentSchemaVersion is bumped whenever the schema or migrations change.
 // initSchemaOnDB checks this against the stored version and skips re-initialization
 // when they match, avoiding ~20 DDL statements per bd invocation.
-const currentSchemaVersion = 3
+const currentSchemaVersion = 7
 
 // schema defines the MySQL-compatible database schema for Dolt.
 // This mirrors the SQLite schema but uses MySQL syntax.
@@ -53,6 +53,8 @@ CREATE TABLE IF NOT EXISTS issues (
     work_type VARCHAR(32) DEFAULT 'mutex',
     -- HOP quality score field (0.0-1.0)
     quality_score DOUBLE,
+    -- HOP validations: who approved or rejected the work (JSON array)
+    validations JSON,
     -- Federation source system field
     source_system VARCHAR(255) DEFAULT '',
     -- Custom metadata field (GH#1406)
@@ -242,6 +244,27 @@ CREATE TABLE IF NOT EXISTS federation_peers (
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     INDEX idx_federation_peers_sovereignty (sovereignty)
 );
+
+-- Webhook outbox (events queued for delivery to configured webhooks).
+-- Local to each clone: the webhook_outbox_local migration adds it to
+-- dolt_ignore so it is never committed.
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    webhook VARCHAR(255) NOT NULL,
+    event VARCHAR(64) NOT NULL,
+    issue_id VARCHAR(255),
+    payload LONGTEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at DATETIME,
+    lease_until DATETIME,
+    INDEX idx_webhook_outbox_due (status, next_attempt_at),
+    INDEX idx_webhook_outbox_webhook (webhook)
+);
 `
 
 // defaultConfig contains the default configuration values
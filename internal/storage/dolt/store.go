@@ -958,9 +958,10 @@ func (s *DoltStore) AddRemote(ctx context.Context, name, url string) error {
 	return nil
 }
 
-// Status returns the current Dolt status (staged/unstaged changes)
+// Status returns the current Dolt status (staged/unstaged changes).
+// Tables matched by dolt_ignore are never committed and are not reported.
 func (s *DoltStore) Status(ctx context.Context) (*DoltStatus, error) {
-	rows, err := s.db.QueryContext(ctx, "SELECT table_name, staged, status FROM dolt_status")
+	rows, err := s.db.QueryContext(ctx, "SELECT table_name, staged, status FROM dolt_status_ignored WHERE ignored = 0")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
@@ -321,6 +321,10 @@ func (s *DoltStore) SearchIssues(_ context.Context, _ string, _ types.IssueFilte
 	return nil, errNoCGO
 }
 
+func (s *DoltStore) GroupIssues(_ context.Context, _ types.IssueFilter, _ types.IssueGrouping) ([]types.IssueGroup, int, error) {
+	return nil, 0, errNoCGO
+}
+
 func (s *DoltStore) GetReadyWork(_ context.Context, _ types.WorkFilter) ([]*types.Issue, error) {
 	return nil, errNoCGO
 }
@@ -485,6 +489,10 @@ func (s *DoltStore) ImportIssueComment(_ context.Context, _, _, _ string, _ time
 	return nil, errNoCGO
 }
 
+func (s *DoltStore) AddValidation(_ context.Context, _ string, _ types.Validation, _ string) error {
+	return errNoCGO
+}
+
 func (s *DoltStore) GetIssueComments(_ context.Context, _ string) ([]*types.Comment, error) {
 	return nil, errNoCGO
 }
@@ -625,6 +633,28 @@ func (s *DoltStore) RemoveFederationPeer(_ context.Context, _ string) error {
 	return errNoCGO
 }
 
+// --- DoltStore: Webhook Outbox ---
+
+func (s *DoltStore) EnqueueWebhookDelivery(_ context.Context, _ *types.WebhookDelivery) error {
+	return errNoCGO
+}
+
+func (s *DoltStore) GetWebhookDelivery(_ context.Context, _ int64) (*types.WebhookDelivery, error) {
+	return nil, errNoCGO
+}
+
+func (s *DoltStore) GetDueWebhookDeliveries(_ context.Context, _ time.Time, _ int) ([]*types.WebhookDelivery, error) {
+	return nil, errNoCGO
+}
+
+func (s *DoltStore) ListWebhookDeliveries(_ context.Context, _ types.WebhookDeliveryStatus, _ string, _ int) ([]*types.WebhookDelivery, error) {
+	return nil, errNoCGO
+}
+
+func (s *DoltStore) UpdateWebhookDelivery(_ context.Context, _ *types.WebhookDelivery) error {
+	return errNoCGO
+}
+
 // --- DoltStore: Compaction ---
 
 func (s *DoltStore) CheckEligibility(_ context.Context, _ string, _ int) (bool, string, error) {
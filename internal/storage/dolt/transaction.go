@@ -89,7 +89,7 @@ func (t *doltTransaction) CreateIssue(ctx context.Context, issue *types.Issue, a
 		issue.ID = generatedID
 	}
 
-	return insertIssueTx(ctx, t.tx, issue)
+	return insertIssue(ctx, t.tx, issue)
 }
 
 // CreateIssues creates multiple issues within the transaction
@@ -225,11 +225,15 @@ func (t *doltTransaction) DeleteIssue(ctx context.Context, id string) error {
 
 // AddDependency adds a dependency within the transaction
 func (t *doltTransaction) AddDependency(ctx context.Context, dep *types.Dependency, actor string) error {
+	metadata := dep.Metadata
+	if metadata == "" {
+		metadata = "{}"
+	}
 	_, err := t.tx.ExecContext(ctx, `
-		INSERT INTO dependencies (issue_id, depends_on_id, type, created_at, created_by, thread_id)
-		VALUES (?, ?, ?, NOW(), ?, ?)
-		ON DUPLICATE KEY UPDATE type = VALUES(type)
-	`, dep.IssueID, dep.DependsOnID, dep.Type, actor, dep.ThreadID)
+		INSERT INTO dependencies (issue_id, depends_on_id, type, created_at, created_by, metadata, thread_id)
+		VALUES (?, ?, ?, NOW(), ?, ?, ?)
+		ON DUPLICATE KEY UPDATE type = VALUES(type), metadata = VALUES(metadata)
+	`, dep.IssueID, dep.DependsOnID, dep.Type, actor, metadata, dep.ThreadID)
 	return err
 }
 
@@ -401,28 +405,6 @@ func (t *doltTransaction) AddComment(ctx context.Context, issueID, actor, commen
 
 // Helper functions for transaction context
 
-func insertIssueTx(ctx context.Context, tx *sql.Tx, issue *types.Issue) error {
-	_, err := tx.ExecContext(ctx, `
-		INSERT INTO issues (
-			id, content_hash, title, description, design, acceptance_criteria, notes,
-			status, priority, issue_type, assignee, estimated_minutes,
-			created_at, created_by, owner, updated_at, closed_at,
-			sender, ephemeral, wisp_type, pinned, is_template, crystallizes
-		) VALUES (
-			?, ?, ?, ?, ?, ?, ?,
-			?, ?, ?, ?, ?,
-			?, ?, ?, ?, ?,
-			?, ?, ?, ?, ?, ?
-		)
-	`,
-		issue.ID, issue.ContentHash, issue.Title, issue.Description, issue.Design, issue.AcceptanceCriteria, issue.Notes,
-		issue.Status, issue.Priority, issue.IssueType, nullString(issue.Assignee), nullInt(issue.EstimatedMinutes),
-		issue.CreatedAt, issue.CreatedBy, issue.Owner, issue.UpdatedAt, issue.ClosedAt,
-		issue.Sender, issue.Ephemeral, string(issue.WispType), issue.Pinned, issue.IsTemplate, issue.Crystallizes,
-	)
-	return err
-}
-
 func scanIssueTx(ctx context.Context, tx *sql.Tx, id string) (*types.Issue, error) {
 	var issue types.Issue
 	var createdAtStr, updatedAtStr sql.NullString // TEXT columns - must parse manually
@@ -0,0 +1,76 @@
+//go:build cgo
+
+package dolt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// AddValidation appends a validation to an issue's Validations. The append
+// happens in a single statement, so concurrent validators cannot overwrite
+// each other.
+func (s *DoltStore) AddValidation(ctx context.Context, issueID string, v types.Validation, actor string) error {
+	if !v.IsValidOutcome() {
+		return fmt.Errorf("invalid validation outcome %q", v.Outcome)
+	}
+	if v.Timestamp.IsZero() {
+		v.Timestamp = time.Now().UTC()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode validation: %w", err)
+	}
+
+	result, err := s.execContext(ctx, `
+		UPDATE issues
+		SET validations = JSON_ARRAY_APPEND(COALESCE(validations, JSON_ARRAY()), '$', CAST(? AS JSON)),
+		    updated_at = ?
+		WHERE id = ?
+	`, string(data), time.Now().UTC(), issueID)
+	if err != nil {
+		return fmt.Errorf("failed to add validation: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("issue %s not found", issueID)
+	}
+
+	comment := fmt.Sprintf("%s by %s", v.Outcome, v.Validator.String())
+	if v.Reason != "" {
+		comment += ": " + v.Reason
+	}
+	_, err = s.execContext(ctx, `
+		INSERT INTO events (issue_id, event_type, actor, comment)
+		VALUES (?, ?, ?, ?)
+	`, issueID, types.EventValidated, actor, comment)
+	if err != nil {
+		return fmt.Errorf("failed to record validation event: %w", err)
+	}
+	return nil
+}
+
+// jsonValidations returns validations as a JSON array, or nil (NULL) if
+// there are none.
+func jsonValidations(validations []types.Validation) interface{} {
+	if len(validations) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(validations)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
+
+// parseValidations decodes the validations column.
+func parseValidations(s string) []types.Validation {
+	var validations []types.Validation
+	if err := json.Unmarshal([]byte(s), &validations); err != nil {
+		return nil
+	}
+	return validations
+}
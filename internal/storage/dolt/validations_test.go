@@ -0,0 +1,76 @@
+//go:build cgo
+
+package dolt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestAddValidation(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	issue := &types.Issue{
+		ID:        "val-gate",
+		Title:     "Release sign-off",
+		Status:    types.StatusOpen,
+		Priority:  1,
+		IssueType: types.IssueType("gate"),
+		AwaitType: "approval",
+		AwaitID:   "2",
+	}
+	if err := store.CreateIssue(ctx, issue, "tester"); err != nil {
+		t.Fatalf("failed to create issue: %v", err)
+	}
+
+	if err := store.AddValidation(ctx, issue.ID, types.Validation{
+		Validator: &types.EntityRef{Name: "alice"},
+		Outcome:   types.ValidationAccepted,
+	}, "alice"); err != nil {
+		t.Fatalf("AddValidation: %v", err)
+	}
+	if err := store.AddValidation(ctx, issue.ID, types.Validation{
+		Validator: &types.EntityRef{Name: "bob"},
+		Outcome:   types.ValidationRejected,
+		Reason:    "changelog missing",
+	}, "bob"); err != nil {
+		t.Fatalf("AddValidation: %v", err)
+	}
+
+	got, err := store.GetIssue(ctx, issue.ID)
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if len(got.Validations) != 2 {
+		t.Fatalf("got %d validations, want 2: %+v", len(got.Validations), got.Validations)
+	}
+	if v := got.Validations[0]; v.Validator.String() != "alice" || v.Outcome != types.ValidationAccepted || v.Timestamp.IsZero() {
+		t.Errorf("first validation = %+v", v)
+	}
+	if v := got.Validations[1]; v.Validator.String() != "bob" || v.Outcome != types.ValidationRejected || v.Reason != "changelog missing" {
+		t.Errorf("second validation = %+v", v)
+	}
+
+	// Search results carry validations too
+	byIDs, err := store.GetIssuesByIDs(ctx, []string{issue.ID})
+	if err != nil || len(byIDs) != 1 || len(byIDs[0].Validations) != 2 {
+		t.Errorf("GetIssuesByIDs validations = %+v, err %v", byIDs, err)
+	}
+
+	if err := store.AddValidation(ctx, issue.ID, types.Validation{Outcome: "maybe"}, "carol"); err == nil {
+		t.Error("expected an error for an invalid outcome")
+	}
+	if err := store.AddValidation(ctx, "val-missing", types.Validation{
+		Validator: &types.EntityRef{Name: "alice"},
+		Outcome:   types.ValidationAccepted,
+	}, "alice"); err == nil {
+		t.Error("expected an error for a missing issue")
+	}
+}
@@ -0,0 +1,166 @@
+//go:build cgo
+
+package dolt
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+const webhookDeliveryColumns = `id, webhook, event, issue_id, payload, status, attempts,
+	next_attempt_at, last_status, last_error, created_at, delivered_at`
+
+// EnqueueWebhookDelivery adds a delivery to the webhook outbox, due now
+// unless NextAttemptAt is set. It sets d.ID.
+func (s *DoltStore) EnqueueWebhookDelivery(ctx context.Context, d *types.WebhookDelivery) error {
+	now := time.Now().UTC()
+	if d.Status == "" {
+		d.Status = types.WebhookPending
+	}
+	if d.NextAttemptAt.IsZero() {
+		d.NextAttemptAt = now
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = now
+	}
+	result, err := s.execContext(ctx, `
+		INSERT INTO webhook_outbox (webhook, event, issue_id, payload, status, attempts, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, d.Webhook, d.Event, d.IssueID, d.Payload, string(d.Status), d.Attempts, outboxTime(d.NextAttemptAt), d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+	}
+	if id, err := result.LastInsertId(); err == nil {
+		d.ID = id
+	}
+	return nil
+}
+
+// GetWebhookDelivery returns a delivery from the outbox, or nil if there is
+// none with that ID.
+func (s *DoltStore) GetWebhookDelivery(ctx context.Context, id int64) (*types.WebhookDelivery, error) {
+	rows, err := s.queryContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_outbox WHERE id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+	return deliveries[0], nil
+}
+
+// GetDueWebhookDeliveries returns up to limit pending deliveries whose next
+// attempt is due at now and that no delivery pass holds a lease on, oldest
+// first.
+func (s *DoltStore) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*types.WebhookDelivery, error) {
+	rows, err := s.queryContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_outbox
+		WHERE status = ? AND next_attempt_at <= ?
+		  AND (lease_until IS NULL OR lease_until < ?)
+		ORDER BY next_attempt_at, id
+		LIMIT ?
+	`, string(types.WebhookPending), now.UTC(), now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+// ClaimWebhookDelivery leases a pending delivery to the caller until
+// now+lease, unless another delivery pass holds an unexpired lease on it.
+// It reports whether the claim succeeded. UpdateWebhookDelivery releases
+// the lease.
+func (s *DoltStore) ClaimWebhookDelivery(ctx context.Context, id int64, now time.Time, lease time.Duration) (bool, error) {
+	result, err := s.execContext(ctx, `
+		UPDATE webhook_outbox
+		SET lease_until = ?
+		WHERE id = ? AND status = ? AND (lease_until IS NULL OR lease_until < ?)
+	`, now.Add(lease).UTC(), id, string(types.WebhookPending), now.UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+	return n == 1, nil
+}
+
+// ListWebhookDeliveries returns up to limit deliveries, newest first,
+// optionally only those with status and for webhook.
+func (s *DoltStore) ListWebhookDeliveries(ctx context.Context, status types.WebhookDeliveryStatus, webhook string, limit int) ([]*types.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_outbox WHERE 1=1`
+	var args []interface{}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, string(status))
+	}
+	if webhook != "" {
+		query += ` AND webhook = ?`
+		args = append(args, webhook)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.queryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return scanWebhookDeliveries(rows)
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt, or a
+// replay: it writes d's status, attempts, schedule and last result, and
+// releases any lease on it.
+func (s *DoltStore) UpdateWebhookDelivery(ctx context.Context, d *types.WebhookDelivery) error {
+	result, err := s.execContext(ctx, `
+		UPDATE webhook_outbox
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_status = ?, last_error = ?, delivered_at = ?,
+		    lease_until = NULL
+		WHERE id = ?
+	`, string(d.Status), d.Attempts, outboxTime(d.NextAttemptAt), d.LastStatus, d.LastError, d.DeliveredAt, d.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("webhook delivery %d not found", d.ID)
+	}
+	return nil
+}
+
+// outboxTime truncates a schedule time to the second. DATETIME rounds
+// fractions, which could leave a delivery due "now" due only after the pass
+// that should send it.
+func outboxTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*types.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*types.WebhookDelivery
+	for rows.Next() {
+		var d types.WebhookDelivery
+		var status string
+		var issueID, lastError sql.NullString
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.Webhook, &d.Event, &issueID, &d.Payload, &status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatus, &lastError, &d.CreatedAt, &deliveredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		d.Status = types.WebhookDeliveryStatus(status)
+		d.IssueID = issueID.String
+		d.LastError = lastError.String
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
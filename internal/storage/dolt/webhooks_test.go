@@ -0,0 +1,192 @@
+//go:build cgo
+
+package dolt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestWebhookOutbox(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	first := &types.WebhookDelivery{Webhook: "ci", Event: "close", IssueID: "bd-1", Payload: `{"event":"close"}`}
+	if err := store.EnqueueWebhookDelivery(ctx, first); err != nil {
+		t.Fatalf("EnqueueWebhookDelivery: %v", err)
+	}
+	later := &types.WebhookDelivery{Webhook: "chat", Event: "create", Payload: `{"event":"create"}`,
+		NextAttemptAt: time.Now().Add(time.Hour)}
+	if err := store.EnqueueWebhookDelivery(ctx, later); err != nil {
+		t.Fatalf("EnqueueWebhookDelivery: %v", err)
+	}
+	if first.ID == 0 || later.ID == first.ID {
+		t.Fatalf("delivery IDs not assigned: %d, %d", first.ID, later.ID)
+	}
+
+	due, err := store.GetDueWebhookDeliveries(ctx, time.Now().Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("GetDueWebhookDeliveries: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != first.ID {
+		t.Fatalf("due = %+v, want only delivery %d", due, first.ID)
+	}
+	d := due[0]
+	if d.Status != types.WebhookPending || d.IssueID != "bd-1" || d.Payload != `{"event":"close"}` {
+		t.Errorf("due delivery = %+v", d)
+	}
+
+	// A claimed delivery is not due, and cannot be claimed again, until
+	// its lease lapses or the outcome is recorded
+	now := time.Now()
+	if claimed, err := store.ClaimWebhookDelivery(ctx, d.ID, now, time.Minute); err != nil || !claimed {
+		t.Fatalf("ClaimWebhookDelivery = %v, %v; want claimed", claimed, err)
+	}
+	if claimed, err := store.ClaimWebhookDelivery(ctx, d.ID, now, time.Minute); err != nil || claimed {
+		t.Fatalf("second ClaimWebhookDelivery = %v, %v; want refused", claimed, err)
+	}
+	if due, err := store.GetDueWebhookDeliveries(ctx, now.Add(time.Second), 10); err != nil || len(due) != 0 {
+		t.Fatalf("due while leased = %+v, %v", due, err)
+	}
+	if claimed, err := store.ClaimWebhookDelivery(ctx, d.ID, now.Add(2*time.Minute), time.Minute); err != nil || !claimed {
+		t.Fatalf("ClaimWebhookDelivery after the lease lapsed = %v, %v; want claimed", claimed, err)
+	}
+
+	// A failed attempt is retried later; a dead one is never due
+	d.Attempts = 1
+	d.LastStatus = 503
+	d.LastError = "HTTP 503"
+	d.Status = types.WebhookDead
+	if err := store.UpdateWebhookDelivery(ctx, d); err != nil {
+		t.Fatalf("UpdateWebhookDelivery: %v", err)
+	}
+	due, err = store.GetDueWebhookDeliveries(ctx, time.Now().Add(2*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("GetDueWebhookDeliveries: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != later.ID {
+		t.Fatalf("due = %+v, want only delivery %d", due, later.ID)
+	}
+
+	dead, err := store.ListWebhookDeliveries(ctx, types.WebhookDead, "", 10)
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries: %v", err)
+	}
+	if len(dead) != 1 || dead[0].LastError != "HTTP 503" || dead[0].LastStatus != 503 || dead[0].Attempts != 1 {
+		t.Errorf("dead = %+v", dead)
+	}
+	all, err := store.ListWebhookDeliveries(ctx, "", "", 10)
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != later.ID {
+		t.Errorf("all = %+v, want newest first", all)
+	}
+	byName, err := store.ListWebhookDeliveries(ctx, "", "chat", 10)
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries: %v", err)
+	}
+	if len(byName) != 1 || byName[0].Webhook != "chat" {
+		t.Errorf("chat deliveries = %+v", byName)
+	}
+
+	now = time.Now().UTC()
+	d.Status = types.WebhookDelivered
+	d.DeliveredAt = &now
+	if err := store.UpdateWebhookDelivery(ctx, d); err != nil {
+		t.Fatalf("UpdateWebhookDelivery: %v", err)
+	}
+	got, err := store.GetWebhookDelivery(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("GetWebhookDelivery: %v", err)
+	}
+	if got == nil || got.Status != types.WebhookDelivered || got.DeliveredAt == nil {
+		t.Errorf("delivered = %+v", got)
+	}
+	if missing, err := store.GetWebhookDelivery(ctx, 999999); err != nil || missing != nil {
+		t.Errorf("GetWebhookDelivery(missing) = %+v, %v", missing, err)
+	}
+}
+
+// TestWebhookOutboxNotCommitted verifies that the outbox stays local to the
+// clone: Dolt commits leave it out, so pulls never bring in another clone's
+// pending deliveries.
+func TestWebhookOutboxNotCommitted(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx, cancel := testContext(t)
+	defer cancel()
+
+	assertLocalOnly := func(t *testing.T, wantRows int) {
+		t.Helper()
+		var n int
+		if err := store.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_outbox AS OF 'HEAD'").Scan(&n); err == nil {
+			t.Errorf("webhook_outbox is in HEAD with %d rows", n)
+		}
+		status, err := store.Status(ctx)
+		if err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+		if len(status.Staged)+len(status.Unstaged) != 0 {
+			t.Errorf("Status after commit = %+v, want clean", status)
+		}
+		all, err := store.ListWebhookDeliveries(ctx, "", "", 10)
+		if err != nil {
+			t.Fatalf("ListWebhookDeliveries: %v", err)
+		}
+		if len(all) != wantRows {
+			t.Errorf("got %d local deliveries, want %d", len(all), wantRows)
+		}
+	}
+
+	issue := &types.Issue{Title: "queued", Status: types.StatusOpen, Priority: 2, IssueType: types.TypeTask}
+	if err := store.CreateIssue(ctx, issue, "tester"); err != nil {
+		t.Fatalf("CreateIssue: %v", err)
+	}
+	if err := store.EnqueueWebhookDelivery(ctx, &types.WebhookDelivery{Webhook: "ci", Event: "create", IssueID: issue.ID, Payload: "{}"}); err != nil {
+		t.Fatalf("EnqueueWebhookDelivery: %v", err)
+	}
+	if err := store.Commit(ctx, "test: commit with a queued delivery"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	assertLocalOnly(t, 1)
+
+	// A database that committed the outbox before it was ignored has it
+	// untracked by the migration, keeping the local rows
+	exec := func(query string) {
+		t.Helper()
+		if _, err := store.db.ExecContext(ctx, query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	exec("DELETE FROM dolt_ignore WHERE pattern = 'webhook_outbox'")
+	exec("CALL DOLT_COMMIT('-Am', 'test: commit the outbox')")
+	var n int
+	if err := store.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_outbox AS OF 'HEAD'").Scan(&n); err != nil || n != 1 {
+		t.Fatalf("setup: webhook_outbox AS OF HEAD = %d, %v", n, err)
+	}
+	update := &types.WebhookDelivery{Webhook: "ci", Event: "update", Payload: "{}"}
+	if err := store.EnqueueWebhookDelivery(ctx, update); err != nil {
+		t.Fatalf("EnqueueWebhookDelivery: %v", err)
+	}
+	// RunMigrations commits the deletion
+	if err := RunMigrations(store.db); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	assertLocalOnly(t, 2)
+
+	next := &types.WebhookDelivery{Webhook: "ci", Event: "close", Payload: "{}"}
+	if err := store.EnqueueWebhookDelivery(ctx, next); err != nil {
+		t.Fatalf("EnqueueWebhookDelivery: %v", err)
+	}
+	if next.ID <= update.ID {
+		t.Errorf("delivery ID %d reused after untracking (last was %d)", next.ID, update.ID)
+	}
+}
@@ -93,7 +93,7 @@ type Issue struct {
 	Crystallizes bool         `json:"crystallizes,omitempty"`  // Work that compounds (true: code, features) vs evaporates (false: ops, support) - affects CV weighting per Decision 006
 
 	// ===== Gate Fields (async coordination primitives) =====
-	AwaitType string        `json:"await_type,omitempty"` // Condition type: gh:run, gh:pr, timer, human, mail
+	AwaitType string        `json:"await_type,omitempty"` // Condition type: gh:run, gh:pr, gl:pipeline, gl:mr, timer, human, mail, bead, query, cmd, approval
 	AwaitID   string        `json:"await_id,omitempty"`   // Condition identifier (run ID, PR number, etc.)
 	Timeout   time.Duration `json:"timeout,omitempty"`    // Max wait time before escalation
 	Waiters   []string      `json:"waiters,omitempty"`    // Mail addresses to notify when gate clears
@@ -170,6 +170,7 @@ func (i *Issue) ComputeContentHash() string {
 		w.str(v.Outcome)
 		w.str(v.Timestamp.Format(time.RFC3339))
 		w.float32Ptr(v.Score)
+		w.str(v.Reason)
 	}
 
 	// HOP aggregate quality score and crystallizes
@@ -730,7 +731,18 @@ func (d DependencyType) IsWellKnown() bool {
 // AffectsReadyWork returns true if this dependency type blocks work.
 // Only blocking types affect the ready work calculation.
 func (d DependencyType) AffectsReadyWork() bool {
-	return d == DepBlocks || d == DepParentChild || d == DepConditionalBlocks || d == DepWaitsFor
+	for _, t := range ReadyWorkDependencyTypes() {
+		if d == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadyWorkDependencyTypes returns the dependency types for which
+// AffectsReadyWork is true.
+func ReadyWorkDependencyTypes() []DependencyType {
+	return []DependencyType{DepBlocks, DepParentChild, DepConditionalBlocks, DepWaitsFor}
 }
 
 // WaitsForMeta holds metadata for waits-for dependencies (fanout gates).
@@ -840,8 +852,46 @@ const (
 	EventLabelAdded        EventType = "label_added"
 	EventLabelRemoved      EventType = "label_removed"
 	EventCompacted         EventType = "compacted"
+	EventValidated         EventType = "validated"
 )
 
+// WebhookDelivery is one event queued for delivery to a configured webhook.
+// Deliveries stay in the outbox after they are sent, so they can be listed
+// and replayed.
+type WebhookDelivery struct {
+	ID            int64                 `json:"id"`
+	Webhook       string                `json:"webhook"` // Name of the webhook in config.yaml
+	Event         string                `json:"event"`
+	IssueID       string                `json:"issue_id,omitempty"`
+	Payload       string                `json:"payload"` // JSON body, sent as-is
+	Status        WebhookDeliveryStatus `json:"status"`
+	Attempts      int                   `json:"attempts"`
+	NextAttemptAt time.Time             `json:"next_attempt_at"`
+	LastStatus    int                   `json:"last_status,omitempty"` // HTTP status of the last attempt
+	LastError     string                `json:"last_error,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	DeliveredAt   *time.Time            `json:"delivered_at,omitempty"`
+}
+
+// WebhookDeliveryStatus is the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+// Webhook delivery states
+const (
+	WebhookPending   WebhookDeliveryStatus = "pending"   // Waiting for its next attempt
+	WebhookDelivered WebhookDeliveryStatus = "delivered" // Accepted with a 2xx response
+	WebhookDead      WebhookDeliveryStatus = "dead"      // Out of attempts; replay to retry
+)
+
+// IsValid checks if the delivery status value is valid
+func (s WebhookDeliveryStatus) IsValid() bool {
+	switch s {
+	case WebhookPending, WebhookDelivered, WebhookDead:
+		return true
+	}
+	return false
+}
+
 // BlockedIssue extends Issue with blocking information
 type BlockedIssue struct {
 	Issue
@@ -890,6 +940,7 @@ type IssueFilter struct {
 	Priority     *int
 	IssueType    *IssueType
 	Assignee     *string
+	Owner        *string
 	Labels       []string // AND semantics: issue must have ALL these labels
 	LabelsAny    []string // OR semantics: issue must have AT LEAST ONE of these labels
 	LabelPattern string   // Glob pattern for label matching (e.g., "tech-*")
@@ -957,6 +1008,184 @@ type IssueFilter struct {
 	DueAfter    *time.Time // Filter issues with due_at > this time
 	DueBefore   *time.Time // Filter issues with due_at < this time
 	Overdue     bool       // Filter issues where due_at < now AND status != closed
+	NoDue       bool       // Filter issues with no due_at
+	NoDefer     bool       // Filter issues with no defer_until
+
+	// Estimate filtering (estimated_minutes, inclusive)
+	EstimateMin *int
+	EstimateMax *int
+	NoEstimate  bool // Filter issues with no estimate
+
+	// External integration filtering
+	ExternalRef       *string // Exact external_ref match
+	ExternalRefPrefix string  // external_ref prefix (e.g., "gh-")
+	NoExternalRef     bool    // Filter issues with no external_ref
+	SourceSystem      *string // Filter by source_system (federation adapter)
+
+	// Comment count filtering (inclusive)
+	CommentsMin *int
+	CommentsMax *int
+
+	// Derived dependency state (nil = any)
+	Blocked         *bool // Has an active blocking dependency
+	Ready           *bool // Ready work, as GetReadyWork with an empty WorkFilter
+	HasDependencies *bool // Has at least one dependency whose type AffectsReadyWork
+
+	// Dependency graph relationships (all must hold)
+	Graph []GraphFilter
+
+	// Boolean combination of further filters (OR, NOT), ANDed with the above
+	Where *FilterExpr
+
+	// Result ordering (nil = default priority ASC, created_at DESC)
+	OrderBy []IssueOrder
+}
+
+// FilterExpr is a boolean expression over IssueFilters. Storage renders it
+// into the SQL WHERE clause, so queries with OR and NOT run in the database.
+// Exactly one field is set; Match leaves ignore Limit and OrderBy.
+type FilterExpr struct {
+	Match *IssueFilter  // Issues matching every condition of the filter
+	And   []*FilterExpr // All must hold (empty = true)
+	Or    []*FilterExpr // At least one must hold (empty = false)
+	Not   *FilterExpr   // Must not hold
+}
+
+// GraphRelation names a dependency-graph relationship usable in IssueFilter.Graph.
+type GraphRelation string
+
+// Graph relations
+const (
+	GraphBlockedBy     GraphRelation = "blocked_by"      // Issues with a 'blocks' dependency on Target
+	GraphBlocks        GraphRelation = "blocks"          // Issues Target has a 'blocks' dependency on
+	GraphDescendantOf  GraphRelation = "descendant_of"   // Transitive parent-child descendants of Target
+	GraphRelatedTo     GraphRelation = "related_to"      // 'related'/'relates-to' links in either direction
+	GraphDependsOnType GraphRelation = "depends_on_type" // Issues with an outgoing dependency of type Target
+	GraphInCycle       GraphRelation = "in_cycle"        // Issues on a 'blocks' cycle (Target unused)
+)
+
+// IsValid checks if the graph relation is known.
+func (r GraphRelation) IsValid() bool {
+	switch r {
+	case GraphBlockedBy, GraphBlocks, GraphDescendantOf, GraphRelatedTo, GraphDependsOnType, GraphInCycle:
+		return true
+	}
+	return false
+}
+
+// GraphFilter restricts results to issues that stand in Relation to Target.
+// Negate inverts the match (issues NOT in the relation).
+type GraphFilter struct {
+	Relation GraphRelation
+	Target   string
+	Negate   bool
+}
+
+// IssueOrder is a single ORDER BY term for SearchIssues.
+// Column must be one of the sortable issue columns (see IsSortableIssueColumn).
+type IssueOrder struct {
+	Column string
+	Desc   bool
+}
+
+// sortableIssueColumns whitelists columns that may appear in IssueFilter.OrderBy.
+// Storage backends interpolate these into SQL, so anything else is rejected.
+var sortableIssueColumns = map[string]bool{
+	"id":                true,
+	"title":             true,
+	"status":            true,
+	"priority":          true,
+	"issue_type":        true,
+	"assignee":          true,
+	"owner":             true,
+	"created_at":        true,
+	"updated_at":        true,
+	"closed_at":         true,
+	"due_at":            true,
+	"defer_until":       true,
+	"estimated_minutes": true,
+}
+
+// IsSortableIssueColumn reports whether column may be used in IssueFilter.OrderBy.
+func IsSortableIssueColumn(column string) bool {
+	return sortableIssueColumns[column]
+}
+
+// IssueGrouping is a GROUP BY over issues, run in storage. Groups are
+// ordered by OrderBy, then by their keys ascending.
+type IssueGrouping struct {
+	Keys       []GroupKey
+	Aggregates []GroupAggregate
+	OrderBy    []GroupOrder
+	Limit      int       // Maximum number of groups (0 = all)
+	Now        time.Time // Reference time for the "age" aggregate
+}
+
+// GroupKey is a grouping dimension: an issue column, or the issue's labels.
+// An issue with several labels is in the group of each. With LabelPrefix set
+// only labels with that prefix count, and the key is the rest of the label.
+// Issues without a value are grouped under the empty key.
+type GroupKey struct {
+	Column      string // A groupable issue column (see IsGroupableIssueColumn), unless Labels
+	Labels      bool
+	LabelPrefix string
+}
+
+// GroupAggregate is count (empty Column), or sum, avg, min or max of an
+// aggregatable issue column (see IsAggregatableIssueColumn).
+type GroupAggregate struct {
+	Func   string
+	Column string
+}
+
+// GroupOrder orders groups by a result column: the keys, then the aggregates.
+type GroupOrder struct {
+	Index int
+	Desc  bool
+}
+
+// IssueGroup is one group of a grouped search.
+type IssueGroup struct {
+	Keys   []string
+	Values []any // Per aggregate: int for count, float64 or time.Time, nil if no issue has a value
+}
+
+// groupableIssueColumns whitelists columns that may appear in GroupKey.Column.
+var groupableIssueColumns = map[string]bool{
+	"status":        true,
+	"priority":      true,
+	"issue_type":    true,
+	"assignee":      true,
+	"owner":         true,
+	"mol_type":      true,
+	"wisp_type":     true,
+	"source_system": true,
+}
+
+// IsGroupableIssueColumn reports whether column may be used in GroupKey.Column.
+func IsGroupableIssueColumn(column string) bool {
+	return groupableIssueColumns[column]
+}
+
+// aggregatableIssueColumns whitelists columns that may appear in
+// GroupAggregate.Column, mapped to whether they are timestamps (min and max
+// only). "age" is days since created_at.
+var aggregatableIssueColumns = map[string]bool{
+	"estimated_minutes": false,
+	"priority":          false,
+	"age":               false,
+	"created_at":        true,
+	"updated_at":        true,
+	"closed_at":         true,
+	"due_at":            true,
+	"defer_until":       true,
+}
+
+// IsAggregatableIssueColumn reports whether column may be used in
+// GroupAggregate.Column, and whether it is a timestamp.
+func IsAggregatableIssueColumn(column string) (ok, isTime bool) {
+	isTime, ok = aggregatableIssueColumns[column]
+	return ok, isTime
 }
 
 // SortPolicy determines how ready work is ordered
@@ -976,17 +1205,36 @@ const (
 	// SortPolicyOldest always sorts by creation date (oldest first)
 	// Use for backlog clearing, preventing issue starvation
 	SortPolicyOldest SortPolicy = "oldest"
+
+	// SortPolicyScore ranks by a weighted score of priority, age, due date,
+	// transitively unblocked dependents, and estimate (shorter first).
+	// Weights are configured under ready.score.* in config.yaml.
+	SortPolicyScore SortPolicy = "score"
 )
 
 // IsValid checks if the sort policy value is valid
 func (s SortPolicy) IsValid() bool {
 	switch s {
-	case SortPolicyHybrid, SortPolicyPriority, SortPolicyOldest, "":
+	case SortPolicyHybrid, SortPolicyPriority, SortPolicyOldest, SortPolicyScore, "":
 		return true
 	}
 	return false
 }
 
+// ReadyWorkExcludedTypes are the workflow/identity issue types left out of
+// ready work unless a WorkFilter asks for them by Type. These are internal
+// items, not actionable work for agents to claim:
+//   - merge-request: processed by Refinery
+//   - gate: async wait conditions
+//   - molecule: workflow containers
+//   - message: mail/communication items
+//   - agent: identity/state tracking beads
+//   - role: agent role definitions (reference metadata)
+//   - rig: rig identity beads (reference metadata)
+func ReadyWorkExcludedTypes() []IssueType {
+	return []IssueType{"merge-request", "gate", "molecule", TypeMessage, "agent", "role", "rig"}
+}
+
 // WorkFilter is used to filter ready work queries
 type WorkFilter struct {
 	Status       Status
@@ -1022,6 +1270,12 @@ type WorkFilter struct {
 	// By default, GetReadyWork excludes mol/wisp steps (IDs containing -mol- or -wisp-)
 	// Set to true for internal callers that need to see mol steps (e.g., findGateReadyMolecules)
 	IncludeMolSteps bool
+
+	// Further conditions ANDed with the ready-work rules (e.g. a saved view)
+	Where *FilterExpr
+
+	// Result ordering (nil = default priority ASC, created_at DESC)
+	OrderBy []IssueOrder
 }
 
 // StaleFilter is used to filter stale issue queries
@@ -1151,6 +1405,9 @@ type Validation struct {
 
 	// Score is an optional quality score (0.0-1.0)
 	Score *float32 `json:"score,omitempty"`
+
+	// Reason optionally explains the outcome (e.g., why the work was rejected)
+	Reason string `json:"reason,omitempty"`
 }
 
 // Validation outcome constants
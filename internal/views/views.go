@@ -0,0 +1,298 @@
+// Package views manages saved views: named queries shared by bd list,
+// bd ready and bd count.
+//
+// A view bundles a query (see internal/query), an optional sort, table
+// columns and an output format. Views live in .beads/views/<name>.toml so
+// they can be checked into the repository, or in config.yaml under "views:"
+// for personal use. A file view overrides a config view of the same name.
+//
+// Example .beads/views/triage.toml:
+//
+//	description = "Unassigned open bugs"
+//	query = "status=open AND type=bug AND assignee=none"
+//	sort = "priority, created desc"
+//	columns = ["id", "priority", "title", "created"]
+//	format = "table"
+package views
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/steveyegge/beads/internal/query"
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// DirName is the directory under .beads that holds view files.
+const DirName = "views"
+
+// SourceConfig marks views defined in config.yaml.
+const SourceConfig = "config.yaml"
+
+// Output formats
+const (
+	FormatCompact = "compact" // One line per issue (default)
+	FormatLong    = "long"    // Multi-line details
+	FormatTable   = "table"   // Aligned columns (see Columns)
+	FormatJSON    = "json"    // Same as --json
+)
+
+// DefaultColumns are used by the table format when a view sets no columns.
+var DefaultColumns = []string{"id", "priority", "status", "title"}
+
+// columnNames lists the columns a view can show in table format.
+var columnNames = []string{
+	"id", "title", "status", "priority", "type", "assignee", "owner",
+	"labels", "created", "updated", "closed", "due", "defer", "estimate",
+}
+
+// View is a saved query with presentation settings.
+type View struct {
+	Name        string   `toml:"-" json:"name" mapstructure:"-"`
+	Description string   `toml:"description,omitempty" json:"description,omitempty" mapstructure:"description"`
+	Query       string   `toml:"query" json:"query" mapstructure:"query"`
+	Sort        string   `toml:"sort,omitempty" json:"sort,omitempty" mapstructure:"sort"` // ORDER BY terms, e.g. "priority, updated desc"
+	Columns     []string `toml:"columns,omitempty" json:"columns,omitempty" mapstructure:"columns"`
+	Format      string   `toml:"format,omitempty" json:"format,omitempty" mapstructure:"format"`
+
+	// Source is the file the view was loaded from, or SourceConfig.
+	Source string `toml:"-" json:"source" mapstructure:"-"`
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateName checks that a view name is usable as a file name.
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid view name %q (use lowercase letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// QueryString returns the full query text, with Sort appended as ORDER BY.
+func (v *View) QueryString() string {
+	if v.Sort == "" {
+		return v.Query
+	}
+	return v.Query + " ORDER BY " + v.Sort
+}
+
+// Compile parses and evaluates the view's query so invalid views are
+// rejected when saved rather than when used.
+func (v *View) Compile() (*query.Query, error) {
+	if strings.TrimSpace(v.Query) == "" {
+		return nil, fmt.Errorf("view %s has no query", v.Name)
+	}
+	q, err := query.ParseQuery(v.Query)
+	if err != nil {
+		return nil, fmt.Errorf("view %s: %w", v.Name, err)
+	}
+	if v.Sort != "" {
+		if len(q.OrderBy) > 0 || q.Limit > 0 {
+			return nil, fmt.Errorf("view %s: set sort or ORDER BY/LIMIT in the query, not both", v.Name)
+		}
+		if err := q.SetOrderBy(v.Sort); err != nil {
+			return nil, fmt.Errorf("view %s: sort: %w", v.Name, err)
+		}
+	}
+	if _, err := query.NewEvaluator(time.Now()).EvaluateQuery(q); err != nil {
+		return nil, fmt.Errorf("view %s: %w", v.Name, err)
+	}
+
+	switch v.Format {
+	case "", FormatCompact, FormatLong, FormatTable, FormatJSON:
+	default:
+		return nil, fmt.Errorf("view %s: invalid format %q (valid: compact, long, table, json)", v.Name, v.Format)
+	}
+	for _, col := range v.Columns {
+		if !slices.Contains(columnNames, col) {
+			return nil, fmt.Errorf("view %s: unknown column %q (valid: %s)", v.Name, col, strings.Join(columnNames, ", "))
+		}
+	}
+	return q, nil
+}
+
+// TableColumns returns the columns to show in table format.
+func (v *View) TableColumns() []string {
+	if len(v.Columns) == 0 {
+		return DefaultColumns
+	}
+	return v.Columns
+}
+
+// Dir returns the views directory for a .beads directory.
+func Dir(beadsDir string) string {
+	return filepath.Join(beadsDir, DirName)
+}
+
+// Load returns all views: those from config (may be nil) overridden by
+// view files in beadsDir/views.
+func Load(beadsDir string, fromConfig map[string]*View) (map[string]*View, error) {
+	result := make(map[string]*View, len(fromConfig))
+	for name, v := range fromConfig {
+		if v == nil {
+			continue
+		}
+		cv := *v
+		cv.Name = name
+		cv.Source = SourceConfig
+		result[name] = &cv
+	}
+
+	if beadsDir == "" {
+		return result, nil
+	}
+	entries, err := os.ReadDir(Dir(beadsDir))
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read views directory: %w", err)
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".toml")
+		if entry.IsDir() || !ok {
+			continue
+		}
+		v, err := loadFile(filepath.Join(Dir(beadsDir), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		v.Name = name
+		result[name] = v
+	}
+	return result, nil
+}
+
+func loadFile(path string) (*View, error) {
+	var v View
+	if _, err := toml.DecodeFile(path, &v); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	v.Source = path
+	return &v, nil
+}
+
+// Get looks up a single view by name.
+func Get(beadsDir string, fromConfig map[string]*View, name string) (*View, error) {
+	all, err := Load(beadsDir, fromConfig)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := all[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown view: %s (see bd views list)", name)
+	}
+	return v, nil
+}
+
+// Names returns the sorted names of all views.
+func Names(all map[string]*View) []string {
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save validates a view and writes it to beadsDir/views/<name>.toml,
+// returning the path written.
+func Save(beadsDir string, v *View) (string, error) {
+	if err := ValidateName(v.Name); err != nil {
+		return "", err
+	}
+	if _, err := v.Compile(); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return "", fmt.Errorf("encode view: %w", err)
+	}
+	if err := os.MkdirAll(Dir(beadsDir), 0o755); err != nil {
+		return "", fmt.Errorf("create views directory: %w", err)
+	}
+	path := filepath.Join(Dir(beadsDir), v.Name+".toml")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil { // #nosec G306 -- views are meant to be committed and shared
+		return "", fmt.Errorf("write view: %w", err)
+	}
+	return path, nil
+}
+
+// Remove deletes the view file for name. Views defined only in config.yaml
+// cannot be removed this way.
+func Remove(beadsDir string, name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	path := filepath.Join(Dir(beadsDir), name+".toml")
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("no view file for %s", name)
+		}
+		return "", fmt.Errorf("remove view: %w", err)
+	}
+	return path, nil
+}
+
+// ColumnValue renders an issue field for table output.
+func ColumnValue(issue *types.Issue, column string) string {
+	formatTime := func(t *time.Time) string {
+		if t == nil || t.IsZero() {
+			return "-"
+		}
+		return t.Local().Format("2006-01-02")
+	}
+
+	switch column {
+	case "id":
+		return issue.ID
+	case "title":
+		return issue.Title
+	case "status":
+		return string(issue.Status)
+	case "priority":
+		return fmt.Sprintf("P%d", issue.Priority)
+	case "type":
+		return string(issue.IssueType)
+	case "assignee":
+		return orDash(issue.Assignee)
+	case "owner":
+		return orDash(issue.Owner)
+	case "labels":
+		return orDash(strings.Join(issue.Labels, ","))
+	case "created":
+		return formatTime(&issue.CreatedAt)
+	case "updated":
+		return formatTime(&issue.UpdatedAt)
+	case "closed":
+		return formatTime(issue.ClosedAt)
+	case "due":
+		return formatTime(issue.DueAt)
+	case "defer":
+		return formatTime(issue.DeferUntil)
+	case "estimate":
+		if issue.EstimatedMinutes == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%dm", *issue.EstimatedMinutes)
+	default:
+		return ""
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,189 @@
+package views
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestSaveLoadRemove(t *testing.T) {
+	beadsDir := t.TempDir()
+
+	v := &View{
+		Name:        "triage",
+		Description: "Unassigned open bugs",
+		Query:       "status=open AND type=bug AND assignee=none",
+		Sort:        "priority, created desc",
+		Columns:     []string{"id", "priority", "title"},
+		Format:      FormatTable,
+	}
+	path, err := Save(beadsDir, v)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if want := filepath.Join(beadsDir, "views", "triage.toml"); path != want {
+		t.Errorf("Save path = %q, want %q", path, want)
+	}
+
+	got, err := Get(beadsDir, nil, "triage")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Name != "triage" || got.Query != v.Query || got.Sort != v.Sort || got.Format != FormatTable {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+	if strings.Join(got.Columns, ",") != "id,priority,title" {
+		t.Errorf("Columns = %v", got.Columns)
+	}
+	if got.Source != path {
+		t.Errorf("Source = %q, want %q", got.Source, path)
+	}
+
+	if _, err := Remove(beadsDir, "triage"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := Get(beadsDir, nil, "triage"); err == nil {
+		t.Error("Get after Remove: expected error")
+	}
+	if _, err := Remove(beadsDir, "triage"); err == nil {
+		t.Error("second Remove: expected error")
+	}
+}
+
+func TestLoadConfigOverride(t *testing.T) {
+	beadsDir := t.TempDir()
+	if err := os.MkdirAll(Dir(beadsDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(Dir(beadsDir), "mine.toml"), []byte(`query = "assignee=bob"`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Non-view files are ignored
+	if err := os.WriteFile(filepath.Join(Dir(beadsDir), "README.md"), []byte("notes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromConfig := map[string]*View{
+		"mine":   {Query: "assignee=alice"},
+		"urgent": {Query: "priority=0"},
+	}
+	all, err := Load(beadsDir, fromConfig)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := strings.Join(Names(all), ","); got != "mine,urgent" {
+		t.Errorf("Names = %s, want mine,urgent", got)
+	}
+	if all["mine"].Query != "assignee=bob" {
+		t.Errorf("file view should override config view, got query %q", all["mine"].Query)
+	}
+	if all["urgent"].Source != SourceConfig || all["urgent"].Name != "urgent" {
+		t.Errorf("config view = %+v", all["urgent"])
+	}
+	if fromConfig["urgent"].Source != "" {
+		t.Error("Load must not modify the config map")
+	}
+
+	// A missing views directory is not an error
+	if all, err := Load(t.TempDir(), nil); err != nil || len(all) != 0 {
+		t.Errorf("Load(empty) = %v, %v", all, err)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		view    View
+		wantErr string
+	}{
+		{name: "simple", view: View{Query: "status=open"}},
+		{name: "sort", view: View{Query: "status=open", Sort: "priority desc"}},
+		{name: "group by", view: View{Query: "GROUP BY owner, count()"}},
+		{name: "empty", view: View{Query: "  "}, wantErr: "no query"},
+		{name: "bad syntax", view: View{Query: "status="}, wantErr: "view"},
+		{name: "unknown field", view: View{Query: "colour=red"}, wantErr: "colour"},
+		{name: "sort and order by", view: View{Query: "status=open ORDER BY id", Sort: "priority"}, wantErr: "not both"},
+		{name: "sort with limit", view: View{Query: "status=open LIMIT 5", Sort: "priority"}, wantErr: "not both"},
+		{name: "bad sort", view: View{Query: "status=open", Sort: "priority,"}, wantErr: "sort"},
+		{name: "group sort on non-group column", view: View{Query: "GROUP BY owner, count()", Sort: "priority"}, wantErr: "view"},
+		{name: "bad format", view: View{Query: "status=open", Format: "csv"}, wantErr: "invalid format"},
+		{name: "bad column", view: View{Query: "status=open", Columns: []string{"id", "color"}}, wantErr: "unknown column"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tt.view
+			v.Name = "test"
+			q, err := v.Compile()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Compile: %v", err)
+				}
+				if q == nil {
+					t.Fatal("Compile returned nil query")
+				}
+				if v.Sort != "" && len(q.OrderBy) == 0 {
+					t.Errorf("Compile dropped sort %q", v.Sort)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Compile error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveRejectsInvalid(t *testing.T) {
+	beadsDir := t.TempDir()
+	for _, name := range []string{"", "Triage", "../x", "a b"} {
+		if _, err := Save(beadsDir, &View{Name: name, Query: "status=open"}); err == nil {
+			t.Errorf("Save(%q): expected name error", name)
+		}
+	}
+	if _, err := Save(beadsDir, &View{Name: "bad", Query: "status="}); err == nil {
+		t.Error("Save with invalid query: expected error")
+	}
+	if _, err := os.Stat(Dir(beadsDir)); !os.IsNotExist(err) {
+		t.Error("invalid views must not create the views directory")
+	}
+}
+
+func TestColumnValue(t *testing.T) {
+	due := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	est := 90
+	issue := &types.Issue{
+		ID:               "bd-1",
+		Title:            "Fix login",
+		Status:           types.StatusOpen,
+		Priority:         1,
+		IssueType:        types.TypeBug,
+		Assignee:         "alice",
+		Labels:           []string{"auth", "web"},
+		DueAt:            &due,
+		EstimatedMinutes: &est,
+	}
+
+	tests := map[string]string{
+		"id":       "bd-1",
+		"priority": "P1",
+		"status":   "open",
+		"type":     "bug",
+		"assignee": "alice",
+		"owner":    "-",
+		"labels":   "auth,web",
+		"due":      "2026-03-01",
+		"defer":    "-",
+		"closed":   "-",
+		"estimate": "90m",
+	}
+	for col, want := range tests {
+		if got := ColumnValue(issue, col); got != want {
+			t.Errorf("ColumnValue(%s) = %q, want %q", col, got, want)
+		}
+	}
+}
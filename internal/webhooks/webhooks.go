@@ -0,0 +1,272 @@
+// Package webhooks delivers bd events to HTTP endpoints configured in
+// config.yaml. Events are queued in an outbox in the database and sent from
+// there, so a delivery that fails, or is cut short by bd exiting, is retried
+// by a later command. Payloads are signed with HMAC-SHA256 so receivers can
+// check they came from bd.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+// Request headers sent with every delivery
+const (
+	SignatureHeader = "X-Beads-Signature" // sha256=<hex HMAC of the body>
+	EventHeader     = "X-Beads-Event"     // The event name, e.g. status_changed
+	DeliveryHeader  = "X-Beads-Delivery"  // The outbox ID, unchanged by retries and replays
+)
+
+// Webhook is an endpoint configured under webhooks.<name> in config.yaml:
+//
+//	webhooks:
+//	  ci:
+//	    url: https://ci.example.com/beads
+//	    events: [close, status_changed]
+//	    secret_env: CI_WEBHOOK_SECRET
+//
+// With no events, the webhook receives every event.
+type Webhook struct {
+	Name      string   `json:"name" mapstructure:"-"`
+	URL       string   `json:"url" mapstructure:"url"`
+	Events    []string `json:"events,omitempty" mapstructure:"events"`
+	Secret    string   `json:"-" mapstructure:"secret"`
+	SecretEnv string   `json:"secret_env,omitempty" mapstructure:"secret_env"` // Read the secret from this environment variable
+}
+
+// Validate checks that the webhook has an http or https URL.
+func (w *Webhook) Validate() error {
+	if w.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url must be http or https, got %q", w.URL)
+	}
+	return nil
+}
+
+// Wants reports whether the webhook subscribes to an event.
+func (w *Webhook) Wants(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, "*") || slices.Contains(w.Events, event)
+}
+
+// SigningSecret returns the webhook's secret: Secret, else the value of
+// SecretEnv. Deliveries are unsigned when it is empty.
+func (w *Webhook) SigningSecret() string {
+	if w.Secret != "" {
+		return w.Secret
+	}
+	if w.SecretEnv != "" {
+		return os.Getenv(w.SecretEnv)
+	}
+	return ""
+}
+
+// Sign returns the signature header value for a body: "sha256=" followed by
+// the hex HMAC-SHA256 of the body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body under secret.
+// Receivers written in Go can use it to check a delivery.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Send posts a delivery's payload to the webhook. It returns the response's
+// HTTP status, and an error unless the status is 2xx.
+func Send(ctx context.Context, client *http.Client, w *Webhook, d *types.WebhookDelivery) (int, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "beads-webhook")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(d.ID, 10))
+	if secret := w.SigningSecret(); secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512)) // Best effort: only used in the error message
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(snippet))
+		if msg == "" {
+			return resp.StatusCode, fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, msg)
+	}
+	return resp.StatusCode, nil
+}
+
+// RetryPolicy controls how often a failing delivery is retried.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts before a delivery is dead-lettered
+	Backoff     time.Duration // Delay before the first retry, doubled for each one after
+	MaxBackoff  time.Duration // Cap on the delay
+}
+
+// DefaultRetryPolicy is used for settings left unset.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 8, Backoff: 30 * time.Second, MaxBackoff: time.Hour}
+
+// Delay returns how long to wait before retrying a delivery that has failed
+// attempts times.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.Backoff
+	if delay <= 0 {
+		delay = DefaultRetryPolicy.Backoff
+	}
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+// RecordAttempt updates d with the outcome of an attempt made at now: sent
+// on success, else scheduled for a retry, or dead once it is out of
+// attempts.
+func (p RetryPolicy) RecordAttempt(d *types.WebhookDelivery, status int, err error, now time.Time) {
+	d.Attempts++
+	d.LastStatus = status
+	if err == nil {
+		d.Status = types.WebhookDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+		return
+	}
+	d.LastError = err.Error()
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if d.Attempts >= maxAttempts {
+		d.Status = types.WebhookDead
+		return
+	}
+	d.Status = types.WebhookPending
+	d.NextAttemptAt = now.Add(p.Delay(d.Attempts))
+}
+
+// Outbox is the store the deliveries are queued in.
+type Outbox interface {
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*types.WebhookDelivery, error)
+	ClaimWebhookDelivery(ctx context.Context, id int64, now time.Time, lease time.Duration) (bool, error)
+	UpdateWebhookDelivery(ctx context.Context, d *types.WebhookDelivery) error
+}
+
+// defaultLease is how long a delivery stays claimed when the client has no
+// timeout.
+const defaultLease = 5 * time.Minute
+
+// LeaseFor returns how long to claim a delivery sent with client: twice the
+// client timeout, so the lease outlasts the attempt.
+func LeaseFor(client *http.Client) time.Duration {
+	if client.Timeout > 0 {
+		return 2 * client.Timeout
+	}
+	return defaultLease
+}
+
+// Result counts the outcomes of a Deliver pass.
+type Result struct {
+	Delivered int
+	Retrying  int // Failed, and scheduled for another attempt
+	Dead      int // Failed for the last time
+}
+
+// Deliver makes one pass over the deliveries due in the outbox, up to limit,
+// sending each to its webhook and recording the outcome. Each delivery is
+// claimed before it is sent, so concurrent passes (from other bd processes)
+// never send the same one; deliveries another pass has claimed are skipped.
+// Once a delivery to a webhook fails, the webhook's other deliveries wait for
+// the next pass, so an unreachable endpoint costs at most one timeout.
+// Deliveries for webhooks no longer configured are dead-lettered. An attempt
+// cut short by ctx is not recorded; the delivery is sent again once its
+// lease lapses.
+func Deliver(ctx context.Context, outbox Outbox, hooks map[string]*Webhook, client *http.Client, policy RetryPolicy, limit int) (Result, error) {
+	var result Result
+	due, err := outbox.GetDueWebhookDeliveries(ctx, time.Now(), limit)
+	if err != nil {
+		return result, err
+	}
+
+	lease := LeaseFor(client)
+	failing := make(map[string]bool)
+	for _, d := range due {
+		if failing[d.Webhook] {
+			continue
+		}
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		claimed, err := outbox.ClaimWebhookDelivery(ctx, d.ID, time.Now(), lease)
+		if err != nil {
+			return result, err
+		}
+		if !claimed {
+			continue
+		}
+
+		w := hooks[d.Webhook]
+		if w == nil {
+			d.Attempts++
+			d.Status = types.WebhookDead
+			d.LastError = fmt.Sprintf("webhook %q is not configured", d.Webhook)
+		} else {
+			status, sendErr := Send(ctx, client, w, d)
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			policy.RecordAttempt(d, status, sendErr, time.Now().UTC())
+			if sendErr != nil {
+				failing[d.Webhook] = true
+			}
+		}
+		if err := outbox.UpdateWebhookDelivery(ctx, d); err != nil {
+			return result, err
+		}
+
+		switch d.Status {
+		case types.WebhookDelivered:
+			result.Delivered++
+		case types.WebhookDead:
+			result.Dead++
+		default:
+			result.Retrying++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,353 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/beads/internal/types"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"close"}`)
+	sig := Sign("s3cret", body)
+	// echo -n '{"event":"close"}' | openssl dgst -sha256 -hmac s3cret
+	want := "sha256=5df3380ceaee007558d13d1dbf4c4167e810e73b57b482cb85cf8e6869e18487"
+	if sig != want {
+		t.Fatalf("Sign() = %q, want %q", sig, want)
+	}
+	if !Verify("s3cret", body, sig) {
+		t.Error("Verify() rejected a valid signature")
+	}
+	if Verify("other", body, sig) {
+		t.Error("Verify() accepted a signature under the wrong secret")
+	}
+	if Verify("s3cret", []byte(`{"event":"open"}`), sig) {
+		t.Error("Verify() accepted a signature for a different body")
+	}
+}
+
+func TestWebhookWants(t *testing.T) {
+	tests := []struct {
+		events []string
+		event  string
+		want   bool
+	}{
+		{nil, "close", true},
+		{[]string{"*"}, "update", true},
+		{[]string{"close", "status_changed"}, "close", true},
+		{[]string{"close", "status_changed"}, "update", false},
+	}
+	for _, tt := range tests {
+		w := &Webhook{Events: tt.events}
+		if got := w.Wants(tt.event); got != tt.want {
+			t.Errorf("Wants(%q) with events %v = %v, want %v", tt.event, tt.events, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+	for _, url := range []string{"", "ftp://example.com", "://bad"} {
+		if err := (&Webhook{URL: url}).Validate(); err == nil {
+			t.Errorf("Validate() accepted url %q", url)
+		}
+	}
+	if err := (&Webhook{URL: "https://example.com/hook"}).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestSigningSecretFromEnv(t *testing.T) {
+	t.Setenv("BD_TEST_WEBHOOK_SECRET", "from-env")
+	w := &Webhook{SecretEnv: "BD_TEST_WEBHOOK_SECRET"}
+	if got := w.SigningSecret(); got != "from-env" {
+		t.Errorf("SigningSecret() = %q, want from-env", got)
+	}
+	w.Secret = "inline"
+	if got := w.SigningSecret(); got != "inline" {
+		t.Errorf("SigningSecret() = %q, want inline", got)
+	}
+}
+
+func TestSendSignsPayload(t *testing.T) {
+	var got struct {
+		body                        []byte
+		sig, event, delivery, ctype string
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.body, _ = io.ReadAll(r.Body)
+		got.sig = r.Header.Get(SignatureHeader)
+		got.event = r.Header.Get(EventHeader)
+		got.delivery = r.Header.Get(DeliveryHeader)
+		got.ctype = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	hook := &Webhook{Name: "ci", URL: srv.URL, Secret: "s3cret"}
+	d := &types.WebhookDelivery{ID: 7, Webhook: "ci", Event: "close", Payload: `{"event":"close","issue_id":"bd-1"}`}
+	status, err := Send(context.Background(), srv.Client(), hook, d)
+	if err != nil || status != http.StatusAccepted {
+		t.Fatalf("Send() = %d, %v; want 202, nil", status, err)
+	}
+	if string(got.body) != d.Payload {
+		t.Errorf("body = %s, want %s", got.body, d.Payload)
+	}
+	if !Verify("s3cret", got.body, got.sig) {
+		t.Errorf("signature %q does not verify", got.sig)
+	}
+	if got.event != "close" || got.delivery != "7" || got.ctype != "application/json" {
+		t.Errorf("headers: event=%q delivery=%q content-type=%q", got.event, got.delivery, got.ctype)
+	}
+
+	// Without a secret the delivery is unsigned
+	hook.Secret = ""
+	if _, err := Send(context.Background(), srv.Client(), hook, d); err != nil {
+		t.Fatal(err)
+	}
+	if got.sig != "" {
+		t.Errorf("unsigned delivery sent signature %q", got.sig)
+	}
+}
+
+func TestSendNon2xxIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	d := &types.WebhookDelivery{ID: 1, Event: "close", Payload: `{}`}
+	status, err := Send(context.Background(), srv.Client(), &Webhook{URL: srv.URL}, d)
+	if status != http.StatusUnauthorized || err == nil {
+		t.Fatalf("Send() = %d, %v; want 401 and an error", status, err)
+	}
+	if err.Error() != "HTTP 401: bad signature" {
+		t.Errorf("error = %q", err)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 8, Backoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, w := range want {
+		if got := p.Delay(i + 1); got != w {
+			t.Errorf("Delay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
+
+func TestRecordAttempt(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Hour}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	d := &types.WebhookDelivery{Status: types.WebhookPending}
+
+	p.RecordAttempt(d, 500, errString("HTTP 500"), now)
+	if d.Status != types.WebhookPending || d.Attempts != 1 || !d.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("after 1st failure: %+v", d)
+	}
+	p.RecordAttempt(d, 0, errString("connection refused"), now)
+	if d.Status != types.WebhookPending || !d.NextAttemptAt.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("after 2nd failure: %+v", d)
+	}
+	p.RecordAttempt(d, 500, errString("HTTP 500"), now)
+	if d.Status != types.WebhookDead || d.Attempts != 3 || d.LastError != "HTTP 500" {
+		t.Fatalf("after last failure: %+v", d)
+	}
+
+	d = &types.WebhookDelivery{Status: types.WebhookPending, LastError: "earlier"}
+	p.RecordAttempt(d, 200, nil, now)
+	if d.Status != types.WebhookDelivered || d.DeliveredAt == nil || d.LastError != "" || d.LastStatus != 200 {
+		t.Fatalf("after success: %+v", d)
+	}
+}
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+// memOutbox is an in-memory Outbox.
+type memOutbox struct {
+	mu         sync.Mutex
+	deliveries []*types.WebhookDelivery
+	leases     map[int64]time.Time
+}
+
+func (o *memOutbox) GetDueWebhookDeliveries(_ context.Context, now time.Time, limit int) ([]*types.WebhookDelivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var due []*types.WebhookDelivery
+	for _, d := range o.deliveries {
+		if d.Status == types.WebhookPending && !d.NextAttemptAt.After(now) && !o.leases[d.ID].After(now) && len(due) < limit {
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (o *memOutbox) ClaimWebhookDelivery(_ context.Context, id int64, now time.Time, lease time.Duration) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	d := o.get(id)
+	if d == nil || d.Status != types.WebhookPending || o.leases[id].After(now) {
+		return false, nil
+	}
+	if o.leases == nil {
+		o.leases = make(map[int64]time.Time)
+	}
+	o.leases[id] = now.Add(lease)
+	return true, nil
+}
+
+func (o *memOutbox) UpdateWebhookDelivery(_ context.Context, d *types.WebhookDelivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i, existing := range o.deliveries {
+		if existing.ID == d.ID {
+			copied := *d
+			o.deliveries[i] = &copied
+			delete(o.leases, d.ID)
+		}
+	}
+	return nil
+}
+
+func (o *memOutbox) get(id int64) *types.WebhookDelivery {
+	for _, d := range o.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+func TestDeliver(t *testing.T) {
+	var received []string
+	var mu sync.Mutex
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify("s3cret", body, r.Header.Get(SignatureHeader)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		received = append(received, r.Header.Get(DeliveryHeader))
+		mu.Unlock()
+	}))
+	defer up.Close()
+	downCalls := 0
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downCalls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	hooks := map[string]*Webhook{
+		"up":   {Name: "up", URL: up.URL, Secret: "s3cret"},
+		"down": {Name: "down", URL: down.URL},
+	}
+	past := time.Now().Add(-time.Minute)
+	outbox := &memOutbox{deliveries: []*types.WebhookDelivery{
+		{ID: 1, Webhook: "up", Event: "create", Payload: `{"n":1}`, Status: types.WebhookPending, NextAttemptAt: past},
+		{ID: 2, Webhook: "down", Event: "create", Payload: `{"n":2}`, Status: types.WebhookPending, NextAttemptAt: past},
+		{ID: 3, Webhook: "down", Event: "update", Payload: `{"n":3}`, Status: types.WebhookPending, NextAttemptAt: past},
+		{ID: 4, Webhook: "up", Event: "update", Payload: `{"n":4}`, Status: types.WebhookPending, NextAttemptAt: past},
+		{ID: 5, Webhook: "removed", Event: "update", Payload: `{"n":5}`, Status: types.WebhookPending, NextAttemptAt: past},
+		{ID: 6, Webhook: "up", Event: "close", Payload: `{"n":6}`, Status: types.WebhookPending, NextAttemptAt: time.Now().Add(time.Hour)},
+	}}
+
+	policy := RetryPolicy{MaxAttempts: 2, Backoff: time.Minute}
+	result, err := Deliver(context.Background(), outbox, hooks, up.Client(), policy, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Delivered: 2, Retrying: 1, Dead: 1}) {
+		t.Errorf("result = %+v", result)
+	}
+	if len(received) != 2 || received[0] != "1" || received[1] != "4" {
+		t.Errorf("up received %v, want [1 4]", received)
+	}
+
+	// The failing webhook's second delivery waits for the next pass
+	if downCalls != 1 {
+		t.Errorf("down was called %d times, want 1", downCalls)
+	}
+	if d := outbox.get(2); d.Status != types.WebhookPending || d.Attempts != 1 || d.LastStatus != 503 {
+		t.Errorf("delivery 2 = %+v", d)
+	}
+	if d := outbox.get(3); d.Attempts != 0 {
+		t.Errorf("delivery 3 was attempted: %+v", d)
+	}
+	if d := outbox.get(5); d.Status != types.WebhookDead {
+		t.Errorf("delivery to removed webhook = %+v, want dead", d)
+	}
+	if d := outbox.get(6); d.Status != types.WebhookPending || d.Attempts != 0 {
+		t.Errorf("delivery not yet due = %+v", d)
+	}
+
+	// Once its retry is due, the failing delivery is dead-lettered
+	outbox.get(2).NextAttemptAt = past
+	if _, err := Deliver(context.Background(), outbox, hooks, up.Client(), policy, 50); err != nil {
+		t.Fatal(err)
+	}
+	if d := outbox.get(2); d.Status != types.WebhookDead || d.Attempts != 2 {
+		t.Errorf("delivery 2 after retry = %+v, want dead", d)
+	}
+}
+
+// TestDeliverClaims checks that a delivery claimed by another pass is not
+// sent again, and that an attempt cut short by the context is not recorded.
+func TestDeliverClaims(t *testing.T) {
+	var mu sync.Mutex
+	sent := make(map[string]int)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(EventHeader) == "slow" {
+			<-release
+		}
+		mu.Lock()
+		sent[r.Header.Get(DeliveryHeader)]++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	hooks := map[string]*Webhook{"ci": {Name: "ci", URL: srv.URL}}
+	past := time.Now().Add(-time.Minute)
+	outbox := &memOutbox{deliveries: []*types.WebhookDelivery{
+		{ID: 1, Webhook: "ci", Event: "create", Payload: `{}`, Status: types.WebhookPending, NextAttemptAt: past},
+		{ID: 2, Webhook: "ci", Event: "update", Payload: `{}`, Status: types.WebhookPending, NextAttemptAt: past},
+	}}
+
+	// Another process holds delivery 2
+	if claimed, _ := outbox.ClaimWebhookDelivery(context.Background(), 2, time.Now(), time.Minute); !claimed {
+		t.Fatal("could not claim delivery 2")
+	}
+	result, err := Deliver(context.Background(), outbox, hooks, srv.Client(), RetryPolicy{}, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Delivered: 1}) || sent["1"] != 1 || sent["2"] != 0 {
+		t.Errorf("result = %+v, sent = %v; want only delivery 1 sent", result, sent)
+	}
+
+	// A pass cut short leaves the delivery pending with no attempt recorded
+	outbox.deliveries = append(outbox.deliveries,
+		&types.WebhookDelivery{ID: 3, Webhook: "ci", Event: "slow", Payload: `{}`, Status: types.WebhookPending, NextAttemptAt: past})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := Deliver(ctx, outbox, hooks, srv.Client(), RetryPolicy{}, 50); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Deliver = %v, want deadline exceeded", err)
+	}
+	if d := outbox.get(3); d.Status != types.WebhookPending || d.Attempts != 0 {
+		t.Errorf("delivery 3 = %+v, want pending with no attempts", d)
+	}
+	if claimed, _ := outbox.ClaimWebhookDelivery(context.Background(), 3, time.Now(), time.Minute); claimed {
+		t.Error("delivery 3 should stay leased after the cut-short pass")
+	}
+}